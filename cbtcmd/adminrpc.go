@@ -0,0 +1,116 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+// Some admin requests need fields that the bigtable package does not expose,
+// such as fully-qualified resource names in other projects. These helpers
+// provide direct access to the admin API for those cases.
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"cloud.google.com/go/bigtable"
+	btapb "cloud.google.com/go/bigtable/admin/apiv2/adminpb"
+	"cloud.google.com/go/longrunning"
+	lroauto "cloud.google.com/go/longrunning/autogen"
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"google.golang.org/api/option"
+	gtransport "google.golang.org/api/transport/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// getAdminConn returns the connection of the run to the admin API, dialing it
+// the first time.
+func (e *env) getAdminConn() (gtransport.ConnPool, error) {
+	if e.adminConn == nil {
+		var opts []option.ClientOption
+		if addr := os.Getenv("BIGTABLE_EMULATOR_HOST"); addr != "" {
+			opts = append(opts,
+				option.WithEndpoint(addr),
+				option.WithoutAuthentication(),
+				option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())))
+		} else {
+			opts = append(opts,
+				option.WithEndpoint(e.config.endpoint("bigtableadmin")),
+				option.WithScopes(bigtable.AdminScope, bigtable.InstanceAdminScope, cloudPlatformScope))
+			opts = append(opts, e.adminClientOpts()...)
+		}
+		conn, err := gtransport.DialPool(context.Background(), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("Dialing admin API: %v", err)
+		}
+		e.adminConn = conn
+	}
+	return e.adminConn, nil
+}
+
+func (e *env) getTableAdminRPC() (btapb.BigtableTableAdminClient, error) {
+	conn, err := e.getAdminConn()
+	if err != nil {
+		return nil, err
+	}
+	return btapb.NewBigtableTableAdminClient(conn), nil
+}
+
+func (e *env) getInstanceAdminRPC() (btapb.BigtableInstanceAdminClient, error) {
+	conn, err := e.getAdminConn()
+	if err != nil {
+		return nil, err
+	}
+	return btapb.NewBigtableInstanceAdminClient(conn), nil
+}
+
+func (e *env) getOperationsClient() (*lroauto.OperationsClient, error) {
+	if e.operations == nil {
+		conn, err := e.getAdminConn()
+		if err != nil {
+			return nil, err
+		}
+		ops, err := lroauto.NewOperationsClient(context.Background(), gtransport.WithConnPool(conn))
+		if err != nil {
+			return nil, fmt.Errorf("Making operations client: %v", err)
+		}
+		e.operations = ops
+	}
+	return e.operations, nil
+}
+
+// waitForOperation blocks until a long-running admin operation completes,
+// storing its result in resp if resp is not nil.
+func (e *env) waitForOperation(ctx context.Context, op *longrunningpb.Operation, resp protoadapt.MessageV1) error {
+	ops, err := e.getOperationsClient()
+	if err != nil {
+		return err
+	}
+	return longrunning.InternalNewOperation(ops, op).Wait(ctx, resp)
+}
+
+// instanceName returns the fully-qualified name of an instance.
+func instanceName(project, instance string) string {
+	return "projects/" + project + "/instances/" + instance
+}
+
+// backupName returns the fully-qualified name of a backup.
+func backupName(project, instance, cluster, backup string) string {
+	return instanceName(project, instance) + "/clusters/" + cluster + "/backups/" + backup
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"cloud.google.com/go/bigtable"
+	"cloud.google.com/go/cbt/cli"
+)
+
+// aggregateFamily describes the type of a family of aggregate cells.
+type aggregateFamily struct {
+	// Aggregator is sum, min, max or hll.
+	Aggregator string
+	// Input is the type of the values added to the cells, e.g. int64.
+	Input string
+}
+
+func (a aggregateFamily) String() string {
+	if a.Aggregator == "hll" {
+		return "HyperLogLog++ unique count of " + a.Input
+	}
+	return a.Aggregator + " of " + a.Input
+}
+
+// Decode returns the current value of an aggregate cell. Sums, minimums and
+// maximums of int64 are stored as 8-byte big-endian integers; unique count
+// sketches have no readable value, so only their size is shown.
+func (a aggregateFamily) Decode(v []byte) (string, error) {
+	switch {
+	case a.Aggregator == "hll":
+		return fmt.Sprintf("<sketch of %d bytes>", len(v)), nil
+	case a.Input == "int64":
+		if len(v) != 8 {
+			return "", fmt.Errorf("aggregate int64 value has %d bytes, want 8", len(v))
+		}
+		return strconv.FormatInt(int64(binary.BigEndian.Uint64(v)), 10), nil
+	}
+	return "", fmt.Errorf("can't decode aggregates of %s", a.Input)
+}
+
+// aggregateFamilies returns the aggregate families among fams, by name.
+func aggregateFamilies(fams []bigtable.FamilyInfo) map[string]aggregateFamily {
+	aggs := map[string]aggregateFamily{}
+	for _, fam := range fams {
+		t, ok := fam.ValueType.(bigtable.AggregateType)
+		if !ok {
+			continue
+		}
+		var a aggregateFamily
+		switch t.Aggregator.(type) {
+		case bigtable.SumAggregator:
+			a.Aggregator = "sum"
+		case bigtable.MinAggregator:
+			a.Aggregator = "min"
+		case bigtable.MaxAggregator:
+			a.Aggregator = "max"
+		case bigtable.HllppUniqueCountAggregator:
+			a.Aggregator = "hll"
+		default:
+			a.Aggregator = "unknown aggregate"
+		}
+		switch t.Input.(type) {
+		case bigtable.Int64Type:
+			a.Input = "int64"
+		case bigtable.StringType:
+			a.Input = "string"
+		case bigtable.BytesType:
+			a.Input = "bytes"
+		default:
+			a.Input = "unknown type"
+		}
+		aggs[fam.Name] = a
+	}
+	return aggs
+}
+
+// setupAggregateDecoding makes the value formatting of e decode the aggregate
+// families of table if decode, the value of a decode-aggregates argument, is
+// true.
+func (e *env) setupAggregateDecoding(ctx context.Context, table, decode string) error {
+	if decode == "" {
+		return nil
+	}
+	on, err := strconv.ParseBool(decode)
+	if err != nil {
+		return fmt.Errorf("bad decode-aggregates %q: must be true or false", decode)
+	}
+	if !on {
+		return nil
+	}
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	ti, err := ac.TableInfo(ctx, table)
+	if err != nil {
+		return fmt.Errorf("getting the families of %s: %v", table, err)
+	}
+	e.formatting.Aggregates = aggregateDecoders(ti.FamilyInfos)
+	return nil
+}
+
+// aggregateDecoders returns decoders for the aggregate families among fams,
+// by name, in the form used by cli.ValueFormatting.
+func aggregateDecoders(fams []bigtable.FamilyInfo) map[string]cli.AggregateDecoder {
+	decoders := map[string]cli.AggregateDecoder{}
+	for name, a := range aggregateFamilies(fams) {
+		decoders[name] = a
+	}
+	return decoders
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"cloud.google.com/go/cbt/cli"
+)
+
+func TestAggregateFamilies(t *testing.T) {
+	aggs := aggregateFamilies([]bigtable.FamilyInfo{
+		{Name: "plain"},
+		{Name: "sum", ValueType: bigtable.AggregateType{Input: bigtable.Int64Type{}, Aggregator: bigtable.SumAggregator{}}},
+		{Name: "max", ValueType: bigtable.AggregateType{Input: bigtable.Int64Type{}, Aggregator: bigtable.MaxAggregator{}}},
+		{Name: "hll", ValueType: bigtable.AggregateType{Input: bigtable.Int64Type{}, Aggregator: bigtable.HllppUniqueCountAggregator{}}},
+	})
+	want := map[string]string{
+		"sum": "sum of int64",
+		"max": "max of int64",
+		"hll": "HyperLogLog++ unique count of int64",
+	}
+	if len(aggs) != len(want) {
+		t.Fatalf("aggregateFamilies = %v, want %v", aggs, want)
+	}
+	for fam, desc := range want {
+		if got := aggs[fam].String(); got != desc {
+			t.Errorf("family %s is a %q, want %q", fam, got, desc)
+		}
+	}
+}
+
+func TestFormatAggregates(t *testing.T) {
+	ctx, ac, c := newEmulatorClients(t)
+	if err := ac.CreateTable(ctx, "my-table"); err != nil {
+		t.Fatal(err)
+	}
+	intsum := bigtable.AggregateType{Input: bigtable.Int64Type{}, Aggregator: bigtable.SumAggregator{}}
+	if err := ac.CreateColumnFamilyWithConfig(ctx, "my-table", "counters", bigtable.Family{ValueType: intsum}); err != nil {
+		t.Fatal(err)
+	}
+	tbl := c.Open("my-table")
+	for _, n := range []int64{40, 2} {
+		mut := bigtable.NewMutation()
+		mut.AddIntToCell("counters", "hits", 0, n)
+		if err := tbl.Apply(ctx, "r1", mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ti, err := ac.TableInfo(ctx, "my-table")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tbl.ReadRow(ctx, "r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := r["counters"][0]
+
+	f := cli.NewValueFormatting()
+	f.Aggregates = aggregateDecoders(ti.FamilyInfos)
+	got, err := f.Format("  ", "counters", item.Column, item.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "  42  (aggregate sum of int64)\n"; got != want {
+		t.Errorf("format = %q, want %q", got, want)
+	}
+	if got, err := f.FormatValue("counters", item.Column, item.Value); err != nil || got != "42" {
+		t.Errorf("formatValue = %q, %v; want 42", got, err)
+	}
+
+	// Without decoding, the raw bytes are printed.
+	raw := cli.NewValueFormatting()
+	if got, err := raw.Format("", "counters", item.Column, item.Value); err != nil || got == "42\n" {
+		t.Errorf("format without decoding = %q, %v; want the raw bytes", got, err)
+	}
+}
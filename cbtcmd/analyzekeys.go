@@ -0,0 +1,277 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxRowKeyBytes is the largest row key Bigtable accepts.
+	maxRowKeyBytes = 4096
+	// keyPrefixDelimiters end the prefix that keys are grouped by, unless
+	// prefix-length is set.
+	keyPrefixDelimiters = "#:|/"
+	// keyPrefixFallback is the length of the prefix of keys without a
+	// delimiter.
+	keyPrefixFallback = 4
+	// topKeyPrefixes is the number of prefixes printed.
+	topKeyPrefixes = 10
+	// maxDuplicateExamples is the number of duplicated keys printed.
+	maxDuplicateExamples = 5
+)
+
+// keyStats summarizes the row keys of an import file, in file order.
+type keyStats struct {
+	keys       int
+	seen       map[string]int
+	empty      int
+	tooLong    int
+	maxLen     int
+	totalLen   int64
+	last       string
+	increasing int // consecutive pairs of keys in non-decreasing order
+	decreasing int // consecutive pairs of keys in non-increasing order
+	numeric    int // keys starting with a long number, like a timestamp
+	prefixes   map[string]int
+	prefixLen  int
+	duplicated int // keys appearing more than once
+}
+
+func newKeyStats(prefixLen int) *keyStats {
+	return &keyStats{seen: map[string]int{}, prefixes: map[string]int{}, prefixLen: prefixLen}
+}
+
+// keyPrefix returns the prefix that key is grouped by: its first prefixLen
+// bytes if prefixLen is positive, else the key up to and including its first
+// delimiter.
+func keyPrefix(key string, prefixLen int) string {
+	if prefixLen > 0 {
+		return truncateKey(key, prefixLen)
+	}
+	if i := strings.IndexAny(key, keyPrefixDelimiters); i >= 0 {
+		return key[:i+1]
+	}
+	return truncateKey(key, keyPrefixFallback)
+}
+
+// leadingDigits returns the number of decimal digits key starts with.
+func leadingDigits(key string) int {
+	n := 0
+	for n < len(key) && key[n] >= '0' && key[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+func (s *keyStats) add(key string) {
+	if s.keys > 0 {
+		if key >= s.last {
+			s.increasing++
+		}
+		if key <= s.last {
+			s.decreasing++
+		}
+	}
+	s.keys++
+	s.last = key
+	if key == "" {
+		s.empty++
+	}
+	if len(key) > maxRowKeyBytes {
+		s.tooLong++
+	}
+	if len(key) > s.maxLen {
+		s.maxLen = len(key)
+	}
+	s.totalLen += int64(len(key))
+	// Eight digits covers dates like 20240501 and epoch seconds.
+	if leadingDigits(key) >= 8 {
+		s.numeric++
+	}
+	s.prefixes[keyPrefix(key, s.prefixLen)]++
+	if s.seen[key]++; s.seen[key] == 2 {
+		s.duplicated++
+	}
+}
+
+// analyzeKeys reads the row keys in column keyColumn of a CSV file, after
+// skipping headerRows rows.
+func analyzeKeys(r *csv.Reader, keyColumn, headerRows, prefixLen int) (*keyStats, error) {
+	r.FieldsPerRecord = -1
+	s := newKeyStats(prefixLen)
+	for line := 1; ; line++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return s, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line <= headerRows {
+			continue
+		}
+		if keyColumn >= len(rec) {
+			return nil, fmt.Errorf("line %d has %d columns, too few for key-column=%d", line, len(rec), keyColumn)
+		}
+		s.add(rec[keyColumn])
+	}
+}
+
+// keyWarnings returns warnings about keys that are rejected by Bigtable, or
+// that would concentrate writes on a few tablets.
+func (s *keyStats) keyWarnings() []string {
+	var warnings []string
+	if s.empty > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d rows have an empty row key, which Bigtable rejects.", s.empty))
+	}
+	if s.tooLong > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d keys are longer than %d bytes, which Bigtable rejects.", s.tooLong, maxRowKeyBytes))
+	}
+	if s.duplicated > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d keys appear more than once; import writes the cells of each to the same row.", s.duplicated))
+	}
+	if pairs := s.keys - 1; pairs >= 2 {
+		if s.increasing*10 >= pairs*9 || s.decreasing*10 >= pairs*9 {
+			warnings = append(warnings, "The keys are sorted. Import writes rows in file order, so the load "+
+				"will write to one tablet at a time; shuffle the file, or create the table with splits= at its keys.")
+		}
+	}
+	if s.keys > 0 && s.numeric*10 >= s.keys*9 {
+		warnings = append(warnings, "Most keys start with a long number, like a timestamp or sequence ID. New rows "+
+			"will be written at one end of the table, hotspotting a single tablet; start keys with a well "+
+			"distributed value, such as a user or device ID, instead.")
+	}
+	if top := s.topPrefixes(1); len(top) == 1 && len(s.prefixes) > 1 && top[0].count*2 > s.keys {
+		warnings = append(warnings, fmt.Sprintf("%.0f%% of keys start with %q. Traffic to these rows will be "+
+			"concentrated on the tablets that hold them.", 100*float64(top[0].count)/float64(s.keys), top[0].prefix))
+	}
+	return warnings
+}
+
+type prefixCount struct {
+	prefix string
+	count  int
+}
+
+// topPrefixes returns the n most common key prefixes.
+func (s *keyStats) topPrefixes(n int) []prefixCount {
+	var counts []prefixCount
+	for p, c := range s.prefixes {
+		counts = append(counts, prefixCount{p, c})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].count != counts[j].count {
+			return counts[i].count > counts[j].count
+		}
+		return counts[i].prefix < counts[j].prefix
+	})
+	if len(counts) > n {
+		counts = counts[:n]
+	}
+	return counts
+}
+
+// duplicateExamples returns up to n keys that appear more than once, sorted.
+func (s *keyStats) duplicateExamples(n int) []string {
+	var keys []string
+	for k, c := range s.seen {
+		if c > 1 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+func printKeyStats(w io.Writer, width int, input string, s *keyStats) {
+	fmt.Fprintf(w, "Analyzed %d keys in %s: %d distinct, %d duplicated\n", s.keys, input, len(s.seen), s.duplicated)
+	if s.keys == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Key length: max %d bytes, average %.1f bytes\n", s.maxLen, float64(s.totalLen)/float64(s.keys))
+	if dups := s.duplicateExamples(maxDuplicateExamples); len(dups) > 0 {
+		var quoted []string
+		for _, k := range dups {
+			quoted = append(quoted, strconv.Quote(k))
+		}
+		fmt.Fprintf(w, "Duplicated keys include %s\n", strings.Join(quoted, ", "))
+	}
+	fmt.Fprintln(w)
+	var rows [][]string
+	for _, p := range s.topPrefixes(topKeyPrefixes) {
+		rows = append(rows, []string{strconv.Quote(p.prefix), strconv.Itoa(p.count), fmt.Sprintf("%.1f%%", 100*float64(p.count)/float64(s.keys))})
+	}
+	printTable(w, width, []string{"Prefix", "Keys", "Share"}, rows)
+	if len(s.prefixes) > topKeyPrefixes {
+		fmt.Fprintf(w, "... and %d more prefixes\n", len(s.prefixes)-topKeyPrefixes)
+	}
+	fmt.Fprintln(w)
+	warnings := s.keyWarnings()
+	if len(warnings) == 0 {
+		fmt.Fprintln(w, "No problems found.")
+	}
+	for _, warning := range warnings {
+		fmt.Fprintln(w, "Warning: "+warning)
+	}
+}
+
+func doAnalyzeKeys(ctx context.Context, e *env, args ...string) error {
+	if len(args) < 1 {
+		return errors.New("usage: cbt analyzekeys <input-file> [key-column=<0>] [header-rows=<2>] [prefix-length=<n>]")
+	}
+	parsed, err := parseArgs(args[1:], []string{"key-column", "header-rows", "prefix-length"})
+	if err != nil {
+		return err
+	}
+	keyColumn, headerRows, prefixLen := 0, 2, 0
+	for _, arg := range []struct {
+		name string
+		v    *int
+	}{{"key-column", &keyColumn}, {"header-rows", &headerRows}, {"prefix-length", &prefixLen}} {
+		if s := parsed[arg.name]; s != "" {
+			if *arg.v, err = strconv.Atoi(s); err != nil || *arg.v < 0 {
+				return fmt.Errorf("Bad %s %q: must be a number >= 0", arg.name, s)
+			}
+		}
+	}
+
+	in := os.Stdin
+	if args[0] != "-" {
+		if in, err = os.Open(args[0]); err != nil {
+			return err
+		}
+		defer in.Close()
+	}
+	s, err := analyzeKeys(csv.NewReader(in), keyColumn, headerRows, prefixLen)
+	if err != nil {
+		return fmt.Errorf("Reading %s: %v", args[0], err)
+	}
+	printKeyStats(e.stdout, e.outputWidth(), args[0], s)
+	return nil
+}
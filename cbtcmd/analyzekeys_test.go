@@ -0,0 +1,122 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestKeyPrefix(t *testing.T) {
+	for _, test := range []struct {
+		key       string
+		prefixLen int
+		want      string
+	}{
+		{"user#123", 0, "user#"},
+		{"a:b#c", 0, "a:"},
+		{"abcdefgh", 0, "abcd"},
+		{"ab", 0, "ab"},
+		{"user#123", 6, "user#1"},
+	} {
+		if got := keyPrefix(test.key, test.prefixLen); got != test.want {
+			t.Errorf("keyPrefix(%q, %d) = %q, want %q", test.key, test.prefixLen, got, test.want)
+		}
+	}
+}
+
+func TestAnalyzeKeys(t *testing.T) {
+	for _, test := range []struct {
+		desc         string
+		input        string
+		keyColumn    int
+		headerRows   int
+		wantKeys     int
+		wantWarnings []string
+	}{
+		{
+			desc:       "well distributed",
+			input:      ",f\n,a\nd7#1,x\nb2#1,x\nc9#1,x\na1#2,x\n",
+			headerRows: 2,
+			wantKeys:   4,
+		},
+		{
+			desc:         "sorted",
+			input:        ",f\n,a\na,x\nb,x\nc,x\nd,x\n",
+			headerRows:   2,
+			wantKeys:     4,
+			wantWarnings: []string{"The keys are sorted"},
+		},
+		{
+			desc:         "timestamps first, duplicated, in another column",
+			input:        "a\nx,1700000002#d\nx,1700000001#d\nx,1700000003#d\nx,1700000001#d\n",
+			keyColumn:    1,
+			headerRows:   1,
+			wantKeys:     4,
+			wantWarnings: []string{"1 keys appear more than once", "start with a long number"},
+		},
+		{
+			desc:         "dominant prefix, rejected keys",
+			input:        "hot#1,x\n,x\nhot#2,x\nhot#0,x\ncold#1,x\nhot#3,x\n" + strings.Repeat("k", maxRowKeyBytes+1) + ",x\n",
+			wantKeys:     7,
+			wantWarnings: []string{"1 rows have an empty row key", "1 keys are longer than 4096 bytes", `57% of keys start with "hot#"`},
+		},
+	} {
+		s, err := analyzeKeys(csv.NewReader(strings.NewReader(test.input)), test.keyColumn, test.headerRows, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", test.desc, err)
+		}
+		if s.keys != test.wantKeys {
+			t.Errorf("%s: analyzed %d keys, want %d", test.desc, s.keys, test.wantKeys)
+		}
+		warnings := s.keyWarnings()
+		if len(warnings) != len(test.wantWarnings) {
+			t.Errorf("%s: warnings = %q, want %d", test.desc, warnings, len(test.wantWarnings))
+			continue
+		}
+		for i, want := range test.wantWarnings {
+			if !strings.Contains(warnings[i], want) {
+				t.Errorf("%s: warning %q does not contain %q", test.desc, warnings[i], want)
+			}
+		}
+	}
+
+	if _, err := analyzeKeys(csv.NewReader(strings.NewReader("a\nb\n")), 1, 0, 0); err == nil {
+		t.Error("analyzeKeys with too few columns did not fail")
+	}
+}
+
+func TestPrintKeyStats(t *testing.T) {
+	s, err := analyzeKeys(csv.NewReader(strings.NewReader("b#1\na#1\na#1\n")), 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	printKeyStats(&buf, 0, "keys.csv", s)
+	for _, want := range []string{
+		"Analyzed 3 keys in keys.csv: 2 distinct, 1 duplicated",
+		`Duplicated keys include "a#1"`,
+		`"a#"`,
+		"66.7%",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("printKeyStats output does not contain %q:\n%s", want, buf.String())
+		}
+	}
+}
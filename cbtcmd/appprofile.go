@@ -0,0 +1,423 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	btapb "cloud.google.com/go/bigtable/admin/apiv2/adminpb"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// appProfileName returns the fully-qualified name of an app profile.
+func appProfileName(project, instance, profile string) string {
+	return instanceName(project, instance) + "/appProfiles/" + profile
+}
+
+// appProfilePriorities are the values of priority= arguments.
+var appProfilePriorities = map[string]btapb.AppProfile_Priority{
+	"low":    btapb.AppProfile_PRIORITY_LOW,
+	"medium": btapb.AppProfile_PRIORITY_MEDIUM,
+	"high":   btapb.AppProfile_PRIORITY_HIGH,
+}
+
+// appProfileUpdate is a partial update of an app profile: only the fields
+// named in the mask are changed.
+type appProfileUpdate struct {
+	profile        *btapb.AppProfile
+	mask           []string
+	ignoreWarnings bool
+}
+
+func isRouteArg(arg string) bool {
+	return arg == "route-any" || strings.HasPrefix(arg, "route-to=")
+}
+
+// parseAppProfileUpdate parses the arguments of updateappprofile after the
+// instance and profile IDs. Besides key=value arguments, it accepts the
+// original form, in which a description and a routing policy are given
+// positionally and both always replaced. current returns the profile as it
+// is, for updates that depend on it.
+func parseAppProfileUpdate(args []string, current func() (*btapb.AppProfile, error)) (appProfileUpdate, error) {
+	u := appProfileUpdate{profile: &btapb.AppProfile{}}
+	var route string
+	var rest []string
+	if len(args) >= 2 && !strings.Contains(args[0], "=") && !isRouteArg(args[0]) && isRouteArg(args[1]) {
+		u.profile.Description = args[0]
+		u.mask = append(u.mask, "description")
+		route = args[1]
+		args = args[2:]
+	}
+	for _, arg := range args {
+		if arg == "route-any" {
+			if route != "" {
+				return u, fmt.Errorf("only one routing policy may be given")
+			}
+			route = arg
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	parsed, err := parseArgs(rest, []string{"description", "route-to", "transactional-writes", "priority", "force", "etag"})
+	if err != nil {
+		return u, err
+	}
+	// The etag needn't be in the mask: any etag sent makes the update fail if
+	// the profile has changed since it was read.
+	u.profile.Etag = parsed["etag"]
+	if desc, ok := parsed["description"]; ok {
+		if stringInSlice("description", u.mask) {
+			return u, fmt.Errorf("the description may only be given once")
+		}
+		u.profile.Description = desc
+		u.mask = append(u.mask, "description")
+	}
+	if cluster, ok := parsed["route-to"]; ok {
+		if route != "" && route != "route-to="+cluster {
+			return u, fmt.Errorf("only one routing policy may be given")
+		}
+		route = "route-to=" + cluster
+	}
+	if u.ignoreWarnings, err = parseProfileOpts("force", parsed); err != nil {
+		return u, err
+	}
+	transactional, err := parseProfileOpts("transactional-writes", parsed)
+	if err != nil {
+		return u, err
+	}
+	_, setTransactional := parsed["transactional-writes"]
+
+	switch {
+	case route == "route-any":
+		if setTransactional {
+			return u, fmt.Errorf("transactional-writes requires single-cluster routing")
+		}
+		u.profile.RoutingPolicy = &btapb.AppProfile_MultiClusterRoutingUseAny_{
+			MultiClusterRoutingUseAny: &btapb.AppProfile_MultiClusterRoutingUseAny{},
+		}
+		u.mask = append(u.mask, "multi_cluster_routing_use_any")
+	case route != "":
+		_, cluster, err := parseProfileRoute(route)
+		if err != nil {
+			return u, fmt.Errorf("bad routing policy %q", route)
+		}
+		u.profile.RoutingPolicy = &btapb.AppProfile_SingleClusterRouting_{
+			SingleClusterRouting: &btapb.AppProfile_SingleClusterRouting{ClusterId: cluster, AllowTransactionalWrites: transactional},
+		}
+		u.mask = append(u.mask, "single_cluster_routing")
+	case setTransactional:
+		// Keep the profile's cluster, changing only whether it allows
+		// transactional writes.
+		cur, err := current()
+		if err != nil {
+			return u, err
+		}
+		single := cur.GetSingleClusterRouting()
+		if single == nil {
+			return u, fmt.Errorf("transactional-writes requires single-cluster routing; give route-to=<cluster-id>")
+		}
+		u.profile.RoutingPolicy = &btapb.AppProfile_SingleClusterRouting_{
+			SingleClusterRouting: &btapb.AppProfile_SingleClusterRouting{ClusterId: single.ClusterId, AllowTransactionalWrites: transactional},
+		}
+		u.mask = append(u.mask, "single_cluster_routing")
+	}
+
+	if p, ok := parsed["priority"]; ok {
+		priority, ok := appProfilePriorities[p]
+		if !ok {
+			return u, fmt.Errorf("bad priority %q: must be low, medium or high", p)
+		}
+		u.profile.Isolation = &btapb.AppProfile_StandardIsolation_{
+			StandardIsolation: &btapb.AppProfile_StandardIsolation{Priority: priority},
+		}
+		u.mask = append(u.mask, "standard_isolation")
+	}
+	if len(u.mask) == 0 {
+		return u, fmt.Errorf("nothing to update")
+	}
+	return u, nil
+}
+
+// isEtagMismatch reports whether err is the failure of an update whose etag
+// doesn't match the resource's.
+func isEtagMismatch(err error) bool {
+	switch status.Code(err) {
+	case codes.Aborted, codes.FailedPrecondition:
+		return true
+	}
+	return false
+}
+
+func doUpdateAppProfile(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt updateappprofile <instance-id> <profile-id> [description=<description>]" +
+		" [route-any | route-to=<cluster-id>] [transactional-writes=<true|false>] [priority=<low|medium|high>] [etag=<etag>]" +
+		" [force=<true|false>]"
+	if len(args) < 3 {
+		return errors.New(usage)
+	}
+	rpc, err := e.getInstanceAdminRPC()
+	if err != nil {
+		return err
+	}
+	name := appProfileName(e.config.Project, args[0], args[1])
+	current := func() (*btapb.AppProfile, error) {
+		p, err := rpc.GetAppProfile(ctx, &btapb.GetAppProfileRequest{Name: name})
+		if err != nil {
+			return nil, fmt.Errorf("getting app profile: %v", err)
+		}
+		return p, nil
+	}
+	u, err := parseAppProfileUpdate(args[2:], current)
+	if err != nil {
+		return fmt.Errorf("%v\n%s", err, usage)
+	}
+	u.profile.Name = name
+	op, err := rpc.UpdateAppProfile(ctx, &btapb.UpdateAppProfileRequest{
+		AppProfile:     u.profile,
+		UpdateMask:     &fieldmaskpb.FieldMask{Paths: u.mask},
+		IgnoreWarnings: u.ignoreWarnings,
+	})
+	if err == nil {
+		err = e.waitForOperation(ctx, op, nil)
+	}
+	if err != nil && u.profile.Etag != "" && isEtagMismatch(err) {
+		return fmt.Errorf("App profile %s has changed since etag %s was read; get it again and retry: %v", args[1], u.profile.Etag, err)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to update app profile : %v", err)
+	}
+	fmt.Fprintf(e.stdout, "Updated %s of app profile %s\n", strings.Join(u.mask, ", "), args[1])
+	return nil
+}
+
+// appProfileInfo is every setting of an app profile, flattened for printing.
+type appProfileInfo struct {
+	Name        string `json:"name"`
+	Etag        string `json:"etag"`
+	Description string `json:"description"`
+	// Routing is "single-cluster" or "multi-cluster".
+	Routing             string `json:"routing"`
+	Cluster             string `json:"cluster,omitempty"`
+	TransactionalWrites bool   `json:"transactionalWrites,omitempty"`
+	// Clusters is the multi-cluster routing group; empty means all clusters.
+	Clusters    []string `json:"clusters,omitempty"`
+	RowAffinity bool     `json:"rowAffinity,omitempty"`
+	// Isolation is "standard" or "data-boost".
+	Isolation           string `json:"isolation"`
+	Priority            string `json:"priority,omitempty"`
+	ComputeBillingOwner string `json:"computeBillingOwner,omitempty"`
+}
+
+// enumName lower-cases an enum value's name and strips its prefix, turning
+// PRIORITY_LOW into low. Unspecified values are empty.
+func enumName(name, prefix string) string {
+	name = strings.TrimPrefix(name, prefix)
+	if name == "UNSPECIFIED" || strings.HasSuffix(name, "_UNSPECIFIED") {
+		return ""
+	}
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+func describeAppProfile(p *btapb.AppProfile) appProfileInfo {
+	info := appProfileInfo{Name: p.Name, Etag: p.Etag, Description: p.Description}
+	if single := p.GetSingleClusterRouting(); single != nil {
+		info.Routing = "single-cluster"
+		info.Cluster = single.ClusterId
+		info.TransactionalWrites = single.AllowTransactionalWrites
+	}
+	if multi := p.GetMultiClusterRoutingUseAny(); multi != nil {
+		info.Routing = "multi-cluster"
+		info.Clusters = multi.ClusterIds
+		info.RowAffinity = multi.GetRowAffinity() != nil
+	}
+	if db := p.GetDataBoostIsolationReadOnly(); db != nil {
+		info.Isolation = "data-boost"
+		info.ComputeBillingOwner = enumName(db.GetComputeBillingOwner().String(), "")
+	} else {
+		// Profiles without an isolation setting are standard, and may still
+		// carry the deprecated top-level priority.
+		info.Isolation = "standard"
+		priority := p.GetStandardIsolation().GetPriority()
+		if priority == btapb.AppProfile_PRIORITY_UNSPECIFIED {
+			priority = p.GetPriority()
+		}
+		info.Priority = enumName(priority.String(), "PRIORITY_")
+	}
+	return info
+}
+
+func (info appProfileInfo) routingSummary() string {
+	var s string
+	switch info.Routing {
+	case "single-cluster":
+		s = "single-cluster " + info.Cluster
+		if info.TransactionalWrites {
+			s += ", transactional writes"
+		}
+	case "multi-cluster":
+		s = "multi-cluster any"
+		if len(info.Clusters) > 0 {
+			s = "multi-cluster " + strings.Join(info.Clusters, ",")
+		}
+		if info.RowAffinity {
+			s += ", row affinity"
+		}
+	}
+	return s
+}
+
+func (info appProfileInfo) isolationSummary() string {
+	if info.Isolation == "data-boost" {
+		return strings.TrimSpace("data-boost " + info.ComputeBillingOwner)
+	}
+	return strings.TrimSpace("standard " + info.Priority)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// printAppProfile prints every setting of a profile, one per line.
+func printAppProfile(w io.Writer, info appProfileInfo) {
+	fmt.Fprintf(w, "Name: %s\n", info.Name)
+	fmt.Fprintf(w, "Etag: %s\n", info.Etag)
+	fmt.Fprintf(w, "Description: %s\n", info.Description)
+	fmt.Fprintf(w, "Routing: %s\n", info.Routing)
+	switch info.Routing {
+	case "single-cluster":
+		fmt.Fprintf(w, "Cluster: %s\n", info.Cluster)
+		fmt.Fprintf(w, "TransactionalWrites: %t\n", info.TransactionalWrites)
+	case "multi-cluster":
+		clusters := "all"
+		if len(info.Clusters) > 0 {
+			clusters = strings.Join(info.Clusters, ", ")
+		}
+		fmt.Fprintf(w, "Clusters: %s\n", clusters)
+		fmt.Fprintf(w, "RowAffinity: %t\n", info.RowAffinity)
+	}
+	fmt.Fprintf(w, "Isolation: %s\n", info.Isolation)
+	if info.Isolation == "data-boost" {
+		fmt.Fprintf(w, "ComputeBillingOwner: %s\n", info.ComputeBillingOwner)
+	} else {
+		fmt.Fprintf(w, "Priority: %s\n", info.Priority)
+	}
+}
+
+// parseFormatArgs parses key=value arguments that may include the flag-like
+// spelling -format=json.
+func parseFormatArgs(args []string, valid []string) (map[string]string, error) {
+	trimmed := make([]string, len(args))
+	for i, arg := range args {
+		trimmed[i] = arg
+		if strings.HasPrefix(strings.TrimLeft(arg, "-"), "format=") {
+			trimmed[i] = strings.TrimLeft(arg, "-")
+		}
+	}
+	return parseArgs(trimmed, valid)
+}
+
+func doGetAppProfile(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt getappprofile <instance-id> <profile-id> [format=<text|json>]"
+	if len(args) < 2 {
+		return errors.New(usage)
+	}
+	parsed, err := parseFormatArgs(args[2:], []string{"format"})
+	if err != nil {
+		return errors.New(usage)
+	}
+	format := parsed["format"]
+	if format != "" && format != "text" && format != "json" {
+		return fmt.Errorf("Bad format %q: must be text or json", format)
+	}
+
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return err
+	}
+	profile, err := iac.GetAppProfile(ctx, args[0], args[1])
+	if err != nil {
+		return fmt.Errorf("Failed to get app profile : %v", err)
+	}
+	info := describeAppProfile(profile)
+	if format == "json" {
+		return writeJSON(e.stdout, info)
+	}
+	printAppProfile(e.stdout, info)
+	return nil
+}
+
+func doListAppProfiles(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt listappprofile <instance-id> [wide=<true|false>] [format=<table|json>]"
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+	parsed, err := parseFormatArgs(args[1:], []string{"wide", "format"})
+	if err != nil {
+		return errors.New(usage)
+	}
+	wide := parsed["wide"] == "true"
+	format := parsed["format"]
+	if format != "" && format != "table" && format != "json" {
+		return fmt.Errorf("Bad format %q: must be table or json", format)
+	}
+
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return err
+	}
+	it := iac.ListAppProfiles(ctx, args[0])
+	infos := []appProfileInfo{}
+	for {
+		profile, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to fetch app profile %v", err)
+		}
+		infos = append(infos, describeAppProfile(profile))
+	}
+	if format == "json" {
+		return writeJSON(e.stdout, infos)
+	}
+
+	header := []string{"AppProfile", "Profile Description", "Profile Etag", "Profile Routing Policy"}
+	if wide {
+		header = append(header, "Isolation")
+	}
+	var rows [][]string
+	for _, info := range infos {
+		row := []string{info.Name, info.Description, info.Etag, info.routingSummary()}
+		if wide {
+			row = append(row, info.isolationSummary())
+		}
+		rows = append(rows, row)
+	}
+	e.printList(header, rows, wide)
+	return nil
+}
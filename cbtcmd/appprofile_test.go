@@ -0,0 +1,189 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	btapb "cloud.google.com/go/bigtable/admin/apiv2/adminpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseAppProfileUpdate(t *testing.T) {
+	single := &btapb.AppProfile{RoutingPolicy: &btapb.AppProfile_SingleClusterRouting_{
+		SingleClusterRouting: &btapb.AppProfile_SingleClusterRouting{ClusterId: "c1"},
+	}}
+	multi := &btapb.AppProfile{RoutingPolicy: &btapb.AppProfile_MultiClusterRoutingUseAny_{
+		MultiClusterRoutingUseAny: &btapb.AppProfile_MultiClusterRoutingUseAny{},
+	}}
+	for _, test := range []struct {
+		args        []string
+		current     *btapb.AppProfile
+		wantMask    string
+		wantProfile *btapb.AppProfile
+		wantForce   bool
+	}{
+		{
+			args:        []string{"description=new"},
+			wantMask:    "description",
+			wantProfile: &btapb.AppProfile{Description: "new"},
+		},
+		{
+			args:        []string{"priority=low", "force=true"},
+			wantMask:    "standard_isolation",
+			wantProfile: &btapb.AppProfile{Isolation: &btapb.AppProfile_StandardIsolation_{StandardIsolation: &btapb.AppProfile_StandardIsolation{Priority: btapb.AppProfile_PRIORITY_LOW}}},
+			wantForce:   true,
+		},
+		{
+			// The etag is sent but isn't part of the mask.
+			args:        []string{"description=new", "etag=CPmKzsIBEAE="},
+			wantMask:    "description",
+			wantProfile: &btapb.AppProfile{Description: "new", Etag: "CPmKzsIBEAE="},
+		},
+		{
+			args:     []string{"route-any"},
+			wantMask: "multi_cluster_routing_use_any",
+			wantProfile: &btapb.AppProfile{RoutingPolicy: &btapb.AppProfile_MultiClusterRoutingUseAny_{
+				MultiClusterRoutingUseAny: &btapb.AppProfile_MultiClusterRoutingUseAny{},
+			}},
+		},
+		{
+			args:     []string{"transactional-writes=true"},
+			current:  single,
+			wantMask: "single_cluster_routing",
+			wantProfile: &btapb.AppProfile{RoutingPolicy: &btapb.AppProfile_SingleClusterRouting_{
+				SingleClusterRouting: &btapb.AppProfile_SingleClusterRouting{ClusterId: "c1", AllowTransactionalWrites: true},
+			}},
+		},
+		{
+			// The original positional form replaces both.
+			args:     []string{"Use this one.", "route-to=c2", "transactional-writes=true"},
+			wantMask: "description single_cluster_routing",
+			wantProfile: &btapb.AppProfile{Description: "Use this one.", RoutingPolicy: &btapb.AppProfile_SingleClusterRouting_{
+				SingleClusterRouting: &btapb.AppProfile_SingleClusterRouting{ClusterId: "c2", AllowTransactionalWrites: true},
+			}},
+		},
+	} {
+		current := func() (*btapb.AppProfile, error) {
+			if test.current == nil {
+				t.Errorf("%q: the current profile was fetched", test.args)
+				return nil, errors.New("no profile")
+			}
+			return test.current, nil
+		}
+		u, err := parseAppProfileUpdate(test.args, current)
+		if err != nil {
+			t.Errorf("parseAppProfileUpdate(%q): %v", test.args, err)
+			continue
+		}
+		if got := strings.Join(u.mask, " "); got != test.wantMask {
+			t.Errorf("parseAppProfileUpdate(%q) mask = %q, want %q", test.args, got, test.wantMask)
+		}
+		if !proto.Equal(u.profile, test.wantProfile) {
+			t.Errorf("parseAppProfileUpdate(%q) profile = %v, want %v", test.args, u.profile, test.wantProfile)
+		}
+		if u.ignoreWarnings != test.wantForce {
+			t.Errorf("parseAppProfileUpdate(%q) ignoreWarnings = %v, want %v", test.args, u.ignoreWarnings, test.wantForce)
+		}
+	}
+
+	for _, args := range [][]string{
+		nil,
+		{"priority=urgent"},
+		{"route-any", "route-to=c1"},
+		{"route-any", "transactional-writes=true"},
+		{"transactional-writes=true"},
+		{"bogus=1"},
+		{"etag=CPmKzsIBEAE="},
+	} {
+		current := func() (*btapb.AppProfile, error) { return multi, nil }
+		if _, err := parseAppProfileUpdate(args, current); err == nil {
+			t.Errorf("parseAppProfileUpdate(%q) succeeded, want an error", args)
+		}
+	}
+}
+
+func TestDescribeAppProfile(t *testing.T) {
+	for _, test := range []struct {
+		profile       *btapb.AppProfile
+		want          appProfileInfo
+		wantRouting   string
+		wantIsolation string
+	}{
+		{
+			profile: &btapb.AppProfile{
+				Name: "p1", Etag: "e1", Description: "serving",
+				RoutingPolicy: &btapb.AppProfile_MultiClusterRoutingUseAny_{MultiClusterRoutingUseAny: &btapb.AppProfile_MultiClusterRoutingUseAny{
+					ClusterIds: []string{"c1", "c2"},
+					Affinity:   &btapb.AppProfile_MultiClusterRoutingUseAny_RowAffinity_{RowAffinity: &btapb.AppProfile_MultiClusterRoutingUseAny_RowAffinity{}},
+				}},
+				Isolation: &btapb.AppProfile_StandardIsolation_{StandardIsolation: &btapb.AppProfile_StandardIsolation{Priority: btapb.AppProfile_PRIORITY_HIGH}},
+			},
+			want: appProfileInfo{
+				Name: "p1", Etag: "e1", Description: "serving",
+				Routing: "multi-cluster", Clusters: []string{"c1", "c2"}, RowAffinity: true,
+				Isolation: "standard", Priority: "high",
+			},
+			wantRouting:   "multi-cluster c1,c2, row affinity",
+			wantIsolation: "standard high",
+		},
+		{
+			profile: &btapb.AppProfile{
+				Name: "p2",
+				RoutingPolicy: &btapb.AppProfile_SingleClusterRouting_{SingleClusterRouting: &btapb.AppProfile_SingleClusterRouting{
+					ClusterId: "c1", AllowTransactionalWrites: true,
+				}},
+				Isolation: &btapb.AppProfile_DataBoostIsolationReadOnly_{DataBoostIsolationReadOnly: &btapb.AppProfile_DataBoostIsolationReadOnly{
+					ComputeBillingOwner: btapb.AppProfile_DataBoostIsolationReadOnly_HOST_PAYS.Enum(),
+				}},
+			},
+			want: appProfileInfo{
+				Name:    "p2",
+				Routing: "single-cluster", Cluster: "c1", TransactionalWrites: true,
+				Isolation: "data-boost", ComputeBillingOwner: "host-pays",
+			},
+			wantRouting:   "single-cluster c1, transactional writes",
+			wantIsolation: "data-boost host-pays",
+		},
+		{
+			// The deprecated top-level priority is used when there's no
+			// isolation setting.
+			profile: &btapb.AppProfile{
+				Name:          "p3",
+				RoutingPolicy: &btapb.AppProfile_MultiClusterRoutingUseAny_{MultiClusterRoutingUseAny: &btapb.AppProfile_MultiClusterRoutingUseAny{}},
+				Isolation:     &btapb.AppProfile_Priority_{Priority: btapb.AppProfile_PRIORITY_LOW},
+			},
+			want:          appProfileInfo{Name: "p3", Routing: "multi-cluster", Isolation: "standard", Priority: "low"},
+			wantRouting:   "multi-cluster any",
+			wantIsolation: "standard low",
+		},
+	} {
+		got := describeAppProfile(test.profile)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("describeAppProfile(%v) = %+v, want %+v", test.profile, got, test.want)
+		}
+		if s := got.routingSummary(); s != test.wantRouting {
+			t.Errorf("%s: routingSummary() = %q, want %q", got.Name, s, test.wantRouting)
+		}
+		if s := got.isolationSummary(); s != test.wantIsolation {
+			t.Errorf("%s: isolationSummary() = %q, want %q", got.Name, s, test.wantIsolation)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/monitoring/v3"
+)
+
+// appProfileRequestMetric counts the requests served by Bigtable, labelled by
+// the app profile they used.
+const appProfileRequestMetric = "bigtable.googleapis.com/server/request_count"
+
+// fetchAppProfileRequests returns the number of requests to the tables of an
+// instance over the window ending at now, by app profile.
+func fetchAppProfileRequests(ctx context.Context, svc *monitoring.Service, project, instance string, window time.Duration, now time.Time) (map[string]int64, error) {
+	filter := fmt.Sprintf(`metric.type=%q AND resource.type="bigtable_table" AND resource.labels.instance=%q`, appProfileRequestMetric, instance)
+	call := svc.Projects.TimeSeries.List("projects/" + project).
+		Filter(filter).
+		IntervalStartTime(now.Add(-window).UTC().Format(time.RFC3339)).
+		IntervalEndTime(now.UTC().Format(time.RFC3339)).
+		AggregationAlignmentPeriod(fmt.Sprintf("%ds", int64(window/time.Second))).
+		AggregationPerSeriesAligner("ALIGN_SUM").
+		AggregationCrossSeriesReducer("REDUCE_SUM").
+		AggregationGroupByFields("metric.labels.app_profile")
+	requests := map[string]int64{}
+	err := call.Pages(ctx, func(res *monitoring.ListTimeSeriesResponse) error {
+		for _, ts := range res.TimeSeries {
+			var profile string
+			if ts.Metric != nil {
+				profile = ts.Metric.Labels["app_profile"]
+			}
+			for _, p := range ts.Points {
+				switch {
+				case p.Value == nil:
+				case p.Value.Int64Value != nil:
+					requests[profile] += *p.Value.Int64Value
+				case p.Value.DoubleValue != nil:
+					requests[profile] += int64(*p.Value.DoubleValue)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %v", appProfileRequestMetric, err)
+	}
+	return requests, nil
+}
+
+// printAppProfileUsage prints the requests of each app profile of an
+// instance, busiest first, marking those with none. Profiles that served
+// requests but no longer exist are listed too.
+func printAppProfileUsage(w io.Writer, width int, profiles []string, requests map[string]int64) {
+	exists := map[string]bool{}
+	for _, p := range profiles {
+		exists[p] = true
+	}
+	names := append([]string(nil), profiles...)
+	for p := range requests {
+		if !exists[p] {
+			names = append(names, p)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if ri, rj := requests[names[i]], requests[names[j]]; ri != rj {
+			return ri > rj
+		}
+		return names[i] < names[j]
+	})
+	var rows [][]string
+	unused := 0
+	for _, p := range names {
+		note := ""
+		switch {
+		case !exists[p]:
+			note = "deleted"
+		case requests[p] == 0:
+			note = "unused"
+			unused++
+		}
+		rows = append(rows, []string{p, strconv.FormatInt(requests[p], 10), note})
+	}
+	printTable(w, width, []string{"AppProfile", "Requests", ""}, rows)
+	fmt.Fprintf(w, "\n%d of %d app profiles served no requests.\n", unused, len(profiles))
+}
+
+func doAppProfileUsage(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt appprofileusage <instance-id> [window=<7d>]"
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+	parsed, err := parseArgs(args[1:], []string{"window"})
+	if err != nil {
+		return errors.New(usage)
+	}
+	window := 7 * 24 * time.Hour
+	if w := parsed["window"]; w != "" {
+		if window, err = parseDuration(w); err != nil || window < time.Minute {
+			return fmt.Errorf("Bad window %q: must be at least 1m", w)
+		}
+	}
+	instance := args[0]
+
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return err
+	}
+	var profiles []string
+	it := iac.ListAppProfiles(ctx, instance)
+	for {
+		profile, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to fetch app profile %v", err)
+		}
+		// Metrics are labelled with profile IDs.
+		profiles = append(profiles, appProfileID(profile.Name))
+	}
+	svc, err := e.getMonitoringService(ctx)
+	if err != nil {
+		return err
+	}
+	requests, err := fetchAppProfileRequests(ctx, svc, e.config.Project, instance, window, time.Now())
+	if err != nil {
+		return fmt.Errorf("Getting request metrics: %v", err)
+	}
+	fmt.Fprintf(e.stdout, "Requests to %s by app profile over the last %v\n\n", instance, window)
+	printAppProfileUsage(e.stdout, e.outputWidth(), profiles, requests)
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/monitoring/v3"
+	"google.golang.org/api/option"
+)
+
+func TestFetchAppProfileRequests(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		fmt.Fprint(w, `{"timeSeries": [
+			{"metric": {"labels": {"app_profile": "batch"}}, "points": [{"value": {"int64Value": "40"}}, {"value": {"int64Value": "2"}}]},
+			{"metric": {"labels": {"app_profile": "default"}}, "points": [{"value": {"int64Value": "7"}}]},
+			{"metric": {"labels": {"app_profile": "old"}}, "points": [{"value": {"int64Value": "1"}}]}]}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	svc, err := monitoring.NewService(ctx, option.WithEndpoint(srv.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests, err := fetchAppProfileRequests(ctx, svc, "proj", "inst", 24*time.Hour, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int64{"batch": 42, "default": 7, "old": 1}
+	if diff := cmp.Diff(want, requests); diff != "" {
+		t.Errorf("requests mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(query.Get("filter"), `resource.labels.instance="inst"`) ||
+		query.Get("aggregation.groupByFields") != "metric.labels.app_profile" ||
+		query.Get("aggregation.alignmentPeriod") != "86400s" {
+		t.Errorf("unexpected query %v", query)
+	}
+
+	var buf bytes.Buffer
+	printAppProfileUsage(&buf, 0, []string{"default", "batch", "idle"}, requests)
+	got := strings.Join(strings.Fields(buf.String()), " ")
+	for _, line := range []string{"batch 42 default 7 old 1 deleted idle 0 unused", "1 of 3 app profiles served no requests."} {
+		if !strings.Contains(got, line) {
+			t.Errorf("output does not contain %q:\n%s", line, buf.String())
+		}
+	}
+}
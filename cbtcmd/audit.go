@@ -0,0 +1,179 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// auditRecord is a line of the audit log. Each mutating command writes a
+// started record before it runs and an ok, failed or interrupted record when
+// it ends.
+type auditRecord struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user,omitempty"`
+	Host     string    `json:"host,omitempty"`
+	Project  string    `json:"project,omitempty"`
+	Instance string    `json:"instance,omitempty"`
+	Table    string    `json:"table,omitempty"`
+	Command  string    `json:"command"`
+	Args     []string  `json:"args"`
+	Status   string    `json:"status"`
+	Error    string    `json:"error,omitempty"`
+	Duration string    `json:"duration,omitempty"`
+}
+
+// auditLog records one run of a command in the audit log file.
+type auditLog struct {
+	path  string
+	start time.Time
+	// log gets the errors of appending the end record.
+	log *log.Logger
+	rec auditRecord
+}
+
+// expandHome replaces a leading ~/ in path with the home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[2:]), nil
+}
+
+// startAudit appends the started record of a command run with e to the
+// audit log at path. The command must not run if this fails, or it would go
+// unrecorded.
+func startAudit(e *env, path, command, table string, args []string) (*auditLog, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, fmt.Errorf("audit log: %v", err)
+	}
+	a := &auditLog{
+		path:  path,
+		start: time.Now(),
+		log:   e.log,
+		rec: auditRecord{
+			Project:  e.config.Project,
+			Instance: e.config.Instance,
+			Table:    table,
+			Command:  command,
+			Args:     args,
+			Status:   "started",
+		},
+	}
+	if a.rec.Args == nil {
+		a.rec.Args = []string{}
+	}
+	if u, err := user.Current(); err == nil {
+		a.rec.User = u.Username
+	}
+	if h, err := os.Hostname(); err == nil {
+		a.rec.Host = h
+	}
+	a.rec.Time = a.start.UTC()
+	if err := a.append(a.rec); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// finish appends the end record of the command from the error it returned:
+// ok if there is none, interrupted if SIGINT or SIGTERM stopped it, and
+// failed otherwise.
+func (a *auditLog) finish(err error) {
+	rec := a.rec
+	rec.Time = time.Now().UTC()
+	rec.Duration = time.Since(a.start).Round(time.Millisecond).String()
+	rec.Status = "ok"
+	if err != nil {
+		rec.Status = "failed"
+		if errors.Is(err, errInterrupted) {
+			rec.Status = "interrupted"
+		}
+		rec.Error = err.Error()
+	}
+	if err := a.append(rec); err != nil {
+		a.log.Print(err)
+	}
+}
+
+// append writes rec as a line of the audit log. The file is opened for each
+// record so that concurrent runs of cbt append whole lines.
+func (a *auditLog) append(rec auditRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("audit log: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(a.path), 0700); err != nil {
+		return fmt.Errorf("audit log: %v", err)
+	}
+	f, err := os.OpenFile(a.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("audit log: %v", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("audit log: writing %s: %v", a.path, err)
+	}
+	return f.Close()
+}
+
+// auditTable returns the table that a command acts on, judging from whether
+// the first argument of its usage is a table, or "" if it takes none.
+// defaultTable is the -table flag.
+func auditTable(name, usage, defaultTable string, args []string) string {
+	fields := strings.Fields(usage)
+	if len(fields) < 3 || !strings.Contains(fields[2], "table") {
+		return ""
+	}
+	omitted := len(args) == 0 || strings.Contains(args[0], "=")
+	if name == "set" {
+		omitted = setTableOmitted(args)
+	}
+	if omitted {
+		if defaultTable != "" && (name == "set" || strings.HasPrefix(fields[2], "[")) {
+			return defaultTable
+		}
+		return ""
+	}
+	return args[0]
+}
+
+// runAudited runs a mutating command between its started record and its ok,
+// failed or interrupted record in the audit log at path.
+func runAudited(ctx context.Context, e *env, path string, cmd *command, args []string) error {
+	a, err := startAudit(e, path, cmd.Name, auditTable(cmd.Name, cmd.Usage, e.config.Table, args), args)
+	if err != nil {
+		return err
+	}
+	err = cmd.do(ctx, e, args...)
+	a.finish(err)
+	return err
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAuditTable(t *testing.T) {
+	for _, test := range []struct {
+		name, usage string
+		args        []string
+		want        string
+	}{
+		{"deleteallrows", "cbt deleteallrows <table-id>", []string{"t1"}, "t1"},
+		{"deleteallrows", "cbt deleteallrows <table-id>", nil, ""},
+		{"renametable", "cbt renametable <old-table-id> <new-table-id>", []string{"t1", "t2"}, "t1"},
+		{"createcluster", "cbt createcluster <cluster-id> <zone>", []string{"c1", "us-east1-b"}, ""},
+		{"set", "cbt set <table-id> <row-key>", []string{"t1", "r1", "cf:c=v"}, "t1"},
+		{"set", "cbt set <table-id> <row-key>", []string{"r1", "cf:c=v"}, "default-table"},
+		{"generate", "cbt generate [<table-id>] rows=<n>", []string{"rows=10"}, "default-table"},
+	} {
+		if got := auditTable(test.name, test.usage, "default-table", test.args); got != test.want {
+			t.Errorf("auditTable(%q, %q, %q) = %q, want %q", test.name, test.usage, test.args, got, test.want)
+		}
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	path := filepath.Join(t.TempDir(), "cbt", "audit.jsonl")
+
+	ok, err := startAudit(e, path, "deleteallrows", "t1", []string{"t1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok.finish(nil)
+	failed, err := startAudit(e, path, "set", "t2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed.finish(errors.New("Setting cell: not found"))
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []auditRecord
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var rec auditRecord
+		if err := json.Unmarshal(s.Bytes(), &rec); err != nil {
+			t.Fatalf("line %q: %v", s.Text(), err)
+		}
+		if rec.Time.IsZero() || rec.User == "" {
+			t.Errorf("record %+v has no time or user", rec)
+		}
+		if (rec.Status == "started") != (rec.Duration == "") {
+			t.Errorf("record %+v: duration should be set only when the command ends", rec)
+		}
+		got = append(got, auditRecord{Project: rec.Project, Instance: rec.Instance, Table: rec.Table,
+			Command: rec.Command, Args: rec.Args, Status: rec.Status, Error: rec.Error})
+	}
+	rec := func(table, command string, args []string, status, errMsg string) auditRecord {
+		return auditRecord{Project: "my-project", Instance: "my-instance", Table: table,
+			Command: command, Args: args, Status: status, Error: errMsg}
+	}
+	want := []auditRecord{
+		rec("t1", "deleteallrows", []string{"t1"}, "started", ""),
+		rec("t1", "deleteallrows", []string{"t1"}, "ok", ""),
+		rec("t2", "set", []string{}, "started", ""),
+		rec("t2", "set", []string{}, "failed", "Setting cell: not found"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("audit log mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunAudited(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for _, want := range []error{
+		nil,
+		errors.New("Deleting rows: not found"),
+		interruptedAfter("purging %d rows", 3),
+	} {
+		cmd := &command{
+			Name:  "purge",
+			Usage: "cbt purge <table> older-than=<duration>",
+			do: func(ctx context.Context, e *env, args ...string) error {
+				return want
+			},
+		}
+		if err := runAudited(context.Background(), e, path, cmd, []string{"t1", "older-than=1d"}); err != want {
+			t.Errorf("runAudited returned %v, want the error of the command, %v", err, want)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec auditRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		got = append(got, rec.Status+": "+rec.Error)
+	}
+	want := []string{
+		"started: ", "ok: ",
+		"started: ", "failed: Deleting rows: not found",
+		"started: ", "interrupted: Interrupted after purging 3 rows",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("audit log statuses mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	t.Setenv("HOME", "/home/someone")
+	for path, want := range map[string]string{
+		"~/.cbt/audit.jsonl": "/home/someone/.cbt/audit.jsonl",
+		"/var/log/cbt.jsonl": "/var/log/cbt.jsonl",
+		"audit.jsonl":        "audit.jsonl",
+	} {
+		got, err := expandHome(path)
+		if err != nil || got != want {
+			t.Errorf("expandHome(%q) = %q, %v, want %q", path, got, err, want)
+		}
+	}
+}
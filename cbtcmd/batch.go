@@ -0,0 +1,305 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"cloud.google.com/go/bigtable"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
+)
+
+// batchManifest describes the tables and app profiles that 'cbt batch'
+// creates or updates.
+type batchManifest struct {
+	Tables      []manifestTable      `yaml:"tables"`
+	AppProfiles []manifestAppProfile `yaml:"app_profiles"`
+}
+
+type manifestTable struct {
+	Name     string           `yaml:"name"`
+	Splits   []string         `yaml:"splits"`
+	Families []manifestFamily `yaml:"families"`
+}
+
+type manifestFamily struct {
+	Name     string `yaml:"name"`
+	GCPolicy string `yaml:"gc_policy"`
+	Type     string `yaml:"type"`
+}
+
+type manifestAppProfile struct {
+	Name                string `yaml:"name"`
+	Instance            string `yaml:"instance"`
+	Description         string `yaml:"description"`
+	Routing             string `yaml:"routing"`
+	TransactionalWrites bool   `yaml:"transactional_writes"`
+}
+
+// batchOp is one operation of a batch. run returns a description of what was
+// done.
+type batchOp struct {
+	resource string
+	run      func(ctx context.Context) (string, error)
+}
+
+type batchResult struct {
+	resource, done string
+	err            error
+}
+
+// parseBatchManifest parses and checks a manifest, whose GC policies may use
+// the templates of cfg.
+func parseBatchManifest(cfg *Config, data []byte) (*batchManifest, error) {
+	m := &batchManifest{}
+	if err := yaml.UnmarshalStrict(data, m); err != nil {
+		return nil, err
+	}
+	for _, t := range m.Tables {
+		if t.Name == "" {
+			return nil, fmt.Errorf("table with no name")
+		}
+		for _, f := range t.Families {
+			if f.Name == "" {
+				return nil, fmt.Errorf("table %s: family with no name", t.Name)
+			}
+			if _, err := f.family(cfg); err != nil {
+				return nil, fmt.Errorf("table %s: family %s: %v", t.Name, f.Name, err)
+			}
+		}
+	}
+	for _, p := range m.AppProfiles {
+		if p.Name == "" {
+			return nil, fmt.Errorf("app profile with no name")
+		}
+		if _, _, err := parseProfileRoute(p.Routing); err != nil {
+			return nil, fmt.Errorf("app profile %s: routing must be route-any or route-to=<cluster-id>, %v", p.Name, err)
+		}
+	}
+	return m, nil
+}
+
+// family returns the configuration of a family. A family with no GC policy
+// keeps all cells.
+func (f manifestFamily) family(cfg *Config) (bigtable.Family, error) {
+	fam := bigtable.Family{GCPolicy: bigtable.NoGcPolicy()}
+	if f.GCPolicy != "" {
+		pol, err := cfg.parseGCPolicyArg(f.GCPolicy)
+		if err != nil {
+			return fam, err
+		}
+		fam.GCPolicy = pol
+	}
+	if f.Type != "" {
+		tpe, err := parseFamilyType(f.Type)
+		if err != nil {
+			return fam, err
+		}
+		fam.ValueType = tpe
+	}
+	return fam, nil
+}
+
+// tableOp creates a table, or adds missing families to an existing one and
+// sets the GC policies of the families listed with one. The manifest must
+// have been checked with cfg.
+func tableOp(ac AdminAPI, cfg *Config, t manifestTable) batchOp {
+	return batchOp{resource: "table " + t.Name, run: func(ctx context.Context) (string, error) {
+		ti, err := ac.TableInfo(ctx, t.Name)
+		if status.Code(err) == codes.NotFound {
+			conf := bigtable.TableConf{TableID: t.Name, SplitKeys: t.Splits, ColumnFamilies: map[string]bigtable.Family{}}
+			for _, f := range t.Families {
+				conf.ColumnFamilies[f.Name], _ = f.family(cfg)
+			}
+			if err := ac.CreateTableFromConf(ctx, &conf); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("created with %d families", len(t.Families)), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		existing := map[string]bool{}
+		for _, fi := range ti.FamilyInfos {
+			existing[fi.Name] = true
+		}
+		var done []string
+		for _, f := range t.Families {
+			fam, _ := f.family(cfg)
+			if !existing[f.Name] {
+				if err := ac.CreateColumnFamilyWithConfig(ctx, t.Name, f.Name, fam); err != nil {
+					return strings.Join(done, ", "), fmt.Errorf("creating family %s: %v", f.Name, err)
+				}
+				done = append(done, "created family "+f.Name)
+				continue
+			}
+			if f.GCPolicy != "" {
+				if err := ac.SetGCPolicy(ctx, t.Name, f.Name, fam.GCPolicy); err != nil {
+					return strings.Join(done, ", "), fmt.Errorf("setting GC policy of %s: %v", f.Name, err)
+				}
+				done = append(done, "set GC policy of "+f.Name)
+			}
+		}
+		if len(done) == 0 {
+			return "unchanged", nil
+		}
+		return strings.Join(done, ", "), nil
+	}}
+}
+
+// appProfileOp creates an app profile, or updates an existing one. Profiles
+// with no instance are in defaultInstance.
+func appProfileOp(iac InstanceAdminAPI, defaultInstance string, p manifestAppProfile) batchOp {
+	instance := p.Instance
+	if instance == "" {
+		instance = defaultInstance
+	}
+	return batchOp{resource: "app profile " + instance + "/" + p.Name, run: func(ctx context.Context) (string, error) {
+		routing, cluster, _ := parseProfileRoute(p.Routing)
+		_, err := iac.GetAppProfile(ctx, instance, p.Name)
+		if status.Code(err) == codes.NotFound {
+			conf := bigtable.ProfileConf{
+				InstanceID:               instance,
+				ProfileID:                p.Name,
+				Description:              p.Description,
+				RoutingPolicy:            routing,
+				ClusterID:                cluster,
+				AllowTransactionalWrites: p.TransactionalWrites,
+			}
+			if _, err := iac.CreateAppProfile(ctx, conf); err != nil {
+				return "", err
+			}
+			return "created", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		attrs := bigtable.ProfileAttrsToUpdate{
+			Description:              p.Description,
+			RoutingPolicy:            routing,
+			ClusterID:                cluster,
+			AllowTransactionalWrites: p.TransactionalWrites,
+		}
+		if err := iac.UpdateAppProfile(ctx, instance, p.Name, attrs); err != nil {
+			return "", err
+		}
+		return "updated", nil
+	}}
+}
+
+// runBatchOps runs ops with up to workers at a time, returning their results
+// in the order of ops.
+func runBatchOps(ctx context.Context, ops []batchOp, workers int) []batchResult {
+	results := make([]batchResult, len(ops))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, op := range ops {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, op batchOp) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			done, err := op.run(ctx)
+			results[i] = batchResult{resource: op.resource, done: done, err: err}
+		}(i, op)
+	}
+	wg.Wait()
+	return results
+}
+
+// printBatchSummary prints a line for each result and returns the number of
+// operations that failed.
+func printBatchSummary(w io.Writer, results []batchResult) int {
+	tw := tabwriter.NewWriter(w, 10, 8, 4, ' ', 0)
+	fmt.Fprintf(tw, "Resource\tResult\n")
+	fmt.Fprintf(tw, "--------\t------\n")
+	failed := 0
+	for _, r := range results {
+		result := r.done
+		if r.err != nil {
+			failed++
+			result = "FAILED: " + r.err.Error()
+			if r.done != "" {
+				result = r.done + "; " + result
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", r.resource, result)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\n%d operations, %d failed\n", len(results), failed)
+	return failed
+}
+
+func doBatch(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt batch <manifest.yaml> [workers=<n>]"
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+	parsed, err := parseArgs(args[1:], []string{"workers"})
+	if err != nil {
+		return errors.New(usage)
+	}
+	workers := 4
+	if s := parsed["workers"]; s != "" {
+		workers, err = strconv.Atoi(s)
+		if err != nil || workers < 1 {
+			return fmt.Errorf("Bad workers %q", s)
+		}
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	m, err := parseBatchManifest(e.config, data)
+	if err != nil {
+		return fmt.Errorf("Reading %s: %v", args[0], err)
+	}
+
+	var ops []batchOp
+	if len(m.Tables) > 0 {
+		ac, err := e.getAdminClient()
+		if err != nil {
+			return err
+		}
+		for _, t := range m.Tables {
+			ops = append(ops, tableOp(ac, e.config, t))
+		}
+	}
+	if len(m.AppProfiles) > 0 {
+		iac, err := e.getInstanceAdminClient()
+		if err != nil {
+			return err
+		}
+		for _, p := range m.AppProfiles {
+			ops = append(ops, appProfileOp(iac, e.config.Instance, p))
+		}
+	}
+	if failed := printBatchSummary(e.stdout, runBatchOps(ctx, ops, workers)); failed > 0 {
+		return fmt.Errorf("%d of %d operations failed", failed, len(ops))
+	}
+	return nil
+}
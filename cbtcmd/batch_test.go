@@ -0,0 +1,148 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseBatchManifest(t *testing.T) {
+	m, err := parseBatchManifest(&Config{}, []byte(`
+tables:
+- name: t1
+  splits: [a, m]
+  families:
+  - name: f1
+    gc_policy: maxage=1d or maxversions=2
+  - name: f2
+    type: intsum
+app_profiles:
+- name: p1
+  routing: route-any
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Tables) != 1 || len(m.Tables[0].Families) != 2 || len(m.AppProfiles) != 1 {
+		t.Errorf("parseBatchManifest returned %+v", m)
+	}
+
+	for _, bad := range []string{
+		"tables:\n- splits: [a]\n",
+		"tables:\n- name: t\n  families:\n  - name: f\n    gc_policy: maxage=soon\n",
+		"tables:\n- name: t\n  families:\n  - name: f\n    type: nosuchtype\n",
+		"app_profiles:\n- name: p\n  routing: somewhere\n",
+		"tabels:\n- name: t\n",
+	} {
+		if _, err := parseBatchManifest(&Config{}, []byte(bad)); err == nil {
+			t.Errorf("parseBatchManifest(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestBatchTables(t *testing.T) {
+	ctx, ac, _ := newEmulatorClients(t)
+	if err := ac.CreateTable(ctx, "existing"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.CreateColumnFamily(ctx, "existing", "old"); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := parseBatchManifest(&Config{}, []byte(`
+tables:
+- name: new
+  families:
+  - name: f1
+  - name: f2
+    gc_policy: maxversions=1
+- name: existing
+  families:
+  - name: old
+    gc_policy: maxversions=3
+  - name: added
+- name: untouched
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.CreateTable(ctx, "untouched"); err != nil {
+		t.Fatal(err)
+	}
+	var ops []batchOp
+	for _, tbl := range m.Tables {
+		ops = append(ops, tableOp(ac, &Config{}, tbl))
+	}
+	ops = append(ops, batchOp{resource: "broken", run: func(context.Context) (string, error) {
+		return "", errors.New("boom")
+	}})
+	results := runBatchOps(ctx, ops, 2)
+
+	var got []string
+	for _, r := range results {
+		s := r.resource + ": " + r.done
+		if r.err != nil {
+			s += " " + r.err.Error()
+		}
+		got = append(got, s)
+	}
+	want := []string{
+		"table new: created with 2 families",
+		"table existing: set GC policy of old, created family added",
+		"table untouched: unchanged",
+		"broken:  boom",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("results mismatch (-want +got):\n%s", diff)
+	}
+
+	for table, wantFams := range map[string][]string{
+		"new":      {"f1", "f2"},
+		"existing": {"added", "old"},
+	} {
+		ti, err := ac.TableInfo(ctx, table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fams []string
+		for _, fi := range ti.FamilyInfos {
+			fams = append(fams, fi.Name)
+			if table == "existing" && fi.Name == "old" && fi.GCPolicy != "versions() > 3" {
+				t.Errorf("GC policy of existing:old = %q, want versions() > 3", fi.GCPolicy)
+			}
+		}
+		sort.Strings(fams)
+		if diff := cmp.Diff(wantFams, fams); diff != "" {
+			t.Errorf("families of %s mismatch (-want +got):\n%s", table, diff)
+		}
+	}
+
+	var buf bytes.Buffer
+	if failed := printBatchSummary(&buf, results); failed != 1 {
+		t.Errorf("printBatchSummary reported %d failures, want 1", failed)
+	}
+	if !strings.Contains(buf.String(), "FAILED: boom") {
+		t.Errorf("summary does not report the failure:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// Exports to BigQuery write the exported cells as newline-delimited JSON,
+// copy them to Cloud Storage with gcloud, and load them with bq, so that cbt
+// needs no BigQuery or Cloud Storage client of its own.
+
+// bigQueryCellsSchema is the schema of a table loaded by export, in the
+// inline form read by bq load. Like format=sqlite, it has a row per cell.
+const bigQueryCellsSchema = "key:BYTES,family:STRING,qualifier:BYTES,ts:TIMESTAMP,value:BYTES"
+
+// runBq runs bq and returns its trimmed standard output.
+var runBq = func(ctx context.Context, args ...string) (string, error) {
+	return runSDKTool(ctx, "bq", args...)
+}
+
+// bigQueryTable names a BigQuery table.
+type bigQueryTable struct {
+	project, dataset, table string
+}
+
+// String returns the table in the form used by bq.
+func (t bigQueryTable) String() string {
+	return t.project + ":" + t.dataset + "." + t.table
+}
+
+// parseBigQueryDest parses a destination of the form
+// bq://[<project>.]<dataset>.<table>, using defaultProject if the project is
+// omitted. Project IDs scoped to a domain, like example.com:my-project, may
+// contain dots, so the dataset and table are taken from the end.
+func parseBigQueryDest(dest, defaultProject string) (bigQueryTable, error) {
+	bad := fmt.Errorf("bad dest %q: want bq://[<project>.]<dataset>.<table>", dest)
+	rest, ok := strings.CutPrefix(dest, "bq://")
+	if !ok {
+		return bigQueryTable{}, bad
+	}
+	var t bigQueryTable
+	i := strings.LastIndex(rest, ".")
+	if i < 0 {
+		return t, bad
+	}
+	rest, t.table = rest[:i], rest[i+1:]
+	if i = strings.LastIndex(rest, "."); i >= 0 {
+		t.project, t.dataset = rest[:i], rest[i+1:]
+	} else {
+		t.project, t.dataset = defaultProject, rest
+	}
+	if t.project == "" || t.dataset == "" || t.table == "" {
+		return t, bad
+	}
+	return t, nil
+}
+
+// validateBigQueryExport checks the arguments of an export to BigQuery,
+// staged in the Cloud Storage object staging.
+func validateBigQueryExport(staging string, ea exporterArgs) error {
+	if _, err := parseBigQueryDest(ea.dest, "default"); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(staging, "gs://") || strings.HasSuffix(staging, "/") {
+		return fmt.Errorf("dest=bq://... requires a Cloud Storage object to stage the rows in as the output file, like gs://<bucket>/<path>.json")
+	}
+	switch {
+	case ea.format != "csv":
+		return fmt.Errorf("dest=bq://... may not be combined with format=%s", ea.format)
+	case ea.parts || ea.resume:
+		return fmt.Errorf("parts and resume are not supported with dest=bq://...")
+	case ea.includeTimestamps:
+		return fmt.Errorf("include-timestamps is not needed with dest=bq://..., which always loads timestamps")
+	}
+	return nil
+}
+
+// bigQueryCell is the JSON form of a cell loaded into BigQuery. Row keys,
+// qualifiers and values may be any bytes, so they are base64 encoded, as
+// BigQuery expects for BYTES. Family names are always printable.
+type bigQueryCell struct {
+	Key       []byte `json:"key"`
+	Family    string `json:"family"`
+	Qualifier []byte `json:"qualifier"`
+	TS        string `json:"ts"`
+	Value     []byte `json:"value"`
+}
+
+// bigQueryTimestamp formats a cell timestamp as a BigQuery TIMESTAMP.
+func bigQueryTimestamp(ts bigtable.Timestamp) string {
+	return ts.Time().UTC().Format("2006-01-02 15:04:05.000000 UTC")
+}
+
+// bigQueryLoadArgs returns the bq arguments loading the staged cells into t.
+func bigQueryLoadArgs(t bigQueryTable, staging string, replace bool) []string {
+	args := []string{"--project_id=" + t.project, "load", "--source_format=NEWLINE_DELIMITED_JSON"}
+	if replace {
+		args = append(args, "--replace")
+	}
+	return append(args, t.String(), staging, bigQueryCellsSchema)
+}
+
+// exportBigQuery writes the selected cells of tbl to the Cloud Storage object
+// staging and loads them into the BigQuery table ea.dest, returning the number
+// of rows read. The staged object is left in place.
+func exportBigQuery(ctx context.Context, e *env, tbl TableReader, staging string, ea exporterArgs) (int, error) {
+	if err := validateBigQueryExport(staging, ea); err != nil {
+		return 0, err
+	}
+	dest, err := parseBigQueryDest(ea.dest, e.config.Project)
+	if err != nil {
+		return 0, err
+	}
+	f, err := os.CreateTemp("", "cbt-export-*.json")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	n, err := ea.readShards(ctx, e.log, tbl, func(r bigtable.Row) error {
+		return forEachCell(r, func(fam, qual string, item bigtable.ReadItem) error {
+			return enc.Encode(bigQueryCell{
+				Key:       []byte(r.Key()),
+				Family:    fam,
+				Qualifier: []byte(qual),
+				TS:        bigQueryTimestamp(item.Timestamp),
+				Value:     item.Value,
+			})
+		})
+	})
+	if err != nil {
+		return n, err
+	}
+	if err := w.Flush(); err != nil {
+		return n, err
+	}
+	if err := f.Close(); err != nil {
+		return n, err
+	}
+
+	e.log.Printf("Read %d rows; copying them to %s\n", n, staging)
+	if _, err := runGcloud(ctx, "storage", "cp", f.Name(), staging); err != nil {
+		return n, err
+	}
+	e.log.Printf("Loading %s into BigQuery table %s\n", staging, dest)
+	if _, err := runBq(ctx, bigQueryLoadArgs(dest, staging, ea.bqReplace)...); err != nil {
+		return n, err
+	}
+	return n, nil
+}
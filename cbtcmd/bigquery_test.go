@@ -0,0 +1,131 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseBigQueryDest(t *testing.T) {
+	for _, test := range []struct {
+		dest string
+		want bigQueryTable
+	}{
+		{"bq://p.d.t", bigQueryTable{"p", "d", "t"}},
+		{"bq://d.t", bigQueryTable{"default-project", "d", "t"}},
+		{"bq://example.com:p.d.t", bigQueryTable{"example.com:p", "d", "t"}},
+	} {
+		got, err := parseBigQueryDest(test.dest, "default-project")
+		if err != nil || got != test.want {
+			t.Errorf("parseBigQueryDest(%q) = %+v, %v, want %+v", test.dest, got, err, test.want)
+		}
+	}
+	for _, bad := range []string{"p.d.t", "bq://t", "bq://d.", "bq://.t"} {
+		if _, err := parseBigQueryDest(bad, "default-project"); err == nil {
+			t.Errorf("parseBigQueryDest(%q) did not fail", bad)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"my-table", "cells.json", "dest=bq://d.t"},
+		{"my-table", "gs://b/", "dest=bq://d.t"},
+		{"my-table", "gs://b/cells.json", "dest=d.t"},
+		{"my-table", "gs://b/cells.json", "dest=bq://d.t", "format=sqlite"},
+		{"my-table", "gs://b/cells.json", "dest=bq://d.t", "include-timestamps=true"},
+	} {
+		if _, err := parseExporterArgs(args); err == nil {
+			t.Errorf("parseExporterArgs(%q) did not fail", args)
+		}
+	}
+}
+
+func TestExportBigQuery(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	defer func(g, b func(context.Context, ...string) (string, error)) {
+		runGcloud, runBq = g, b
+	}(runGcloud, runBq)
+
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f", "g"})
+	tbl := c.Open("my-table")
+	mut := bigtable.NewMutation()
+	mut.Set("g", "b", 1000, []byte{0, 1})
+	mut.Set("f", "a", 1700000000000000, []byte("x"))
+	if err := tbl.Apply(ctx, "r1", mut); err != nil {
+		t.Fatal(err)
+	}
+	// Binary row keys and qualifiers that aren't UTF-8 must keep their bytes.
+	mut = bigtable.NewMutation()
+	mut.Set("f", "\xfe", 1000, []byte("y"))
+	if err := tbl.Apply(ctx, "\xff\x00", mut); err != nil {
+		t.Fatal(err)
+	}
+
+	var staged []bigQueryCell
+	runGcloud = func(ctx context.Context, args ...string) (string, error) {
+		if args[0] != "storage" || args[1] != "cp" || args[3] != "gs://b/cells.json" {
+			t.Errorf("gcloud called with %q", args)
+		}
+		data, err := os.ReadFile(args[2])
+		if err != nil {
+			return "", err
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var cell bigQueryCell
+			if err := json.Unmarshal([]byte(line), &cell); err != nil {
+				return "", err
+			}
+			staged = append(staged, cell)
+		}
+		return "", nil
+	}
+	var loadArgs []string
+	runBq = func(ctx context.Context, args ...string) (string, error) {
+		loadArgs = args
+		return "", nil
+	}
+
+	ea, err := parseExporterArgs([]string{"my-table", "gs://b/cells.json", "dest=bq://analytics.cells", "bq-replace=true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := exportBigQuery(ctx, e, tbl, "gs://b/cells.json", ea)
+	if err != nil || n != 2 {
+		t.Fatalf("exportBigQuery = %d, %v, want 2 rows", n, err)
+	}
+	wantCells := []bigQueryCell{
+		{Key: []byte("r1"), Family: "f", Qualifier: []byte("a"), TS: "2023-11-14 22:13:20.000000 UTC", Value: []byte("x")},
+		{Key: []byte("r1"), Family: "g", Qualifier: []byte("b"), TS: "1970-01-01 00:00:00.001000 UTC", Value: []byte{0, 1}},
+		{Key: []byte{0xff, 0}, Family: "f", Qualifier: []byte{0xfe}, TS: "1970-01-01 00:00:00.001000 UTC", Value: []byte("y")},
+	}
+	if diff := cmp.Diff(wantCells, staged); diff != "" {
+		t.Errorf("staged cells mismatch (-want +got):\n%s", diff)
+	}
+	wantArgs := []string{
+		"--project_id=my-project", "load", "--source_format=NEWLINE_DELIMITED_JSON", "--replace",
+		"my-project:analytics.cells", "gs://b/cells.json", bigQueryCellsSchema,
+	}
+	if diff := cmp.Diff(wantArgs, loadArgs); diff != "" {
+		t.Errorf("bq arguments mismatch (-want +got):\n%s", diff)
+	}
+}
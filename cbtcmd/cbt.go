@@ -0,0 +1,4864 @@
+/*
+Copyright 2015 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+// Command docs are in cbtdoc.go.
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	btapb "cloud.google.com/go/bigtable/admin/apiv2/adminpb"
+	btpb "cloud.google.com/go/bigtable/apiv2/bigtablepb"
+	"cloud.google.com/go/cbt/cli"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var (
+	version      = "<unknown version>"
+	revision     = "<unknown revision>"
+	revisionDate = "<unknown revision date>"
+	cliUserAgent = "cbt-cli-go/unknown"
+
+	//go:embed THIRD_PARTY_NOTICES.txt
+	noticesContents []byte
+)
+
+// Main runs cbt with the command line in os.Args, and exits.
+func Main() {
+	cfg, err := Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg.RegisterFlags()
+	oFlag := flag.String("o", "", "if set, redirect stdout to this file")
+
+	flag.Usage = func() { usage(os.Stderr) }
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage(os.Stderr)
+		os.Exit(1)
+	}
+
+	colorStderr, err := useColor(cfg.Color, os.Stderr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if colorStderr {
+		log.SetOutput(colorLog{log.Writer()})
+	}
+	deps := Deps{Stdout: os.Stdout, Stderr: log.Writer()}
+	var out *os.File
+	if *oFlag != "" {
+		if out, err = os.Create(*oFlag); err != nil {
+			log.Fatal(err)
+		}
+		deps.Stdout = out
+	}
+
+	ctx, stop := withInterrupt(context.Background())
+	err = runCommand(ctx, cfg, flag.Args(), deps)
+	stop()
+	if out != nil {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}
+	os.Exit(exitStatus(err))
+}
+
+// exitStatus logs the error of a command, and returns the status for cbt to
+// exit with after it.
+func exitStatus(err error) int {
+	var ee *ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &ee):
+		return ee.Code
+	case errors.Is(err, errInterrupted):
+		log.Print(err)
+		return interruptedExitCode
+	}
+	log.Print(err)
+	return 1
+}
+
+// RunCommand runs the cbt command in args, such as []string{"ls", "-l"}, with
+// cfg and deps, as cbt does with the flags in cfg. If deps has any client,
+// cfg's flags and credentials aren't checked.
+//
+// RunCommand returns the error of a command that fails. An *ExitError
+// reports that the command ended with a status other than 0 or 1, such as
+// exists when the row is missing.
+func RunCommand(cfg *Config, args []string, deps Deps) error {
+	return runCommand(context.Background(), cfg, args, deps)
+}
+
+// findCommand returns the command called name, or nil if there is none.
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].Name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func runCommand(ctx context.Context, cfg *Config, args []string, deps Deps) (err error) {
+	if len(args) == 0 {
+		return errors.New("no command")
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		return fmt.Errorf("Unknown command %q", args[0])
+	}
+
+	e := newEnv(cfg, deps)
+	defer e.close()
+	if cfg.UserAgent != "" {
+		e.userAgent = cfg.UserAgent
+	}
+	if cfg.WorkloadTag != "" {
+		ua, err := workloadUserAgent(e.userAgent, cfg.WorkloadTag)
+		if err != nil {
+			return err
+		}
+		e.userAgent = ua
+	}
+
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+	if cfg.AuthToken != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-goog-iam-authorization-token", cfg.AuthToken)
+	}
+
+	cmdArgs := args[1:]
+	var allInstances bool
+	if cmd.FanOut {
+		if allInstances, cmdArgs, err = parseAllInstances(cmdArgs); err != nil {
+			return err
+		}
+	}
+	required := cmd.Required
+	fanOut := cfg.Instances != "" || allInstances
+	if fanOut {
+		if !cmd.FanOut {
+			return fmt.Errorf("cbt %s can't run on several instances", cmd.Name)
+		}
+		required &^= InstanceRequired
+	}
+	if cfg.Creds == "-" && readsStdin(cmd.Name, cmdArgs) {
+		return fmt.Errorf("-creds=- can't be used with cbt %s reading from stdin; pass the key in $%s instead", cmd.Name, credsJSONEnv)
+	}
+	if !deps.hasClients() {
+		if err := cfg.CheckFlags(required); err != nil {
+			return err
+		}
+	}
+	if cfg.ProxyURL != nil && cfg.Environment != "emulator" {
+		useHTTPProxy(cfg.ProxyURL)
+	}
+	if e.color, err = useColor(cfg.Color, e.stdout); err != nil {
+		return err
+	}
+	if cmd.Paged && !cfg.NoPager && isTerminal(e.stdout) && !isFollowing(cmdArgs) {
+		if pipe, stop := startPager(e.stdout, e.log.Writer()); pipe != nil {
+			e.stdout = pipe
+			defer func() {
+				// Show the error at the end of the paged output too.
+				if err != nil {
+					fmt.Fprintln(pipe, err)
+				}
+				stop()
+			}()
+		}
+	}
+
+	switch {
+	case fanOut:
+		instances, err := fanOutInstances(ctx, e, cfg.Instances, allInstances)
+		if err != nil {
+			return err
+		}
+		return runFanOut(ctx, e, instances, cmd.do, cmdArgs)
+	case cmd.Mutating && cfg.AuditLog != "":
+		return runAudited(ctx, e, cfg.AuditLog, cmd, cmdArgs)
+	}
+	return cmd.do(ctx, e, cmdArgs...)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s [flags] <command> ...\n", os.Args[0])
+	flag.CommandLine.SetOutput(w)
+	flag.CommandLine.PrintDefaults()
+	fmt.Fprintf(w, "\n%s", cmdSummary)
+}
+
+var cmdSummary string // generated in init, below
+
+func init() {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 10, 8, 4, '\t', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "cbt %s\t%s\n", cmd.Name, cmd.Desc)
+	}
+	tw.Flush()
+	buf.WriteString(configHelp)
+	buf.WriteString("\ncbt " + version + " " + revision + " " + revisionDate + "\n")
+	cmdSummary = buf.String()
+}
+
+const configHelp = `
+Preview features are not available to most Bigtable customers, they
+might be changed in backward-incompatible ways and are not recommended
+for production use. They are not subject to any SLA or deprecation policy.
+
+Syntax rules for the Bash shell apply to the ` + "`cbt`" + ` CLI. This means, for example,
+that you must put quotes around values that contain spaces or operators. It also means that
+if a value is arbitrary bytes, you need to prefix it with a dollar sign and use single quotes.
+
+Example:
+
+cbt -project my-project -instance my-instance lookup my-table $'\224\257\312W\365:\205d\333\2471\315\'
+
+Interrupting read, count, import or export with Ctrl-C (SIGINT) or SIGTERM stops it cleanly: it
+logs how much work was done, an export to a file saves its checkpoint for resume=true, and cbt
+exits with status 130. Interrupt again to exit at once.
+
+Commands that wait on long operations without output, such as deleteallrows, waitforreplication,
+copybackup and restoretable, log that they are still running every 30 seconds, with the
+operation's progress where it is known.
+
+
+For convenience, you can add values for the -project, -instance, -creds, -admin-endpoint and -data-endpoint
+options to your ~/.cbtrc file in the following format:
+
+
+    project = my-project-123
+    instance = my-instance
+    creds = path-to-account-key.json
+    environment = staging
+    universe-domain = example-universe.com
+    admin-endpoint = hostname:port
+    data-endpoint = hostname:port
+    proxy = proxy.example.com:3128
+    auth-token = AJAvW039NO1nDcijk_J6_rFXG_...
+    timeout = 30s
+    table = my-table
+    color = never
+    workload-tag = nightly-etl
+    gcpolicy.default = maxage=30d or maxversions=3
+    audit-log = ~/.cbt/audit.jsonl
+
+All values are optional and can be overridden at the command prompt.
+
+If a default table is set with "table" or the -table flag, the table argument
+can be omitted from the read, lookup, set and count commands:
+
+    cbt lookup phone#4c410523#20190501 columns=stats_summary:os_name
+
+GC policies defined as gcpolicy.<name> can be used by name in createtable and
+setgcpolicy, to keep policies consistent across tables:
+
+    cbt createtable mobile-time-series families=stats_summary:@default
+    cbt setgcpolicy mobile-time-series stats_detail policy=@default
+
+To avoid writing a key file to disk, pass the JSON key on stdin with -creds=-,
+or set the CBT_CREDS_JSON environment variable to the JSON key itself:
+
+    vault read -field=key secret/bigtable | cbt -creds=- ls
+    CBT_CREDS_JSON="$(cat key.json)" cbt ls
+
+Commands that read their own input from stdin, such as lookup -stdin, can't be used with
+-creds=-; use CBT_CREDS_JSON with them.
+
+Without -creds, CBT_CREDS_JSON or -access-token, cbt gets a token from gcloud. The token is
+cached in cbt/gcloud-token.json under your user configuration directory, readable
+only by you, and reused until shortly before it expires, or until the active gcloud
+configuration, account or project changes. Use -no-token-cache to run gcloud on every
+invocation.
+
+To attribute the requests of a team or job in server-side logs and support cases, set
+-workload-tag or "workload-tag". The tag is appended to the user agent as workload/<tag>
+and sent with every request as x-cbt-workload-tag metadata:
+
+    cbt -workload-tag=nightly-etl import my-table data.csv
+
+Instead of setting -admin-endpoint and -data-endpoint, set -environment or "environment"
+to prod (the default), staging or emulator. An explicitly set endpoint overrides the
+preset. emulator sends requests without credentials to $BIGTABLE_EMULATOR_HOST,
+-data-endpoint or localhost:8086, and defaults -project and -instance to "emulator".
+custom requires both endpoints. Other environments ignore $BIGTABLE_EMULATOR_HOST:
+
+    cbt -environment=emulator createtable my-table families=cf
+    cbt -environment=custom -admin-endpoint=admin.example.com:443 -data-endpoint=data.example.com:443 ls
+
+To use cbt in a universe other than googleapis.com, such as Trusted Partner Cloud, set
+-universe-domain or "universe-domain". The default endpoints become bigtable.<domain>:443
+and bigtableadmin.<domain>:443, and the universe of the credentials must match:
+
+    cbt -universe-domain=example-universe.com -creds=key.json ls
+
+Behind an egress proxy, cbt connects through the HTTP proxy in $HTTPS_PROXY, except to
+hosts listed in $NO_PROXY. To set the proxy for cbt alone, set -proxy or "proxy" to
+[http://|https://][<user>:<password>@]<host>:<port>. It is used for the Bigtable APIs,
+for BigQuery and Cloud Storage, and to fetch tokens, but not by gcloud or the emulator:
+
+    cbt -proxy=http://proxy.example.com:3128 ls
+
+To audit a fleet, the read-only commands count, describeinstance, listclusters, ls and
+tablesize run on several instances with -instances=<instance-id>,... or on every instance of
+the project with the all-instances=true argument. Each line of output starts with the
+instance; JSON output becomes an object of each instance's output:
+
+    cbt -instances=prod-us,prod-eu ls
+    cbt describeinstance all-instances=true format=json
+
+To keep a record of changes, set -audit-log or "audit-log" to a file. Commands that change
+tables, data or instances, such as set, import, deleteallrows and deletetable, append a JSON
+line with the time, user, host, project, instance, table and arguments before they run, and
+another with the status ok, failed or interrupted, the error or what an interrupted command
+completed, and the duration when they end:
+
+    {"time":"2024-05-01T09:30:00Z","user":"alice","host":"bastion-1","project":"my-project","instance":"my-instance","table":"my-table","command":"deleteallrows","args":["my-table"],"status":"ok","duration":"1.2s"}
+`
+
+// const formatHelp = `
+
+// ## Custom data formatting for the ` + "`" + `lookup` + "`" +
+// 	` and ` + "`" + `read` + "`" + ` commands.
+
+// You can provide custom formatting information for formatting stored
+// data values in the ` + "`" + `lookup` + "`" + ` and ` + "`" + `read` +
+// 	"`" + ` commands.
+
+// The formatting data follows a formatting model consisting of an
+// encoding and type for each column.
+
+// The available encodings are:
+
+// - ` + "`" + `Hex` + "`" + ` (alias: ` + "`" + `H` + "`" + `)
+
+// - ` + "`" + `BigEndian` + "`" + ` (aliases: ` + "`" + `BINARY` + "`" + `, ` +
+// 	"`" + `B` + "`" + `)
+
+// - ` + "`" + `LittleEndian` + "`" + ` (alias: ` + "`" + `L` + "`" + `)
+
+// - ` + "`" + `ProtocolBuffer` + "`" + ` (aliases: ` + "`" + `Proto` + "`" + `, ` +
+// 	"`" + `P` + "`" + `)
+
+// Encoding names and aliases are case insensitive.
+
+// The Hex encoding is type agnostic. Data are displayed as a raw
+// hexadecimal representation of the stored data.
+
+// The available types for the BigEndian and LittleEndian encodings are ` +
+// 	"`" + `int8` + "`" + `, ` + "`" + `int16` + "`" + `, ` + "`" +
+// 	`int32` + "`" + `, ` + "`" + `int64` + "`" + `, ` + "`" + `uint8` +
+// 	"`" + `, ` + "`" + `uint16` + "`" + `, ` + "`" + `uint32` + "`" + `, ` +
+// 	"`" + `uint64` + "`" + `, ` + "`" + `float32` + "`" + `, and ` + "`" +
+// 	`float64` + "`" + `.  Stored data length must be a multiple of the
+// type sized, in bytes.  Data are displayed as scalars if the stored
+// length matches the type size, or as arrays otherwise.  Types names are case
+// insensitive.
+
+// The types given for the ` + "`" + `ProtocolBuffer` + "`" + ` encoding
+// must case-insensitively match message types defined in provided
+// protocol-buffer definition files.  If no type is specified, it
+// defaults to the column name for the column data being displayed.
+
+// Encoding and type are provided at the column level.  Default encodings
+// and types may be provided overall and at the column-family level.  You
+// don't need to define column formatting at the family level unless you
+// have multiple column families and want to provide family-specific
+// defaults or need to specify different formats for columns of the same
+// name in different families.
+
+// Protocol-buffer definition files may be given, as well as directories
+// used to search for definition files and files imported by them. If
+// no paths are specified, then the current working directory is used.
+// Locations of standard protocol buffer imports (` + "`" +
+// 	`google/protobuf/*` + "`" + `) need not be specified.
+
+// Format information in YAML format is provided using the ` + "`" +
+// 	`format-file` + "`" + ` option for the ` + "`" + `lookup` + "`" + `
+// and ` + "`" + `read` + "`" + ` commands (e.g ` + "`" +
+// 	`format-file=myformat.yml` + "`" + `).
+
+// The YAML file provides an object with optional properties:
+
+// ` + "`" + `default_encoding` + "`" + `
+// : The name of the overall default encoding
+
+// ` + "`" + `default_type` + "`" + `
+// : The name of the overall default type
+
+// ` + "`" + `protocol_buffer_definitions` + "`" + `
+// : A list of protocol-buffer files defining
+// : available message types.
+
+// ` + "`" + `protocol_buffer_paths` + "`" + `
+// : A list of directories to search for definition
+// : files and imports. If not provided, the current
+// : working directory will be used. Locations
+// : need not be provided for standard
+// : protocol-buffer imports.
+
+// ` + "`" + `columns` + "`" + `
+// : A mapping from column names to column objects.
+
+// ` + "`" + `families` + "`" + `
+// : A mapping from family names to family objects.
+
+// Column objects have two properties:
+
+// ` + "`" + `encoding` + "`" + `
+// : The encoding to be used for the column
+// : (overriding the default encoding, if any)
+
+// ` + "`" + `type` + "`" + `
+// : The data type to be used for the column
+// : (overriding the default type, if any)
+
+// Family objects have properties:
+
+// ` + "`" + `default_encoding` + "`" + `
+// : The name of the default encoding for columns in
+// : the family
+
+// ` + "`" + `default_type` + "`" + `
+// : The name of the default type for columns in the
+// : family
+
+// ` + "`" + `columns` + "`" + `
+// : A mapping from column names to column objects for
+// : columns in the family.
+
+// Here's an example of a format file:` + "\n```" + `
+
+//   default_encoding: ProtocolBuffer
+
+//   protocol_buffer_definitions:
+//     - MyProto.proto
+
+//   columns:
+//     contact:
+//       type: person
+//     size:
+//       encoding: BigEndian
+//       type: uint32
+
+// ` + "```" + `
+// `
+
+const docIntroTemplate = `The ` + "`cbt`" + ` CLI is a command-line interface that lets you interact with Bigtable.
+See the [cbt CLI overview](https://cloud.google.com/bigtable/docs/cbt-overview) to learn how to install the ` + "`cbt`" + ` CLI.
+Before you use the ` + "`cbt`" + ` CLI, you should be familiar with the [Bigtable overview](https://cloud.google.com/bigtable/docs/overview).
+
+The examples on this page use [sample data](https://cloud.google.com/bigtable/docs/using-filters#data) similar to data
+that you might store in Bigtable.
+
+Usage:
+
+	cbt [-<option> <option-argument>] <command> <required-argument> [optional-argument]
+
+
+The commands are:
+{{range .Commands}}
+    {{printf "%-25s %s" .Name .Desc}}{{end}}
+
+The options are:
+{{range .Flags}}
+    -{{.Name}} string
+        {{.Usage}}{{end}}
+
+Example:  cbt -instance=my-instance ls
+
+Use "cbt help \<command>" for more information about a command.
+
+{{.ConfigHelp}}
+`
+
+// command is an entry of the commands table.
+type command struct {
+	Name, Desc string
+	do         func(context.Context, *env, ...string) error
+	Usage      string
+	Required   RequiredFlags
+	// Paged commands pipe their output through a pager on a terminal.
+	Paged bool
+	// Mutating commands change tables, data or instances, and are recorded
+	// in the audit log if there is one.
+	Mutating bool
+	// FanOut commands are read-only and can run on several instances with
+	// -instances or all-instances=true.
+	FanOut bool
+}
+
+var commands = []command{
+	{
+		Name: "addtocell",
+		Desc: "Add a value to an aggregate cell (write)",
+		do:   doAddToCell,
+		Usage: "cbt addtocell <table-id> <row-key> [app-profile=<app-profile-id>] [include-stats=full] <family>:<column>=<val>[@<timestamp>] ...\n\n" +
+			"  app-profile=<app profile id>          The app profile ID to use for the request\n" +
+			"  include-stats=full                    Print the request latency and retry count after writing\n" +
+			"  <family>:<column>=<val>[@<timestamp>] may be repeated to set multiple cells.\n\n" +
+			"    If <val> can be parsed as an integer it will be used as one, otherwise the call will fail.\n" +
+			"    timestamp is optional, and may be an integer, now, a time relative to now such as now-1h, or an\n" +
+			"    RFC 3339 time such as 2024-05-01T00:00:00Z.\n" +
+			"    If the timestamp cannot be parsed, '@<timestamp>' will be interpreted as part of the value.\n" +
+			"    For most uses, an integer timestamp is the number of microseconds since 1970-01-01 00:00:00 UTC.\n\n" +
+			"    Examples:\n" +
+			"      cbt addtocell table1 user1 sum_cf:col1=1@12345\n" +
+			"      cbt addtocell table1 user1 sum_cf:col1=1@now",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "analyzekeys",
+		Desc: "Check the row keys of an import file for hotspotting and other problems",
+		do:   doAnalyzeKeys,
+		Usage: "cbt analyzekeys <input-file> [key-column=<0>] [header-rows=<2>] [prefix-length=<n>]\n\n" +
+			"  input-file              A CSV file, as read by import, or - for standard input\n" +
+			"  key-column=<0>          The column holding the row keys\n" +
+			"  header-rows=<2>         The number of header rows to skip: 2 for the column family and qualifier\n" +
+			"                          rows read by import, or 1 with import column-family=\n" +
+			"  prefix-length=<n>       Group keys by their first n bytes, instead of by their first segment\n" +
+			"                          ending in one of # : | /\n\n" +
+			"  Prints the number of keys, duplicates, key lengths and the most common key prefixes, and warns\n" +
+			"  about keys Bigtable rejects and about layouts that concentrate writes on a few tablets: keys in\n" +
+			"  sorted order, keys starting with a timestamp or sequence number, and a dominant prefix.\n" +
+			"  Every distinct key is held in memory.\n\n" +
+			"    Examples:\n" +
+			"      cbt analyzekeys data.csv\n" +
+			"      cbt analyzekeys events.csv key-column=2 header-rows=1 prefix-length=6",
+		Required: NoneRequired,
+	},
+	{
+		Name: "appprofileusage",
+		Desc: "Show the number of requests served with each app profile of an instance",
+		do:   doAppProfileUsage,
+		Usage: "cbt appprofileusage <instance-id> [window=<7d>]\n\n" +
+			"  Reads the request counts of the instance's tables from Cloud Monitoring, grouped by app profile,\n" +
+			"  and prints them with the instance's app profiles, marking those that served no requests as\n" +
+			"  unused. Profiles that served requests but no longer exist are marked deleted. Requires the\n" +
+			"  monitoring.timeSeries.list permission.\n\n" +
+			"  window=<7d>                         The period to count requests over, ending now\n\n" +
+			"    Example: cbt appprofileusage my-instance window=30d",
+		Required: ProjectRequired,
+	},
+	{
+		Name: "batch",
+		Desc: "Create or update tables, families and app profiles from a manifest",
+		do:   doBatch,
+		Usage: "cbt batch <manifest.yaml> [workers=<n>]\n\n" +
+			"  Creates the tables and app profiles described in a YAML manifest, or updates them if they\n" +
+			"  exist. Missing families are added to existing tables and listed GC policies are set;\n" +
+			"  nothing is deleted. Operations run concurrently and a summary is printed at the end.\n\n" +
+			"  workers=<n>                         Number of operations to run at once (default 4)\n\n" +
+			"  The manifest has the form:\n\n" +
+			"    tables:\n" +
+			"    - name: mobile-time-series\n" +
+			"      splits: [phone#4c410523, phone#5c10102]\n" +
+			"      families:\n" +
+			"      - name: stats_summary\n" +
+			"        gc_policy: maxage=30d or maxversions=1\n" +
+			"      - name: counters\n" +
+			"        type: intsum\n" +
+			"    app_profiles:\n" +
+			"    - name: batch-jobs\n" +
+			"      instance: my-instance          # defaults to the configured instance\n" +
+			"      description: Offline jobs\n" +
+			"      routing: route-to=my-instance-c1\n" +
+			"      transactional_writes: false\n\n" +
+			"    Example: cbt batch bootstrap.yaml",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "checkanddelete",
+		Desc: "Delete a row, family or column only if a predicate matches",
+		do:   doCheckAndDelete,
+		Usage: "cbt checkanddelete <table-id> <row-key> (<family>:<column>[=<value>] | if=<filter>;...) [<family>[:<column>]] [app-profile=<app-profile-id>]\n\n" +
+			"  <family>:<column>[=<value>]         The predicate. Without a value it matches if the column has any cells;\n" +
+			"                                      with a value it matches if the column's latest cell has exactly that value\n" +
+			"  if=<filter>;...                     A predicate chaining filters, as in read's if= and all-of=, such as\n" +
+			"                                      column:<family>:<qualifier>, value:<regex> or latest:<n>. It matches if\n" +
+			"                                      the filters return any cell\n" +
+			"  <family>[:<column>]                 Delete only this family or column instead of the whole row\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the request\n\n" +
+			"  The check and the delete are applied atomically as a single conditional mutation.\n\n" +
+			"    Examples:\n" +
+			"      cbt checkanddelete mobile-time-series phone#4c410523#20190501 cell_plan:status=tombstoned\n" +
+			"      cbt checkanddelete mobile-time-series phone#4c410523#20190501 cell_plan:status=tombstoned stats_summary\n" +
+			"      cbt checkanddelete mobile-time-series phone#4c410523#20190501 'if=column:cell_plan:status;latest:1;value:tomb.*'",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "checksum",
+		Desc: "Compute a digest of the rows of a table to verify a copy of it",
+		do:   doChecksum,
+		Usage: "cbt checksum <table-id> [start=<row-key>] [end=<row-key>] [prefix=<row-key-prefix>] [workers=<1>] [splits=<row-key>,...] [app-profile=<app-profile-id>]\n\n" +
+			"  Reads the rows and prints a digest of their keys and of the qualifier, timestamp and value of\n" +
+			"  each of their cells, per range and in total. The total digest doesn't depend on how the table\n" +
+			"  is split, so two tables hold the same data if their totals match. To narrow down a mismatch,\n" +
+			"  checksum both tables with the same splits= and compare the digests of each range.\n\n" +
+			"  start=<row-key>                     Start reading at this row\n" +
+			"  end=<row-key>                       Stop reading before this row\n" +
+			"  prefix=<row-key-prefix>             Read rows with this prefix\n" +
+			"  workers=<1>                         Number of ranges to read concurrently. Without splits=,\n" +
+			"                                      the table is split into this many ranges at its sample\n" +
+			"                                      row keys\n" +
+			"  splits=<row-key>,...                Split the table into ranges at these row keys\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the request\n\n" +
+			"    Examples:\n" +
+			"      cbt checksum mobile-time-series workers=8\n" +
+			"      cbt checksum mobile-time-series splits=phone#4c410523,phone#5c10102",
+		Required: ProjectAndInstanceRequired,
+	},
+	{
+		Name: "clusterstats",
+		Desc: "Show CPU, storage and node count metrics of the instance's clusters",
+		do:   doClusterStats,
+		Usage: "cbt clusterstats [window=<duration>]\n\n" +
+			"  Reads the CPU utilization, storage utilization and node count of each cluster of the\n" +
+			"  instance from Cloud Monitoring, and prints their current, minimum and maximum values.\n" +
+			"  Requires the monitoring.timeSeries.list permission.\n\n" +
+			"  window=<duration>                   The period to summarize, ending now (default 1h)\n\n" +
+			"    Example: cbt clusterstats window=6h",
+		Required: ProjectAndInstanceRequired,
+	},
+	{
+		Name: "colstats",
+		Desc: "Show cell counts, value sizes and timestamp ranges of each column",
+		do:   doColStats,
+		Usage: "cbt colstats <table-id> [sample=<fraction>] [prefix=<row-key-prefix>] [app-profile=<app-profile-id>]\n\n" +
+			"  Reads a random sample of rows and prints, for each family:qualifier, the number of sampled rows\n" +
+			"  and cells that have it, the average and largest value size, and the oldest and newest cell\n" +
+			"  timestamps, to surface schema drift and unexpectedly large columns.\n\n" +
+			"  sample=<fraction>                   The fraction of rows to sample, default 0.05\n" +
+			"  prefix=<row-key-prefix>             Sample only rows with this prefix\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the request\n\n" +
+			"    Example: cbt colstats mobile-time-series sample=0.01",
+		Required: ProjectAndInstanceRequired,
+		Paged:    true,
+	},
+	{
+		Name: "completion",
+		Desc: "Print a shell completion script, or the resource names it completes",
+		do:   doCompletion,
+		Usage: "cbt completion bash\n" +
+			"cbt completion names <tables|families|app-profiles> [table=<table-id>] [refresh=<true|false>]\n\n" +
+			"  bash prints a bash completion script that completes commands, table IDs, and the values of\n" +
+			"  app-profile=, columns=, display= and hide= with the instance's real resource names.\n\n" +
+			"  names prints the names that the script completes. They are cached per instance for 5 minutes;\n" +
+			"  after that the cached names are printed while they are refreshed in the background.\n\n" +
+			"  table=<table-id>                    The table whose families to print\n" +
+			"  refresh=<true|false>                Fetch and cache the names without printing them\n\n" +
+			"    Example: source <(cbt completion bash)",
+		Required: NoneRequired,
+	},
+	{
+		Name: "console",
+		Desc: "Print the Cloud Console URL of the instance or a table",
+		do:   doConsole,
+		Usage: "cbt console [instance|tables|monitoring|table|keyvisualizer] [table=<table-id>] [-open]\n\n" +
+			"  Prints the Cloud Console URL of a page of the instance: its overview (the default), its\n" +
+			"  tables, its monitoring, or a table's overview or Key Visualizer. A table page uses the table\n" +
+			"  given with table=, or the default table.\n\n" +
+			"  table=<table-id>                    The table of the table or keyvisualizer page\n" +
+			"  -open                               Also open the URL in a browser\n\n" +
+			"    Examples:\n" +
+			"      cbt console\n" +
+			"      cbt console keyvisualizer table=mobile-time-series -open",
+		Required: ProjectAndInstanceRequired,
+	},
+	{
+		Name: "copybackup",
+		Desc: "Copy a backup to another cluster, instance or project",
+		do:   doCopyBackup,
+		Usage: "cbt copybackup <src-cluster> <src-backup> <dst-project> <dst-instance> <dst-cluster> <dst-backup> [expire=<d|timestamp>]\n\n" +
+			"  src-cluster      The cluster of the configured instance holding the backup\n" +
+			"  src-backup       The backup to copy\n" +
+			"  dst-project      The project to copy the backup to\n" +
+			"  dst-instance     The instance to copy the backup to\n" +
+			"  dst-cluster      The cluster to copy the backup to\n" +
+			"  dst-backup       The ID of the new backup\n" +
+			"  expire=<d|timestamp>  Expiration of the copy, either a duration from now (e.g. \"30d\") or an\n" +
+			"                        RFC 3339 timestamp. Defaults to the expiration of the source backup.\n\n" +
+			"    Example: cbt copybackup my-instance-c1 my-backup other-project other-instance other-instance-c1 my-backup-copy expire=30d",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "count",
+		Desc: "Count rows in a table",
+		do:   doCount,
+		Usage: "cbt count <table-id> [prefix=<row-key-prefix>] [retries=<n>] [progress-interval=<duration>] [app-profile=<app-profile-id>] [priority=<low|medium|high>]\n\n" +
+			"  prefix=<row-key-prefix>             Count only rows whose keys start with this prefix\n" +
+			"  retries=<n>                         Resume the scan after a transient error up to n times in a row\n" +
+			"                                      without progress (default 5)\n" +
+			"  progress-interval=<duration>        Print the rows counted so far and the last row key this often\n" +
+			"                                      (default 1m); 0 prints nothing\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the request\n" +
+			"  priority=<low|medium|high>          Check that app-profile= has this request priority, or without\n" +
+			"                                      it, use the first app profile of the instance that does\n\n" +
+			"  A scan that fails or reaches a stream deadline is resumed after the last row counted,\n" +
+			"  so counting a large table is not started over.\n\n" +
+			"    Examples:\n" +
+			"      cbt count mobile-time-series\n" +
+			"      cbt count mobile-time-series prefix=phone# retries=20 progress-interval=10s",
+		Required: ProjectAndInstanceRequired,
+		FanOut:   true,
+	},
+	{
+		Name: "countdistinct",
+		Desc: "Count the distinct values of a column",
+		do:   doCountDistinct,
+		Usage: "cbt countdistinct <table-id> <family>:<qualifier> [prefix=<row-key-prefix>] [exact-limit=<n>] [app-profile=<app-profile-id>]\n\n" +
+			"  Scans the latest cell of the column in each row and prints the number of rows with the column\n" +
+			"  and of distinct values. Values are counted exactly up to exact-limit distinct values, and\n" +
+			"  beyond that approximately, with a HyperLogLog sketch whose standard error is about 0.8%.\n\n" +
+			"  prefix=<row-key-prefix>             Count only rows with this prefix\n" +
+			"  exact-limit=<n>                     The number of distinct values to count exactly, default 1000000\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the request\n\n" +
+			"    Example: cbt countdistinct mobile-time-series stats_summary:os_build prefix=phone",
+		Required: ProjectAndInstanceRequired,
+	},
+	{
+		Name: "createappprofile",
+		Desc: "Create app profile for an instance",
+		do:   doCreateAppProfile,
+		Usage: "cbt createappprofile <instance-id> <app-profile-id> <description> " +
+			"(route-any | [ route-to=<cluster-id> : transactional-writes]) [-force] \n" +
+			"  force:  Optional flag to override any warnings causing the command to fail\n\n" +
+			"    Examples:\n" +
+			"      cbt createappprofile my-instance multi-cluster-app-profile-1 \"Routes to nearest available cluster\" route-any\n" +
+			"      cbt createappprofile my-instance single-cluster-app-profile-1 \"Europe routing\" route-to=my-instance-cluster-2",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "createcluster",
+		Desc: "Create a cluster in the configured instance",
+		do:   doCreateCluster,
+		Usage: "cbt createcluster <cluster-id> <zone> <num-nodes> <storage-type>\n\n" +
+			"  cluster-id       Permanent, unique ID for the cluster in the instance\n" +
+			"  zone             The zone in which to create the cluster; see 'cbt listzones'\n" +
+			"  num-nodes        The number of nodes to create\n" +
+			"  storage-type     SSD or HDD\n\n" +
+			"    Example: cbt createcluster my-instance-c2 europe-west1-b 3 SSD",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "createfamily",
+		Desc: "Create a column family",
+		do:   doCreateFamily,
+		Usage: "cbt createfamily <table-id> <family>\n\n" +
+			"    Example: cbt createfamily mobile-time-series stats_summary",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "createinstance",
+		Desc: "Create an instance with one or more initial clusters",
+		do:   doCreateInstance,
+		Usage: "cbt createinstance <instance-id> <display-name> <cluster-id> <zone> <num-nodes> <storage-type>\n" +
+			"   [cluster=<cluster-id>:<zone>:<num-nodes>:<storage-type> ...]\n\n" +
+			"  instance-id      Permanent, unique ID for the instance\n" +
+			"  display-name     Description of the instance\n" +
+			"  cluster-id       Permanent, unique ID for the cluster in the instance\n" +
+			"  zone             The zone in which to create the cluster; see 'cbt listzones'\n" +
+			"  num-nodes        The number of nodes to create\n" +
+			"  storage-type     SSD or HDD\n" +
+			"  cluster=...      An additional cluster to create in the instance; may be repeated.\n" +
+			"                   The first cluster may also be given this way instead of positionally.\n\n" +
+			"    Examples:\n" +
+			"      cbt createinstance my-instance \"My instance\" my-instance-c1 us-central1-b 3 SSD\n" +
+			"      cbt createinstance my-instance \"My instance\" cluster=my-instance-c1:us-central1-b:3:SSD cluster=my-instance-c2:us-east1-c:3:SSD",
+		Required: ProjectRequired,
+		Mutating: true,
+	},
+	// {
+	// 	Name: "createsnapshot",
+	// 	Desc: "Create a backup from a source table (deprecated)",
+	// 	do:   doSnapshotTable,
+	// 	Usage: "cbt createsnapshot <cluster> <backup> <table> [ttl=<d>]\n" +
+	// 		`  [ttl=<d>]        Lifespan of the backup (e.g. "1h", "4d")`,
+	// 	Required: ProjectAndInstanceRequired,
+	// },
+	{
+		Name: "createtable",
+		Desc: "Create a table",
+		do:   doCreateTable,
+		Usage: "cbt createtable <table-id> [families=<family>:<gcpolicy-expression>:<type-expression>,...]\n" +
+			"   [splits=<split-row-key-1>,<split-row-key-2>,...]\n\n" +
+			"  families     Column families and their associated garbage collection (gc) policies and types.\n" +
+			"               Put gc policies in quotes when they include shell operators && and ||. For gcpolicy,\n" +
+			"               see \"setgcpolicy\". A gcpolicy of @<template> uses the policy defined as\n" +
+			"               gcpolicy.<template> in ~/.cbtrc.\n" +
+			"               Types \"intsum\", \"intmin\", \"intmax\", and \"inthll\" are supported.\n" +
+			"  splits       Row key(s) where the table should initially be split\n\n" +
+			"    Example: cbt createtable mobile-time-series \"families=stats_summary:maxage=10d||maxversions=1,stats_detail:maxage=10d||maxversions=1\" splits=tablet,phone",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	// {
+	// 	Name: "createtablefromsnapshot",
+	// 	Desc: "Create a table from a backup (deprecated)",
+	// 	do:   doCreateTableFromSnapshot,
+	// 	Usage: "cbt createtablefromsnapshot <table> <cluster> <backup>\n" +
+	// 		"  table        The name of the table to create\n" +
+	// 		"  cluster      The cluster where the snapshot is located\n" +
+	// 		"  backup       The snapshot to restore\n",
+	// 	Required: ProjectAndInstanceRequired,
+	// },
+	{
+		Name: "deleteallrows",
+		Desc: "Delete all rows",
+		do:   doDeleteAllRows,
+		Usage: "cbt deleteallrows <table-id>\n\n" +
+			"    Example: cbt deleteallrows  mobile-time-series",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "deleteappprofile",
+		Desc: "Delete app profile for an instance",
+		do:   doDeleteAppProfile,
+		Usage: "cbt deleteappprofile <instance-id> <profile-id>\n\n" +
+			"    Example: cbt deleteappprofile my-instance single-cluster",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "deletecells",
+		Desc: "Delete cells in a family or column, optionally within a timestamp range",
+		do:   doDeleteCells,
+		Usage: "cbt deletecells <table-id> <row-key> <family> [column=<column>] [from=<timestamp>] [to=<timestamp>] [app-profile=<app-profile-id>]\n\n" +
+			"  column=<column>                     Delete only cells in this column of the family\n" +
+			"  from=<timestamp>                    Delete only cells with timestamps at or after this time\n" +
+			"  to=<timestamp>                      Delete only cells with timestamps before this time\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the request\n\n" +
+			"  Timestamps have the same forms as in 'set': microseconds, now, now-<duration>, or RFC 3339.\n" +
+			"  Without a column or timestamps, every cell in the family is deleted.\n\n" +
+			"    Examples:\n" +
+			"      cbt deletecells mobile-time-series phone#4c410523#20190501 stats_summary\n" +
+			"      cbt deletecells mobile-time-series phone#4c410523#20190501 stats_summary from=2024-05-01T00:00:00Z to=2024-05-02T00:00:00Z\n" +
+			"      cbt deletecells mobile-time-series phone#4c410523#20190501 stats_summary column=os_name from=now-1h",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "deletecluster",
+		Desc: "Delete a cluster from the configured instance",
+		do:   doDeleteCluster,
+		Usage: "cbt deletecluster <cluster-id>\n\n" +
+			"    Example: cbt deletecluster my-instance-c2",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "deletecolumn",
+		Desc: "Delete all cells in a column",
+		do:   doDeleteColumn,
+		Usage: "cbt deletecolumn <table-id> <row-key> <family> <column> [app-profile=<app-profile-id>] [authorized-view=<authorized-view-id>]" +
+			" [from=<timestamp>] [to=<timestamp>] [backup-before-delete=<true|false>] [backup-file=<file.json>] [backup-table=<table-id>]\n\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the request\n" +
+			"  authorized-view=<authorized-view-id>  Delete through the specified authorized view of the table\n" +
+			"  from=<timestamp>                    Delete only cells with timestamps at or after this time\n" +
+			"  to=<timestamp>                      Delete only cells with timestamps before this time\n" +
+			deleteBackupHelp +
+			"  Timestamps have the same forms as in 'set': microseconds, now, now-<duration>, or RFC 3339.\n\n" +
+			"    Examples:\n" +
+			"      cbt deletecolumn mobile-time-series phone#4c410523#20190501 stats_summary os_name\n" +
+			"      cbt deletecolumn mobile-time-series phone#4c410523#20190501 stats_summary os_name from=now-1h",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "deletefamily",
+		Desc: "Delete a column family",
+		do:   doDeleteFamily,
+		Usage: "cbt deletefamily <table-id> <family>\n\n" +
+			"    Example: cbt deletefamily mobile-time-series stats_summary",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "deleteinstance",
+		Desc: "Delete an instance",
+		do:   doDeleteInstance,
+		Usage: "cbt deleteinstance <instance-id> [force]\n\n" +
+			"  Prints the clusters and tables that will be destroyed before deleting. When run\n" +
+			"  from a terminal, asks for confirmation unless force is given.\n\n" +
+			"    Example: cbt deleteinstance my-instance",
+		Required: ProjectRequired,
+		Mutating: true,
+	},
+	{
+		Name: "deleterow",
+		Desc: "Delete a row",
+		do:   doDeleteRow,
+		Usage: "cbt deleterow <table-id> <row-key> [app-profile=<app-profile-id>] [authorized-view=<authorized-view-id>]" +
+			" [backup-before-delete=<true|false>] [backup-file=<file.json>] [backup-table=<table-id>]\n\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the request\n" +
+			"  authorized-view=<authorized-view-id>  Delete through the specified authorized view of the table\n" +
+			deleteBackupHelp +
+			"    Examples:\n" +
+			"      cbt deleterow mobile-time-series phone#4c410523#20190501\n" +
+			"      cbt deleterow mobile-time-series phone#4c410523#20190501 backup-before-delete=true\n" +
+			"      cbt deleterow mobile-time-series phone#4c410523#20190501 backup-table=mobile-time-series-deleted",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	// {
+	// 	Name:     "deletesnapshot",
+	// 	Desc:     "Delete snapshot in a cluster (deprecated)",
+	// 	do:       doDeleteSnapshot,
+	// 	Usage:    "cbt deletesnapshot <cluster> <backup>",
+	// 	Required: ProjectAndInstanceRequired,
+	// },
+	{
+		Name: "deletetable",
+		Desc: "Delete a table",
+		do:   doDeleteTable,
+		Usage: "cbt deletetable <table-id> [force]\n\n" +
+			"  Prints the column families and backups of the table before deleting. When run\n" +
+			"  from a terminal, asks for confirmation unless force is given.\n\n" +
+			"    Example: cbt deletetable mobile-time-series",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "describeinstance",
+		Desc: "Describe an instance's clusters, app profiles, tables, encryption and labels",
+		do:   doDescribeInstance,
+		Usage: "cbt describeinstance [instance-id] [format=<text|json>]\n\n" +
+			"  instance-id                         The instance to describe (default the -instance flag)\n" +
+			"  format=<text|json>                  Print a summary (the default), or JSON\n\n" +
+			"  Prints the instance's state, type and labels, its number of tables, whether its clusters are\n" +
+			"  encrypted with customer-managed keys (CMEK), and a table each of its clusters, with their nodes,\n" +
+			"  storage type and autoscaling, and of its app profiles.\n\n" +
+			"    Examples:\n" +
+			"      cbt describeinstance\n" +
+			"      cbt describeinstance my-instance format=json",
+		Required: ProjectRequired,
+		FanOut:   true,
+	},
+	{
+		Name: "diffrow",
+		Desc: "Show the differences between two rows as a unified diff",
+		do:   doDiffRow,
+		Usage: "cbt diffrow <table-id> <row-key> [<table-id-2>] <row-key-2> [columns=<family>:<qualifier>,...] [cells-per-column=<n>]" +
+			" [timestamps=<true|false>] [context=<n>] [app-profile=<app-profile-id>] [format-file=<path-to-format-file>]\n\n" +
+			"  <table-id-2>                        The table of the second row; defaults to the table of the first\n" +
+			"  columns=<family>:<qualifier>,...    Compare only these columns, comma-separated\n" +
+			"  cells-per-column=<n>                Compare this number of cells per column (default 1)\n" +
+			"  timestamps=<true|false>             Whether cells with different timestamps differ (default false)\n" +
+			"  context=<n>                         The number of unchanged lines around each change (default 3)\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the requests\n" +
+			"  format-file=<path-to-format-file>   The format-configuration file used to format values, so that\n" +
+			"                                      protocol buffer and JSON values are compared field by field\n" +
+			"  display=<family>:<qualifier>,...    Compare only these columns, in this order\n" +
+			"  hide=<family>:<qualifier>,...       Do not compare these columns\n" +
+			"  decode-aggregates=<true|false>      Compare the current value of aggregate cells instead of raw bytes\n\n" +
+			"  The exit status is 0 if the rows are the same and 3 if they differ.\n\n" +
+			"    Examples:\n" +
+			"      cbt diffrow mobile-time-series phone#4c410523#20190501 phone#4c410523#20190502\n" +
+			"      cbt diffrow mobile-time-series phone#4c410523#20190501 mobile-time-series-copy phone#4c410523#20190501 format-file=formats.yaml",
+		Required: ProjectAndInstanceRequired,
+	},
+	{
+		Name:     "doc",
+		Desc:     "Print godoc-suitable documentation for cbt",
+		do:       doDoc,
+		Usage:    "cbt doc",
+		Required: NoneRequired,
+	},
+	{
+		Name: "exists",
+		Desc: "Check whether a row exists",
+		do:   doExists,
+		Usage: "cbt exists <table-id> <row-key> [app-profile=<app-profile-id>]\n\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the request\n\n" +
+			"  Prints nothing, and exits with status 0 if the row exists or 3 if it does not.\n\n" +
+			"    Example: cbt exists mobile-time-series phone#4c410523#20190501 && echo found",
+		Required: ProjectAndInstanceRequired,
+	},
+	{
+		Name: "export",
+		Desc: "Write rows to a CSV file in the format read by import, to a SQLite database, or to BigQuery",
+		do:   doExport,
+		Usage: "cbt export <table-id> <output-file> [columns=<family>:<qualifier>,...] [include-timestamps=<true|false>] [cells-per-column=<1>]" +
+			" [start=<row-key>] [end=<row-key>] [prefix=<row-key-prefix>] [workers=<1>] [parts=<true|false>]" +
+			" [resume=<true|false>] [format=<csv|sqlite>] [dest=bq://[<project>.]<dataset>.<table>] [bq-replace=<true|false>] [regex=<regex>] [value-regex=<regex>] [from=<timestamp>] [to=<timestamp>]" +
+			" [any-of=<filter>;...] [all-of=<filter>;...] [if=<filter>;... [then=<filter>;...] [else=<filter>;...]]" +
+			" [app-profile=<app-profile-id>] [priority=<low|medium|high>]\n\n" +
+			"  output-file                           The file to write, or - for standard output with format=csv\n" +
+			"  columns=<family>:<qualifier>,...      Export only these columns, in this order\n" +
+			"  include-timestamps=<true|false>       Append @<timestamp> to each value. Import the file with\n" +
+			"                                        timestamp=value-encoded to keep the timestamps\n" +
+			"  cells-per-column=<1>                  Export up to this many versions of each column, oldest first,\n" +
+			"                                        as additional lines with the same row key. Only versions matching\n" +
+			"                                        the other filters are counted. More than 1 requires\n" +
+			"                                        include-timestamps=true with format=csv\n" +
+			"  start=<row-key>                       Start exporting at this row\n" +
+			"  end=<row-key>                         Stop exporting before this row\n" +
+			"  prefix=<row-key-prefix>               Export rows with this prefix\n" +
+			"  workers=<1>                           Split the rows into this many shards at the table's sample row keys\n" +
+			"                                        and read them concurrently\n" +
+			"  parts=<true|false>                    Write each shard to its own file, named like data-00000-of-00004.csv,\n" +
+			"                                        instead of merging them in row key order into the output file\n" +
+			"  resume=<true|false>                   Continue an interrupted export from its checkpoint file\n" +
+			"  format=<csv|sqlite>                   The output format (default csv). See below for sqlite\n" +
+			"  dest=bq://[<project>.]<dataset>.<table>\n" +
+			"                                        Load the rows into this BigQuery table, staging them in the output\n" +
+			"                                        file, which must be in Cloud Storage. See below\n" +
+			"  bq-replace=<true|false>               Replace the BigQuery table's data instead of appending to it\n" +
+			"  regex=<regex>                         Export rows with keys matching this regex\n" +
+			"  value-regex=<regex>                   Export only cells with values matching this regex\n" +
+			"  from=<timestamp>                      Export only cells at or after this timestamp, e.g. now-24h\n" +
+			"  to=<timestamp>                        Export only cells before this timestamp\n" +
+			"  any-of=<filter>;...                   Export only cells matching any of these filters, see 'cbt help read'\n" +
+			"  all-of=<filter>;...                   Export only cells matching all of these filters\n" +
+			"  if=<filter>;... then=<filter>;... else=<filter>;...\n" +
+			"                                        Export the cells of the then filters from rows where the if\n" +
+			"                                        filters match a cell, and of the else filters from the others\n" +
+			"  app-profile=<app-profile-id>          The app profile ID to use for the request\n" +
+			"  priority=<low|medium|high>            Check that app-profile= has this request priority, or without\n" +
+			"                                        it, use the first app profile of the instance that does\n\n" +
+			"  The file starts with a column family header row and a column qualifier header row, as described in\n" +
+			"  'cbt help import'. Without columns=, the table is scanned once first to find its columns.\n\n" +
+			"  While a single worker exports to a file, the last row written is recorded in <output-file>.checkpoint\n" +
+			"  every 1000 rows. If the export is interrupted, run the same command with resume=true to continue\n" +
+			"  after that row. The checkpoint file is removed when the export completes.\n\n" +
+			"  With format=sqlite, the output file is replaced by a SQLite database with a table created by\n" +
+			"    CREATE TABLE cells(key TEXT, family TEXT, qualifier TEXT, ts INTEGER, value BLOB)\n" +
+			"  holding a row for each exported cell, in row key order with a single worker. ts is in microseconds\n" +
+			"  since the epoch; use CAST(value AS TEXT) to compare values with strings. parts, resume and\n" +
+			"  include-timestamps do not apply.\n\n" +
+			"  With dest=bq://..., the cells are written as newline-delimited JSON with the same columns, copied to the\n" +
+			"  output file with 'gcloud storage cp', and loaded with 'bq load', which creates the table if needed.\n" +
+			"  ts is loaded as a TIMESTAMP, and key, qualifier and value as BYTES, so that binary row keys and\n" +
+			"  qualifiers keep their bytes; use SAFE_CONVERT_BYTES_TO_STRING(key) to read them as text. The project\n" +
+			"  defaults to the -project flag. The staged file is not deleted.\n\n" +
+			"    Examples:\n" +
+			"      cbt export mobile-time-series data.csv\n" +
+			"      cbt export mobile-time-series - columns=stats_summary:os_name,stats_summary:os_build include-timestamps=true cells-per-column=3\n" +
+			"      cbt export mobile-time-series data.csv workers=8 parts=true\n" +
+			"      cbt export mobile-time-series recent.csv from=now-24h cells-per-column=1\n" +
+			"      cbt export mobile-time-series data.csv resume=true\n" +
+			"      cbt export mobile-time-series rows.db format=sqlite prefix=phone#\n" +
+			"      cbt export mobile-time-series gs://my-bucket/staging/cells.json dest=bq://analytics.phone_cells workers=8",
+		Required: ProjectAndInstanceRequired,
+	},
+	{
+		Name: "families",
+		Desc: "List the column families of all tables",
+		do:   doFamilies,
+		Usage: "cbt families [pattern=<table-glob>] [all-instances=<true|false>]\n\n" +
+			"  Lists the column families of every table with their GC policies and value types, to\n" +
+			"  audit them in one place. Tables are queried concurrently.\n\n" +
+			"  pattern=<table-glob>                Only list tables whose names match this glob, e.g. prod-*\n" +
+			"  all-instances=<true|false>          List the tables of every instance in the project\n\n" +
+			"    Examples:\n" +
+			"      cbt families\n" +
+			"      cbt families pattern=mobile-* all-instances=true",
+		Required: ProjectRequired,
+		Paged:    true,
+	},
+	{
+		Name: "find",
+		Desc: "Find the tables that contain a row",
+		do:   doFind,
+		Usage: "cbt find <row-key> [tables=<table-id>,... | pattern=<table-glob>] [app-profile=<app-profile-id>]\n\n" +
+			"  Looks up a row key in several tables at once and prints the tables that contain it. Exits\n" +
+			"  with status 3 if no table contains the row.\n\n" +
+			"  tables=<table-id>,...               The tables to look in (default: all tables)\n" +
+			"  pattern=<table-glob>                Look in the tables whose names match this glob\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the request\n\n" +
+			"    Examples:\n" +
+			"      cbt find phone#4c410523#20190501\n" +
+			"      cbt find phone#4c410523#20190501 pattern=mobile-*",
+		Required: ProjectAndInstanceRequired,
+	},
+	{
+		Name: "gcpolicy-preview",
+		Desc: "Estimate how much data a GC policy would make eligible for collection",
+		do:   doGCPolicyPreview,
+		Usage: "cbt gcpolicy-preview <table-id> <family> ((maxage=<d> | maxversions=<n>) [(and|or) (maxage=<d> | maxversions=<n>),...] | never) [sample=<0.01>]\n\n" +
+			"  sample=<0.01>      The fraction of rows to read\n\n" +
+			"  Reads a random sample of rows, applies the policy to them locally, and reports how many cells\n" +
+			"  and value bytes would be eligible for garbage collection. The table is not modified.\n\n" +
+			"    Example: cbt gcpolicy-preview mobile-time-series stats_detail maxage=10d or maxversions=1 sample=0.001",
+		Required: ProjectAndInstanceRequired,
+	},
+	{
+		Name: "generate",
+		Desc: "Write deterministic synthetic rows",
+		do:   doGenerate,
+		Usage: "cbt generate <table-id> rows=<n> [families=<family>,...] [columns=<1>] [value-size=<16>] [key-pattern=<row{seq}>]" +
+			" [workers=<1>] [app-profile=<app-profile-id>]\n\n" +
+			"  rows=<n>                            The number of rows to write\n" +
+			"  families=<family>,...               The column families to write; defaults to all families of the table\n" +
+			"  columns=<1>                         The number of columns to write in each family, named col0, col1, ...\n" +
+			"  value-size=<16>                     The size of each value in bytes\n" +
+			"  key-pattern=<row{seq}>              The row key pattern. {seq} is replaced by the row number, and\n" +
+			"                                      {seq:<width>} by the row number zero-padded to width digits\n" +
+			"  workers=<1>                         The number of worker threads\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the request\n\n" +
+			"  Keys and values depend only on the arguments, so repeated runs write the same data.\n\n" +
+			"    Examples:\n" +
+			"      cbt generate mobile-time-series rows=1000\n" +
+			"      cbt generate mobile-time-series rows=1000000 families=stats_summary columns=5 value-size=100 key-pattern=user{seq:08} workers=8",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "getappprofile",
+		Desc: "Read app profile for an instance",
+		do:   doGetAppProfile,
+		Usage: "cbt getappprofile <instance-id> <profile-id> [format=<text|json>]\n\n" +
+			"  format=json                         Print every field of the profile as JSON",
+		Required: ProjectAndInstanceRequired,
+	},
+	{
+		Name: "getbackup",
+		Desc: "Get backup metadata",
+		do:   doGetBackup,
+		Usage: "cbt getbackup <cluster> <backup>\n\n" +
+			"  Prints the source table, size, state, and start, end and expiration times of a backup.\n\n" +
+			"    Example: cbt getbackup my-instance-c1 my-backup",
+		Required: ProjectAndInstanceRequired,
+	},
+	// {
+	// 	Name:     "getsnapshot",
+	// 	Desc:     "Get backups info (deprecated)",
+	// 	do:       doGetSnapshot,
+	// 	Usage:    "cbt getsnapshot <cluster> <backup>",
+	// 	Required: ProjectAndInstanceRequired,
+	// },
+	{
+		Name: "help",
+		Desc: "Print help text",
+		do:   doHelp,
+		Usage: "cbt help <command>\n\n" +
+			"    Example: cbt help createtable",
+		Required: NoneRequired,
+		Paged:    true,
+	},
+	{
+		Name: "import",
+		Desc: "Batch write many rows based on the input file",
+		do:   doImport,
+		Usage: "cbt import <table-id> <input-file> [app-profile=<app-profile-id>] [priority=<low|medium|high>] [column-family=<family-name>] [batch-size=<500>] [workers=<1>] [in-flight-batches=<n>] [timestamp=<now|value-encoded>] [format=<csv|hbase-sequencefile>] [create-table=<true|false> [gc-policy=<policy>]]" +
+			" [trim-whitespace=<true|false>] [null-string=<token>] [empty-as-delete=<true|false>]\n\n" +
+			"  app-profile=<app-profile-id>          The app profile ID to use for the request\n" +
+			"  priority=<low|medium|high>            Check that app-profile= has this request priority, or without\n" +
+			"                                        it, use the first app profile of the instance that does\n" +
+			"  column-family=<family-name>           The column family label to use\n" +
+			"  batch-size=<500>                      The max number of rows per batch write request\n" +
+			"  workers=<1>                           The number of worker threads\n" +
+			"  in-flight-batches=<2 × workers>       The max number of parsed batches waiting for a worker, which\n" +
+			"                                        bounds the memory used. With more than one worker, the rows\n" +
+			"                                        written by each and its rate are logged at the end\n" +
+			"  timestamp=<now|value-encoded>	     	Whether to use current time for all cells or interpret the timestamp from cell value. Defaults to 'now'.\n" +
+			"  include-stats=full                    Print the latency distribution (p50/p95/p99) and retry count of batch writes\n" +
+			"  format=<csv|hbase-sequencefile>       The format of the input file. Defaults to 'csv'.\n" +
+			"  engine=<client|dataflow>              Write rows from cbt (client, the default), or launch a Dataflow job\n" +
+			"  gcs-temp=gs://<bucket>/<path>         The Cloud Storage location for Dataflow's temporary files\n" +
+			"  region=<us-central1>                  The region to run the Dataflow job in\n" +
+			"  create-table=<true|false>             Create the table, or the families it lacks, from the CSV header rows\n" +
+			"                                        before importing (default false)\n" +
+			"  gc-policy=<policy>                    The GC policy of the created families, as in setgcpolicy, e.g.\n" +
+			"                                        maxversions=1 or @<template> (default: keep all cells)\n" +
+			"  trim-whitespace=<true|false>          Remove leading and trailing white space from values (default false)\n" +
+			"  null-string=<token>                   Treat values equal to this token, such as \\N, as NULL: like empty\n" +
+			"                                        values, they set no cell\n" +
+			"  empty-as-delete=<true|false>          Delete the column's cells in the row for empty and NULL values,\n" +
+			"                                        instead of leaving the column as it is (default false)\n\n" +
+			"  Import data from a CSV file into an existing Cloud Bigtable table that already has the column families your data requires,\n" +
+			"  or, with create-table=true, into a table created with the families of the header rows.\n\n" +
+			"  The CSV file can support two rows of headers:\n" +
+			"      - (Optional) column families\n" +
+			"      - Column qualifiers\n" +
+			"  Because the first column is reserved for row keys, leave it empty in the header rows.\n" +
+			"  In the column family header, provide each column family once; it applies to the column it is in and every column to the right until another column family is found.\n" +
+			"  Each row after the header rows should contain a row key in the first column, followed by the data cells for the row.\n" +
+			"  See the example below. If you don't provide a column family header row, the column header is your first row and your import command must include the `column-family` flag to specify an existing column family. \n\n" +
+			"  The timestamp for each cell will default to current time (timestamp=now), to explicitly set the timestamp for cells, set timestamp=value-encoded use <val>[@<timestamp>] as the value for the cell.\n" +
+			"  If no timestamp is delimited for a cell, current time will be used. If the timestamp cannot be parsed, '@<timestamp>' will be interpreted as part of the value.\n" +
+			"  For most uses, a timestamp is the number of microseconds since 1970-01-01 00:00:00 UTC.\n\n" +
+			"  Rows that fail to be written with a transient error, such as RESOURCE_EXHAUSTED, are retried with backoff. Rows\n" +
+			"  that still fail don't stop the import: the number that failed with each error code is logged at the end, and\n" +
+			"  cbt exits with an error.\n\n" +
+			"    ,column-family-1,,column-family-2,      // Optional column family row (1st cell empty)\n" +
+			"    ,column-1,column-2,column-3,column-4    // Column qualifiers row (1st cell empty)\n" +
+			"    a,TRUE,,,FALSE                          // Rowkey 'a' followed by data\n" +
+			"    b,,,TRUE,FALSE                          // Rowkey 'b' followed by data\n" +
+			"    c,,TRUE,,TRUE                           // Rowkey 'c' followed by data\n" +
+			"    d,TRUE@1577862000000000,,,FALSE		 	// Rowkey 'd' followed by data\n\n" +
+			"  With format=hbase-sequencefile, the input is an uncompressed SequenceFile written by HBase's Export tool.\n" +
+			"  Cells keep their families, qualifiers and timestamps; column-family and timestamp are ignored, and delete\n" +
+			"  markers are skipped. The table must already have the column families the data requires.\n\n" +
+			"  With engine=dataflow, cbt launches the Google-provided Cloud Storage SequenceFile to Bigtable template\n" +
+			"  with gcloud and waits for the job to finish. The input must be a gs:// file pattern and the format\n" +
+			"  hbase-sequencefile, as there is no Google-provided template for CSV files.\n\n" +
+			"  Examples:\n" +
+			"    cbt import csv-import-table data.csv\n" +
+			"    cbt import new-table data.csv create-table=true gc-policy=maxversions=1\n" +
+			"    cbt import migrated-table part-m-00000 format=hbase-sequencefile workers=4\n" +
+			"    cbt import migrated-table 'gs://my-bucket/export/part-*' format=hbase-sequencefile engine=dataflow gcs-temp=gs://my-bucket/tmp\n" +
+			"    cbt import csv-import-table data-no-families.csv app-profile=batch-write-profile column-family=my-family workers=5\n",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "keydist",
+		Desc: "Print a histogram of data volume across row key ranges",
+		do:   doKeyDist,
+		Usage: "cbt keydist <table-id> [buckets=<50>] [prefix-length=<n>] [sample=<fraction>]\n\n" +
+			"  buckets=<50>            The maximum number of key ranges to print\n" +
+			"  prefix-length=<n>       Group key ranges whose start keys share their first n bytes\n" +
+			"  sample=<fraction>       Also scan this fraction of rows and print the estimated row count of each range\n\n" +
+			"  Data volumes are approximate and come from the row key samples that Bigtable maintains for the table.\n\n" +
+			"    Examples:\n" +
+			"      cbt keydist mobile-time-series\n" +
+			"      cbt keydist mobile-time-series prefix-length=5 sample=0.01",
+		Required: ProjectAndInstanceRequired,
+		Paged:    true,
+	},
+	{
+		Name: "listappprofile",
+		Desc: "Lists app profile for an instance",
+		do:   doListAppProfiles,
+		Usage: "cbt listappprofile <instance-id> [wide=<true|false>] [format=<table|json>]\n\n" +
+			"  wide=true                           Include the isolation settings and don't truncate columns\n" +
+			"  format=json                         Print every field of each profile as a JSON array",
+		Required: ProjectAndInstanceRequired,
+		Paged:    true,
+	},
+	{
+		Name:     "listclusters",
+		Desc:     "List clusters in an instance",
+		do:       doListClusters,
+		Usage:    "cbt listclusters [wide=<true|false>]",
+		Required: ProjectAndInstanceRequired,
+		FanOut:   true,
+		Paged:    true,
+	},
+	{
+		Name:     "listinstances",
+		Desc:     "List instances in a project",
+		do:       doListInstances,
+		Usage:    "cbt listinstances [wide=<true|false>]",
+		Required: ProjectRequired,
+		Paged:    true,
+	},
+	// {
+	// 	Name:     "listsnapshots",
+	// 	Desc:     "List backups in a cluster (deprecated)",
+	// 	do:       doListSnapshots,
+	// 	Usage:    "cbt listsnapshots [<cluster>]",
+	// 	Required: ProjectAndInstanceRequired,
+	// },
+	{
+		Name: "listzones",
+		Desc: "List the zones where the project can create clusters",
+		do:   doListZones,
+		Usage: "cbt listzones [region=<region>] [wide=<true|false>]\n\n" +
+			"  region=<region>                     List only the zones of this region, such as us-central1\n" +
+			"  wide=<true|false>                   Also print the labels the API reports for each zone\n\n" +
+			"  Lists the zones returned by the Bigtable admin API's locations list for the project, which are\n" +
+			"  the zones accepted by createcluster and createinstance. The API does not report which storage\n" +
+			"  types each zone supports; see https://cloud.google.com/bigtable/docs/locations.\n\n" +
+			"    Examples:\n" +
+			"      cbt listzones\n" +
+			"      cbt listzones region=europe-west1",
+		Required: ProjectRequired,
+	},
+	{
+		Name: "lookup",
+		Desc: "Read from a single row",
+		do:   doLookup,
+		Usage: "cbt lookup <table-id> (<row-key> | -stdin) [columns=<family>:<qualifier>,...] [cells-per-column=<n>]" +
+			" [any-of=<filter>;...] [all-of=<filter>;...] [app-profile=<app profile id>] [priority=<low|medium|high>] [authorized-view=<authorized-view-id>]\n\n" +
+			"  row-key                             String or raw bytes. Raw bytes must be enclosed in single quotes and have a dollar-sign prefix\n" +
+			"  -stdin                              Look up the row keys read from stdin, one per line, as they arrive.\n" +
+			"                                      Each key is answered in order, with an empty row, or an empty line\n" +
+			"                                      with format=value, if it has no row\n" +
+			"  columns=<family>:<qualifier>,...    Read only these columns, comma-separated\n" +
+			"  cells-per-column=<n>                Read only this number of cells per column\n" +
+			"  any-of=<filter>;<filter>;...        Read only cells matching any of these filters\n" +
+			"  all-of=<filter>;<filter>;...        Read only cells matching all of these filters. A filter is\n" +
+			"                                      column:<family>:<qualifier>, family:<regex>, qualifier:<regex>,\n" +
+			"                                      value:<regex>, key:<regex>, latest:<n> or label:<label>\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the request\n" +
+			"  priority=<low|medium|high>          Check that app-profile= has this request priority, or without\n" +
+			"                                      it, use the first app profile of the instance that does\n" +
+			"  authorized-view=<authorized-view-id>  Read from the specified authorized view of the table\n" +
+			"  format-file=<path-to-format-file>   The path to a format-configuration file to use for the request\n" +
+			"  display=<family>:<qualifier>,...    Print only these columns, in this order\n" +
+			"  hide=<family>:<qualifier>,...       Do not print these columns\n" +
+			"  keys-only=<true|false>              Whether to print only row keys\n" +
+			"  include-stats=<full|json>           Include a summary of request stats at the end of the request,\n" +
+			"                                      as text (full) or as a JSON RequestStats message (json)\n" +
+			"  decode-aggregates=<true|false>      Print the current value of aggregate cells, e.g. of intsum\n" +
+			"                                      families, labelled with their type, instead of raw bytes\n" +
+			"  sort-cells=<order>                  Order the printed cells by column (the default), or by\n" +
+			"                                      timestamp-desc or timestamp-asc across columns\n" +
+			"  format=value                        Print only the latest value of the single requested column, for use in scripts\n" +
+			"\n" +
+			" Example: cbt lookup mobile-time-series phone#4c410523#20190501 columns=stats_summary:os_build,os_name cells-per-column=1\n" +
+			" Example: cbt lookup mobile-time-series phone#4c410523#20190501 columns=stats_summary:os_name format=value\n" +
+			" Example: cbt lookup mobile-time-series $'\\x41\\x42'\n" +
+			" Example: cut -f1 devices.tsv | cbt lookup mobile-time-series -stdin columns=stats_summary:os_name format=value",
+		Required: ProjectAndInstanceRequired,
+		Paged:    true,
+	},
+	{
+		Name: "ls",
+		Desc: "List tables and column families",
+		do:   doLS,
+		Usage: "cbt ls                List tables\n" +
+			"cbt ls <table-id>     List a table's column families and garbage collection policies\n" +
+			"cbt ls -l [pattern=<table-glob>] [replication=<true|false>] [consistency=<true|false>]\n" +
+			"                      List tables with their families, deletion protection, change stream\n" +
+			"                      retention and automated backup policy, describing them concurrently\n\n" +
+			"  replication=<true|false>            Add the replication state of each table: ready, or the state of\n" +
+			"                                      each cluster that doesn't serve it yet, such as initializing.\n" +
+			"                                      Tables still initializing on a cluster are also logged\n" +
+			"  consistency=<true|false>            Also check whether the writes made so far have replicated to\n" +
+			"                                      every cluster, shown as consistent or replicating. Implies\n" +
+			"                                      replication=true\n\n" +
+			"    Examples:\n" +
+			"      cbt ls mobile-time-series\n" +
+			"      cbt ls -l pattern='prod-*'\n" +
+			"      cbt ls -l replication=true consistency=true",
+		Required: ProjectAndInstanceRequired,
+		FanOut:   true,
+		Paged:    true,
+	},
+	{
+		Name: "mddoc",
+		Desc: "Print documentation for cbt in Markdown format",
+		do:   doMDDoc,
+		Usage: "cbt mddoc [out-dir=<dir>]\n\n" +
+			"  out-dir=<dir>                       Instead of printing one document, write an index.md and one\n" +
+			"                                      <command>.md page per command, with front matter, to <dir>\n\n" +
+			"    Examples:\n" +
+			"      cbt mddoc\n" +
+			"      cbt mddoc out-dir=docs/reference",
+		Required: NoneRequired,
+	},
+	{
+		Name: "notices",
+		Desc: "Display licence information for any third-party dependencies",
+		do:   doNotices,
+		Usage: "cbt notices [<module>] [format=<text|json>]\n\n" +
+			"  Without a module, prints the licenses of all third-party dependencies. With a module, prints\n" +
+			"  only the license of that dependency.\n\n" +
+			"  format=json                         Print the module, version and license identifier of each\n" +
+			"                                      dependency as JSON, for compliance tooling\n\n" +
+			"    Examples:\n" +
+			"      cbt notices format=json\n" +
+			"      cbt notices gopkg.in/yaml.v2",
+		Required: NoneRequired,
+		Paged:    true,
+	},
+	{
+		Name: "operations",
+		Desc: "List, describe or cancel the long-running admin operations of the instance",
+		do:   doOperations,
+		Usage: "cbt operations list [filter=<filter>] [running=<true|false>] [format=<table|json>]\n" +
+			"cbt operations describe <operation> [format=<text|json>]\n" +
+			"cbt operations cancel <operation> [force]\n\n" +
+			"  Long-running operations include table restores, backup copies and cluster creation and updates,\n" +
+			"  whether started by cbt or elsewhere. list prints each operation's name, type, start time and\n" +
+			"  status; pass the name to describe or cancel.\n\n" +
+			"  filter=<filter>                     Only list the operations matching this admin API filter,\n" +
+			"                                      e.g. done=false\n" +
+			"  running=<true|false>                Only list the operations that haven't finished\n" +
+			"  format=<table|text|json>            The output format of list (table or json) or describe (text or json)\n" +
+			"  force                               Cancel without asking for confirmation. Cancellation is best\n" +
+			"                                      effort: describe shows whether the operation stopped\n\n" +
+			"    Examples:\n" +
+			"      cbt operations list running=true\n" +
+			"      cbt operations describe projects/my-project/instances/my-instance/tables/my-table/operations/1234\n" +
+			"      cbt operations cancel projects/my-project/instances/my-instance/tables/my-table/operations/1234",
+		Required: ProjectAndInstanceRequired,
+	},
+	{
+		Name: "plan",
+		Desc: "Show the tablets and approximate bytes that a read would scan",
+		do:   doPlan,
+		Usage: "cbt plan read <table-id> [start=<row-key>] [end=<row-key>] [prefix=<row-key-prefix>]\n\n" +
+			"  Shows, without reading any rows, the ranges between the table's sample row keys (roughly its\n" +
+			"  tablets) that a read of the given range would scan, with the approximate size of each, to\n" +
+			"  explain why a scan is slow before running it.\n\n" +
+			"  start=<row-key>                     Start reading at this row\n" +
+			"  end=<row-key>                       Stop reading before this row\n" +
+			"  prefix=<row-key-prefix>             Read rows with this prefix\n\n" +
+			"    Examples:\n" +
+			"      cbt plan read mobile-time-series prefix=phone#4c410523\n" +
+			"      cbt plan read mobile-time-series start=phone#4c410523#20190501 end=phone#4c410523#20190601",
+		Required: ProjectAndInstanceRequired,
+		Paged:    true,
+	},
+	{
+		Name: "purge",
+		Desc: "Delete cells older than a given age",
+		do:   doPurge,
+		Usage: "cbt purge <table-id> older-than=<duration> [prefix=<row-key-prefix>] [columns=<family>:<qualifier>,...] [dry-run=<true|false>]" +
+			" [workers=<1>] [max-rate=<rows/s>] [progress-interval=<duration>] [app-profile=<app-profile-id>]\n\n" +
+			"  older-than=<duration>               Delete cells with timestamps older than this, e.g. 30d\n" +
+			"  prefix=<row-key-prefix>             Purge only rows with this prefix\n" +
+			"  columns=<family>:<qualifier>,...    Purge only these columns, comma-separated\n" +
+			"  dry-run=<true|false>                Only count the cells that would be deleted\n" +
+			"  workers=<1>                         The number of worker threads writing deletions\n" +
+			"  max-rate=<rows/s>                   Write deletions to at most this many rows a second, shared by\n" +
+			"                                      the workers, to protect the latency of serving traffic\n" +
+			"  progress-interval=<duration>        How often to log the rows scanned and purged (default 1m, 0 for\n" +
+			"                                      never)\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the request\n\n" +
+			"  Scans the table and deletes old cells directly, rather than waiting for garbage collection\n" +
+			"  to remove them. The scan reads every matching row, so it can be slow on large tables.\n" +
+			"  Deletions aren't throttled unless max-rate is set. At the end, purge prints the number of\n" +
+			"  cells and of rows deleted.\n\n" +
+			"    Examples:\n" +
+			"      cbt purge mobile-time-series older-than=90d dry-run=true\n" +
+			"      cbt purge mobile-time-series older-than=30d prefix=phone columns=stats_summary: workers=4\n" +
+			"      cbt purge mobile-time-series older-than=30d workers=4 max-rate=500",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "read",
+		Desc: "Read rows",
+		do:   doRead,
+		Usage: "cbt read <table-id> [authorized-view=<authorized-view-id>] [start=<row-key>] [end=<row-key>] [prefix=<row-key-prefix>]" +
+			" [regex=<regex>] [columns=<family>:<qualifier>,...] [count=<n>] [offset=<n>] [sample=<fraction>] [cells-per-column=<n>]" +
+			" [any-of=<filter>;...] [all-of=<filter>;...] [if=<filter>;... [then=<filter>;...] [else=<filter>;...]]" +
+			" [follow=<true|false> [interval=<5s>]] [gc-pending=<true|false>] [app-profile=<app-profile-id>] [priority=<low|medium|high>] [-force]\n\n" +
+			"  authorized-view=<authorized-view-id>  Read from the specified authorized view of the table\n" +
+			"  start=<row-key>                       Start reading at this row\n" +
+			"  end=<row-key>                         Stop reading before this row\n" +
+			"  prefix=<row-key-prefix>               Read rows with this prefix\n" +
+			"  regex=<regex>                         Read rows with keys matching this regex\n" +
+			"  value-regex=<regex>                   Read only cells with values matching this regex\n" +
+			"  from=<timestamp>                      Read only cells at or after this timestamp, e.g. now-1h\n" +
+			"  to=<timestamp>                        Read only cells before this timestamp\n" +
+			"  reversed=<true|false>                 Read rows in reverse order\n" +
+			"  columns=<family>:<qualifier>,...      Read only these columns, comma-separated\n" +
+			"  count=<n>                             Read only this many rows\n" +
+			"  offset=<n>                            Skip this many matching rows first, reading only their keys,\n" +
+			"                                        to page through the results with count=\n" +
+			"  sample=<fraction>                     Read a random sample of about this fraction of rows, e.g. 0.01\n" +
+			"  cells-per-column=<n>                  Read only this many cells per column, of those matching the\n" +
+			"                                        other filters\n" +
+			"  any-of=<filter>;<filter>;...          Read only cells matching any of these filters\n" +
+			"  all-of=<filter>;<filter>;...          Read only cells matching all of these filters. A filter is\n" +
+			"                                        column:<family>:<qualifier>, family:<regex>, qualifier:<regex>,\n" +
+			"                                        value:<regex>, key:<regex>, latest:<n> or label:<label>\n" +
+			"  if=<filter>;... then=<filter>;... else=<filter>;...\n" +
+			"                                        Read the cells of the then filters from rows where the if\n" +
+			"                                        filters match a cell, and of the else filters from the\n" +
+			"                                        others, as a server-side condition filter. Each is a chain\n" +
+			"                                        like all-of; a missing then or else returns no cells\n" +
+			"  label=<label>                         Label the returned cells; labels are printed after timestamps\n" +
+			"  gc-pending=<true|false>               Label gc-pending the cells that are past their family's GC\n" +
+			"                                        policy but not yet collected, which can take up to a week.\n" +
+			"                                        Versions are counted among the cells read, so filters that\n" +
+			"                                        skip newer cells, such as to=, hide older cells' state\n" +
+			"  sink=<true|false>                     Also return every cell as read, before the other filters,\n" +
+			"                                        labelled sink, to debug what the filters keep\n" +
+			"  app-profile=<app-profile-id>          The app profile ID to use for the request\n" +
+			"  priority=<low|medium|high>            Check that app-profile= has this request priority, or without\n" +
+			"                                        it, use the first app profile of the instance that does\n" +
+
+			"  format-file=<path-to-format-file>     The path to a format-configuration file to use for the request\n" +
+			"  display=<family>:<qualifier>,...      Print only these columns, in this order\n" +
+			"  hide=<family>:<qualifier>,...         Do not print these columns\n" +
+			"  keys-only=<true|false>                Whether to print only row keys\n" +
+			"  decode-aggregates=<true|false>        Print the current value of aggregate cells, e.g. of intsum\n" +
+			"                                        families, labelled with their type, instead of raw bytes\n" +
+			"  sort-cells=<order>                    Order the printed cells by column (the default), or by\n" +
+			"                                        timestamp-desc or timestamp-asc across columns, to follow\n" +
+			"                                        the version history of a row\n" +
+			"  include-stats=<full|json>             Include a summary of request stats at the end of the request,\n" +
+			"                                        as text (full) or as a JSON RequestStats message (json).\n" +
+			"                                        Stats are summed over all requests of the scan; full also\n" +
+			"                                        reports filter selectivity and warns about badly-filtered scans\n" +
+			"  estimate=<true|false>                 Print the estimated rows, cells and bytes the read would\n" +
+			"                                        scan and return, from a sample of rows, instead of reading\n" +
+			"  follow=<true|false>                   After reading, keep polling for cells newer than any read so\n" +
+			"                                        far and print the rows with only those cells, like tail -f.\n" +
+			"                                        Cells written with older timestamps are not seen\n" +
+			"  interval=<5s>                         How often to poll with follow=true\n" +
+			"  -force                                Read even if the arguments are likely to scan far more than\n" +
+			"                                        they return, such as reversed=true with only regex=\n" +
+			"\n" +
+			"    Examples: (see 'set' examples to create data to read)\n" +
+			"      cbt read mobile-time-series prefix=phone columns=stats_summary:os_build,os_name count=10\n" +
+			"      cbt read mobile-time-series start=phone#4c410523#20190501 end=phone#4c410523#20190601\n" +
+			"      cbt read mobile-time-series regex=\"phone.*\" cells-per-column=1\n" +
+			"      cbt read mobile-time-series start=phone#4c410523#20190501 end=phone#4c410523#20190601 reversed=true count=10\n" +
+			"      cbt read mobile-time-series sample=0.001 count=100\n" +
+			"      cbt read mobile-time-series prefix=phone count=100 offset=200\n" +
+			"      cbt read mobile-time-series \"any-of=value:Android.*;column:stats_summary:os_build\"\n" +
+			"      cbt read mobile-time-series if=value:Android.* then=family:stats_summary else=latest:1\n" +
+			"      cbt read mobile-time-series regex=\"phone.*\" estimate=true\n" +
+			"      cbt read mobile-time-series prefix=phone#4c410523# gc-pending=true\n" +
+			"      cbt read mobile-time-series prefix=phone#4c410523# follow=true interval=10s\n\n" +
+			"   Note: Using a regex without also specifying start, end, prefix, or count results in a full\n" +
+			"   table scan, which can be slow.\n",
+		Required: ProjectAndInstanceRequired,
+		Paged:    true,
+	},
+	{
+		Name: "readchangestream",
+		Desc: "Tail a table's change stream",
+		do:   doReadChangeStream,
+		Usage: "cbt readchangestream <table-id> [start-time=<timestamp>] [end-time=<timestamp>] [partitions=all|<row-key>]" +
+			" [app-profile=<app-profile-id>]\n\n" +
+			"  Prints each change to the table, with its row key, mutations and commit timestamp, as it\n" +
+			"  arrives. Runs until end-time, or until interrupted if no end time is given. The table\n" +
+			"  must have a change stream enabled.\n\n" +
+			"  start-time=<timestamp>              Start reading from this time instead of now\n" +
+			"  end-time=<timestamp>                Stop reading at this time\n" +
+			"  partitions=all|<row-key>            Read all partitions (default), or only the partition\n" +
+			"                                      containing a row key\n" +
+			"  app-profile=<app-profile-id>        The app profile ID to use for the request\n\n" +
+			"  Timestamps have the same forms as in 'set': microseconds, now, now-<duration>, or RFC 3339.\n\n" +
+			"    Examples:\n" +
+			"      cbt readchangestream mobile-time-series\n" +
+			"      cbt readchangestream mobile-time-series start-time=now-10m end-time=now",
+		Required: ProjectAndInstanceRequired,
+	},
+	{
+		Name: "renamefamily",
+		Desc: "Copy a column family to a new name and optionally delete the old family",
+		do:   doRenameFamily,
+		Usage: "cbt renamefamily <table-id> <old-family> <new-family> [workers=<1>] [delete-old=<true|false>] [force]\n\n" +
+			"  Bigtable cannot rename column families, so the new family is created with the GC policy\n" +
+			"  and type of the old one and every cell is copied to it with its timestamp. Stop writes\n" +
+			"  to the old family first: writes made during the copy are not carried over.\n\n" +
+			"  workers=<1>                         Copy this many ranges of the table in parallel\n" +
+			"  delete-old=<true|false>             Compare the two families row by row after the copy and,\n" +
+			"                                      if they match, delete the old family (default false)\n" +
+			"  force                               Delete the old family without asking for confirmation\n\n" +
+			"    Examples:\n" +
+			"      cbt renamefamily mobile-time-series stats_summary stats\n" +
+			"      cbt renamefamily mobile-time-series stats_summary stats workers=8 delete-old=true",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "renametable",
+		Desc: "Copy a table to a new name and optionally delete the old table",
+		do:   doRenameTable,
+		Usage: "cbt renametable <old-table-id> <new-table-id> [via=backup|copy] [cluster=<cluster-id>] [delete-old=<true|false>] [force]\n\n" +
+			"  Bigtable cannot rename tables, so the table is recreated under the new name. Stop writes\n" +
+			"  to the old table first: writes made during the rename are not carried over.\n\n" +
+			"  via=backup                          Back up the old table and restore the backup as the new\n" +
+			"                                      table (default). The backup is deleted afterwards\n" +
+			"  via=copy                            Create the new table with the same column families and\n" +
+			"                                      copy every cell to it through the data API\n" +
+			"  cluster=<cluster-id>                The cluster to back up on (default: the first cluster)\n" +
+			"  delete-old=<true|false>             Delete the old table once the new one exists (default false)\n" +
+			"  force                               Delete the old table without asking for confirmation\n\n" +
+			"    Examples:\n" +
+			"      cbt renametable mobile-time-series phone-time-series\n" +
+			"      cbt renametable mobile-time-series phone-time-series via=copy delete-old=true",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "restoretable",
+		Desc: "Restore a table from a backup",
+		do:   doRestoreTable,
+		Usage: "cbt restoretable <table-id> <cluster> <backup> [dst-instance=<instance-id>] [dst-project=<project-id>]\n\n" +
+			"  table-id                     The ID of the table to create\n" +
+			"  cluster                      The cluster of the configured instance holding the backup\n" +
+			"  backup                       The backup to restore\n" +
+			"  dst-instance=<instance-id>   Restore into this instance instead of the configured one\n" +
+			"  dst-project=<project-id>     Restore into an instance in this project instead of the configured one\n\n" +
+			"    Examples:\n" +
+			"      cbt restoretable mobile-time-series my-instance-c1 my-backup\n" +
+			"      cbt restoretable mobile-time-series my-instance-c1 my-backup dst-instance=standby-instance",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "selftest",
+		Desc: "Verify this build of cbt against an in-process emulator",
+		do:   doSelftest,
+		Usage: "cbt selftest\n\n" +
+			"  Runs a scripted sequence of commands against an emulator started inside cbt and compares\n" +
+			"  their output with the output recorded when cbt was released. Prints ok or FAIL for each\n" +
+			"  script, with the differences, and exits with status 1 if any script fails. No project,\n" +
+			"  instance or credentials are needed.\n\n" +
+			"    Examples:\n" +
+			"      cbt selftest",
+		Required: NoneRequired,
+	},
+	{
+		Name: "set",
+		Desc: "Set value of a cell (write)",
+		do:   doSet,
+		Usage: "cbt set <table-id> <row-key> [authorized-view=<authorized-view-id>] [app-profile=<app-profile-id>] [include-stats=full] <family>:<column>=<val>[@<timestamp>] ...\n\n" +
+			"  authorized-view=<authorized-view-id>  Write to the specified authorized view of the table\n" +
+			"  app-profile=<app profile id>          The app profile ID to use for the request\n" +
+			"  include-stats=full                    Print the request latency and retry count after writing\n" +
+			"  <family>:<column>=<val>[@<timestamp>] may be repeated to set multiple cells.\n\n" +
+			"    timestamp is optional, and may be an integer, now, a time relative to now such as now-1h, or an\n" +
+			"    RFC 3339 time such as 2024-05-01T00:00:00Z.\n" +
+			"    If the timestamp cannot be parsed, '@<timestamp>' will be interpreted as part of the value.\n" +
+			"    For most uses, an integer timestamp is the number of microseconds since 1970-01-01 00:00:00 UTC.\n\n" +
+			"    Examples:\n" +
+			"      cbt set mobile-time-series phone#4c410523#20190501 stats_summary:connected_cell=1@12345 stats_summary:connected_cell=0@1570041766\n" +
+			"      cbt set mobile-time-series phone#4c410523#20190501 stats_summary:os_build=PQ2A.190405.003 stats_summary:os_name=android\n" +
+			"      cbt set mobile-time-series phone#4c410523#20190501 stats_summary:connected_cell=1@now-1h stats_summary:connected_cell=0@2024-05-01T00:00:00Z",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "setgcpolicy",
+		Desc: "Set the garbage-collection policy (age, versions) for a column family",
+		do:   doSetGCPolicy,
+		Usage: "cbt setgcpolicy <table> <family> ((maxage=<d> | maxversions=<n>) [(and|or) (maxage=<d> | maxversions=<n>),...] | never | policy=@<template>) [force]\n\n" +
+			"  force: Optional flag to override warnings when relaxing the garbage-collection policy on replicated clusters.\n" +
+			"    This may cause your clusters to be temporarily inconsistent, make sure you understand the risks\n" +
+			"    listed at https://cloud.google.com/bigtable/docs/garbage-collection#increasing\n\n" +
+			"  maxage=<d>         Maximum timestamp age to preserve. Acceptable units: ms, s, m, h, d\n" +
+			"  maxversions=<n>    Maximum number of versions to preserve\n" +
+			"  policy=@<template> The policy defined as gcpolicy.<template> in ~/.cbtrc\n" +
+			"  Put garbage collection policies in quotes when they include shell operators && and ||.\n\n" +
+			"    Examples:\n" +
+			"      cbt setgcpolicy mobile-time-series stats_detail maxage=10d\n" +
+			"      cbt setgcpolicy mobile-time-series stats_summary maxage=10d or maxversions=1 force\n" +
+			"      cbt setgcpolicy mobile-time-series stats_summary policy=@default\n",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "setmany",
+		Desc: "Set cells in several rows at once (write)",
+		do:   doSetMany,
+		Usage: "cbt setmany <table-id> [authorized-view=<authorized-view-id>] [app-profile=<app-profile-id>]" +
+			" <row-key> <family>:<column>=<val>[@<timestamp>] ... [-- <row-key> <family>:<column>=<val>[@<timestamp>] ...]...\n\n" +
+			"  authorized-view=<authorized-view-id>  Write to the specified authorized view of the table\n" +
+			"  app-profile=<app profile id>          The app profile ID to use for the request\n\n" +
+			"  Each row key is followed by the cells to set in it, as in 'cbt set', and rows are separated by --.\n" +
+			"  All rows are written in one bulk request; rows that fail are logged and don't stop the others.\n\n" +
+			"    Examples:\n" +
+			"      cbt setmany mobile-time-series phone#4c410523#20190501 stats_summary:os_name=android -- phone#5c10102#20190502 stats_summary:os_name=ios\n" +
+			"      cbt setmany mobile-time-series r1 cf:a=1@now-1h cf:b=2 -- r2 cf:a=3",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "setvaluetype",
+		Desc: "Update column family's value type.",
+		do:   doSetFamilyValueType,
+		Usage: "cbt setvaluetype <table> <family> <type>\n" +
+			"      type: The type to be updated.\n" +
+			"   Supported type(s):" +
+			"      stringutf8bytes: UTF8 encoded string\n" +
+			"   Updating to or from aggregate types is currently unsupported.\n" +
+			"   Example:\n" +
+			"       cbt setvaluetype mobile-time-series vendor-info stringutf8bytes",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "tablesize",
+		Desc: "Print the approximate size and row count of a table",
+		do:   doTableSize,
+		Usage: "cbt tablesize <table-id>\n\n" +
+			"  The size comes from the row key samples that Bigtable maintains for the table, and the row count\n" +
+			"  is estimated from the average size of a small number of rows, so neither requires a full scan.\n\n" +
+			"    Example: cbt tablesize mobile-time-series",
+		Required: ProjectAndInstanceRequired,
+		FanOut:   true,
+	},
+	{
+		Name: "update",
+		Desc: "Check for a newer version of cbt and install it",
+		do:   doUpdate,
+		Usage: "cbt update [-check-only]\n\n" +
+			"  Checks the latest cbt release against the version of this build and, if the release is newer,\n" +
+			"  downloads it, verifies its SHA-256 checksum and replaces the running binary with it.\n\n" +
+			"  -check-only                         Only report whether a newer version is available\n\n" +
+			"    Examples:\n" +
+			"      cbt update -check-only\n" +
+			"      cbt update",
+		Required: NoneRequired,
+	},
+	{
+		Name: "updateappprofile",
+		Desc: "Update app profile for an instance",
+		do:   doUpdateAppProfile,
+		Usage: "cbt updateappprofile <instance-id> <profile-id> [description=<description>] [route-any | route-to=<cluster-id>]" +
+			" [transactional-writes=<true|false>] [priority=<low|medium|high>] [etag=<etag>] [force=<true|false>]\n\n" +
+			"  Only the given attributes are changed; the others keep their values.\n\n" +
+			"  description=<description>           The new description\n" +
+			"  route-any                           Route requests to any cluster (multi-cluster routing)\n" +
+			"  route-to=<cluster-id>               Route requests to this cluster (single-cluster routing)\n" +
+			"  transactional-writes=<true|false>   Whether to allow transactional writes with single-cluster routing.\n" +
+			"                                      Without route-to=, the profile's current cluster is kept\n" +
+			"  priority=<low|medium|high>          The priority of the profile's requests\n" +
+			"  etag=<etag>                         The profile's etag, as printed by getappprofile. The update fails if\n" +
+			"                                      the profile has changed since, so concurrent updates aren't lost\n" +
+			"  force=<true|false>                  Override any warnings causing the command to fail\n\n" +
+			"  The original form, cbt updateappprofile <instance-id> <profile-id> <description> (route-any |\n" +
+			"  route-to=<cluster-id>), which replaces both the description and the routing policy, still works.\n\n" +
+			"    Examples:\n" +
+			"      cbt updateappprofile my-instance multi-cluster-app-profile-1 description=\"Use this one.\"\n" +
+			"      cbt updateappprofile my-instance batch-profile priority=low\n" +
+			"      cbt updateappprofile my-instance batch-profile priority=low etag=CPmKzsIBEAE=\n" +
+			"      cbt updateappprofile my-instance multi-cluster-app-profile-1 \"Use this one.\" route-any",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "updatebackup",
+		Desc: "Update the expiration time of a backup",
+		do:   doUpdateBackup,
+		Usage: "cbt updatebackup <cluster> <backup> expire=<d|timestamp>\n\n" +
+			"  expire=<d|timestamp>  New expiration time, either a duration from now (e.g. \"30d\", \"12h\")\n" +
+			"                        or an RFC 3339 timestamp (e.g. 2024-05-01T00:00:00Z)\n\n" +
+			"    Examples:\n" +
+			"      cbt updatebackup my-instance-c1 my-backup expire=30d\n" +
+			"      cbt updatebackup my-instance-c1 my-backup expire=2024-05-01T00:00:00Z",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "updatecluster",
+		Desc: "Update a cluster in the configured instance",
+		do:   doUpdateCluster,
+		Usage: "cbt updatecluster <cluster-id> [num-nodes=<num-nodes>]\n\n" +
+			"  cluster-id    Permanent, unique ID for the cluster in the instance\n" +
+			"  num-nodes     The new number of nodes\n\n" +
+			"    Example: cbt updatecluster my-instance-c1 num-nodes=5",
+		Required: ProjectAndInstanceRequired,
+		Mutating: true,
+	},
+	{
+		Name: "verifybackup",
+		Desc: "Verify that a backup restores to the data of a table",
+		do:   doVerifyBackup,
+		Usage: "cbt verifybackup <cluster> <backup> [against=<table-id>] [workers=<1>]\n\n" +
+			"  Restores the backup to a temporary table, compares its row count and checksum (see\n" +
+			"  'cbt help checksum') with those of a table, and deletes the temporary table. Fails if they\n" +
+			"  differ.\n\n" +
+			"  against=<table-id>                  The table to compare with. Defaults to the backup's source\n" +
+			"                                      table, which only matches if it hasn't changed since the\n" +
+			"                                      backup was taken\n" +
+			"  workers=<1>                         Number of ranges of each table to read concurrently\n\n" +
+			"    Examples:\n" +
+			"      cbt verifybackup my-instance-c1 my-backup\n" +
+			"      cbt verifybackup my-instance-c1 my-backup against=mobile-time-series-copy workers=8",
+		Required: ProjectAndInstanceRequired,
+	},
+	{
+		Name: "version",
+		Desc: "Print the current cbt version",
+		do:   doVersion,
+		Usage: "cbt version [format=<text|json>]\n\n" +
+			"  format=json                         Print the version, revision, revision date, Go version,\n" +
+			"                                      platform and client library versions as JSON\n\n" +
+			"    Examples:\n" +
+			"      cbt version\n" +
+			"      cbt version format=json",
+		Required: NoneRequired,
+	},
+	{
+		Name:     "waitforreplication",
+		Desc:     "Block until all the completed writes have been replicated to all the clusters",
+		do:       doWaitForReplicaiton,
+		Usage:    "cbt waitforreplication <table-id>\n",
+		Required: ProjectAndInstanceRequired,
+	},
+}
+
+func doCheckAndDelete(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt checkanddelete <table> <row> (<family>:<column>[=<value>] | if=<filter>;...) [<family>[:<column>]] [app-profile=<app profile id>]"
+	if len(args) < 3 {
+		return errors.New(usage)
+	}
+	predicate, err := parseCheckPredicate(args[2])
+	if err != nil {
+		return err
+	}
+	var target, appProfile string
+	for _, arg := range args[3:] {
+		switch {
+		case strings.HasPrefix(arg, "app-profile="):
+			appProfile = strings.Split(arg, "=")[1]
+		case target == "":
+			target = arg
+		default:
+			return errors.New(usage)
+		}
+	}
+
+	mut := bigtable.NewMutation()
+	if target == "" {
+		mut.DeleteRow()
+	} else if family, column, ok := strings.Cut(target, ":"); ok {
+		mut.DeleteCellsInColumn(family, column)
+	} else {
+		mut.DeleteCellsInFamily(target)
+	}
+
+	var matched bool
+	c, err := e.getClient(bigtable.ClientConfig{AppProfile: appProfile})
+	if err != nil {
+		return err
+	}
+	tbl := c.Open(args[0])
+	cond := bigtable.NewCondMutation(predicate, mut, nil)
+	if err := tbl.Apply(ctx, args[1], cond, bigtable.GetCondMutationResult(&matched)); err != nil {
+		return fmt.Errorf("Applying conditional delete: %v", err)
+	}
+	if matched {
+		fmt.Fprintln(e.stdout, "Predicate matched; deleted.")
+	} else {
+		fmt.Fprintln(e.stdout, "Predicate did not match; nothing deleted.")
+	}
+	return nil
+}
+
+// parseCheckPredicate parses a checkanddelete predicate. if=<filter>;...
+// chains filters of the same <kind>:<argument> form as read's if and all-of,
+// and matches if they return any cell. The shorthand
+// <family>:<column>[=<value>] matches if the column has any cell, or with a
+// value, if its latest cell has exactly that value.
+func parseCheckPredicate(pred string) (bigtable.Filter, error) {
+	if spec, ok := strings.CutPrefix(pred, "if="); ok {
+		return parseFilterGroup("if", spec)
+	}
+	column, value, hasValue := strings.Cut(pred, "=")
+	family, qualifier, ok := strings.Cut(column, ":")
+	if !ok || family == "" || qualifier == "" {
+		return nil, fmt.Errorf("bad predicate %q: want <family>:<column>[=<value>] or if=<filter>;...", pred)
+	}
+	filters := []bigtable.Filter{
+		bigtable.FamilyFilter("^" + regexp.QuoteMeta(family) + "$"),
+		bigtable.ColumnFilter("^" + regexp.QuoteMeta(qualifier) + "$"),
+		bigtable.LatestNFilter(1),
+	}
+	if hasValue {
+		filters = append(filters, bigtable.ValueFilter("^"+regexp.QuoteMeta(value)+"$"))
+	}
+	return bigtable.ChainFilters(filters...), nil
+}
+
+// defaultProgressInterval is how often count and purge log the rows done so
+// far.
+const defaultProgressInterval = time.Minute
+
+// parseProgressInterval parses a progress-interval argument: a duration, or 0
+// to turn progress off. An empty argument selects defaultProgressInterval.
+func parseProgressInterval(s string) (time.Duration, error) {
+	switch s {
+	case "":
+		return defaultProgressInterval, nil
+	case "0":
+		return 0, nil
+	}
+	d, err := parseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("bad progress-interval %q: %v", s, err)
+	}
+	return d, nil
+}
+
+func doCount(ctx context.Context, e *env, args ...string) error {
+	valid := []string{"prefix", "retries", "progress-interval", "app-profile", "priority"}
+	args = e.withDefaultTable(args, len(args) == 0 || isOptionArg(args[0], valid))
+	if len(args) < 1 {
+		return errors.New("usage: cbt count <table> [prefix=<row-key-prefix>] [retries=<n>] [progress-interval=<duration>] [app-profile=<app-profile-id>] [priority=<low|medium|high>]")
+	}
+	parsed, err := parseArgs(args[1:], valid)
+	if err != nil {
+		return err
+	}
+
+	var rd resumableRead
+	if prefix, ok := parsed["prefix"]; ok {
+		rd.start, rd.end = prefix, prefixEnd(prefix)
+	}
+	if s, ok := parsed["retries"]; ok {
+		if rd.attempts, err = strconv.Atoi(s); err != nil || rd.attempts < 1 {
+			return fmt.Errorf("Bad retries %q: must be a positive number", s)
+		}
+	}
+	interval, err := parseProgressInterval(parsed["progress-interval"])
+	if err != nil {
+		return err
+	}
+
+	appProfile, err := e.priorityAppProfile(ctx, parsed["app-profile"], parsed["priority"])
+	if err != nil {
+		return err
+	}
+	tbl, err := e.getTable(bigtable.ClientConfig{AppProfile: appProfile}, args[0])
+	if err != nil {
+		return err
+	}
+
+	filter := bigtable.ChainFilters(
+		bigtable.CellsPerRowLimitFilter(1),
+		bigtable.StripValueFilter(),
+	)
+	rd.opts = []bigtable.ReadOption{bigtable.RowFilter(filter)}
+	n := 0
+	var last string
+	lastProgress := time.Now()
+	err = rd.run(ctx, e.log, tbl, func(r bigtable.Row) bool {
+		n++
+		last = r.Key()
+		if interval > 0 && time.Since(lastProgress) >= interval {
+			e.log.Printf("Counted %d rows so far, up to %q", n, last)
+			lastProgress = time.Now()
+		}
+		return true
+	})
+	if err != nil {
+		if interrupted(ctx) {
+			return interruptedAfter("counting %d rows, up to %q", n, last)
+		}
+		if n > 0 {
+			return fmt.Errorf("Reading rows: %v; counted %d rows up to %q", err, n, last)
+		}
+		return fmt.Errorf("Reading rows: %v", err)
+	}
+	fmt.Fprintln(e.stdout, n)
+	return nil
+}
+
+func parseFamilyType(s string) (bigtable.Type, error) {
+	sl := strings.ToLower(s)
+	if sl == "intsum" {
+		return bigtable.AggregateType{
+			Input:      bigtable.Int64Type{},
+			Aggregator: bigtable.SumAggregator{}}, nil
+	} else if sl == "intmin" {
+		return bigtable.AggregateType{
+			Input:      bigtable.Int64Type{},
+			Aggregator: bigtable.MinAggregator{}}, nil
+	} else if sl == "intmax" {
+		return bigtable.AggregateType{
+			Input:      bigtable.Int64Type{},
+			Aggregator: bigtable.MaxAggregator{}}, nil
+	} else if sl == "inthll" {
+		return bigtable.AggregateType{
+			Input:      bigtable.Int64Type{},
+			Aggregator: bigtable.HllppUniqueCountAggregator{}}, nil
+	} else if sl == "stringutf8bytes" {
+		return bigtable.StringType{
+			Encoding: bigtable.StringUtf8Encoding{},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown type %s", s)
+}
+
+// parseGCPolicyArg parses a GC policy given as an argument, optionally
+// prefixed with "policy=". A policy of @<name> refers to a template defined
+// in ~/.cbtrc as gcpolicy.<name> = <policy>.
+func (c *Config) parseGCPolicyArg(s string) (bigtable.GCPolicy, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "policy=")
+	if strings.HasPrefix(s, "@") {
+		name := s[1:]
+		var ok bool
+		if c != nil {
+			s, ok = c.GCPolicies[name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown GC policy template %q; define it in %s as gcpolicy.%s = <policy>", "@"+name, Filename(), name)
+		}
+	}
+	return parseGCPolicy(s)
+}
+
+func (c *Config) parseFamilyText(family string) (string, bigtable.Family, error) {
+	famPolicy := strings.Split(family, ":")
+	var gcPolicy bigtable.GCPolicy
+	var tpe bigtable.Type
+	var err error = nil
+	if len(famPolicy) < 2 {
+		gcPolicy = bigtable.NoGcPolicy()
+	} else {
+		gcPolicy, err = c.parseGCPolicyArg(famPolicy[1])
+		if err != nil {
+			return "", bigtable.Family{}, err
+		}
+		if len(famPolicy) == 3 {
+			tpe, err = parseFamilyType(famPolicy[2])
+			if err != nil {
+				return "", bigtable.Family{}, err
+			}
+		}
+	}
+	return famPolicy[0], bigtable.Family{GCPolicy: gcPolicy, ValueType: tpe}, nil
+}
+
+func doSetFamilyValueType(ctx context.Context, e *env, args ...string) error {
+	if len(args) < 3 {
+		return errors.New("usage: cbt setvaluetype <table> <family> <type>")
+	}
+	familyType, err := parseFamilyType(args[2])
+	if err != nil {
+		return fmt.Errorf("Failed to update family value type: %v", err)
+	}
+
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	err = ac.UpdateFamily(ctx, args[0] /*table*/, args[1], /*familyName*/
+		bigtable.Family{
+			ValueType: familyType,
+		})
+	if err != nil {
+		return fmt.Errorf("Set value type: %v", err)
+	}
+	return nil
+}
+
+func doCreateTable(ctx context.Context, e *env, args ...string) error {
+	if len(args) < 1 {
+		return errors.New("usage: cbt createtable <table> [families=family[:gcpolicy[:type]],...] [splits=split,...]")
+	}
+
+	tblConf := bigtable.TableConf{TableID: args[0]}
+	parsed, err := parseArgs(args[1:], []string{"families", "splits"})
+	if err != nil {
+		return err
+	}
+	for key, val := range parsed {
+		chunks, err := csv.NewReader(strings.NewReader(val)).Read()
+		if err != nil {
+			return fmt.Errorf("Invalid %s arg format: %v", key, err)
+		}
+		switch key {
+		case "families":
+			tblConf.ColumnFamilies = make(map[string]bigtable.Family)
+			for _, family := range chunks {
+				familyId, familyConfig, err := e.config.parseFamilyText(family)
+				if err != nil {
+					return err
+				}
+
+				tblConf.ColumnFamilies[familyId] = familyConfig
+			}
+		case "splits":
+			tblConf.SplitKeys = chunks
+		}
+	}
+
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	if err := ac.CreateTableFromConf(ctx, &tblConf); err != nil {
+		return fmt.Errorf("Creating table: %v", err)
+	}
+	return nil
+}
+
+func doCreateFamily(ctx context.Context, e *env, args ...string) error {
+	if len(args) != 2 {
+		return errors.New("usage: cbt createfamily <table> <family>")
+	}
+	familyId, config, err := e.config.parseFamilyText(args[1])
+	if err != nil {
+		return err
+	}
+
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	err = ac.CreateColumnFamilyWithConfig(ctx, args[0], familyId, config)
+	if err != nil {
+		return fmt.Errorf("Creating column family: %v", err)
+	}
+	return nil
+}
+
+func doCreateInstance(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt createinstance <instance-id> <display-name> " +
+		"(<cluster-id> <zone> <num-nodes> <storage type> | cluster=<cluster-id>:<zone>:<num-nodes>:<storage type>) " +
+		"[cluster=<cluster-id>:<zone>:<num-nodes>:<storage type> ...]"
+	if len(args) < 3 {
+		return errors.New(usage)
+	}
+
+	var clusters []bigtable.ClusterConfig
+	rest := args[2:]
+	if !strings.HasPrefix(rest[0], "cluster=") {
+		if len(rest) < 4 {
+			return errors.New(usage)
+		}
+		numNodes, err := strconv.ParseInt(rest[2], 0, 32)
+		if err != nil {
+			return fmt.Errorf("Bad num-nodes %q: %v", rest[2], err)
+		}
+		sType, err := parseStorageType(rest[3])
+		if err != nil {
+			return err
+		}
+		clusters = append(clusters, bigtable.ClusterConfig{
+			ClusterID:   rest[0],
+			Zone:        rest[1],
+			NumNodes:    int32(numNodes),
+			StorageType: sType,
+		})
+		rest = rest[4:]
+	}
+	for _, arg := range rest {
+		if !strings.HasPrefix(arg, "cluster=") {
+			return errors.New(usage)
+		}
+		cc, err := parseClusterSpec(strings.TrimPrefix(arg, "cluster="))
+		if err != nil {
+			return err
+		}
+		clusters = append(clusters, cc)
+	}
+
+	ic := bigtable.InstanceWithClustersConfig{
+		InstanceID:  args[0],
+		DisplayName: args[1],
+		Clusters:    clusters,
+	}
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return err
+	}
+	if err := iac.CreateInstanceWithClusters(ctx, &ic); err != nil {
+		return fmt.Errorf("Creating instance: %v", err)
+	}
+	return nil
+}
+
+// parseClusterSpec parses a cluster given as <cluster-id>:<zone>:<num-nodes>:<storage type>.
+func parseClusterSpec(spec string) (bigtable.ClusterConfig, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) != 4 || parts[0] == "" || parts[1] == "" {
+		return bigtable.ClusterConfig{}, fmt.Errorf("bad cluster %q, want <cluster-id>:<zone>:<num-nodes>:<storage type>", spec)
+	}
+	numNodes, err := strconv.ParseInt(parts[2], 0, 32)
+	if err != nil {
+		return bigtable.ClusterConfig{}, fmt.Errorf("bad num-nodes %q in cluster %q: %v", parts[2], spec, err)
+	}
+	sType, err := parseStorageType(parts[3])
+	if err != nil {
+		return bigtable.ClusterConfig{}, err
+	}
+	return bigtable.ClusterConfig{
+		ClusterID:   parts[0],
+		Zone:        parts[1],
+		NumNodes:    int32(numNodes),
+		StorageType: sType,
+	}, nil
+}
+
+func doCreateCluster(ctx context.Context, e *env, args ...string) error {
+	if len(args) < 4 {
+		return errors.New("usage: cbt createcluster <cluster-id> <zone> <num-nodes> <storage type>")
+	}
+
+	numNodes, err := strconv.ParseInt(args[2], 0, 32)
+	if err != nil {
+		return fmt.Errorf("Bad num_nodes %q: %v", args[2], err)
+	}
+
+	sType, err := parseStorageType(args[3])
+	if err != nil {
+		return err
+	}
+
+	cc := bigtable.ClusterConfig{
+		InstanceID:  e.config.Instance,
+		ClusterID:   args[0],
+		Zone:        args[1],
+		NumNodes:    int32(numNodes),
+		StorageType: sType,
+	}
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return err
+	}
+	if err := iac.CreateCluster(ctx, &cc); err != nil {
+		return fmt.Errorf("Creating cluster: %v", err)
+	}
+	return nil
+}
+
+func doUpdateCluster(ctx context.Context, e *env, args ...string) error {
+	if len(args) < 2 {
+		return errors.New("cbt updatecluster <cluster-id> [num-nodes=num-nodes]")
+	}
+
+	numNodes := int64(0)
+	parsed, err := parseArgs(args[1:], []string{"num-nodes"})
+	if err != nil {
+		return err
+	}
+	if val, ok := parsed["num-nodes"]; ok {
+		numNodes, err = strconv.ParseInt(val, 0, 32)
+		if err != nil {
+			return fmt.Errorf("Bad num-nodes %q: %v", val, err)
+		}
+	}
+	if numNodes <= 0 {
+		return errors.New("Updating cluster: nothing to update")
+	}
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return err
+	}
+	if err := iac.UpdateCluster(ctx, e.config.Instance, args[0], int32(numNodes)); err != nil {
+		return fmt.Errorf("Updating cluster: %v", err)
+	}
+	return nil
+}
+
+func doDeleteInstance(ctx context.Context, e *env, args ...string) error {
+	if len(args) != 1 && (len(args) != 2 || args[1] != "force") {
+		return errors.New("usage: cbt deleteinstance <instance> [force]")
+	}
+	instance := args[0]
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return err
+	}
+	printInstanceDeletionImpact(ctx, e, iac, instance)
+	if len(args) == 1 && !e.confirm(fmt.Sprintf("Delete instance %q and all of its data?", instance)) {
+		return errors.New("Deletion cancelled")
+	}
+	if err := iac.DeleteInstance(ctx, instance); err != nil {
+		return fmt.Errorf("Deleting instance: %v", err)
+	}
+	return nil
+}
+
+// printInstanceDeletionImpact prints the clusters and tables that deleting
+// an instance would destroy. Failures to look them up are reported but do
+// not prevent the deletion.
+func printInstanceDeletionImpact(ctx context.Context, e *env, iac InstanceAdminAPI, instance string) {
+	fmt.Fprintf(e.stdout, "Instance %q will be deleted, including:\n", instance)
+	clusters, err := iac.Clusters(ctx, instance)
+	if err != nil {
+		e.log.Printf("Could not list clusters: %v", err)
+	} else {
+		fmt.Fprintf(e.stdout, "  %d cluster(s)\n", len(clusters))
+		for _, c := range clusters {
+			fmt.Fprintf(e.stdout, "    %s (%s, %d nodes)\n", c.Name, c.Zone, c.ServeNodes)
+		}
+	}
+	ac, err := e.getAdminClientForInstance(ctx, instance)
+	if err != nil {
+		e.log.Printf("Could not list tables: %v", err)
+		return
+	}
+	if ac != e.adminClient {
+		defer ac.Close()
+	}
+	tables, err := ac.Tables(ctx)
+	if err != nil {
+		e.log.Printf("Could not list tables: %v", err)
+		return
+	}
+	sort.Strings(tables)
+	fmt.Fprintf(e.stdout, "  %d table(s)\n", len(tables))
+	for _, t := range tables {
+		fmt.Fprintf(e.stdout, "    %s\n", t)
+	}
+}
+
+// printTableDeletionImpact prints the column families of a table and the
+// backups that were taken from it. Failures to look them up are reported
+// but do not prevent the deletion.
+func printTableDeletionImpact(ctx context.Context, e *env, ac AdminAPI, table string) {
+	fmt.Fprintf(e.stdout, "Table %q will be deleted, including:\n", table)
+	ti, err := ac.TableInfo(ctx, table)
+	if err != nil {
+		e.log.Printf("Could not get table info: %v", err)
+	} else {
+		sort.Sort(byFamilyName(ti.FamilyInfos))
+		fmt.Fprintf(e.stdout, "  %d column family(s)\n", len(ti.FamilyInfos))
+		for _, fam := range ti.FamilyInfos {
+			fmt.Fprintf(e.stdout, "    %s (GC policy: %s)\n", fam.Name, fam.GCPolicy)
+		}
+	}
+	var backups []string
+	it := ac.Backups(ctx, "-")
+	for {
+		b, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			e.log.Printf("Could not list backups: %v", err)
+			return
+		}
+		if b.SourceTable == table {
+			backups = append(backups, b.Name)
+		}
+	}
+	fmt.Fprintf(e.stdout, "Backups taken from this table are not deleted: %d backup(s)\n", len(backups))
+	for _, b := range backups {
+		fmt.Fprintf(e.stdout, "    %s\n", b)
+	}
+}
+
+// confirm asks the user a yes/no question when stdin is a terminal. It
+// returns true without asking when stdin is not interactive, so that
+// scripts keep working.
+func (e *env) confirm(question string) bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return true
+	}
+	fmt.Fprintf(e.stdout, "%s [y/N]: ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+func doDeleteCluster(ctx context.Context, e *env, args ...string) error {
+	if len(args) != 1 {
+		return errors.New("usage: cbt deletecluster <cluster>")
+	}
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return err
+	}
+	if err := iac.DeleteCluster(ctx, e.config.Instance, args[0]); err != nil {
+		return fmt.Errorf("Deleting cluster: %v", err)
+	}
+	return nil
+}
+
+func doDeleteColumn(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt deletecolumn <table> <row> <family> <column> [app-profile=<app profile id>] " +
+		"[authorized-view=<authorized-view-id>] [from=<timestamp>] [to=<timestamp>] [backup-before-delete=<true|false>] " +
+		"[backup-file=<file.json>] [backup-table=<table-id>]"
+	if len(args) < 4 {
+		return errors.New(usage)
+	}
+	parsed, err := parseArgs(args[4:], append([]string{"app-profile", "authorized-view", "from", "to"}, deleteBackupOptions...))
+	if err != nil {
+		return errors.New(usage)
+	}
+	backup, err := parseDeleteBackup(parsed, args[0], time.Now())
+	if err != nil {
+		return err
+	}
+	tbl, err := e.openTableAPI(parsed["app-profile"], args[0], parsed["authorized-view"])
+	if err != nil {
+		return err
+	}
+	mut := bigtable.NewMutation()
+	var start, end bigtable.Timestamp
+	from, fromOK := parsed["from"]
+	to, toOK := parsed["to"]
+	if fromOK || toOK {
+		start, end, err = parseTimestampRange(from, to, time.Now())
+		if err != nil {
+			return err
+		}
+		mut.DeleteTimestampRange(args[2], args[3], start, end)
+	} else {
+		mut.DeleteCellsInColumn(args[2], args[3])
+	}
+	if backup.enabled() {
+		if err := backup.save(ctx, e, tbl, args[0], args[1], deletedColumnFilter(args[2], args[3], start, end), parsed["app-profile"]); err != nil {
+			return fmt.Errorf("Not deleting cells in column: %v", err)
+		}
+	}
+	if err := tbl.Apply(ctx, args[1], mut); err != nil {
+		return fmt.Errorf("Deleting cells in column: %v", err)
+	}
+	return nil
+}
+
+// parseTimestampRange parses the from and to arguments of the cell deletion
+// commands. Either may be empty, leaving that end of the range unbounded.
+func parseTimestampRange(from, to string, now time.Time) (start, end bigtable.Timestamp, err error) {
+	if from != "" {
+		var ok bool
+		if start, ok = parseCellTimestamp(from, now); !ok {
+			return 0, 0, fmt.Errorf("bad from timestamp %q", from)
+		}
+	}
+	if to != "" {
+		var ok bool
+		if end, ok = parseCellTimestamp(to, now); !ok {
+			return 0, 0, fmt.Errorf("bad to timestamp %q", to)
+		}
+		if end <= start {
+			return 0, 0, fmt.Errorf("to timestamp %q must be after from timestamp %q", to, from)
+		}
+	}
+	return start, end, nil
+}
+
+func doDeleteCells(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt deletecells <table> <row> <family> [column=<column>] [from=<timestamp>] [to=<timestamp>] [app-profile=<app profile id>]"
+	if len(args) < 3 {
+		return errors.New(usage)
+	}
+	parsed, err := parseArgs(args[3:], []string{"column", "from", "to", "app-profile"})
+	if err != nil {
+		return errors.New(usage)
+	}
+	start, end, err := parseTimestampRange(parsed["from"], parsed["to"], time.Now())
+	if err != nil {
+		return err
+	}
+	row, family := args[1], args[2]
+	bounded := parsed["from"] != "" || parsed["to"] != ""
+	c, err := e.getClient(bigtable.ClientConfig{AppProfile: parsed["app-profile"]})
+	if err != nil {
+		return err
+	}
+	tbl := c.Open(args[0])
+
+	mut := bigtable.NewMutation()
+	if column, ok := parsed["column"]; ok {
+		mut.DeleteTimestampRange(family, column, start, end)
+	} else if !bounded {
+		mut.DeleteCellsInFamily(family)
+	} else {
+		// There is no mutation for a timestamp range across a whole family,
+		// so delete the range from each column the row has in the family.
+		r, err := tbl.ReadRow(ctx, row, bigtable.RowFilter(bigtable.ChainFilters(
+			bigtable.FamilyFilter(regexp.QuoteMeta(family)),
+			bigtable.TimestampRangeFilterMicros(start, end),
+			bigtable.StripValueFilter(),
+		)))
+		if err != nil {
+			return fmt.Errorf("Reading row: %v", err)
+		}
+		seen := map[string]bool{}
+		for _, item := range r[family] {
+			column := strings.TrimPrefix(item.Column, family+":")
+			if !seen[column] {
+				seen[column] = true
+				mut.DeleteTimestampRange(family, column, start, end)
+			}
+		}
+		if len(seen) == 0 {
+			fmt.Fprintln(e.stdout, "No cells in range.")
+			return nil
+		}
+	}
+	if err := tbl.Apply(ctx, row, mut); err != nil {
+		return fmt.Errorf("Deleting cells: %v", err)
+	}
+	return nil
+}
+
+func doDeleteFamily(ctx context.Context, e *env, args ...string) error {
+	if len(args) != 2 {
+		return errors.New("usage: cbt deletefamily <table> <family>")
+	}
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	if err := ac.DeleteColumnFamily(ctx, args[0], args[1]); err != nil {
+		return fmt.Errorf("Deleting column family: %v", err)
+	}
+	return nil
+}
+
+func doDeleteRow(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt deleterow <table> <row> [app-profile=<app profile id>] [authorized-view=<authorized-view-id>] " +
+		"[backup-before-delete=<true|false>] [backup-file=<file.json>] [backup-table=<table-id>]"
+	if len(args) < 2 {
+		return errors.New(usage)
+	}
+	parsed, err := parseArgs(args[2:], append([]string{"app-profile", "authorized-view"}, deleteBackupOptions...))
+	if err != nil {
+		return errors.New(usage)
+	}
+	backup, err := parseDeleteBackup(parsed, args[0], time.Now())
+	if err != nil {
+		return err
+	}
+	tbl, err := e.openTableAPI(parsed["app-profile"], args[0], parsed["authorized-view"])
+	if err != nil {
+		return err
+	}
+	if backup.enabled() {
+		if err := backup.save(ctx, e, tbl, args[0], args[1], nil, parsed["app-profile"]); err != nil {
+			return fmt.Errorf("Not deleting row: %v", err)
+		}
+	}
+	mut := bigtable.NewMutation()
+	mut.DeleteRow()
+	if err := tbl.Apply(ctx, args[1], mut); err != nil {
+		return fmt.Errorf("Deleting row: %v", err)
+	}
+	return nil
+}
+
+func doDeleteAllRows(ctx context.Context, e *env, args ...string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Can't do `cbt deleteallrows %s`", args)
+	}
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	err = withHeartbeat(ctx, e, "deleting all rows of "+args[0], nil, func() error {
+		return ac.DropAllRows(ctx, args[0])
+	})
+	if err != nil {
+		return fmt.Errorf("Deleting all rows: %v", err)
+	}
+	return nil
+}
+
+func doDeleteTable(ctx context.Context, e *env, args ...string) error {
+	if len(args) != 1 && (len(args) != 2 || args[1] != "force") {
+		return fmt.Errorf("Can't do `cbt deletetable %s`", args)
+	}
+	table := args[0]
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	printTableDeletionImpact(ctx, e, ac, table)
+	if len(args) == 1 && !e.confirm(fmt.Sprintf("Delete table %q and all of its data?", table)) {
+		return errors.New("Deletion cancelled")
+	}
+	if err := ac.DeleteTable(ctx, table); err != nil {
+		return fmt.Errorf("Deleting table: %v", err)
+	}
+	return nil
+}
+
+// to break circular dependencies
+var (
+	doCompletionFn func(ctx context.Context, e *env, args ...string) error
+	doDocFn        func(ctx context.Context, e *env, args ...string) error
+	doHelpFn       func(ctx context.Context, e *env, args ...string) error
+	doMDDocFn      func(ctx context.Context, e *env, args ...string) error
+	doSelftestFn   func(ctx context.Context, e *env, args ...string) error
+)
+
+func init() {
+	doCompletionFn = doCompletionReal
+	doDocFn = doDocReal
+	doHelpFn = doHelpReal
+	doMDDocFn = doMDDocReal
+	doSelftestFn = doSelftestReal
+}
+
+func doCompletion(ctx context.Context, e *env, args ...string) error {
+	return doCompletionFn(ctx, e, args...)
+}
+func doDoc(ctx context.Context, e *env, args ...string) error   { return doDocFn(ctx, e, args...) }
+func doHelp(ctx context.Context, e *env, args ...string) error  { return doHelpFn(ctx, e, args...) }
+func doMDDoc(ctx context.Context, e *env, args ...string) error { return doMDDocFn(ctx, e, args...) }
+func doSelftest(ctx context.Context, e *env, args ...string) error {
+	return doSelftestFn(ctx, e, args...)
+}
+
+func docFlags() ([]*flag.Flag, error) {
+	// Only include specific flags, in a specific order.
+	var flags []*flag.Flag
+	for _, name := range []string{"project", "instance", "creds", "timeout"} {
+		f := flag.Lookup(name)
+		if f == nil {
+			return nil, fmt.Errorf("Flag not linked: -%s", name)
+		}
+		flags = append(flags, f)
+	}
+	return flags, nil
+}
+
+func doDocReal(ctx context.Context, e *env, args ...string) error {
+	flags, err := docFlags()
+	if err != nil {
+		return err
+	}
+	data := map[string]interface{}{
+		"Commands":   commands,
+		"Flags":      flags,
+		"ConfigHelp": configHelp,
+		// "FormatHelp": formatHelp,
+	}
+	var buf bytes.Buffer
+	if err := docTemplate.Execute(&buf, data); err != nil {
+		return fmt.Errorf("Bad doc template: %v", err)
+	}
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("Bad doc output: %v", err)
+	}
+	_, err = e.stdout.Write(out)
+	return err
+}
+
+func indentLines(s, ind string) string {
+	ss := strings.Split(s, "\n")
+	for i, p := range ss {
+		ss[i] = ind + p
+	}
+	return strings.Join(ss, "\n")
+}
+
+var docTemplate = template.Must(template.New("doc").Funcs(template.FuncMap{
+	"indent": indentLines,
+}).
+	Parse(`
+// Copyright 2016 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// DO NOT EDIT. THIS IS AUTOMATICALLY GENERATED.
+// Run "go generate" to regenerate.
+//go:generate go run . -o cbtdoc.go doc
+
+/*
+` + docIntroTemplate + `
+
+{{range .Commands}}
+{{.Desc}}
+
+Usage:
+{{indent .Usage "\t"}}
+
+
+
+{{end}}
+*/
+package cbtcmd
+`))
+
+func doHelpReal(ctx context.Context, e *env, args ...string) error {
+	if len(args) == 0 {
+		usage(e.stdout)
+		return nil
+	}
+	for _, cmd := range commands {
+		if cmd.Name == args[0] {
+			fmt.Fprintln(e.stdout, cmd.Usage)
+			return nil
+		}
+	}
+	return fmt.Errorf("Don't know command %q", args[0])
+}
+
+func doListInstances(ctx context.Context, e *env, args ...string) error {
+	parsed, err := parseArgs(args, []string{"wide"})
+	if err != nil {
+		return errors.New("usage: cbt listinstances [wide=<true|false>]")
+	}
+	wide := parsed["wide"] == "true"
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return err
+	}
+	is, err := iac.Instances(ctx)
+	if err != nil {
+		return fmt.Errorf("Getting list of instances: %v", err)
+	}
+	header := []string{"Instance Name", "Info"}
+	if wide {
+		header = append(header, "State", "Type", "Labels")
+	}
+	var rows [][]string
+	for _, i := range is {
+		row := []string{i.Name, i.DisplayName}
+		if wide {
+			var labels []string
+			for k, v := range i.Labels {
+				labels = append(labels, k+"="+v)
+			}
+			sort.Strings(labels)
+			row = append(row, btapb.Instance_State(i.InstanceState).String(), btapb.Instance_Type(i.InstanceType).String(),
+				strings.Join(labels, ","))
+		}
+		rows = append(rows, row)
+	}
+	e.printList(header, rows, wide)
+	return nil
+}
+
+func doListClusters(ctx context.Context, e *env, args ...string) error {
+	parsed, err := parseArgs(args, []string{"wide"})
+	if err != nil {
+		return errors.New("usage: cbt listclusters [wide=<true|false>]")
+	}
+	wide := parsed["wide"] == "true"
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return err
+	}
+	cis, err := iac.Clusters(ctx, e.config.Instance)
+	if err != nil {
+		return fmt.Errorf("Getting list of clusters: %v", err)
+	}
+	header := []string{"Cluster Name", "Zone", "State"}
+	if wide {
+		header = append(header, "Storage", "Autoscaling", "KMS Key")
+	}
+	var rows [][]string
+	for _, ci := range cis {
+		row := []string{ci.Name, ci.Zone, fmt.Sprintf("%s (%d serve nodes)", ci.State, ci.ServeNodes)}
+		if wide {
+			autoscaling := clusterAutoscaling(ci)
+			if autoscaling == "" {
+				autoscaling = "-"
+			}
+			row = append(row, clusterStorage(ci), autoscaling, ci.KMSKeyName)
+		}
+		rows = append(rows, row)
+	}
+	e.printList(header, rows, wide)
+	return nil
+}
+
+// printList prints the table of a listing command. Wide tables are never
+// truncated.
+func (e *env) printList(header []string, rows [][]string, wide bool) {
+	width := e.outputWidth()
+	if wide {
+		width = 0
+	}
+	printTable(e.stdout, width, header, rows)
+}
+
+func printFullReadStats(w io.Writer, stats *bigtable.FullReadStats) {
+	readStats := stats.ReadIterationStats
+	latencyStats := stats.RequestLatencyStats
+	fmt.Fprintln(w, "Summary Stats")
+	fmt.Fprintln(w, strings.Repeat("=", 20))
+	fmt.Fprintf(w, "rows_seen_count: %d\n", readStats.RowsSeenCount)
+	fmt.Fprintf(w, "rows_returned_count: %d\n", readStats.RowsReturnedCount)
+	fmt.Fprintf(w, "cells_seen_count: %d\n", readStats.CellsSeenCount)
+	fmt.Fprintf(w, "cells_returned_count: %d\n", readStats.CellsReturnedCount)
+	fmt.Fprintf(w, "frontend_server_latency: %dms\n", latencyStats.FrontendServerLatency.Milliseconds())
+	fmt.Fprintln(w, "")
+}
+
+// fullReadStatsProto converts stats back into the RequestStats message the
+// service returned, so that the JSON output uses the documented field names.
+func fullReadStatsProto(stats *bigtable.FullReadStats) *btpb.RequestStats {
+	readStats := stats.ReadIterationStats
+	return &btpb.RequestStats{
+		StatsView: &btpb.RequestStats_FullReadStatsView{
+			FullReadStatsView: &btpb.FullReadStatsView{
+				ReadIterationStats: &btpb.ReadIterationStats{
+					RowsSeenCount:      readStats.RowsSeenCount,
+					RowsReturnedCount:  readStats.RowsReturnedCount,
+					CellsSeenCount:     readStats.CellsSeenCount,
+					CellsReturnedCount: readStats.CellsReturnedCount,
+				},
+				RequestLatencyStats: &btpb.RequestLatencyStats{
+					FrontendServerLatency: durationpb.New(stats.RequestLatencyStats.FrontendServerLatency),
+				},
+			},
+		},
+	}
+}
+
+func printFullReadStatsJSON(w io.Writer, stats *bigtable.FullReadStats) error {
+	out, err := protojson.Marshal(fullReadStatsProto(stats))
+	if err != nil {
+		return fmt.Errorf("Encoding stats: %v", err)
+	}
+	fmt.Fprintln(w, string(out))
+	return nil
+}
+
+// lowSelectivity is the fraction of the rows seen by a scan below which the
+// scan is reported as badly filtered.
+const lowSelectivity = 0.1
+
+// scanStats sums the stats of every request made by a read. A scan that is
+// retried or resumed reports stats for each request separately.
+type scanStats struct {
+	mu       sync.Mutex
+	requests int
+	total    bigtable.FullReadStats
+}
+
+func (s *scanStats) add(stats *bigtable.FullReadStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	r, t := stats.ReadIterationStats, &s.total.ReadIterationStats
+	t.RowsSeenCount += r.RowsSeenCount
+	t.RowsReturnedCount += r.RowsReturnedCount
+	t.CellsSeenCount += r.CellsSeenCount
+	t.CellsReturnedCount += r.CellsReturnedCount
+	s.total.RequestLatencyStats.FrontendServerLatency += stats.RequestLatencyStats.FrontendServerLatency
+}
+
+func (s *scanStats) option() bigtable.ReadOption {
+	return bigtable.WithFullReadStats(s.add)
+}
+
+// printScanEfficiency prints how much of the data seen by a scan was
+// returned, and warns if most of it was filtered out.
+func printScanEfficiency(w io.Writer, s *scanStats) {
+	r := s.total.ReadIterationStats
+	fmt.Fprintln(w, "Scan Efficiency")
+	fmt.Fprintln(w, strings.Repeat("=", 20))
+	fmt.Fprintf(w, "requests: %d\n", s.requests)
+	if r.RowsSeenCount == 0 {
+		fmt.Fprintln(w, "")
+		return
+	}
+	rowSelectivity := float64(r.RowsReturnedCount) / float64(r.RowsSeenCount)
+	if r.RowsReturnedCount > 0 {
+		fmt.Fprintf(w, "rows_seen_per_row_returned: %.1f\n", float64(r.RowsSeenCount)/float64(r.RowsReturnedCount))
+	}
+	fmt.Fprintf(w, "row_filter_selectivity: %.2f%%\n", rowSelectivity*100)
+	if r.CellsSeenCount > 0 {
+		fmt.Fprintf(w, "cell_filter_selectivity: %.2f%%\n", float64(r.CellsReturnedCount)/float64(r.CellsSeenCount)*100)
+	}
+	if rowSelectivity < lowSelectivity {
+		fmt.Fprintf(w, "\nWarning: the scan read %d rows to return %d. Narrow the range with start=, end= or prefix=\n"+
+			"instead of filtering, or redesign the row key so these rows are contiguous.\n",
+			r.RowsSeenCount, r.RowsReturnedCount)
+	}
+	fmt.Fprintln(w, "")
+}
+
+func makeFullReadStatsOption(statsChannel *chan *bigtable.FullReadStats) bigtable.ReadOption {
+	// Return a callback that sends stats through a channel. This ensures that stats are
+	// printed after rows. We cannot print in this callback, because stats would come before
+	// row output in doLookup().
+	return bigtable.WithFullReadStats(func(stats *bigtable.FullReadStats) {
+		select {
+		case *statsChannel <- stats:
+		default:
+		}
+	})
+}
+
+func doLookup(ctx context.Context, e *env, args ...string) error {
+	valid := []string{
+		"columns", "cells-per-column", "app-profile", "authorized-view", "format-file", "keys-only",
+		"include-stats", "display", "hide", "format", "any-of", "all-of", "decode-aggregates", "sort-cells", "priority"}
+	// With -stdin, the row keys are read from stdin instead of the arguments.
+	stdin := false
+	var rest []string
+	for _, arg := range args {
+		if arg == "-stdin" || arg == "--stdin" {
+			stdin = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	args = rest
+	if stdin {
+		args = e.withDefaultTable(args, len(args) == 0 || isOptionArg(args[0], valid))
+	} else {
+		args = e.withDefaultTable(args, len(args) == 1 || len(args) > 1 && isOptionArg(args[1], valid))
+	}
+	if len(args) < 2 && !(stdin && len(args) == 1) {
+		return errors.New("usage: cbt lookup <table> (<row> | -stdin) [columns=<family:qualifier>...] [cells-per-column=<n>] " +
+			"[app-profile=<app profile id>] [priority=<low|medium|high>] [authorized-view=<authorized-view-id>]")
+	}
+	keyArgs := 2
+	if stdin {
+		keyArgs = 1
+	}
+
+	parsed, err := parseArgs(args[keyArgs:], valid)
+
+	if err != nil {
+		return err
+	}
+	var opts []bigtable.ReadOption
+	var filters []bigtable.Filter
+	if cellsPerColumn := parsed["cells-per-column"]; cellsPerColumn != "" {
+		n, err := strconv.Atoi(cellsPerColumn)
+		if err != nil {
+			return fmt.Errorf("Bad number of cells per column %q: %v", cellsPerColumn, err)
+		}
+		filters = append(filters, bigtable.LatestNFilter(n))
+	}
+	if columns := parsed["columns"]; columns != "" {
+		columnFilters, err := parseColumnsFilter(columns)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, columnFilters)
+	}
+	groupFilters, err := parseFilterGroups(parsed)
+	if err != nil {
+		return err
+	}
+	filters = append(filters, groupFilters...)
+
+	var keysOnly bool
+	if keyStr := parsed["keys-only"]; keyStr != "" {
+		keysOnly, err = strconv.ParseBool(keyStr)
+		if err != nil {
+			return err
+		}
+	}
+
+	if keysOnly {
+		filters = append(filters, bigtable.StripValueFilter())
+	}
+
+	// Gather up all of the filters being applied and determine whether we
+	// need to chain them together.
+	if len(filters) > 1 {
+		opts = append(opts, bigtable.RowFilter(bigtable.ChainFilters(filters...)))
+	} else if len(filters) == 1 {
+		opts = append(opts, bigtable.RowFilter(filters[0]))
+	}
+
+	statsChannel := make(chan *bigtable.FullReadStats, 1)
+	includeStats := parsed["include-stats"]
+	switch includeStats {
+	case "":
+	case "full", "json":
+		opts = append(opts, makeFullReadStatsOption(&statsChannel))
+	default:
+		return fmt.Errorf("Bad include-stats value: %q is not one of the supported stats views.", includeStats)
+	}
+
+	table := args[0]
+	appProfile, err := e.priorityAppProfile(ctx, parsed["app-profile"], parsed["priority"])
+	if err != nil {
+		return err
+	}
+	tbl, err := e.openTableAPI(appProfile, table, parsed["authorized-view"])
+	if err != nil {
+		return err
+	}
+
+	formatFilePath := parsed["format-file"]
+	err = e.formatting.Setup(formatFilePath)
+	if err != nil {
+		return fmt.Errorf("Reading row: %v", err)
+	}
+	if err := e.formatting.SetColumnSelection(parsed["display"], parsed["hide"]); err != nil {
+		return fmt.Errorf("Reading row: %v", err)
+	}
+	if err := e.setupAggregateDecoding(ctx, table, parsed["decode-aggregates"]); err != nil {
+		return err
+	}
+	if err := e.formatting.SetCellOrder(parsed["sort-cells"]); err != nil {
+		return err
+	}
+	format := parsed["format"]
+	if format != "" && format != "value" {
+		return fmt.Errorf("Bad format value: %q must be \"value\" if set", format)
+	}
+
+	if stdin {
+		if includeStats != "" {
+			return errors.New("include-stats can't be used with -stdin")
+		}
+		if err := e.lookupKeys(ctx, tbl, os.Stdin, e.stdout, format, opts); err != nil {
+			return fmt.Errorf("Looking up rows: %v", err)
+		}
+		return nil
+	}
+
+	r, err := tbl.ReadRow(ctx, args[1], opts...)
+	if err != nil {
+		return fmt.Errorf("Reading row: %v", err)
+	}
+
+	switch format {
+	case "":
+		var buf bytes.Buffer
+		if err := e.printRow(r, &buf); err != nil {
+			return err
+		}
+		fmt.Fprintln(e.stdout, buf.String())
+	case "value":
+		val, err := e.rowValue(r)
+		if err != nil {
+			return fmt.Errorf("Reading row: %v", err)
+		}
+		fmt.Fprintln(e.stdout, val)
+	}
+	select {
+	case stats := <-statsChannel:
+		if includeStats == "json" {
+			return printFullReadStatsJSON(e.stdout, stats)
+		}
+		printFullReadStats(e.stdout, stats)
+	default:
+		if includeStats != "" {
+			return errors.New("Stats were requested but not received.")
+		}
+	}
+	return nil
+}
+
+// rowValue returns the formatted value of the most recent cell in a row
+// that holds a single column. It is used for format=value, so it fails if
+// the row is missing or spans more than one column.
+func (e *env) rowValue(r bigtable.Row) (string, error) {
+	items := e.formatting.DisplayItems(r)
+	if len(items) == 0 {
+		return "", errors.New("no value found")
+	}
+	// The cells may be sorted oldest first by sort-cells.
+	ri := items[0]
+	for _, it := range items[1:] {
+		if it.Column != ri.Column {
+			return "", fmt.Errorf("format=value needs a single column, got %s and %s; use columns= to select one",
+				ri.Column, it.Column)
+		}
+		if it.Timestamp > ri.Timestamp {
+			ri = it
+		}
+	}
+	return e.formatting.FormatValue(ri.Column[:strings.Index(ri.Column, ":")], ri.Column, ri.Value)
+}
+
+func (e *env) printRow(r bigtable.Row, w io.Writer) error {
+	return e.printRowAtTimezone(r, w, time.Local)
+}
+
+func (e *env) printRowAtTimezone(r bigtable.Row, w io.Writer, loc *time.Location) error {
+	fmt.Fprintln(w, strings.Repeat("-", 40))
+	fmt.Fprintln(w, e.colorize(colorRowKey, r.Key()))
+
+	for _, ri := range e.formatting.DisplayItems(r) {
+		fam := ri.Column
+		if i := strings.Index(fam, ":"); i >= 0 {
+			fam = fam[:i]
+		}
+		ts := time.UnixMicro(int64(ri.Timestamp))
+		var labels string
+		if len(ri.Labels) > 0 {
+			labels = " [" + strings.Join(ri.Labels, ",") + "]"
+		}
+		fmt.Fprintf(w, "  %s @ %s%s\n",
+			e.colorize(colorColumn, fmt.Sprintf("%-40s", ri.Column)),
+			e.colorize(colorTimestamp, ts.In(loc).Format("2006/01/02-15:04:05.000000")), labels)
+		formatted, err :=
+			e.formatting.Format(
+				"    ", fam, ri.Column, ri.Value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(w, formatted)
+	}
+	return nil
+}
+
+type byFamilyName []bigtable.FamilyInfo
+
+func (b byFamilyName) Len() int           { return len(b) }
+func (b byFamilyName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byFamilyName) Less(i, j int) bool { return b[i].Name < b[j].Name }
+
+func doLS(ctx context.Context, e *env, args ...string) error {
+	if len(args) > 0 && args[0] == "-l" {
+		return doLSLong(ctx, e, args[1:]...)
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("Can't do `cbt ls %s`", args)
+	}
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	switch len(args) {
+	case 0:
+		tables, err := ac.Tables(ctx)
+		if err != nil {
+			return fmt.Errorf("Getting list of tables: %v", err)
+		}
+		sort.Strings(tables)
+		for _, table := range tables {
+			fmt.Fprintln(e.stdout, table)
+		}
+	case 1:
+		table := args[0]
+		ti, err := ac.TableInfo(ctx, table)
+		if err != nil {
+			return fmt.Errorf("Getting table info: %v", err)
+		}
+		sort.Sort(byFamilyName(ti.FamilyInfos))
+		var rows [][]string
+		for _, fam := range ti.FamilyInfos {
+			jsonString, err := bigtable.MarshalJSON(fam.ValueType)
+			if err != nil {
+				return fmt.Errorf("Getting table info: %v", err)
+			}
+			rows = append(rows, []string{fam.Name, fam.GCPolicy, string(jsonString)})
+		}
+		e.printList([]string{"Family Name", "GC Policy", "Value Type"}, rows, false)
+	}
+	return nil
+}
+
+func doMDDocReal(ctx context.Context, e *env, args ...string) error {
+	for i, arg := range args {
+		// Accept the flag-like spelling -out-dir=<dir> as well.
+		args[i] = strings.TrimLeft(arg, "-")
+	}
+	parsed, err := parseArgs(args, []string{"out-dir"})
+	if err != nil {
+		return errors.New("usage: cbt mddoc [out-dir=<dir>]")
+	}
+	flags, err := docFlags()
+	if err != nil {
+		return err
+	}
+	data := map[string]interface{}{
+		"Commands":   commands,
+		"Flags":      flags,
+		"ConfigHelp": configHelp,
+		// "FormatHelp": formatHelp,
+	}
+	if dir := parsed["out-dir"]; dir != "" {
+		if err := writeMDDocPages(dir, data); err != nil {
+			return fmt.Errorf("Writing the Markdown pages: %v", err)
+		}
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := mddocTemplate.Execute(&buf, data); err != nil {
+		return fmt.Errorf("Bad mddoc template: %v", err)
+	}
+	_, err = io.Copy(e.stdout, &buf)
+	return err
+}
+
+var mddocTemplate = template.Must(template.New("mddoc").Funcs(template.FuncMap{
+	"indent": indentLines,
+}).
+	Parse(docIntroTemplate + `
+
+
+{{range .Commands}}
+## {{.Desc}}
+
+{{indent .Usage "\t"}}
+
+
+
+{{end}}
+`))
+
+// writeMDDocPages writes an index.md page with the introduction and one
+// <command>.md page per command to dir. Each page starts with YAML front
+// matter that the docs site uses for its title and navigation.
+func writeMDDocPages(dir string, data map[string]interface{}) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	write := func(name string, t *template.Template, data interface{}) error {
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return fmt.Errorf("bad mddoc template: %v", err)
+		}
+		return os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0o644)
+	}
+	if err := write("index.md", mddocIndexTemplate, data); err != nil {
+		return err
+	}
+	for _, cmd := range commands {
+		if err := write(cmd.Name+".md", mddocPageTemplate, cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var mddocIndexTemplate = template.Must(template.New("mddoc-index").Funcs(template.FuncMap{
+	"indent": indentLines,
+}).
+	Parse(`---
+title: "cbt reference"
+description: "Reference for the cbt CLI for Bigtable"
+---
+
+` + docIntroTemplate + `
+
+## Commands
+{{range .Commands}}
+- [cbt {{.Name}}]({{.Name}}.md): {{.Desc}}{{end}}
+`))
+
+// requiredFlagNames returns the names of the flags that r requires.
+func requiredFlagNames(r RequiredFlags) []string {
+	var names []string
+	if r&ProjectRequired != 0 {
+		names = append(names, "project")
+	}
+	if r&InstanceRequired != 0 {
+		names = append(names, "instance")
+	}
+	return names
+}
+
+var mddocPageTemplate = template.Must(template.New("mddoc-page").Funcs(template.FuncMap{
+	"indent":        indentLines,
+	"requiredFlags": func(r RequiredFlags) string { return strings.Join(requiredFlagNames(r), ", ") },
+}).
+	Parse(`---
+title: "cbt {{.Name}}"
+description: {{printf "%q" .Desc}}
+command: "{{.Name}}"
+required_flags: [{{requiredFlags .Required}}]
+---
+
+## {{.Desc}}
+
+{{indent .Usage "\t"}}
+`))
+
+func doPurge(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt purge <table> older-than=<duration> [prefix=<row-key-prefix>] [columns=<family>:<qualifier>,...] [dry-run=<true|false>] [workers=<1>] [max-rate=<rows/s>] [progress-interval=<duration>] [app-profile=<app profile id>]"
+	if len(args) < 2 {
+		return errors.New(usage)
+	}
+	parsed, err := parseArgs(args[1:], []string{"older-than", "prefix", "columns", "dry-run", "workers", "max-rate", "progress-interval", "app-profile"})
+	if err != nil {
+		return err
+	}
+	if parsed["older-than"] == "" {
+		return errors.New(usage)
+	}
+	age, err := parseDuration(parsed["older-than"])
+	if err != nil {
+		return err
+	}
+	var dryRun bool
+	if v := parsed["dry-run"]; v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("Bad dry-run %q: %v", v, err)
+		}
+	}
+	workers := 1
+	if v := parsed["workers"]; v != "" {
+		if workers, err = strconv.Atoi(v); err != nil || workers <= 0 {
+			return fmt.Errorf("Bad workers %q: must be > 0", v)
+		}
+	}
+	var limiter *rowLimiter
+	if v := parsed["max-rate"]; v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || rate <= 0 {
+			return fmt.Errorf("Bad max-rate %q: must be a number of rows per second > 0", v)
+		}
+		limiter = newRowLimiter(rate)
+	}
+	interval, err := parseProgressInterval(parsed["progress-interval"])
+	if err != nil {
+		return err
+	}
+	cutoff := bigtable.Time(time.Now().Add(-age)).TruncateToMilliseconds()
+
+	filters := []bigtable.Filter{bigtable.TimestampRangeFilterMicros(0, cutoff), bigtable.StripValueFilter()}
+	if columns := parsed["columns"]; columns != "" {
+		columnFilters, err := parseColumnsFilter(columns)
+		if err != nil {
+			return err
+		}
+		filters = append([]bigtable.Filter{columnFilters}, filters...)
+	}
+	rr := bigtable.InfiniteRange("")
+	if prefix := parsed["prefix"]; prefix != "" {
+		rr = bigtable.PrefixRange(prefix)
+	}
+
+	type purge struct {
+		row string
+		mut *bigtable.Mutation
+	}
+	c, err := e.getClient(bigtable.ClientConfig{AppProfile: parsed["app-profile"]})
+	if err != nil {
+		return err
+	}
+	tbl := c.Open(args[0])
+	purges := make(chan purge)
+	// errs holds the first write error. After it, the workers stop writing
+	// but keep taking rows until the reader sees it and stops.
+	errs := make(chan error, 1)
+	wctx, stop := context.WithCancel(ctx)
+	defer stop()
+	var purged atomic.Int64 // rows written
+	batchSize := limiter.batchSize(100)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			var rows []string
+			var muts []*bigtable.Mutation
+			flush := func() {
+				if len(rows) == 0 || wctx.Err() != nil {
+					rows, muts = nil, nil
+					return
+				}
+				err := limiter.wait(wctx, len(rows))
+				if err == nil {
+					var n int
+					n, err = e.batchWrite(wctx, tbl, rows, muts, worker)
+					purged.Add(int64(n))
+				}
+				if err != nil {
+					select {
+					case errs <- err:
+						stop()
+					default:
+					}
+				}
+				rows, muts = nil, nil
+			}
+			for p := range purges {
+				rows, muts = append(rows, p.row), append(muts, p.mut)
+				if len(rows) == batchSize {
+					flush()
+				}
+			}
+			flush()
+		}(i)
+	}
+
+	var nRows, nCells int
+	start, lastProgress := time.Now(), time.Now()
+	err = tbl.ReadRows(ctx, rr, func(r bigtable.Row) bool {
+		mut, cells := purgeMutation(r, cutoff)
+		nRows++
+		nCells += cells
+		if !dryRun {
+			purges <- purge{r.Key(), mut}
+		}
+		if interval > 0 && time.Since(lastProgress) >= interval {
+			n := purged.Load()
+			e.log.Printf("Scanned %d rows with %d old cells up to %q; purged %d rows, %.0f rows/s",
+				nRows, nCells, r.Key(), n, float64(n)/time.Since(start).Seconds())
+			lastProgress = time.Now()
+		}
+		return len(errs) == 0
+	}, bigtable.RowFilter(bigtable.ChainFilters(filters...)))
+	close(purges)
+	wg.Wait()
+	if interrupted(ctx) {
+		return interruptedAfter("purging %d rows", purged.Load())
+	}
+	if err != nil {
+		return fmt.Errorf("Reading rows: %v", err)
+	}
+	select {
+	case err := <-errs:
+		return fmt.Errorf("Deleting cells: %v; purged %d rows before the failure", err, purged.Load())
+	default:
+	}
+	if dryRun {
+		fmt.Fprintf(e.stdout, "Would purge %d cells in %d rows older than %s.\n", nCells, nRows, cutoff.Time().UTC().Format(time.RFC3339))
+		return nil
+	}
+	fmt.Fprintf(e.stdout, "Purged %d cells in %d rows older than %s.\n", nCells, purged.Load(), cutoff.Time().UTC().Format(time.RFC3339))
+	return nil
+}
+
+// purgeMutation returns a mutation deleting the cells before cutoff in each
+// column of r, along with the number of cells in r.
+func purgeMutation(r bigtable.Row, cutoff bigtable.Timestamp) (*bigtable.Mutation, int) {
+	mut := bigtable.NewMutation()
+	cells := 0
+	for fam, items := range r {
+		var last string
+		for _, item := range items {
+			cells++
+			column := strings.TrimPrefix(item.Column, fam+":")
+			if item.Column != last {
+				mut.DeleteTimestampRange(fam, column, 0, cutoff)
+				last = item.Column
+			}
+		}
+	}
+	return mut, cells
+}
+
+func doRead(ctx context.Context, e *env, args ...string) error {
+	valid := []string{
+		"authorized-view", "start", "end", "prefix", "columns", "count",
+		"cells-per-column", "regex", "app-profile", "limit",
+		"format-file", "keys-only", "include-stats", "reversed", "display", "hide",
+		"sample", "estimate", "any-of", "all-of", "decode-aggregates", "value-regex", "from", "to",
+		"sort-cells", "offset", "label", "sink", "if", "then", "else", "follow", "interval", "gc-pending",
+		"priority",
+	}
+	// With -force, costly combinations of arguments are only warnings;
+	// contradictory ones still stop the read.
+	force := false
+	var rest []string
+	for _, arg := range args {
+		if arg == "-force" || arg == "--force" {
+			force = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	args = e.withDefaultTable(rest, len(rest) == 0 || isOptionArg(rest[0], valid))
+	if len(args) < 1 {
+		return fmt.Errorf("usage: cbt read <table> [args ...]")
+	}
+
+	parsed, err := parseArgs(args[1:], valid)
+	if err != nil {
+		return err
+	}
+	if _, ok := parsed["limit"]; ok {
+		// Be nicer; we used to support this, but renamed it to "end".
+		return errors.New("Unknown arg key 'limit'; did you mean 'end'?")
+	}
+	if err := reportReadProblems(e.log, checkRead(parsed), force); err != nil {
+		return err
+	}
+
+	rd := resumableRead{start: parsed["start"], end: parsed["end"]}
+	if prefix := parsed["prefix"]; prefix != "" {
+		rd.start, rd.end = prefix, prefixEnd(prefix)
+	}
+
+	var opts []bigtable.ReadOption
+	if count := parsed["count"]; count != "" {
+		n, err := strconv.ParseInt(count, 0, 64)
+		if err != nil {
+			return fmt.Errorf("Bad count %q: %v", count, err)
+		}
+		rd.limit = n
+	}
+	var offset int64
+	if o := parsed["offset"]; o != "" {
+		offset, err = strconv.ParseInt(o, 0, 64)
+		if err != nil || offset < 0 {
+			return fmt.Errorf("Bad offset %q: must be a number of rows", o)
+		}
+	}
+
+	if reversedStr := parsed["reversed"]; reversedStr != "" {
+		reversed, err := strconv.ParseBool(reversedStr)
+		if err != nil {
+			return err
+		}
+		rd.reversed = reversed
+	}
+
+	stats := &scanStats{}
+	includeStats := parsed["include-stats"]
+	switch includeStats {
+	case "":
+	case "full", "json":
+		opts = append(opts, stats.option())
+	default:
+		return fmt.Errorf("Bad include-stats value: %q is not one of the supported stats views.", includeStats)
+	}
+
+	var filters []bigtable.Filter
+	if sample := parsed["sample"]; sample != "" {
+		p, err := strconv.ParseFloat(sample, 64)
+		if err != nil || p <= 0 || p > 1 {
+			return fmt.Errorf("Bad sample %q: must be a fraction in (0, 1]", sample)
+		}
+		filters = append(filters, bigtable.RowSampleFilter(p))
+	}
+	cellFilters, err := parseCellFilters(parsed, time.Now())
+	if err != nil {
+		return err
+	}
+	filters = append(filters, cellFilters...)
+	if columns := parsed["columns"]; columns != "" {
+		columnFilters, err := parseColumnsFilter(columns)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, columnFilters)
+	}
+	// Limit the cells per column last, so that it counts the cells that
+	// match the other filters.
+	if cellsPerColumn := parsed["cells-per-column"]; cellsPerColumn != "" {
+		n, err := strconv.Atoi(cellsPerColumn)
+		if err != nil {
+			return fmt.Errorf("Bad number of cells per column %q: %v", cellsPerColumn, err)
+		}
+		filters = append(filters, bigtable.LatestNFilter(n))
+	}
+	if label := parsed["label"]; label != "" {
+		filters = append(filters, bigtable.LabelFilter(label))
+	}
+	var keysOnly bool
+	if keyStr := parsed["keys-only"]; keyStr != "" {
+		keysOnly, err = strconv.ParseBool(keyStr)
+		if err != nil {
+			return err
+		}
+	}
+
+	if keysOnly {
+		filters = append(filters, bigtable.StripValueFilter())
+	}
+
+	var filter bigtable.Filter
+	if len(filters) > 1 {
+		filter = bigtable.ChainFilters(filters...)
+	} else if len(filters) == 1 {
+		filter = filters[0]
+	}
+	if sinkStr := parsed["sink"]; sinkStr != "" {
+		sink, err := strconv.ParseBool(sinkStr)
+		if err != nil {
+			return fmt.Errorf("Bad sink %q: %v", sinkStr, err)
+		}
+		if sink {
+			filter = withSink(filter)
+		}
+	}
+	if filter != nil {
+		opts = append(opts, bigtable.RowFilter(filter))
+	}
+
+	formatFilePath := parsed["format-file"]
+	err = e.formatting.Setup(formatFilePath)
+	if err != nil {
+		return err
+	}
+	if err := e.formatting.SetColumnSelection(parsed["display"], parsed["hide"]); err != nil {
+		return err
+	}
+	if err := e.setupAggregateDecoding(ctx, args[0], parsed["decode-aggregates"]); err != nil {
+		return err
+	}
+	if err := e.formatting.SetCellOrder(parsed["sort-cells"]); err != nil {
+		return err
+	}
+
+	var follow bool
+	if f := parsed["follow"]; f != "" {
+		if follow, err = strconv.ParseBool(f); err != nil {
+			return fmt.Errorf("Bad follow %q: %v", f, err)
+		}
+	}
+	interval := defaultFollowInterval
+	if i := parsed["interval"]; i != "" {
+		if !follow {
+			return errors.New("interval can only be used with follow=true")
+		}
+		if interval, err = parseDuration(i); err != nil || interval <= 0 {
+			return fmt.Errorf("Bad interval %q: must be a duration like 5s", i)
+		}
+	}
+	if follow {
+		for _, arg := range []string{"count", "offset", "reversed", "estimate", "include-stats"} {
+			if parsed[arg] != "" {
+				return fmt.Errorf("%s can't be used with follow=true", arg)
+			}
+		}
+	}
+
+	var gcPolicies map[string]bigtable.GCPolicy
+	if v := parsed["gc-pending"]; v != "" {
+		on, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("Bad gc-pending %q: must be true or false", v)
+		}
+		if on {
+			if gcPolicies, err = e.familyGCPolicies(ctx, args[0]); err != nil {
+				return fmt.Errorf("Getting GC policies: %v", err)
+			}
+		}
+	}
+	pending := 0
+	// printErr is the error of the row that could not be printed, which
+	// stops the read.
+	var printErr error
+	printReadRow := func(r bigtable.Row) bool {
+		if gcPolicies != nil {
+			pending += markGCPending(r, gcPolicies, time.Now())
+		}
+		var buf bytes.Buffer
+		if printErr = e.printRow(r, &buf); printErr != nil {
+			return false
+		}
+		fmt.Fprintln(e.stdout, buf.String())
+		return true
+	}
+
+	appProfile, err := e.priorityAppProfile(ctx, parsed["app-profile"], parsed["priority"])
+	if err != nil {
+		return err
+	}
+	tbl, err := e.openTableAPI(appProfile, args[0], parsed["authorized-view"])
+	if err != nil {
+		return err
+	}
+
+	if estimate := parsed["estimate"]; estimate == "true" {
+		if offset > 0 {
+			return errors.New("offset can't be used with estimate=true")
+		}
+		start, end := rd.start, rd.end
+		samples, err := e.sampleRowKeys(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("Sampling row keys: %v", err)
+		}
+		est, err := estimateRead(ctx, tbl, start, end, rangeBytes(keyRanges(samples), start, end), filter)
+		if err != nil {
+			return fmt.Errorf("Reading rows: %v", err)
+		}
+		if count := parsed["count"]; count != "" {
+			n, _ := strconv.ParseInt(count, 0, 64)
+			est.limitRows(n)
+		}
+		printScanEstimate(e.stdout, args[0], est)
+		return nil
+	} else if estimate != "" && estimate != "false" {
+		return fmt.Errorf("Bad estimate %q: must be true or false", estimate)
+	}
+
+	rd.opts = opts
+	if offset > 0 {
+		var more bool
+		rd, more, err = skipRows(ctx, e.log, tbl, rd, offset, filter)
+		if err != nil {
+			return fmt.Errorf("Skipping rows: %v", err)
+		}
+		if !more {
+			return nil
+		}
+	}
+	n := 0
+	if follow {
+		err = followRead(ctx, e.log, tbl, rd, filter, interval, func(r bigtable.Row) bool {
+			if !printReadRow(r) {
+				return false
+			}
+			n++
+			return true
+		})
+		if printErr != nil {
+			return printErr
+		}
+		if interrupted(ctx) {
+			return interruptedAfter("reading %d rows", n)
+		}
+		if err != nil {
+			return fmt.Errorf("Reading rows: %v", err)
+		}
+		return nil
+	}
+	var last string
+	err = rd.run(ctx, e.log, tbl, func(r bigtable.Row) bool {
+		if !printReadRow(r) {
+			return false
+		}
+		n, last = n+1, r.Key()
+		return true
+	})
+	if printErr != nil {
+		return printErr
+	}
+	if err != nil {
+		if interrupted(ctx) {
+			return interruptedAfter("reading %d rows, up to %q", n, last)
+		}
+		return fmt.Errorf("Reading rows: %v", err)
+	}
+	if gcPolicies != nil {
+		e.log.Printf("%d cells past their GC policy, pending garbage collection", pending)
+	}
+	switch {
+	case includeStats == "":
+	case stats.requests == 0:
+		return errors.New("Stats were requested but not received.")
+	case includeStats == "json":
+		return printFullReadStatsJSON(e.stdout, &stats.total)
+	default:
+		printFullReadStats(e.stdout, &stats.total)
+		printScanEfficiency(e.stdout, stats)
+	}
+	return nil
+}
+
+var setArg = regexp.MustCompile(`([^:]+):([^=]*)=(.*)`)
+
+// splitCellTimestamp splits an optional "@<timestamp>" suffix from a cell
+// value. The timestamp may be microseconds since the epoch, "now", a
+// duration relative to now such as "now-1h", or an RFC 3339 time. If there is
+// no suffix, or it cannot be parsed, the whole string is the value and the
+// timestamp is now.
+func splitCellTimestamp(val string, now time.Time) (string, bigtable.Timestamp) {
+	i := strings.LastIndex(val, "@")
+	if i < 0 {
+		return val, bigtable.Time(now)
+	}
+	if ts, ok := parseCellTimestamp(val[i+1:], now); ok {
+		return val[:i], ts
+	}
+	return val, bigtable.Time(now)
+}
+
+func parseCellTimestamp(s string, now time.Time) (bigtable.Timestamp, bool) {
+	if n, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return bigtable.Timestamp(n), true
+	}
+	if strings.HasPrefix(s, "now") {
+		rel := s[len("now"):]
+		if rel == "" {
+			return bigtable.Time(now), true
+		}
+		d, err := parseDuration(rel[1:])
+		if err != nil {
+			return 0, false
+		}
+		switch rel[0] {
+		case '-':
+			return bigtable.Time(now.Add(-d)), true
+		case '+':
+			return bigtable.Time(now.Add(d)), true
+		}
+		return 0, false
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return bigtable.Time(t), true
+	}
+	return 0, false
+}
+
+// setTableOmitted reports whether the table argument of cbt set is left out:
+// without a table, the second argument is already an option or a
+// family:column=value.
+func setTableOmitted(args []string) bool {
+	return len(args) > 1 && (setArg.MatchString(args[1]) ||
+		isOptionArg(args[1], []string{"app-profile", "authorized-view", "include-stats"}))
+}
+
+func doSet(ctx context.Context, e *env, args ...string) error {
+	args = e.withDefaultTable(args, setTableOmitted(args))
+	if len(args) < 3 {
+		return errors.New("usage: cbt set <table> <row> [authorized-view=<authorized-view-id>] [app-profile=<app profile id>] family:[column]=val[@ts] ...")
+	}
+	var appProfile string
+	var authorizedView string
+	row := args[1]
+	mut := bigtable.NewMutation()
+	for _, arg := range args[2:] {
+		if strings.HasPrefix(arg, "app-profile=") {
+			appProfile = strings.Split(arg, "=")[1]
+			continue
+		}
+		if strings.HasPrefix(arg, "authorized-view=") {
+			authorizedView = strings.Split(arg, "=")[1]
+			continue
+		}
+		if strings.HasPrefix(arg, "include-stats=") {
+			var err error
+			if e.writeStats, err = parseWriteStatsArg(strings.Split(arg, "=")[1]); err != nil {
+				return err
+			}
+			continue
+		}
+		m := setArg.FindStringSubmatch(arg)
+		if m == nil {
+			return fmt.Errorf("Bad set arg %q", arg)
+		}
+		val, ts := splitCellTimestamp(m[3], time.Now())
+		mut.Set(m[1], m[2], ts, []byte(val))
+	}
+
+	tbl, err := e.openTableAPI(appProfile, args[0], authorizedView)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := tbl.Apply(ctx, row, mut); err != nil {
+		return fmt.Errorf("Applying mutation: %v", err)
+	}
+	if e.writeStats != nil {
+		e.writeStats.record(time.Since(start))
+		e.writeStats.print(e.stdout)
+	}
+	return nil
+}
+
+func doAddToCell(ctx context.Context, e *env, args ...string) error {
+	if len(args) < 3 {
+		return errors.New("usage: cbt addtocell <table> <row> [app-profile=<app profile id>] family:[column]=val[@ts] ...")
+	}
+	var appProfile string
+	row := args[1]
+	mut := bigtable.NewMutation()
+	for _, arg := range args[2:] {
+		if strings.HasPrefix(arg, "app-profile=") {
+			appProfile = strings.Split(arg, "=")[1]
+			continue
+		}
+		if strings.HasPrefix(arg, "include-stats=") {
+			var err error
+			if e.writeStats, err = parseWriteStatsArg(strings.Split(arg, "=")[1]); err != nil {
+				return err
+			}
+			continue
+		}
+		m := setArg.FindStringSubmatch(arg)
+		if m == nil {
+			return fmt.Errorf("Bad set arg %q", arg)
+		}
+		val, ts := splitCellTimestamp(m[3], time.Now())
+
+		if intVal, err := strconv.ParseInt(val, 0, 64); err == nil {
+			mut.AddIntToCell(m[1], m[2], ts, intVal)
+		} else {
+			return errors.New("Only int values are supported by addtocell.")
+		}
+
+	}
+	c, err := e.getClient(bigtable.ClientConfig{AppProfile: appProfile})
+	if err != nil {
+		return err
+	}
+	tbl := c.Open(args[0])
+	start := time.Now()
+	if err := tbl.Apply(ctx, row, mut); err != nil {
+		return fmt.Errorf("Applying mutation: %v", err)
+	}
+	if e.writeStats != nil {
+		e.writeStats.record(time.Since(start))
+		e.writeStats.print(e.stdout)
+	}
+	return nil
+}
+
+func doSetGCPolicy(ctx context.Context, e *env, args ...string) error {
+	if len(args) < 3 {
+		return errors.New("usage: cbt setgcpolicy <table> <family> ((maxage=<d> | maxversions=<n>) [(and|or) (maxage=<d> | maxversions=<n>),...] | never | policy=@<template>) [force]")
+	}
+	table := args[0]
+	fam := args[1]
+
+	// Remaining possible args are `force` and the gc policy itself, which may be
+	// arbitrarily long. Since `force` in the middle of the policy would be invalid
+	// we check only the next and last elements
+	remainingArgs := args[2:]
+	force := false
+	if remainingArgs[0] == "force" {
+		remainingArgs = remainingArgs[1:]
+		force = true
+	} else if remainingArgs[len(remainingArgs)-1] == "force" {
+		remainingArgs = remainingArgs[:len(remainingArgs)-1]
+		force = true
+	}
+
+	pol, err := e.config.parseGCPolicyArg(strings.Join(remainingArgs, " "))
+	if err != nil {
+		return err
+	}
+	opts := []bigtable.GCPolicyOption{}
+	if force {
+		opts = append(opts, bigtable.IgnoreWarnings())
+	}
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	if err := ac.SetGCPolicyWithOptions(ctx, table, fam, pol, opts...); err != nil {
+		return fmt.Errorf("Setting GC policy: %v", err)
+	}
+	return nil
+}
+
+func doGCPolicyPreview(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt gcpolicy-preview <table> <family> ((maxage=<d> | maxversions=<n>) [(and|or) (maxage=<d> | maxversions=<n>),...] | never) [sample=<0.01>]"
+	if len(args) < 3 {
+		return errors.New(usage)
+	}
+	table, fam := args[0], args[1]
+	sample := 0.01
+	var policyArgs []string
+	for _, arg := range args[2:] {
+		if strings.HasPrefix(arg, "sample=") {
+			var err error
+			sample, err = strconv.ParseFloat(strings.TrimPrefix(arg, "sample="), 64)
+			if err != nil || sample <= 0 || sample > 1 {
+				return fmt.Errorf("Bad sample %q: must be a fraction in (0, 1]", arg)
+			}
+			continue
+		}
+		policyArgs = append(policyArgs, arg)
+	}
+	pol, err := e.config.parseGCPolicyArg(strings.Join(policyArgs, " "))
+	if err != nil {
+		return err
+	}
+
+	filter := bigtable.ChainFilters(
+		bigtable.RowSampleFilter(sample),
+		bigtable.FamilyFilter("^"+regexp.QuoteMeta(fam)+"$"),
+	)
+	now := time.Now()
+	var rows, cells, bytes, eligibleCells, eligibleBytes int64
+	tbl, err := e.getTable(bigtable.ClientConfig{}, table)
+	if err != nil {
+		return err
+	}
+	err = tbl.ReadRows(ctx, bigtable.InfiniteRange(""), func(r bigtable.Row) bool {
+		rows++
+		items := r[fam]
+		// Policies apply to each column separately.
+		for start := 0; start < len(items); {
+			end := start + 1
+			for end < len(items) && items[end].Column == items[start].Column {
+				end++
+			}
+			column := items[start:end]
+			for i, eligible := range gcEligible(pol, column, now) {
+				size := int64(len(column[i].Value))
+				cells++
+				bytes += size
+				if eligible {
+					eligibleCells++
+					eligibleBytes += size
+				}
+			}
+			start = end
+		}
+		return true
+	}, bigtable.RowFilter(filter))
+	if err != nil {
+		return fmt.Errorf("Reading rows: %v", err)
+	}
+
+	percent := func(n, total int64) float64 {
+		if total == 0 {
+			return 0
+		}
+		return 100 * float64(n) / float64(total)
+	}
+	estimate := func(n int64) int64 { return int64(float64(n)/sample + 0.5) }
+	fmt.Fprintf(e.stdout, "Policy: %s\n", pol)
+	fmt.Fprintf(e.stdout, "Sampled %d rows (%g of the table) with %d cells (%s) in family %s.\n", rows, sample, cells, formatBytes(bytes), fam)
+	fmt.Fprintf(e.stdout, "Eligible for collection: %d cells (%.1f%%), %s (%.1f%%) of values.\n",
+		eligibleCells, percent(eligibleCells, cells), formatBytes(eligibleBytes), percent(eligibleBytes, bytes))
+	fmt.Fprintf(e.stdout, "Estimated for the whole table: %d cells, %s of values.\n", estimate(eligibleCells), formatBytes(estimate(eligibleBytes)))
+	return nil
+}
+
+func doWaitForReplicaiton(ctx context.Context, e *env, args ...string) error {
+	if len(args) != 1 {
+		return errors.New("usage: cbt waitforreplication <table>")
+	}
+	table := args[0]
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(e.stdout, "Waiting for all writes up to %s to be replicated.\n", time.Now().Format("2006/01/02-15:04:05"))
+	err = withHeartbeat(ctx, e, "waiting for replication of "+table, nil, func() error {
+		return ac.WaitForReplication(ctx, table)
+	})
+	if err != nil {
+		return fmt.Errorf("Waiting for replication: %v", err)
+	}
+	return nil
+}
+
+func doCopyBackup(ctx context.Context, e *env, args ...string) error {
+	if len(args) != 6 && len(args) != 7 {
+		return errors.New("usage: cbt copybackup <src-cluster> <src-backup> <dst-project> <dst-instance> <dst-cluster> <dst-backup> [expire=<d|timestamp>]")
+	}
+	parsed, err := parseArgs(args[6:], []string{"expire"})
+	if err != nil {
+		return err
+	}
+	srcCluster, srcBackup := args[0], args[1]
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	var expire time.Time
+	if val, ok := parsed["expire"]; ok {
+		expire, err = parseExpireTime(val, time.Now())
+		if err != nil {
+			return err
+		}
+	} else {
+		b, err := ac.BackupInfo(ctx, srcCluster, srcBackup)
+		if err != nil {
+			return fmt.Errorf("Getting source backup: %v", err)
+		}
+		expire = b.ExpireTime
+	}
+	err = withHeartbeat(ctx, e, "copying backup "+srcBackup, nil, func() error {
+		return ac.CopyBackup(ctx, srcCluster, srcBackup, args[2], args[3], args[4], args[5], expire)
+	})
+	if err != nil {
+		return fmt.Errorf("Copying backup: %v", err)
+	}
+	return nil
+}
+
+func doRestoreTable(ctx context.Context, e *env, args ...string) error {
+	if len(args) < 3 {
+		return errors.New("usage: cbt restoretable <table-id> <cluster> <backup> [dst-instance=<instance-id>] [dst-project=<project-id>]")
+	}
+	table, cluster, backup := args[0], args[1], args[2]
+	parsed, err := parseArgs(args[3:], []string{"dst-instance", "dst-project"})
+	if err != nil {
+		return err
+	}
+	dstInstance, dstProject := parsed["dst-instance"], parsed["dst-project"]
+	if dstProject != "" && dstInstance == "" {
+		return errors.New("dst-project requires dst-instance")
+	}
+	if dstProject == "" {
+		dstProject = e.config.Project
+	}
+	if dstInstance == "" {
+		dstInstance = e.config.Instance
+	}
+
+	if dstProject == e.config.Project {
+		ac, err := e.getAdminClientForInstance(ctx, dstInstance)
+		if err != nil {
+			return fmt.Errorf("Making bigtable.AdminClient: %v", err)
+		}
+		err = withHeartbeat(ctx, e, "restoring "+table, nil, func() error {
+			return ac.RestoreTableFrom(ctx, e.config.Instance, table, cluster, backup)
+		})
+		if err != nil {
+			return fmt.Errorf("Restoring table: %v", err)
+		}
+		return nil
+	}
+
+	// The bigtable package only restores backups from the client's own
+	// project, so build the request with a fully-qualified source.
+	rpc, err := e.getTableAdminRPC()
+	if err != nil {
+		return err
+	}
+	op, err := rpc.RestoreTable(ctx, &btapb.RestoreTableRequest{
+		Parent:  instanceName(dstProject, dstInstance),
+		TableId: table,
+		Source: &btapb.RestoreTableRequest_Backup{
+			Backup: backupName(e.config.Project, e.config.Instance, cluster, backup),
+		},
+	})
+	if err == nil {
+		err = withHeartbeat(ctx, e, "restoring "+table, restoreStatus(e, op), func() error {
+			return e.waitForOperation(ctx, op, &btapb.Table{})
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("Restoring table: %v", err)
+	}
+	return nil
+}
+
+func doGetBackup(ctx context.Context, e *env, args ...string) error {
+	if len(args) != 2 {
+		return errors.New("usage: cbt getbackup <cluster> <backup>")
+	}
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	b, err := ac.BackupInfo(ctx, args[0], args[1])
+	if err != nil {
+		return fmt.Errorf("Getting backup: %v", err)
+	}
+
+	tf := "2006-01-02 15:04:05 MST"
+	fmt.Fprintf(e.stdout, "Name: %s\n", b.Name)
+	fmt.Fprintf(e.stdout, "Source table: %s\n", b.SourceTable)
+	if b.SourceBackup != "" {
+		fmt.Fprintf(e.stdout, "Source backup: %s\n", b.SourceBackup)
+	}
+	fmt.Fprintf(e.stdout, "Size: %d bytes\n", b.SizeBytes)
+	fmt.Fprintf(e.stdout, "State: %s\n", b.State)
+	fmt.Fprintf(e.stdout, "Started at: %s\n", b.StartTime.Format(tf))
+	fmt.Fprintf(e.stdout, "Ended at: %s\n", b.EndTime.Format(tf))
+	fmt.Fprintf(e.stdout, "Expires at: %s\n", b.ExpireTime.Format(tf))
+	return nil
+}
+
+func doUpdateBackup(ctx context.Context, e *env, args ...string) error {
+	if len(args) != 3 {
+		return errors.New("usage: cbt updatebackup <cluster> <backup> expire=<d|timestamp>")
+	}
+	parsed, err := parseArgs(args[2:], []string{"expire"})
+	if err != nil {
+		return err
+	}
+	expire, err := parseExpireTime(parsed["expire"], time.Now())
+	if err != nil {
+		return err
+	}
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	if err := ac.UpdateBackup(ctx, args[0], args[1], expire); err != nil {
+		return fmt.Errorf("Updating backup: %v", err)
+	}
+	return nil
+}
+
+// parseExpireTime parses a backup expiration time given either as a
+// duration from now, in the units accepted by parseDuration, or as an
+// RFC 3339 timestamp.
+func parseExpireTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, errors.New("missing expiration time")
+	}
+	if d, err := parseDuration(s); err == nil {
+		return now.Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiration time %q: want a duration (e.g. 30d) or an RFC 3339 timestamp", s)
+	}
+	return t, nil
+}
+
+func parseStorageType(storageTypeStr string) (bigtable.StorageType, error) {
+	switch storageTypeStr {
+	case "SSD":
+		return bigtable.SSD, nil
+	case "HDD":
+		return bigtable.HDD, nil
+	}
+	return -1, fmt.Errorf("invalid storage type: %v, must be SSD or HDD", storageTypeStr)
+}
+
+// NOTE: Previous version of this feature was called "snapshots"
+// func doCreateTableFromSnapshot(ctx context.Context, args ...string) {
+// 	log.Println("Warning: This command is deprecated. Please use gcloud instead. Usage info: gcloud bigtable instances tables restore --help")
+// 	if len(args) != 3 {
+// 		log.Fatal("usage: cbt createtablefromsnapshot <table> <cluster> <backup>")
+// 	}
+// 	tableName := args[0]
+// 	clusterName := args[1]
+// 	backupName := args[2]
+
+// 	err := getAdminClient().RestoreTableFrom(ctx, config.Instance, tableName, clusterName, backupName)
+
+// 	if err != nil {
+// 		log.Fatalf("Creating table: %v", err)
+// 	}
+// }
+
+// NOTE: Previous version of this feature was called "snapshots"
+// func doSnapshotTable(ctx context.Context, args ...string) {
+// 	log.Println("Warning: This command is deprecated. Please use gcloud instead. Usage info: gcloud bigtable backups create --help")
+// 	if len(args) != 3 && len(args) != 4 {
+// 		log.Fatal("usage: cbt createsnapshot <cluster> <backup> <table> [ttl=<d>]")
+// 	}
+// 	clusterName := args[0]
+// 	snapshotName := args[1]
+// 	tableName := args[2]
+// 	ttl := bigtable.DefaultSnapshotDuration
+
+// 	parsed, err := parseArgs(args[3:], []string{"ttl"})
+// 	if err != nil {
+// 		log.Fatal(err)
+// 	}
+// 	if val, ok := parsed["ttl"]; ok {
+// 		var err error
+// 		ttl, err = parseDuration(val)
+// 		if err != nil {
+// 			log.Fatalf("Invalid snapshot ttl value %q: %v", val, err)
+// 		}
+// 	}
+
+// 	t := time.Now()
+// 	t.Add(ttl)
+
+// 	err = getAdminClient().CreateBackup(ctx, tableName, clusterName, snapshotName, t)
+// 	if err != nil {
+// 		log.Fatalf("Failed to create Snapshot: %v", err)
+// 	}
+// }
+
+// NOTE: Previous version of this feature was called "snapshots"
+// func doListSnapshots(ctx context.Context, args ...string) {
+// 	log.Println("Warning: This command is deprecated. Please use gcloud instead. Usage info: gcloud bigtable backups list --help")
+// 	if len(args) != 0 && len(args) != 1 {
+// 		log.Fatal("usage: cbt listsnapshots [<cluster>]")
+// 	}
+
+// 	var cl string
+
+// 	if len(args) == 0 {
+// 		cl = "-"
+// 	} else {
+// 		cl = args[0]
+// 	}
+
+// 	it := getAdminClient().Backups(ctx, cl)
+
+// 	tw := tabwriter.NewWriter(os.Stdout, 10, 8, 4, '\t', 0)
+// 	fmt.Fprintf(tw, "Backup\tSource Table\tCreated At\tExpires At\n")
+// 	fmt.Fprintf(tw, "------\t------------\t----------\t----------\n")
+// 	tf := "2006-01-02 15:04 MST"
+
+// 	for {
+// 		b, err := it.Next()
+// 		if err == iterator.Done {
+// 			break
+// 		}
+// 		if err != nil {
+// 			log.Fatalf("Failed to fetch snapshots %v", err)
+// 		}
+// 		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", b.Name, b.SourceTable, b.StartTime.Format(tf), b.ExpireTime.Format(tf))
+// 	}
+// 	tw.Flush()
+// }
+
+// NOTE: Previous version of this feature was called "snapshots"
+// func doGetSnapshot(ctx context.Context, args ...string) {
+// 	log.Println("Warning: This command is deprecated. Please use gcloud instead. Usage info: gcloud bigtable backups describe --help")
+// 	if len(args) != 2 {
+// 		log.Fatalf("usage: cbt getsnapshot <cluster> <backup>")
+// 	}
+// 	c := args[0]
+// 	bName := args[1]
+
+// 	b, err := getAdminClient().BackupInfo(ctx, c, bName)
+// 	if err != nil {
+// 		log.Fatalf("Failed to get backup: %v", err)
+// 	}
+
+// 	tf := "2006-01-02 15:04 MST"
+
+// 	fmt.Printf("Name: %s\n", b.Name)
+// 	fmt.Printf("Source table: %s\n", b.SourceTable)
+// 	fmt.Printf("Created at: %s\n", b.StartTime.Format(tf))
+// 	fmt.Printf("Expires at: %s\n", b.ExpireTime.Format(tf))
+// }
+
+// NOTE: Previous version of this feature was called "snapshots"
+// func doDeleteSnapshot(ctx context.Context, args ...string) {
+// 	log.Println("Warning: This command is deprecated. Please use gcloud instead. Usage info: gcloud bigtable backups delete --help")
+// 	if len(args) != 2 {
+// 		log.Fatal("usage: cbt deletesnapshot <cluster> <backup>")
+// 	}
+// 	cl := args[0]
+// 	b := args[1]
+
+// 	err := getAdminClient().DeleteBackup(ctx, cl, b)
+
+// 	if err != nil {
+// 		log.Fatalf("Failed to delete backup: %v", err)
+// 	}
+// }
+
+func doCreateAppProfile(ctx context.Context, e *env, args ...string) error {
+	if len(args) < 4 || len(args) > 6 {
+		return errors.New("usage: cbt createappprofile <instance-id> <profile-id> <description> " +
+			" (route-any | [ route-to=<cluster-id> : transactional-writes]) [optional flag] \n" +
+			"optional flags may be `force`")
+	}
+
+	routingPolicy, clusterID, err := parseProfileRoute(args[3])
+	if err != nil {
+		return errors.New("Exactly one of (route-any | [route-to : transactional-writes]) must be specified.")
+	}
+
+	conf := bigtable.ProfileConf{
+		RoutingPolicy: routingPolicy,
+		InstanceID:    args[0],
+		ProfileID:     args[1],
+		Description:   args[2],
+	}
+
+	opFlags := []string{"force", "transactional-writes"}
+	parseValues, err := parseArgs(args[4:], opFlags)
+	if err != nil {
+		return fmt.Errorf("optional flags can be specified as (force=<true>|transactional-writes=<true>) got %s ", args[4:])
+	}
+
+	for _, f := range opFlags {
+		fv, err := parseProfileOpts(f, parseValues)
+		if err != nil {
+			return fmt.Errorf("optional flags can be specified as (force=<true>|transactional-writes=<true>) got %s ", args[4:])
+		}
+
+		switch f {
+		case opFlags[0]:
+			conf.IgnoreWarnings = fv
+		case opFlags[1]:
+			conf.AllowTransactionalWrites = fv
+		default:
+
+		}
+	}
+
+	if routingPolicy == bigtable.SingleClusterRouting {
+		conf.ClusterID = clusterID
+	}
+
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return err
+	}
+	profile, err := iac.CreateAppProfile(ctx, conf)
+	if err != nil {
+		return fmt.Errorf("Failed to create app profile : %v", err)
+	}
+
+	fmt.Fprintf(e.stdout, "Name: %s\n", profile.Name)
+	fmt.Fprintf(e.stdout, "RoutingPolicy: %v\n", profile.RoutingPolicy)
+	return nil
+}
+
+func doExists(ctx context.Context, e *env, args ...string) error {
+	if len(args) < 2 {
+		return errors.New("usage: cbt exists <table> <row> [app-profile=<app profile id>]")
+	}
+	parsed, err := parseArgs(args[2:], []string{"app-profile"})
+	if err != nil {
+		return err
+	}
+	tbl, err := e.getTable(bigtable.ClientConfig{AppProfile: parsed["app-profile"]}, args[0])
+	if err != nil {
+		return err
+	}
+	found, err := rowExists(ctx, tbl, args[1])
+	if err != nil {
+		return fmt.Errorf("Reading row: %v", err)
+	}
+	if !found {
+		// Exit code 1 is already used for errors.
+		return &ExitError{Code: 3}
+	}
+	return nil
+}
+
+// rowExists reads at most one stripped cell of row to check whether it exists.
+func rowExists(ctx context.Context, tbl TableReader, row string) (bool, error) {
+	r, err := tbl.ReadRow(ctx, row, bigtable.RowFilter(bigtable.ChainFilters(
+		bigtable.CellsPerRowLimitFilter(1),
+		bigtable.StripValueFilter(),
+	)))
+	if err != nil {
+		return false, err
+	}
+	return len(r) > 0, nil
+}
+
+func doDeleteAppProfile(ctx context.Context, e *env, args ...string) error {
+	if len(args) != 2 {
+		return errors.New("usage: cbt deleteappprofile <instance-id> <profile-id>")
+	}
+
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return err
+	}
+	err = iac.DeleteAppProfile(ctx, args[0], args[1])
+	if err != nil {
+		return fmt.Errorf("Failed to delete  app profile : %v", err)
+	}
+	return nil
+}
+
+type importerArgs struct {
+	table      string
+	appProfile string
+	priority   string
+	fam        string
+	sz         int
+	workers    int
+	inFlight   int
+	timestamp  string
+	format     string
+	engine     string
+	gcsTemp    string
+	region     string
+	stats      *mutationStats
+	// createTable creates the table and the families of the header row
+	// with gcPolicy before importing.
+	createTable bool
+	gcPolicy    bigtable.GCPolicy
+	values      csvValueOptions
+}
+
+func doImport(ctx context.Context, e *env, args ...string) error {
+	ia, err := parseImporterArgs(ctx, e.config, args)
+	if err != nil {
+		return fmt.Errorf("error parsing importer args: %s", err)
+	}
+	if ia.appProfile, err = e.priorityAppProfile(ctx, ia.appProfile, ia.priority); err != nil {
+		return fmt.Errorf("error parsing importer args: %s", err)
+	}
+	if ia.engine == "dataflow" {
+		if err := runDataflowImport(ctx, e, args[0], args[1], ia); err != nil {
+			return fmt.Errorf("error running Dataflow import: %s", err)
+		}
+		return nil
+	}
+	f, err := os.Open(args[1])
+	if err != nil {
+		return fmt.Errorf("couldn't open the csv file: %s", err)
+	}
+	defer f.Close()
+
+	e.writeStats = ia.stats
+	c, err := e.getClient(bigtable.ClientConfig{AppProfile: ia.appProfile})
+	if err != nil {
+		return err
+	}
+	tbl := c.Open(args[0])
+	if ia.format == "hbase-sequencefile" {
+		n, err := importSequenceFile(ctx, e, tbl, f, ia)
+		if err != nil && interrupted(ctx) {
+			return interruptedAfter("importing %d rows", n)
+		}
+		if err != nil {
+			return fmt.Errorf("error importing SequenceFile: %s", err)
+		}
+		e.log.Printf("Done importing %d rows.\n", n)
+	} else {
+		r := csv.NewReader(f)
+		if err := importCSV(ctx, e, tbl, r, ia); err != nil {
+			return err
+		}
+	}
+	if e.writeStats != nil {
+		e.writeStats.print(e.stdout)
+	}
+	return nil
+}
+
+func parseImporterArgs(ctx context.Context, cfg *Config, args []string) (importerArgs, error) {
+	var err error
+	ia := importerArgs{
+		fam:       "",
+		sz:        500,
+		workers:   1,
+		timestamp: "now",
+	}
+	if len(args) < 2 {
+		return ia, fmt.Errorf("usage: cbt import <table-id> <input-file> [app-profile=<app-profile-id>] [column-family=<family-name>] [batch-size=<500>] [workers=<1>] [timestamp=<now|value-encoded>]")
+	}
+	ia.table = args[0]
+	for _, arg := range args[2:] {
+		switch {
+		case strings.HasPrefix(arg, "app-profile="):
+			ia.appProfile = strings.Split(arg, "=")[1]
+		case strings.HasPrefix(arg, "priority="):
+			ia.priority = strings.Split(arg, "=")[1]
+		case strings.HasPrefix(arg, "column-family="):
+			ia.fam = strings.Split(arg, "=")[1]
+			if ia.fam == "" {
+				return ia, fmt.Errorf("column-family cannot be ''")
+			}
+		case strings.HasPrefix(arg, "batch-size="):
+			ia.sz, err = strconv.Atoi(strings.Split(arg, "=")[1])
+			if err != nil || ia.sz <= 0 || ia.sz >= 100000 {
+				return ia, fmt.Errorf("batch-size must be > 0 and <= 100000")
+			}
+		case strings.HasPrefix(arg, "workers="):
+			ia.workers, err = strconv.Atoi(strings.Split(arg, "=")[1])
+			if err != nil || ia.workers <= 0 {
+				return ia, fmt.Errorf("workers must be > 0, err:%s", err)
+			}
+		case strings.HasPrefix(arg, "in-flight-batches="):
+			ia.inFlight, err = strconv.Atoi(strings.Split(arg, "=")[1])
+			if err != nil || ia.inFlight <= 0 {
+				return ia, fmt.Errorf("in-flight-batches must be > 0")
+			}
+		case strings.HasPrefix(arg, "timestamp="):
+			ia.timestamp = strings.Split(arg, "=")[1]
+			if ia.timestamp != "now" && ia.timestamp != "value-encoded" {
+				return ia, fmt.Errorf("timestamp must be one of 'now' or 'value-encoded'")
+			}
+		case strings.HasPrefix(arg, "include-stats="):
+			ia.stats, err = parseWriteStatsArg(strings.Split(arg, "=")[1])
+			if err != nil {
+				return ia, err
+			}
+		case strings.HasPrefix(arg, "format="):
+			ia.format = strings.Split(arg, "=")[1]
+			if ia.format != "csv" && ia.format != "hbase-sequencefile" {
+				return ia, fmt.Errorf("format must be one of 'csv' or 'hbase-sequencefile'")
+			}
+		case strings.HasPrefix(arg, "engine="):
+			ia.engine = strings.Split(arg, "=")[1]
+			if ia.engine != "client" && ia.engine != "dataflow" {
+				return ia, fmt.Errorf("engine must be one of 'client' or 'dataflow'")
+			}
+		case strings.HasPrefix(arg, "gcs-temp="):
+			ia.gcsTemp = strings.Split(arg, "=")[1]
+		case strings.HasPrefix(arg, "region="):
+			ia.region = strings.Split(arg, "=")[1]
+		case strings.HasPrefix(arg, "create-table="):
+			ia.createTable, err = strconv.ParseBool(strings.Split(arg, "=")[1])
+			if err != nil {
+				return ia, fmt.Errorf("create-table must be true or false")
+			}
+		case strings.HasPrefix(arg, "trim-whitespace="):
+			ia.values.trimSpace, err = strconv.ParseBool(strings.Split(arg, "=")[1])
+			if err != nil {
+				return ia, fmt.Errorf("trim-whitespace must be true or false")
+			}
+		case strings.HasPrefix(arg, "null-string="):
+			ia.values.nullString = strings.SplitN(arg, "=", 2)[1]
+		case strings.HasPrefix(arg, "empty-as-delete="):
+			ia.values.emptyAsDelete, err = strconv.ParseBool(strings.Split(arg, "=")[1])
+			if err != nil {
+				return ia, fmt.Errorf("empty-as-delete must be true or false")
+			}
+		case strings.HasPrefix(arg, "gc-policy="):
+			ia.gcPolicy, err = cfg.parseGCPolicyArg(strings.SplitN(arg, "=", 2)[1])
+			if err != nil {
+				return ia, err
+			}
+		}
+	}
+	if ia.gcPolicy != nil && !ia.createTable {
+		return ia, fmt.Errorf("gc-policy is only used with create-table=true")
+	}
+	if ia.createTable && (ia.format == "hbase-sequencefile" || ia.engine == "dataflow") {
+		return ia, fmt.Errorf("create-table=true is only supported for CSV files imported by cbt")
+	}
+	if ia.values != (csvValueOptions{}) && (ia.format == "hbase-sequencefile" || ia.engine == "dataflow") {
+		return ia, fmt.Errorf("trim-whitespace, null-string and empty-as-delete are only supported for CSV files imported by cbt")
+	}
+	return ia, nil
+}
+
+func importCSV(ctx context.Context, e *env, tbl *bigtable.Table, r *csv.Reader, ia importerArgs) error {
+	fams, cols, err := parseCsvHeaders(r, ia.fam)
+	if err != nil {
+		return fmt.Errorf("error parsing headers: %s", err)
+	}
+	if ia.createTable {
+		ac, err := e.getAdminClient()
+		if err != nil {
+			return err
+		}
+		if err := createImportTable(ctx, e.log, ac, ia.table, fams[1:], ia.gcPolicy); err != nil {
+			return fmt.Errorf("error creating table: %s", err)
+		}
+	}
+	sr := safeReader{r: r, inFlight: ia.inFlight, logThroughput: ia.workers > 1, values: ia.values}
+	if err := sr.parseAndWrite(ctx, e, tbl, ia.timestamp, fams, cols, bigtable.Now(), ia.sz, ia.workers); err != nil {
+		if interrupted(ctx) {
+			return interruptedAfter("importing %d rows", sr.t)
+		}
+		return fmt.Errorf("error: %s", err)
+	}
+	e.log.Printf("Done importing %d rows.\n", sr.t)
+	return nil
+}
+
+func parseCsvHeaders(r *csv.Reader, family string) ([]string, []string, error) {
+	var err error
+	var fams, cols []string
+	if family == "" { // no column-family from flag, using first row
+		fams, err = r.Read()
+		if err != nil {
+			return nil, nil, fmt.Errorf("family header reader error:%s", err)
+		}
+	}
+	cols, err = r.Read() // column names are next row
+	if err != nil {
+		return nil, nil, fmt.Errorf("columns header reader error:%s", err)
+	}
+	if family != "" {
+		fams = make([]string, len(cols))
+		fams[1] = family
+	}
+	if len(fams) < 2 || len(cols) < 2 {
+		return fams, cols, fmt.Errorf("at least 2 columns are required (rowkey + data)")
+	}
+	if fams[0] != "" || cols[0] != "" {
+		return fams, cols, fmt.Errorf("the first column must be empty for column-family and column name rows")
+	}
+	if fams[1] == "" || cols[1] == "" {
+		return fams, cols, fmt.Errorf("the second column (first data column) must have values for column family and column name rows if present")
+	}
+	for i := range cols { // fill any blank column families with previous
+		if i > 0 && fams[i] == "" {
+			fams[i] = fams[i-1]
+		}
+	}
+	return fams, cols, nil
+}
+
+// createImportTable creates table with the column families fams, or the
+// families of fams that it lacks if it exists, with the GC policy policy.
+func createImportTable(ctx context.Context, logger *log.Logger, ac AdminAPI, table string, fams []string, policy bigtable.GCPolicy) error {
+	if policy == nil {
+		policy = bigtable.NoGcPolicy()
+	}
+	existing := map[string]bool{}
+	ti, err := ac.TableInfo(ctx, table)
+	switch {
+	case status.Code(err) == codes.NotFound:
+		ti = nil
+	case err != nil:
+		return err
+	default:
+		for _, fi := range ti.FamilyInfos {
+			existing[fi.Name] = true
+		}
+	}
+	var missing []string
+	for _, fam := range fams {
+		if !existing[fam] {
+			existing[fam] = true
+			missing = append(missing, fam)
+		}
+	}
+	if ti == nil {
+		conf := bigtable.TableConf{TableID: table, ColumnFamilies: map[string]bigtable.Family{}}
+		for _, fam := range missing {
+			conf.ColumnFamilies[fam] = bigtable.Family{GCPolicy: policy}
+		}
+		logger.Printf("Creating table %s with families %s", table, strings.Join(missing, ", "))
+		return ac.CreateTableFromConf(ctx, &conf)
+	}
+	for _, fam := range missing {
+		logger.Printf("Creating family %s", fam)
+		if err := ac.CreateColumnFamilyWithConfig(ctx, table, fam, bigtable.Family{GCPolicy: policy}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *env) batchWrite(ctx context.Context, tbl *bigtable.Table, rk []string, muts []*bigtable.Mutation, worker int) (int, error) {
+	failed, err := e.applyBulkWithRetry(ctx, tbl, rk, muts, worker)
+	if err != nil {
+		return 0, fmt.Errorf("applying bulk mutations process error: %v", err)
+	}
+	if len(failed) > 0 {
+		return 0, fmt.Errorf("applying bulk mutations had %d errors, first:%v", len(failed), failed[0].err)
+	}
+	return len(rk), nil
+}
+
+// parseDuration parses a duration string; see cli.ParseDuration.
+func parseDuration(s string) (time.Duration, error) {
+	return cli.ParseDuration(s)
+}
+
+// clientLibraryPrefixes are the module path prefixes of the client libraries
+// reported by 'cbt version format=json'.
+var clientLibraryPrefixes = []string{
+	"cloud.google.com/go",
+	"google.golang.org/api",
+	"google.golang.org/grpc",
+}
+
+// versionInfo is the build metadata printed by 'cbt version format=json'.
+type versionInfo struct {
+	Version         string            `json:"version"`
+	Revision        string            `json:"revision"`
+	RevisionDate    string            `json:"revision_date"`
+	GoVersion       string            `json:"go_version"`
+	Platform        string            `json:"platform"`
+	ClientLibraries map[string]string `json:"client_libraries"`
+}
+
+func buildVersionInfo() versionInfo {
+	libs := map[string]string{}
+	for path, v := range moduleVersions() {
+		for _, prefix := range clientLibraryPrefixes {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				libs[path] = v
+				break
+			}
+		}
+	}
+	return versionInfo{
+		Version:         version,
+		Revision:        revision,
+		RevisionDate:    revisionDate,
+		GoVersion:       runtime.Version(),
+		Platform:        runtime.GOOS + "/" + runtime.GOARCH,
+		ClientLibraries: libs,
+	}
+}
+
+func doVersion(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt version [format=<text|json>]"
+	for i, arg := range args {
+		// Accept the flag-like spelling -format=json as well.
+		args[i] = strings.TrimLeft(arg, "-")
+	}
+	parsed, err := parseArgs(args, []string{"format"})
+	if err != nil {
+		return errors.New(usage)
+	}
+	switch parsed["format"] {
+	case "", "text":
+		fmt.Fprintf(e.stdout, "%s %s %s\n", version, revision, revisionDate)
+	case "json":
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(buildVersionInfo()); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("Bad format %q: must be text or json", parsed["format"])
+	}
+	return nil
+}
+
+// parseArgs takes a slice of arguments of the form key=value and returns a map from
+// key to value; see cli.ParseArgs.
+func parseArgs(args []string, valid []string) (map[string]string, error) {
+	return cli.ParseArgs(args, valid)
+}
+
+// withDefaultTable prepends the configured default table to a command's args
+// if omitted reports that the table argument was left out.
+func (e *env) withDefaultTable(args []string, omitted bool) []string {
+	if omitted && e.config != nil && e.config.Table != "" {
+		return append([]string{e.config.Table}, args...)
+	}
+	return args
+}
+
+// isOptionArg reports whether arg is a key=value argument with one of the
+// given keys.
+func isOptionArg(arg string, valid []string) bool {
+	i := strings.Index(arg, "=")
+	return i > 0 && stringInSlice(arg[:i], valid)
+}
+
+func stringInSlice(s string, list []string) bool {
+	for _, e := range list {
+		if s == e {
+			return true
+		}
+	}
+	return false
+}
+
+// parseColumnsFilter builds the filter for a columns= argument; see
+// cli.ParseColumnsFilter.
+func parseColumnsFilter(columns string) (bigtable.Filter, error) {
+	return cli.ParseColumnsFilter(columns)
+}
+
+func parseProfileRoute(str string) (routingPolicy, clusterID string, err error) {
+
+	route := strings.Split(str, "=")
+	switch route[0] {
+	case "route-any":
+		if len(route) > 1 {
+			err = fmt.Errorf("got %v", route)
+			break
+		}
+		routingPolicy = bigtable.MultiClusterRouting
+
+	case "route-to":
+		if len(route) != 2 || route[1] == "" {
+			err = fmt.Errorf("got %v", route)
+			break
+		}
+		routingPolicy = bigtable.SingleClusterRouting
+		clusterID = route[1]
+	default:
+		err = fmt.Errorf("got %v", route)
+	}
+
+	return
+}
+
+func parseProfileOpts(opt string, parsedArgs map[string]string) (bool, error) {
+
+	if val, ok := parsedArgs[opt]; ok {
+		status, err := strconv.ParseBool(val)
+		if err != nil {
+			return false, fmt.Errorf("expected %s = <true> got %s ", opt, val)
+		}
+
+		return status, nil
+	}
+	return false, nil
+}
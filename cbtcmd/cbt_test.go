@@ -0,0 +1,1352 @@
+// Copyright 2016 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"cloud.google.com/go/bigtable/bttest"
+	"cloud.google.com/go/cbt/cli"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	alwaysEqual = cmp.Comparer(func(_, _ interface{}) bool { return true })
+
+	defaultCmpOptions = []cmp.Option{
+		// Use proto.Equal for protobufs
+		cmp.Comparer(proto.Equal),
+		// Use big.Rat.Cmp for big.Rats
+		cmp.Comparer(func(x, y *big.Rat) bool {
+			if x == nil || y == nil {
+				return x == y
+			}
+			return x.Cmp(y) == 0
+		}),
+		// NaNs compare equal
+		cmp.FilterValues(func(x, y float64) bool {
+			return math.IsNaN(x) && math.IsNaN(y)
+		}, alwaysEqual),
+		cmp.FilterValues(func(x, y float32) bool {
+			return math.IsNaN(float64(x)) && math.IsNaN(float64(y))
+		}, alwaysEqual),
+	}
+)
+
+// Equal tests two values for equality.
+func Equal(x, y interface{}, opts ...cmp.Option) bool {
+	// Put default options at the end. Order doesn't matter.
+	opts = append(opts[:len(opts):len(opts)], defaultCmpOptions...)
+	return cmp.Equal(x, y, opts...)
+}
+
+// Check if we get a substring of the expected error.
+// Returns "" if so, else returns the expected substring and error.
+func matchesExpectedError(want string, err error) string {
+	if err != nil {
+		got := err.Error()
+		if want == "" || !strings.Contains(got, want) {
+			return fmt.Sprintf("expected error substr:%s, got:%s", want, got)
+		}
+	} else if want != "" {
+		return fmt.Sprintf("expected error substr:%s", want)
+	}
+	return ""
+}
+
+func TestCsvImporterArgs(t *testing.T) {
+	tests := []struct {
+		in  []string
+		out importerArgs
+		err string
+	}{
+		{in: []string{"my-table", "my-file.csv"}, out: importerArgs{fam: "", sz: 500, workers: 1, timestamp: "now"}},
+		{in: []string{"my-table", "my-file.csv", "app-profile="}, out: importerArgs{fam: "", sz: 500, workers: 1, timestamp: "now"}},
+		{in: []string{"my-table", "my-file.csv", "app-profile=my-ap", "column-family=my-family", "batch-size=100", "workers=20"},
+			out: importerArgs{appProfile: "my-ap", fam: "my-family", sz: 100, workers: 20, timestamp: "now"}},
+		{in: []string{"my-table", "my-file.csv", "workers=4", "in-flight-batches=16"},
+			out: importerArgs{sz: 500, workers: 4, inFlight: 16, timestamp: "now"}},
+
+		{in: []string{}, err: "usage: cbt import <table-id> <input-file> [app-profile=<app-profile-id>] [column-family=<family-name>] [batch-size=<500>] [workers=<1>] [timestamp=<now|value-encoded>]"},
+		{in: []string{"my-table", "my-file.csv", "column-family="}, err: "column-family cannot be ''"},
+		{in: []string{"my-table", "my-file.csv", "batch-size=-5"}, err: "batch-size must be > 0 and <= 100000"},
+		{in: []string{"my-table", "my-file.csv", "batch-size=5000000"}, err: "batch-size must be > 0 and <= 100000"},
+		{in: []string{"my-table", "my-file.csv", "batch-size=nan"}, err: "batch-size must be > 0 and <= 100000"},
+		{in: []string{"my-table", "my-file.csv", "batch-size="}, err: "batch-size must be > 0 and <= 100000"},
+		{in: []string{"my-table", "my-file.csv", "workers=0"}, err: "workers must be > 0, err:%!s(<nil>)"},
+		{in: []string{"my-table", "my-file.csv", "workers=nan"}, err: "workers must be > 0, err:strconv.Atoi: parsing \"nan\": invalid syntax"},
+		{in: []string{"my-table", "my-file.csv", "workers="}, err: "workers must be > 0, err:strconv.Atoi: parsing \"\": invalid syntax"},
+		{in: []string{"my-table", "my-file.csv", "in-flight-batches=0"}, err: "in-flight-batches must be > 0"},
+		{in: []string{"my-table", "my-file.csv", "create-table=maybe"}, err: "create-table must be true or false"},
+		{in: []string{"my-table", "my-file.csv", "empty-as-delete=maybe"}, err: "empty-as-delete must be true or false"},
+		{in: []string{"my-table", "my-file", "null-string=NULL", "format=hbase-sequencefile"}, err: "only supported for CSV files"},
+		{in: []string{"my-table", "my-file.csv", "gc-policy=maxversions=1"}, err: "gc-policy is only used with create-table=true"},
+		{in: []string{"my-table", "my-file", "create-table=true", "format=hbase-sequencefile"}, err: "only supported for CSV files"},
+	}
+	for _, tc := range tests {
+		got, err := parseImporterArgs(context.Background(), &Config{}, tc.in)
+		if e := matchesExpectedError(tc.err, err); e != "" {
+			t.Errorf("%s", e)
+			continue
+		}
+		if tc.err != "" {
+			continue // received expected error, do not parse below
+		}
+		if got.appProfile != tc.out.appProfile ||
+			got.fam != tc.out.fam ||
+			got.sz != tc.out.sz ||
+			got.workers != tc.out.workers ||
+			got.inFlight != tc.out.inFlight {
+			t.Errorf("parseImportArgs(%q) did not fail, out: %+v", tc.in, got)
+		}
+	}
+}
+
+func transformToCsvBuffer(data [][]string) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("Data cannot be empty")
+	}
+	var buf bytes.Buffer
+	csvWriter := csv.NewWriter(&buf)
+	if err := csvWriter.WriteAll(data); err != nil {
+		return nil, err
+	}
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func TestCsvHeaderParser(t *testing.T) {
+	tests := []struct {
+		label    string
+		iData    [][]string
+		iFam     string
+		oFams    []string
+		oCols    []string
+		nextLine []string
+		err      string
+	}{
+		{label: "extend-family-gap",
+			iData:    [][]string{{"", "my-family", "", "my-family-2"}, {"", "col-1", "col-2", "col-3"}, {"rk-1", "A", "", ""}},
+			iFam:     "",
+			oFams:    []string{"", "my-family", "my-family", "my-family-2"},
+			oCols:    []string{"", "col-1", "col-2", "col-3"},
+			nextLine: []string{"rk-1", "A", "", ""}},
+		{label: "handle-family-arg",
+			iData:    [][]string{{"", "col-1", "col-2"}, {"rk-1", "A", ""}},
+			iFam:     "arg-family",
+			oFams:    []string{"", "arg-family", "arg-family"},
+			oCols:    []string{"", "col-1", "col-2"},
+			nextLine: []string{"rk-1", "A", ""}},
+
+		{label: "eof-header-family",
+			iData: [][]string{{""}},
+			iFam:  "",
+			err:   "family header reader error:EOF"},
+		{label: "eof-header-column",
+			iData: [][]string{{""}, {""}},
+			iFam:  "arg-family",
+			err:   "columns header reader error:EOF"},
+		{label: "rowkey-in-header-row",
+			iData: [][]string{{"ABC", "my-family", ""}},
+			iFam:  "arg-family",
+			err:   "the first column must be empty for column-family and column name rows"},
+		{label: "blank-first-headers",
+			iData: [][]string{{"", "", ""}},
+			iFam:  "arg-family",
+			err:   "the second column (first data column) must have values for column family and column name rows if present"},
+	}
+
+	for _, tc := range tests {
+		// create in memory csv like file
+		byteData, err := transformToCsvBuffer(tc.iData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader := csv.NewReader(bytes.NewReader(byteData))
+
+		fams, cols, err := parseCsvHeaders(reader, tc.iFam)
+		if e := matchesExpectedError(tc.err, err); e != "" {
+			t.Errorf("%s %s", tc.label, e)
+			continue
+		}
+		if tc.err != "" {
+			continue // received expected error, do not parse below
+		}
+
+		line, _ := reader.Read()
+		if err != nil {
+			t.Errorf("Next line for reader error, got: %q, expect: %q, error:%s", line, tc.nextLine, err)
+			continue
+		}
+		if len(fams) != len(tc.oFams) ||
+			len(cols) != len(tc.oCols) ||
+			len(line) != len(tc.nextLine) {
+			t.Errorf("parseCsvHeaders() did not fail, incorrect output sizes found, fams: %d, cols:%d, line:%d", len(fams), len(cols), len(line))
+			continue
+		}
+		for i, f := range fams {
+			if f != tc.oFams[i] {
+				t.Errorf("Incorrect column families idx:%d, got: %q, want %q", i, fams[i], tc.oFams[i])
+				continue
+			}
+		}
+		for i, c := range cols {
+			if c != tc.oCols[i] {
+				t.Errorf("parseCsvHeaders() did not fail for column names idx:%d, got: %q, want %q", i, cols[i], tc.oCols[i])
+				continue
+			}
+		}
+		for i, v := range line {
+			if v != tc.nextLine[i] {
+				t.Errorf("parseCsvHeaders() did not fail for next line idx:%d, got: %q, want %q", i, cols[i], tc.oCols[i])
+				continue
+			}
+		}
+	}
+}
+
+func setupEmulator(t *testing.T, tables, families []string) (context.Context, *bigtable.Client) {
+	srv, err := bttest.NewServer("localhost:0")
+	if err != nil {
+		t.Fatalf("Error starting bttest server: %s", err)
+	}
+
+	ctx := context.Background()
+
+	conn, err := grpc.Dial(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+
+	proj, instance := "proj", "instance"
+	adminClient, err := bigtable.NewAdminClient(ctx, proj, instance, option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+
+	for _, ta := range tables {
+		if err = adminClient.CreateTable(ctx, ta); err != nil {
+			t.Fatalf("Error %s", err)
+		}
+		for _, f := range families {
+			if err = adminClient.CreateColumnFamily(ctx, ta, f); err != nil {
+				t.Fatalf("Error %s", err)
+			}
+		}
+	}
+
+	client, err := bigtable.NewClient(ctx, proj, instance, option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("Error %s", err)
+	}
+
+	return ctx, client
+}
+
+// newEmulatorClients starts an empty emulator and returns admin and data
+// clients for it.
+func newEmulatorClients(t *testing.T) (context.Context, *bigtable.AdminClient, *bigtable.Client) {
+	srv, err := bttest.NewServer("localhost:0")
+	if err != nil {
+		t.Fatalf("Error starting bttest server: %s", err)
+	}
+	t.Cleanup(srv.Close)
+	conn, err := grpc.Dial(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	ac, err := bigtable.NewAdminClient(ctx, "proj", "instance", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := bigtable.NewClient(ctx, "proj", "instance", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx, ac, c
+}
+
+func validateData(ctx context.Context, tbl *bigtable.Table, tstype string, fams, cols []string, rowData [][]string) error {
+	// vaildate table entries, valMap["rowkey:family:column"] = mutation value
+	valMap := make(map[string]string)
+	for _, row := range rowData {
+		for i, val := range row {
+			if i > 0 && val != "" {
+				valMap[row[0]+":"+fams[i]+":"+cols[i]] = val
+			}
+		}
+	}
+	for _, data := range rowData {
+		row, err := tbl.ReadRow(ctx, data[0])
+		if err != nil {
+			return err
+		}
+		for _, cf := range row {
+			for _, column := range cf {
+				k := data[0] + ":" + string(column.Column)
+				v, ok := valMap[k]
+				if tstype == "value-encoded" {
+					if i := strings.LastIndex(v, "@"); i >= 0 {
+						if _, err := strconv.ParseInt(v[i+1:], 0, 64); err == nil {
+							v = v[:i]
+						}
+					}
+				}
+				if ok && v == string(column.Value) {
+					delete(valMap, k)
+				}
+			}
+		}
+	}
+	if len(valMap) != 0 {
+		return fmt.Errorf("Data didn't match after read, not found %v", valMap)
+	}
+	return nil
+}
+
+func TestCsvParseAndWrite(t *testing.T) {
+	ctx, client := setupEmulator(t, []string{"my-table"}, []string{"my-family", "my-family-2"})
+
+	tbl := client.Open("my-table")
+	fams := []string{"", "my-family", "my-family-2"}
+	cols := []string{"", "col-1", "col-2"}
+	rowData := [][]string{
+		{"rk-0", "A", "B"},
+		{"rk-1", "", "C"},
+	}
+
+	byteData, err := transformToCsvBuffer(rowData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := csv.NewReader(bytes.NewReader(byteData))
+
+	sr := safeReader{r: reader}
+	e, _ := newTestEnv(Deps{Client: client})
+	if err = sr.parseAndWrite(ctx, e, tbl, "now", fams, cols, 1, 1, 1); err != nil {
+		t.Fatalf("parseAndWrite() failed unexpectedly, error:%s", err)
+	}
+	if err := validateData(ctx, tbl, "now", fams, cols, rowData); err != nil {
+		t.Fatalf("Read back validation error:%s", err)
+	}
+}
+
+func TestPrintRowWithHighTimestamp(t *testing.T) {
+	ctx, client := setupEmulator(t, []string{"my-table"}, []string{"my-family"})
+	tbl := client.Open("my-table")
+	mut := bigtable.NewMutation()
+
+	loc, err := time.LoadLocation("US/Pacific")
+	if (err != nil) {
+		t.Fatalf("Failed to load timezone: %v", err)
+	}
+
+	// a timestamp that is just over int64 max in nanoseconds
+	mut.Set("my-family", "foo", 9223372036855000, []byte("bar"))
+	err = tbl.Apply(ctx, "my-key", mut)
+	if (err != nil) {
+		t.Fatalf("Could not write some rows to prepare the test.")
+	}
+	row, err := tbl.ReadRow(ctx, "my-key")
+	var sb strings.Builder
+	e, _ := newTestEnv(Deps{Client: client})
+	e.printRowAtTimezone(row, &sb, loc)
+
+	expected := "@ 2262/04/11-16:47:16.855000"
+	if !strings.Contains(sb.String(), expected) {
+		t.Fatalf("Could not find correct timestamp string in printRow result.\nExpected a string containing: %s\nGot: %s", expected, sb.String())
+	}
+}
+
+func TestCsvParseAndWriteBadFamily(t *testing.T) {
+	ctx, client := setupEmulator(t, []string{"my-table"}, []string{"my-family"})
+
+	tbl := client.Open("my-table")
+	fams := []string{"", "my-family", "not-my-family"}
+	cols := []string{"", "col-1", "col-2"}
+	rowData := [][]string{
+		{"rk-0", "A", "B"},
+		{"rk-1", "", "C"},
+	}
+
+	byteData, err := transformToCsvBuffer(rowData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := csv.NewReader(bytes.NewReader(byteData))
+
+	sr := safeReader{r: reader}
+	e, _ := newTestEnv(Deps{Client: client})
+	if err = sr.parseAndWrite(ctx, e, tbl, "now", fams, cols, 1, 1, 1); err == nil {
+		t.Fatalf("parseAndWrite() should have failed with non-existant column family")
+	}
+}
+
+func TestCsvParseAndWriteDuplicateRowkeys(t *testing.T) {
+	ctx, client := setupEmulator(t, []string{"my-table"}, []string{"my-family"})
+
+	tbl := client.Open("my-table")
+	fams := []string{"", "my-family", "my-family"}
+	cols := []string{"", "col-1", "col-2"}
+	rowData := [][]string{
+		{"rk-0", "A", ""},
+		{"rk-0", "", "B"},
+		{"rk-0", "C", ""},
+	}
+
+	byteData, err := transformToCsvBuffer(rowData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := csv.NewReader(bytes.NewReader(byteData))
+
+	sr := safeReader{r: reader}
+	e, _ := newTestEnv(Deps{Client: client})
+	if err = sr.parseAndWrite(ctx, e, tbl, "now", fams, cols, 1, 1, 1); err != nil {
+		t.Fatalf("parseAndWrite() should not have failed for duplicate rowkeys: %s", err)
+	}
+
+	// the "A" not present result is expected, the emulator only keeps 1 version
+	valMap := map[string]bool{"rk-0:my-family:col-2:B": true, "rk-0:my-family:col-1:C": true}
+	row, err := tbl.ReadRow(ctx, "rk-0")
+	if err != nil {
+		t.Errorf("error %s", err)
+	}
+	for _, cf := range row { // each column family in row
+		for _, column := range cf { // each cf:column, aka each mutation
+			k := "rk-0:" + string(column.Column) + ":" + string(column.Value)
+			_, ok := valMap[k]
+			if ok {
+				delete(valMap, k)
+				continue
+			}
+			t.Errorf("row data not found for %s\n", k)
+		}
+	}
+
+	if len(valMap) != 0 {
+		t.Fatalf("values were not present in table: %v", valMap)
+	}
+}
+
+func TestCsvToCbt(t *testing.T) {
+	tests := []struct {
+		label        string
+		ia           importerArgs
+		csvData      [][]string
+		expectedFams []string
+		dataStartIdx int
+	}{
+		{
+			label: "has-column-families",
+			ia:    importerArgs{fam: "", sz: 1, workers: 1, timestamp: "now"},
+			csvData: [][]string{
+				{"", "my-family", ""},
+				{"", "col-1", "col-2"},
+				{"rk-0", "A", ""},
+				{"rk-1", "", "B"},
+				{"rk-2", "", ""},
+				{"rk-3", "C", ""},
+			},
+			expectedFams: []string{"", "my-family", "my-family"},
+			dataStartIdx: 2,
+		},
+		{
+			label: "no-column-families",
+			ia:    importerArgs{fam: "arg-family", sz: 1, workers: 1, timestamp: "now"},
+			csvData: [][]string{
+				{"", "col-1", "col-2"},
+				{"rk-0", "A", ""},
+				{"rk-1", "", "B"},
+				{"rk-2", "", ""},
+				{"rk-3", "C", "D"},
+			},
+			expectedFams: []string{"", "arg-family", "arg-family"},
+			dataStartIdx: 1,
+		},
+		{
+			label: "larger-batches",
+			ia:    importerArgs{fam: "arg-family", sz: 100, workers: 1, timestamp: "now"},
+			csvData: [][]string{
+				{"", "col-1", "col-2"},
+				{"rk-0", "A", ""},
+				{"rk-1", "", "B"},
+				{"rk-2", "", ""},
+				{"rk-3", "C", "D"},
+			},
+			expectedFams: []string{"", "arg-family", "arg-family"},
+			dataStartIdx: 1,
+		},
+		{
+			label: "many-workers",
+			ia:    importerArgs{fam: "arg-family", sz: 1, workers: 20, timestamp: "now"},
+			csvData: [][]string{
+				{"", "col-1", "col-2"},
+				{"rk-0", "A", ""},
+				{"rk-1", "", "B"},
+				{"rk-2", "", ""},
+				{"rk-3", "C", "D"},
+			},
+			expectedFams: []string{"", "arg-family", "arg-family"},
+			dataStartIdx: 1,
+		},
+		{
+			label: "value-encoded-timestamp",
+			ia:    importerArgs{fam: "", sz: 1, workers: 1, timestamp: "value-encoded"},
+			csvData: [][]string{
+				{"", "my-family", ""},
+				{"", "col-1", "col-2"},
+				{"rk-0", "A@1577862000000000", ""},
+				{"rk-1", "", "B"},
+				{"rk-2", "", ""},
+				{"rk-3", "", "C@1430823905000000"},
+			},
+			expectedFams: []string{"", "my-family", "my-family"},
+			dataStartIdx: 2,
+		},
+		{
+			label: "now-timestamp",
+			ia:    importerArgs{fam: "", sz: 1, workers: 1, timestamp: "now"},
+			csvData: [][]string{
+				{"", "my-family", ""},
+				{"", "col-1", "col-2"},
+				{"rk-0", "A@1577862000000000", ""},
+				{"rk-1", "", "B"},
+				{"rk-2", "", ""},
+				{"rk-3", "", "C@1430823905000000"},
+			},
+			expectedFams: []string{"", "my-family", "my-family"},
+			dataStartIdx: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		ctx, client := setupEmulator(t, []string{"my-table"}, []string{"my-family", "arg-family"})
+		tbl := client.Open("my-table")
+
+		byteData, err := transformToCsvBuffer(tc.csvData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader := csv.NewReader(bytes.NewReader(byteData))
+
+		e, _ := newTestEnv(Deps{Client: client})
+		if err := importCSV(ctx, e, tbl, reader, tc.ia); err != nil {
+			t.Fatalf("importCSV: %v", err)
+		}
+
+		if err := validateData(ctx, tbl, tc.ia.timestamp, tc.expectedFams, tc.csvData[tc.dataStartIdx-1], tc.csvData[tc.dataStartIdx:]); err != nil {
+			t.Fatalf("Read back validation error: %s", err)
+		}
+	}
+}
+
+func TestImportCreateTable(t *testing.T) {
+	ctx, ac, c := newEmulatorClients(t)
+	e, _ := newTestEnv(Deps{Client: c, AdminClient: ac})
+	if err := ac.CreateTable(ctx, "existing"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.CreateColumnFamily(ctx, "existing", "fam-1"); err != nil {
+		t.Fatal(err)
+	}
+	csvData := [][]string{
+		{"", "fam-1", "", "fam-2"},
+		{"", "col-1", "col-2", "col-3"},
+		{"rk-0", "A", "B", "C"},
+	}
+	byteData, err := transformToCsvBuffer(csvData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range []string{"new", "existing"} {
+		ia, err := parseImporterArgs(ctx, e.config, []string{table, "data.csv", "create-table=true", "gc-policy=maxversions=1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ia.sz, ia.workers = 1, 1
+		tbl := c.Open(table)
+		if err := importCSV(ctx, e, tbl, csv.NewReader(bytes.NewReader(byteData)), ia); err != nil {
+			t.Fatalf("%s: importCSV: %v", table, err)
+		}
+
+		ti, err := ac.TableInfo(ctx, table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		policies := map[string]string{}
+		for _, fi := range ti.FamilyInfos {
+			policies[fi.Name] = fi.GCPolicy
+		}
+		want := map[string]string{"fam-1": "versions() > 1", "fam-2": "versions() > 1"}
+		if table == "existing" {
+			want["fam-1"] = "<never>"
+		}
+		if diff := cmp.Diff(want, policies); diff != "" {
+			t.Errorf("%s: families mismatch (-want +got):\n%s", table, diff)
+		}
+		if err := validateData(ctx, tbl, "now", []string{"", "fam-1", "fam-1", "fam-2"}, csvData[1], csvData[2:]); err != nil {
+			t.Errorf("%s: read back validation error: %s", table, err)
+		}
+	}
+}
+
+func TestParseColumnFamily(t *testing.T) {
+	expectedGc := bigtable.IntersectionPolicy(bigtable.MaxVersionsPolicy(2), bigtable.MaxAgePolicy(time.Hour))
+	expectedType := bigtable.AggregateType{Input: bigtable.Int64Type{}, Aggregator: bigtable.SumAggregator{}}
+
+	var tests = []struct {
+		name   string
+		input  string
+		id     string
+		family bigtable.Family
+	}{
+		{name: "simple", input: "family1", id: "family1", family: bigtable.Family{GCPolicy: bigtable.NoGcPolicy()}},
+		{name: "gc policy only", input: "family1:(((maxversions=2 and (maxage=1h))))",
+			id:     "family1",
+			family: bigtable.Family{GCPolicy: expectedGc}},
+		{name: "type only", input: "family1:never:intsum", id: "family1", family: bigtable.Family{GCPolicy: bigtable.NoGcPolicy(), ValueType: expectedType}},
+		{name: "gc policy and type", input: "family1:(((maxversions=2 and (maxage=1h)))):intsum", id: "family1", family: bigtable.Family{GCPolicy: expectedGc, ValueType: expectedType}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, family, err := (&Config{}).parseFamilyText(tt.input)
+			if err != nil {
+				t.Error(err)
+			}
+			if id != tt.id {
+				t.Errorf("got %s, want %s", id, tt.id)
+			}
+			if !cmp.Equal(family, tt.family, cmp.AllowUnexported(bigtable.IntersectionPolicy(), bigtable.UnionPolicy())) {
+				t.Errorf("got %s, want %s", family, tt.family)
+			}
+
+		})
+	}
+}
+
+func TestRowValue(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	e.formatting.Settings.Columns["size"] = cli.ValueFormatColumn{Encoding: "BigEndian", Type: "uint16"}
+
+	tests := []struct {
+		name string
+		row  bigtable.Row
+		want string
+		fail bool
+	}{
+		{
+			name: "raw value",
+			row:  bigtable.Row{"f": {{Row: "r", Column: "f:c", Value: []byte("hello world")}}},
+			want: "hello world",
+		},
+		{
+			name: "latest cell",
+			row: bigtable.Row{"f": {
+				{Row: "r", Column: "f:c", Timestamp: 2, Value: []byte("new")},
+				{Row: "r", Column: "f:c", Timestamp: 1, Value: []byte("old")},
+			}},
+			want: "new",
+		},
+		{
+			name: "latest cell sorted oldest first",
+			row: bigtable.Row{"f": {
+				{Row: "r", Column: "f:c", Timestamp: 1, Value: []byte("old")},
+				{Row: "r", Column: "f:c", Timestamp: 2, Value: []byte("new")},
+			}},
+			want: "new",
+		},
+		{
+			name: "formatted value",
+			row:  bigtable.Row{"f": {{Row: "r", Column: "f:size", Value: []byte{1, 2}}}},
+			want: "258",
+		},
+		{
+			name: "missing row",
+			row:  nil,
+			fail: true,
+		},
+		{
+			name: "multiple columns",
+			row: bigtable.Row{"f": {
+				{Row: "r", Column: "f:a", Value: []byte("a")},
+				{Row: "r", Column: "f:b", Value: []byte("b")},
+			}},
+			fail: true,
+		},
+	}
+	for _, tc := range tests {
+		got, err := e.rowValue(tc.row)
+		if tc.fail {
+			if err == nil {
+				t.Errorf("%s: rowValue did not fail", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: rowValue unexpectedly failed: %v", tc.name, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: rowValue = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestScanStats(t *testing.T) {
+	s := &scanStats{}
+	for _, seen := range []int64{3000, 7000} {
+		s.add(&bigtable.FullReadStats{
+			ReadIterationStats: bigtable.ReadIterationStats{
+				RowsSeenCount:      seen,
+				RowsReturnedCount:  seen / 100,
+				CellsSeenCount:     seen * 2,
+				CellsReturnedCount: seen / 50,
+			},
+			RequestLatencyStats: bigtable.RequestLatencyStats{
+				FrontendServerLatency: time.Second,
+			},
+		})
+	}
+	r := s.total.ReadIterationStats
+	if s.requests != 2 || r.RowsSeenCount != 10000 || r.RowsReturnedCount != 100 || r.CellsSeenCount != 20000 ||
+		s.total.RequestLatencyStats.FrontendServerLatency != 2*time.Second {
+		t.Errorf("scanStats = %+v", s)
+	}
+
+	var buf bytes.Buffer
+	printScanEfficiency(&buf, s)
+	for _, want := range []string{"requests: 2", "rows_seen_per_row_returned: 100.0", "row_filter_selectivity: 1.00%", "cell_filter_selectivity: 1.00%", "Warning"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("efficiency output does not contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	s = &scanStats{}
+	s.add(&bigtable.FullReadStats{ReadIterationStats: bigtable.ReadIterationStats{RowsSeenCount: 10, RowsReturnedCount: 10}})
+	buf.Reset()
+	printScanEfficiency(&buf, s)
+	if strings.Contains(buf.String(), "Warning") {
+		t.Errorf("warning for an unfiltered scan:\n%s", buf.String())
+	}
+}
+
+func TestFullReadStatsProto(t *testing.T) {
+	stats := &bigtable.FullReadStats{
+		ReadIterationStats: bigtable.ReadIterationStats{
+			RowsSeenCount:      10,
+			RowsReturnedCount:  2,
+			CellsSeenCount:     30,
+			CellsReturnedCount: 4,
+		},
+		RequestLatencyStats: bigtable.RequestLatencyStats{
+			FrontendServerLatency: 1500 * time.Millisecond,
+		},
+	}
+	out, err := protojson.Marshal(fullReadStatsProto(stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"fullReadStatsView": map[string]interface{}{
+			"readIterationStats": map[string]interface{}{
+				"rowsSeenCount":      "10",
+				"rowsReturnedCount":  "2",
+				"cellsSeenCount":     "30",
+				"cellsReturnedCount": "4",
+			},
+			"requestLatencyStats": map[string]interface{}{
+				"frontendServerLatency": "1.500s",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("stats JSON mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseClusterSpec(t *testing.T) {
+	tests := []struct {
+		in   string
+		out  bigtable.ClusterConfig
+		fail bool
+	}{
+		{
+			in:  "my-c1:us-central1-b:3:SSD",
+			out: bigtable.ClusterConfig{ClusterID: "my-c1", Zone: "us-central1-b", NumNodes: 3, StorageType: bigtable.SSD},
+		},
+		{
+			in:  "my-c2:europe-west1-c:1:HDD",
+			out: bigtable.ClusterConfig{ClusterID: "my-c2", Zone: "europe-west1-c", NumNodes: 1, StorageType: bigtable.HDD},
+		},
+		{in: "my-c1:us-central1-b:3", fail: true},
+		{in: "my-c1:us-central1-b:three:SSD", fail: true},
+		{in: "my-c1:us-central1-b:3:FLASH", fail: true},
+		{in: ":us-central1-b:3:SSD", fail: true},
+	}
+	for _, tc := range tests {
+		got, err := parseClusterSpec(tc.in)
+		if tc.fail {
+			if err == nil {
+				t.Errorf("parseClusterSpec(%q) did not fail", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseClusterSpec(%q) unexpectedly failed: %v", tc.in, err)
+			continue
+		}
+		if !cmp.Equal(got, tc.out) {
+			t.Errorf("parseClusterSpec(%q) = %+v, want %+v", tc.in, got, tc.out)
+		}
+	}
+}
+
+func TestParseExpireTime(t *testing.T) {
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		out  time.Time
+		fail bool
+	}{
+		{in: "30d", out: now.Add(30 * 24 * time.Hour)},
+		{in: "6h", out: now.Add(6 * time.Hour)},
+		{in: "2024-05-01T00:00:00Z", out: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{in: "", fail: true},
+		{in: "tomorrow", fail: true},
+		{in: "2024-05-01", fail: true},
+	}
+	for _, tc := range tests {
+		got, err := parseExpireTime(tc.in, now)
+		if tc.fail {
+			if err == nil {
+				t.Errorf("parseExpireTime(%q) did not fail", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseExpireTime(%q) unexpectedly failed: %v", tc.in, err)
+			continue
+		}
+		if !got.Equal(tc.out) {
+			t.Errorf("parseExpireTime(%q) = %v, want %v", tc.in, got, tc.out)
+		}
+	}
+}
+
+func TestSplitCellTimestamp(t *testing.T) {
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in    string
+		value string
+		ts    bigtable.Timestamp
+	}{
+		{in: "v", value: "v", ts: bigtable.Time(now)},
+		{in: "v@12345", value: "v", ts: 12345},
+		{in: "v@now", value: "v", ts: bigtable.Time(now)},
+		{in: "v@now-1h", value: "v", ts: bigtable.Time(now.Add(-time.Hour))},
+		{in: "v@now+30m", value: "v", ts: bigtable.Time(now.Add(30 * time.Minute))},
+		{in: "v@2024-05-01T00:00:00Z", value: "v", ts: bigtable.Time(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))},
+		{in: "a@b@now", value: "a@b", ts: bigtable.Time(now)},
+		{in: "user@example.com", value: "user@example.com", ts: bigtable.Time(now)},
+		{in: "v@now*1h", value: "v@now*1h", ts: bigtable.Time(now)},
+		{in: "v@nowhere", value: "v@nowhere", ts: bigtable.Time(now)},
+	}
+	for _, tc := range tests {
+		value, ts := splitCellTimestamp(tc.in, now)
+		if value != tc.value || ts != tc.ts {
+			t.Errorf("splitCellTimestamp(%q) = (%q, %d), want (%q, %d)", tc.in, value, ts, tc.value, tc.ts)
+		}
+	}
+}
+
+func TestParseTimestampRange(t *testing.T) {
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		from, to   string
+		start, end bigtable.Timestamp
+		fail       bool
+	}{
+		{},
+		{from: "1000", start: 1000},
+		{to: "2000", end: 2000},
+		{from: "1000", to: "2000", start: 1000, end: 2000},
+		{from: "now-1h", to: "now", start: bigtable.Time(now.Add(-time.Hour)), end: bigtable.Time(now)},
+		{from: "2000", to: "1000", fail: true},
+		{from: "yesterday", fail: true},
+		{to: "later", fail: true},
+	}
+	for _, tc := range tests {
+		start, end, err := parseTimestampRange(tc.from, tc.to, now)
+		if tc.fail {
+			if err == nil {
+				t.Errorf("parseTimestampRange(%q, %q) did not fail", tc.from, tc.to)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTimestampRange(%q, %q) unexpectedly failed: %v", tc.from, tc.to, err)
+			continue
+		}
+		if start != tc.start || end != tc.end {
+			t.Errorf("parseTimestampRange(%q, %q) = (%d, %d), want (%d, %d)", tc.from, tc.to, start, end, tc.start, tc.end)
+		}
+	}
+}
+
+func TestDeleteCells(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f1", "f2"})
+	e, _ := newTestEnv(Deps{Client: c})
+
+	tbl := c.Open("my-table")
+	mut := bigtable.NewMutation()
+	for _, ts := range []bigtable.Timestamp{1000, 2000, 3000} {
+		mut.Set("f1", "a", ts, []byte("x"))
+		mut.Set("f1", "b", ts, []byte("x"))
+		mut.Set("f2", "a", ts, []byte("x"))
+	}
+	if err := tbl.Apply(ctx, "r", mut); err != nil {
+		t.Fatal(err)
+	}
+
+	cells := func() map[string][]bigtable.Timestamp {
+		r, err := tbl.ReadRow(ctx, "r")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string][]bigtable.Timestamp{}
+		for _, items := range r {
+			for _, item := range items {
+				got[item.Column] = append(got[item.Column], item.Timestamp)
+			}
+		}
+		return got
+	}
+
+	if err := doDeleteCells(ctx, e, "my-table", "r", "f1", "from=2000", "to=3000"); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]bigtable.Timestamp{
+		"f1:a": {3000, 1000},
+		"f1:b": {3000, 1000},
+		"f2:a": {3000, 2000, 1000},
+	}
+	if diff := cmp.Diff(want, cells()); diff != "" {
+		t.Errorf("after family range delete (-want +got):\n%s", diff)
+	}
+
+	if err := doDeleteCells(ctx, e, "my-table", "r", "f1", "column=a", "from=3000"); err != nil {
+		t.Fatal(err)
+	}
+	want["f1:a"] = []bigtable.Timestamp{1000}
+	if diff := cmp.Diff(want, cells()); diff != "" {
+		t.Errorf("after column range delete (-want +got):\n%s", diff)
+	}
+
+	if err := doDeleteCells(ctx, e, "my-table", "r", "f1"); err != nil {
+		t.Fatal(err)
+	}
+	want = map[string][]bigtable.Timestamp{"f2:a": {3000, 2000, 1000}}
+	if diff := cmp.Diff(want, cells()); diff != "" {
+		t.Errorf("after family delete (-want +got):\n%s", diff)
+	}
+}
+
+func TestRowExists(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	tbl := c.Open("my-table")
+	mut := bigtable.NewMutation()
+	mut.Set("f", "c", bigtable.Now(), []byte("v"))
+	if err := tbl.Apply(ctx, "present", mut); err != nil {
+		t.Fatal(err)
+	}
+	for row, want := range map[string]bool{"present": true, "absent": false} {
+		got, err := rowExists(ctx, tbl, row)
+		if err != nil {
+			t.Fatalf("rowExists(%q) failed: %v", row, err)
+		}
+		if got != want {
+			t.Errorf("rowExists(%q) = %v, want %v", row, got, want)
+		}
+	}
+}
+
+func TestCheckAndDelete(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f", "g"})
+	e, _ := newTestEnv(Deps{Client: c})
+
+	tbl := c.Open("my-table")
+	mut := bigtable.NewMutation()
+	mut.Set("f", "status", 1000, []byte("tombstoned"))
+	mut.Set("f", "status", 2000, []byte("live"))
+	mut.Set("g", "c", 1000, []byte("v"))
+	if err := tbl.Apply(ctx, "r", mut); err != nil {
+		t.Fatal(err)
+	}
+	families := func() []string {
+		r, err := tbl.ReadRow(ctx, "r")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fams []string
+		for fam := range r {
+			fams = append(fams, fam)
+		}
+		sort.Strings(fams)
+		return fams
+	}
+
+	// Only the latest cell is checked.
+	if err := doCheckAndDelete(ctx, e, "my-table", "r", "f:status=tombstoned"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := families(), []string{"f", "g"}; !cmp.Equal(got, want) {
+		t.Errorf("after unmatched delete, families = %v, want %v", got, want)
+	}
+	if err := doCheckAndDelete(ctx, e, "my-table", "r", "f:status=live", "g"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := families(), []string{"f"}; !cmp.Equal(got, want) {
+		t.Errorf("after family delete, families = %v, want %v", got, want)
+	}
+	if err := doCheckAndDelete(ctx, e, "my-table", "r", "f:status"); err != nil {
+		t.Fatal(err)
+	}
+	if got := families(); len(got) != 0 {
+		t.Errorf("after row delete, families = %v, want none", got)
+	}
+
+	for _, bad := range []string{"status", "f:", ":status=live", "f=live", "if=", "if=nosuch:x", "if=latest:0"} {
+		if _, err := parseCheckPredicate(bad); err == nil {
+			t.Errorf("parseCheckPredicate(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestCheckAndDeleteFilterPredicate(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f", "g"})
+	e, _ := newTestEnv(Deps{Client: c})
+
+	tbl := c.Open("my-table")
+	for row, values := range map[string][]string{
+		"r1": {"tombstoned-2024", "live"},
+		"r2": {"live", "tombstoned"},
+		"r3": {"live", "live"},
+	} {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "status", 1000, []byte(values[0]))
+		mut.Set("f", "status", 2000, []byte(values[1]))
+		if err := tbl.Apply(ctx, row, mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows := func() []string {
+		var keys []string
+		err := tbl.ReadRows(ctx, bigtable.InfiniteRange(""), func(r bigtable.Row) bool {
+			keys = append(keys, r.Key())
+			return true
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return keys
+	}
+
+	// A value regex matches any version of the column.
+	if err := doCheckAndDelete(ctx, e, "my-table", "r1", "if=value:tomb.*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doCheckAndDelete(ctx, e, "my-table", "r3", "if=value:tomb.*"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rows(), []string{"r2", "r3"}; !cmp.Equal(got, want) {
+		t.Errorf("after value regex deletes, rows = %v, want %v", got, want)
+	}
+	// Several filters chain, so only the latest cell is checked here.
+	if err := doCheckAndDelete(ctx, e, "my-table", "r2", "if=column:f:status;latest:1;value:live"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doCheckAndDelete(ctx, e, "my-table", "r3", "if=family:f;qualifier:status;latest:1;value:live"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rows(), []string{"r2"}; !cmp.Equal(got, want) {
+		t.Errorf("after chained filter deletes, rows = %v, want %v", got, want)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f", "g"})
+	e, out := newTestEnv(Deps{Client: c})
+
+	now := time.Now()
+	old := bigtable.Time(now.Add(-48 * time.Hour)).TruncateToMilliseconds()
+	recent := bigtable.Time(now.Add(-time.Hour)).TruncateToMilliseconds()
+	tbl := c.Open("my-table")
+	for _, row := range []string{"a1", "a2", "b1"} {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "c", old, []byte("x"))
+		mut.Set("f", "c", recent, []byte("x"))
+		mut.Set("g", "c", old, []byte("x"))
+		if err := tbl.Apply(ctx, row, mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	countCells := func() int {
+		n := 0
+		err := tbl.ReadRows(ctx, bigtable.InfiniteRange(""), func(r bigtable.Row) bool {
+			for _, items := range r {
+				n += len(items)
+			}
+			return true
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	if err := doPurge(ctx, e, "my-table", "older-than=1d", "dry-run=true"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := countCells(), 9; got != want {
+		t.Errorf("after dry run, %d cells, want %d", got, want)
+	}
+	if err := doPurge(ctx, e, "my-table", "older-than=1d", "prefix=a", "columns=f:"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := countCells(), 7; got != want {
+		t.Errorf("after prefix and column purge, %d cells, want %d", got, want)
+	}
+	out.Reset()
+	if err := doPurge(ctx, e, "my-table", "older-than=1d", "workers=2", "max-rate=1000"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := countCells(), 3; got != want {
+		t.Errorf("after full purge, %d cells, want %d", got, want)
+	}
+	if want := "Purged 4 cells in 3 rows older than "; !strings.HasPrefix(out.String(), want) {
+		t.Errorf("purge printed %q, want %q...", out, want)
+	}
+}
+
+func TestPurgeWriteError(t *testing.T) {
+	// An emulator that refuses every write.
+	deny := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if info.FullMethod == "/google.bigtable.v2.Bigtable/MutateRows" {
+			return status.Error(codes.PermissionDenied, "writes denied")
+		}
+		return handler(srv, ss)
+	}
+	srv, err := bttest.NewServer("localhost:0", grpc.StreamInterceptor(deny))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	conn, err := grpc.Dial(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	ac, err := bigtable.NewAdminClient(ctx, "proj", "instance", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := bigtable.NewClient(ctx, "proj", "instance", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.CreateTable(ctx, "my-table"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.CreateColumnFamily(ctx, "my-table", "f"); err != nil {
+		t.Fatal(err)
+	}
+	// Apply uses MutateRow, which the emulator still allows. Write enough
+	// rows for several batches of 100.
+	old := bigtable.Time(time.Now().Add(-48 * time.Hour)).TruncateToMilliseconds()
+	tbl := c.Open("my-table")
+	for i := 0; i < 350; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "c", old, []byte("x"))
+		if err := tbl.Apply(ctx, fmt.Sprintf("r%03d", i), mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- RunCommand(&Config{}, []string{"purge", "my-table", "older-than=1d", "workers=1"},
+			Deps{Client: c, Stdout: io.Discard, Stderr: io.Discard})
+	}()
+	select {
+	case err := <-done:
+		if want := "Deleting cells: "; err == nil || !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("purge with failing writes = %v, want error %q...", err, want)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("purge with failing writes did not return")
+	}
+}
+
+func TestDefaultTable(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table", "other-table"}, []string{"f"})
+	e, _ := newTestEnv(Deps{Client: c})
+	e.config.Table = "my-table"
+
+	if err := doSet(ctx, e, "r1", "f:c=v"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doSet(ctx, e, "r2", "app-profile=", "f:c=v"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doSet(ctx, e, "other-table", "r3", "f:c=v"); err != nil {
+		t.Fatal(err)
+	}
+
+	for table, want := range map[string][]string{
+		"my-table":    {"r1", "r2"},
+		"other-table": {"r3"},
+	} {
+		var got []string
+		err := c.Open(table).ReadRows(ctx, bigtable.InfiniteRange(""), func(r bigtable.Row) bool {
+			got = append(got, r.Key())
+			return true
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("rows of %s mismatch (-want +got):\n%s", table, diff)
+		}
+	}
+
+	for _, test := range []struct {
+		args    []string
+		omitted bool
+		want    []string
+	}{
+		{nil, true, []string{"my-table"}},
+		{[]string{"t"}, false, []string{"t"}},
+		{[]string{"prefix=a"}, true, []string{"my-table", "prefix=a"}},
+	} {
+		if got := e.withDefaultTable(test.args, test.omitted); !cmp.Equal(got, test.want) {
+			t.Errorf("withDefaultTable(%q, %t) = %q, want %q", test.args, test.omitted, got, test.want)
+		}
+	}
+}
+
+func TestParseGCPolicyArg(t *testing.T) {
+	cfg := &Config{GCPolicies: map[string]string{"default": "maxage=30d or maxversions=3"}}
+
+	for _, arg := range []string{"@default", "policy=@default", " maxage=30d or maxversions=3"} {
+		got, err := cfg.parseGCPolicyArg(arg)
+		if err != nil {
+			t.Fatalf("parseGCPolicyArg(%q) failed: %v", arg, err)
+		}
+		if got.String() != "(age() > 30d || versions() > 3)" {
+			t.Errorf("parseGCPolicyArg(%q) = %s", arg, got)
+		}
+	}
+	if _, err := cfg.parseGCPolicyArg("@missing"); err == nil {
+		t.Error("parseGCPolicyArg(@missing) did not fail")
+	}
+	_, fam, err := cfg.parseFamilyText("f:@default")
+	if err != nil || fam.GCPolicy.String() != "(age() > 30d || versions() > 3)" {
+		t.Errorf("parseFamilyText(f:@default) = %v, %v", fam.GCPolicy, err)
+	}
+}
+
+func TestBuildVersionInfo(t *testing.T) {
+	info := buildVersionInfo()
+	if info.Version != version || info.Revision != revision || info.RevisionDate != revisionDate {
+		t.Errorf("buildVersionInfo = %+v, want version %q, revision %q, revision date %q", info, version, revision, revisionDate)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+	if want := runtime.GOOS + "/" + runtime.GOARCH; info.Platform != want {
+		t.Errorf("Platform = %q, want %q", info.Platform, want)
+	}
+	for path := range info.ClientLibraries {
+		if !strings.HasPrefix(path, "cloud.google.com/go") && !strings.HasPrefix(path, "google.golang.org/") {
+			t.Errorf("ClientLibraries has %q, which isn't a client library", path)
+		}
+	}
+}
+
+func TestWriteMDDocPages(t *testing.T) {
+	dir := t.TempDir()
+	data := map[string]interface{}{
+		"Commands":   commands,
+		"ConfigHelp": configHelp,
+	}
+	if err := writeMDDocPages(dir, data); err != nil {
+		t.Fatal(err)
+	}
+	index, err := os.ReadFile(filepath.Join(dir, "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "- [cbt read](read.md): Read rows") {
+		t.Errorf("index.md doesn't link to read.md:\n%s", index)
+	}
+	for _, cmd := range commands {
+		page, err := os.ReadFile(filepath.Join(dir, cmd.Name+".md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(page), "---\ntitle: \"cbt "+cmd.Name+"\"\n") {
+			t.Errorf("%s.md has no front matter:\n%s", cmd.Name, page)
+		}
+	}
+	page, err := os.ReadFile(filepath.Join(dir, "read.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), "required_flags: [project, instance]\n") {
+		t.Errorf("read.md doesn't list its required flags:\n%s", page)
+	}
+}
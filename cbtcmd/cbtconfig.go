@@ -0,0 +1,702 @@
+/*
+Copyright 2015 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/sys/execabs"
+	"google.golang.org/grpc/credentials"
+)
+
+// Config represents a configuration.
+type Config struct {
+	Project, Instance string                           // required
+	Instances         string                           // optional, comma-separated
+	Creds             string                           // optional
+	Environment       string                           // optional
+	UniverseDomain    string                           // optional
+	AdminEndpoint     string                           // optional
+	DataEndpoint      string                           // optional
+	CertFile          string                           // optional
+	Proxy             string                           // optional
+	UserAgent         string                           // optional
+	WorkloadTag       string                           // optional
+	AccessToken       string                           // optional
+	AuthToken         string                           // optional
+	Timeout           time.Duration                    // optional
+	Table             string                           // optional
+	NoTokenCache      bool                             // optional
+	NoPager           bool                             // optional
+	Color             string                           // optional
+	OutputWidth       int                              // optional
+	Truncate          bool                             // optional
+	AuditLog          string                           // optional
+	GCPolicies        map[string]string                // optional, by template name
+	CredsJSON         []byte                           // derived
+	TokenSource       oauth2.TokenSource               // derived
+	TLSCreds          credentials.TransportCredentials // derived
+	ProxyURL          *url.URL                         // derived
+
+	credsName string // where CredsJSON came from
+}
+
+// RequiredFlags describes the flag requirements for a cbt command.
+type RequiredFlags uint
+
+const (
+	// NoneRequired specifies that not flags are required.
+	NoneRequired RequiredFlags = 0
+	// ProjectRequired specifies that the -project flag is required.
+	ProjectRequired RequiredFlags = 1 << iota
+	// InstanceRequired specifies that the -instance flag is required.
+	InstanceRequired
+	// ProjectAndInstanceRequired specifies that both -project and -instance is required.
+	ProjectAndInstanceRequired = ProjectRequired | InstanceRequired
+)
+
+// RegisterFlags registers a set of standard flags for this config.
+// It should be called before flag.Parse.
+func (c *Config) RegisterFlags() {
+	flag.StringVar(&c.Project, "project", c.Project, "project ID. If unset uses gcloud configured project")
+	flag.StringVar(&c.Instance, "instance", c.Instance, "Cloud Bigtable instance")
+	flag.StringVar(&c.Instances, "instances", c.Instances, "Comma-separated instances to run ls, count, describeinstance, listclusters or tablesize on, instead of -instance")
+	flag.StringVar(&c.Creds, "creds", c.Creds, "Path to the credentials file. If set, uses the application credentials in this file. Use - to read the JSON key from stdin")
+	flag.StringVar(&c.Environment, "environment", c.Environment, "Use the endpoints and credentials of an environment: prod (the default), staging, emulator or custom")
+	flag.StringVar(&c.UniverseDomain, "universe-domain", c.UniverseDomain, "The universe domain of the endpoints and credentials, e.g. for Trusted Partner Cloud (default googleapis.com)")
+	flag.StringVar(&c.AdminEndpoint, "admin-endpoint", c.AdminEndpoint, "Override the admin api endpoint")
+	flag.StringVar(&c.DataEndpoint, "data-endpoint", c.DataEndpoint, "Override the data api endpoint")
+	flag.StringVar(&c.CertFile, "cert-file", c.CertFile, "Override the TLS certificates file")
+	flag.StringVar(&c.Proxy, "proxy", c.Proxy, "Connect through this HTTP proxy, e.g. http://proxy.example.com:3128, instead of $HTTPS_PROXY")
+	flag.StringVar(&c.UserAgent, "user-agent", c.UserAgent, "Override the user agent string")
+	flag.StringVar(&c.WorkloadTag, "workload-tag", c.WorkloadTag, "Tag requests with this workload, e.g. a team or job name, in the user agent and request metadata")
+	flag.StringVar(&c.AccessToken, "access-token", c.AccessToken, "if set, use access token for requests")
+	flag.StringVar(&c.AuthToken, "auth-token", c.AuthToken, "if set, use IAM Auth Token for requests")
+	flag.DurationVar(&c.Timeout, "timeout", c.Timeout,
+		"Timeout (e.g. 10s, 100ms, 5m )")
+	flag.StringVar(&c.Table, "table", c.Table, "Default table for read, lookup, set and count when the table argument is omitted")
+	flag.StringVar(&c.Color, "color", c.Color, "Whether to color the output: auto, always or never. auto colors output to a terminal")
+	flag.IntVar(&c.OutputWidth, "output-width", c.OutputWidth, "Fit the tables of listing commands to this many columns, cutting long cells short")
+	flag.BoolVar(&c.Truncate, "truncate", c.Truncate, "Fit the tables of listing commands to $COLUMNS, or 80 columns, cutting long cells short")
+	flag.BoolVar(&c.NoPager, "no-pager", c.NoPager, "Do not pipe the output of read, lookup, ls and other listing commands through $PAGER on a terminal")
+	flag.StringVar(&c.AuditLog, "audit-log", c.AuditLog, "Append a JSON line to this file before and after each command that changes tables, data or instances")
+	flag.BoolVar(&c.NoTokenCache, "no-token-cache", c.NoTokenCache, "Always run gcloud for credentials instead of reusing a cached token")
+}
+
+// defaultUniverseDomain is the universe domain of Google Cloud's public
+// endpoints.
+const defaultUniverseDomain = "googleapis.com"
+
+// endpoint returns the default endpoint of a service, such as bigtable or
+// bigtableadmin, in the configured universe.
+func (c *Config) endpoint(service string) string {
+	ud := c.UniverseDomain
+	if ud == "" {
+		ud = defaultUniverseDomain
+	}
+	return service + "." + ud + ":443"
+}
+
+// stagingEndpoints are the admin and data endpoints of -environment=staging.
+var stagingEndpoints = struct{ admin, data string }{
+	"test-bigtableadmin.sandbox.googleapis.com:443", "test-bigtable.sandbox.googleapis.com:443",
+}
+
+const (
+	// emulatorHostEnv is the environment variable that points the client
+	// libraries at an emulator.
+	emulatorHostEnv = "BIGTABLE_EMULATOR_HOST"
+	// defaultEmulatorHost is where 'gcloud beta emulators bigtable start'
+	// listens by default.
+	defaultEmulatorHost = "localhost:8086"
+	// emulatorDefaultName is the project and instance used with the emulator
+	// when none is given; the emulator accepts any.
+	emulatorDefaultName = "emulator"
+)
+
+// applyEnvironment sets the endpoints of the selected environment, unless
+// they are set explicitly. With -environment=emulator, requests go to the
+// emulator at $BIGTABLE_EMULATOR_HOST, -data-endpoint or defaultEmulatorHost,
+// without credentials. Any other explicit environment ignores
+// $BIGTABLE_EMULATOR_HOST.
+func (c *Config) applyEnvironment() error {
+	switch c.Environment {
+	case "":
+		return nil
+	case "emulator":
+		host := os.Getenv(emulatorHostEnv)
+		if host == "" {
+			host = c.DataEndpoint
+		}
+		if host == "" {
+			host = defaultEmulatorHost
+		}
+		os.Setenv(emulatorHostEnv, host)
+		c.AdminEndpoint, c.DataEndpoint = "", ""
+		if c.Project == "" {
+			c.Project = emulatorDefaultName
+		}
+		if c.Instance == "" {
+			c.Instance = emulatorDefaultName
+		}
+		return nil
+	case "custom":
+		if c.AdminEndpoint == "" || c.DataEndpoint == "" {
+			return fmt.Errorf("-environment=custom requires -admin-endpoint and -data-endpoint")
+		}
+	case "prod":
+		if c.AdminEndpoint == "" {
+			c.AdminEndpoint = c.endpoint("bigtableadmin")
+		}
+		if c.DataEndpoint == "" {
+			c.DataEndpoint = c.endpoint("bigtable")
+		}
+	case "staging":
+		if c.UniverseDomain != "" && c.UniverseDomain != defaultUniverseDomain {
+			return fmt.Errorf("-environment=staging is only available in the %s universe", defaultUniverseDomain)
+		}
+		if c.AdminEndpoint == "" {
+			c.AdminEndpoint = stagingEndpoints.admin
+		}
+		if c.DataEndpoint == "" {
+			c.DataEndpoint = stagingEndpoints.data
+		}
+	default:
+		return fmt.Errorf("unknown environment %q: want prod, staging, emulator or custom", c.Environment)
+	}
+	os.Unsetenv(emulatorHostEnv)
+	return nil
+}
+
+// CheckFlags checks that the required config values are set.
+func (c *Config) CheckFlags(required RequiredFlags) error {
+	var missing []string
+	if err := c.applyEnvironment(); err != nil {
+		return err
+	}
+	if c.CertFile != "" {
+		b, err := ioutil.ReadFile(c.CertFile)
+		if err != nil {
+			return fmt.Errorf("failed to load certificates from %s: %v", c.CertFile, err)
+		}
+
+		cp := x509.NewCertPool()
+		if !cp.AppendCertsFromPEM(b) {
+			return fmt.Errorf("failed to append certificates from %s", c.CertFile)
+		}
+
+		c.TLSCreds = credentials.NewTLS(&tls.Config{RootCAs: cp})
+	}
+	if c.Proxy != "" {
+		u, err := parseProxyURL(c.Proxy)
+		if err != nil {
+			return err
+		}
+		c.ProxyURL = u
+	}
+	// The emulator needs no credentials.
+	if required != NoneRequired && c.Environment != "emulator" {
+		if c.Creds != "" && c.AccessToken != "" {
+			return fmt.Errorf("-creds and -access-token should not both be specified")
+		}
+		if c.AccessToken == "" {
+			if err := c.loadCredsJSON(os.Stdin); err != nil {
+				return err
+			}
+		}
+		c.SetFromGcloud()
+		if c.AccessToken != "" {
+			c.TokenSource = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: c.AccessToken})
+		} else if c.CredsJSON != nil {
+			data := c.CredsJSON
+			c.TokenSource = credsTokenSource(c.credsName, func() ([]byte, error) { return data, nil })
+		} else if c.Creds != "" {
+			filename := c.Creds
+			c.TokenSource = credsTokenSource(filename, func() ([]byte, error) { return ioutil.ReadFile(filename) })
+		}
+	}
+	if required&ProjectRequired != 0 && c.Project == "" {
+		missing = append(missing, "-project")
+	}
+	if required&InstanceRequired != 0 && c.Instance == "" {
+		missing = append(missing, "-instance")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing %s", strings.Join(missing, " and "))
+	}
+	return nil
+}
+
+// Filename returns the filename consulted for standard configuration.
+func Filename() string {
+	// TODO(dsymonds): Might need tweaking for Windows.
+	return filepath.Join(os.Getenv("HOME"), ".cbtrc")
+}
+
+// Load loads a .cbtrc file.
+// If the file is not present, an empty config is returned.
+func Load() (*Config, error) {
+	filename := Filename()
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		// silent fail if the file isn't there
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("reading %s: %v", filename, err)
+	}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	return readConfig(s, filename)
+}
+
+func readConfig(s *bufio.Scanner, filename string) (*Config, error) {
+	c := new(Config)
+	for s.Scan() {
+		line := s.Text()
+		// Ignore empty lines.
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("bad line in %s: %q", filename, line)
+		}
+		key, val := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		if name := strings.TrimPrefix(key, "gcpolicy."); name != key && name != "" {
+			if c.GCPolicies == nil {
+				c.GCPolicies = map[string]string{}
+			}
+			c.GCPolicies[name] = val
+			continue
+		}
+		switch key {
+		default:
+			return nil, fmt.Errorf("unknown key in %s: %q", filename, key)
+		case "project":
+			c.Project = val
+		case "instance":
+			c.Instance = val
+		case "creds":
+			c.Creds = val
+		case "environment":
+			c.Environment = val
+		case "universe-domain":
+			c.UniverseDomain = val
+		case "admin-endpoint":
+			c.AdminEndpoint = val
+		case "data-endpoint":
+			c.DataEndpoint = val
+		case "cert-file":
+			c.CertFile = val
+		case "proxy":
+			c.Proxy = val
+		case "user-agent":
+			c.UserAgent = val
+		case "workload-tag":
+			c.WorkloadTag = val
+		case "auth-token":
+			c.AuthToken = val
+		case "timeout":
+			timeout, err := time.ParseDuration(val)
+			if err != nil {
+				return nil, err
+			}
+			c.Timeout = timeout
+		case "table":
+			c.Table = val
+		case "color":
+			c.Color = val
+		case "audit-log":
+			c.AuditLog = val
+		}
+
+	}
+	return c, s.Err()
+}
+
+// credsJSONEnv is the environment variable that can hold the JSON key of the
+// credentials to use, so that no key file needs to be written to disk.
+const credsJSONEnv = "CBT_CREDS_JSON"
+
+// loadCredsJSON reads the JSON key of the credentials from stdin if -creds is
+// "-", or from credsJSONEnv if -creds is unset.
+func (c *Config) loadCredsJSON(stdin io.Reader) error {
+	switch {
+	case c.Creds == "-":
+		data, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return fmt.Errorf("reading credentials from stdin: %v", err)
+		}
+		c.CredsJSON = data
+		c.credsName = "stdin"
+	case c.Creds == "":
+		if data := os.Getenv(credsJSONEnv); data != "" {
+			c.CredsJSON = []byte(data)
+			c.credsName = "$" + credsJSONEnv
+		}
+	}
+	return nil
+}
+
+// readsStdin reports whether the command name reads its input from stdin
+// with args, which -creds=- would leave empty.
+func readsStdin(name string, args []string) bool {
+	for _, arg := range args {
+		if arg == "/dev/stdin" {
+			return true
+		}
+	}
+	switch name {
+	case "lookup":
+		for _, arg := range args {
+			if arg == "-stdin" || arg == "--stdin" {
+				return true
+			}
+		}
+	case "analyzekeys":
+		return len(args) > 0 && args[0] == "-"
+	}
+	return false
+}
+
+// credsTokenSource returns a token source for the credentials in the JSON key
+// returned by load, which refreshes the token as it expires. Using it rather
+// than leaving each client to find the credentials means that every client,
+// including the raw RPC connections, refreshes the same way. The key is loaded
+// when the first token is needed.
+func credsTokenSource(name string, load func() ([]byte, error)) oauth2.TokenSource {
+	return &refreshLoggingTokenSource{src: &jsonKeyTokenSource{name: name, load: load}, name: name}
+}
+
+type jsonKeyTokenSource struct {
+	name string
+	load func() ([]byte, error)
+
+	once sync.Once
+	ts   oauth2.TokenSource
+	err  error
+}
+
+// Token implements the oauth2.TokenSource interface
+func (s *jsonKeyTokenSource) Token() (*oauth2.Token, error) {
+	s.once.Do(func() {
+		data, err := s.load()
+		if err != nil {
+			s.err = fmt.Errorf("reading credentials: %v", err)
+			return
+		}
+		creds, err := google.CredentialsFromJSON(context.Background(), data, cloudPlatformScope)
+		if err != nil {
+			s.err = fmt.Errorf("reading credentials from %s: %v", s.name, err)
+			return
+		}
+		s.ts = creds.TokenSource
+	})
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.ts.Token()
+}
+
+// refreshLoggingTokenSource logs when the token of src changes, so that
+// token refreshes during long-running commands are visible.
+type refreshLoggingTokenSource struct {
+	src  oauth2.TokenSource
+	name string
+
+	mu   sync.Mutex
+	last string
+}
+
+// Token implements the oauth2.TokenSource interface
+func (s *refreshLoggingTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := s.src.Token()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		log.Printf("Refreshing the token from %s: %v", s.name, err)
+		return nil, err
+	}
+	if s.last != "" && tok.AccessToken != s.last {
+		log.Printf("Refreshed the token from %s; it expires at %s", s.name, tok.Expiry.Format(time.RFC3339))
+	}
+	s.last = tok.AccessToken
+	return tok, nil
+}
+
+// GcloudCredential holds gcloud credential information.
+type GcloudCredential struct {
+	AccessToken string    `json:"access_token"`
+	Expiry      time.Time `json:"token_expiry"`
+}
+
+// Token creates an oauth2 token using gcloud credentials.
+func (cred *GcloudCredential) Token() *oauth2.Token {
+	return &oauth2.Token{AccessToken: cred.AccessToken, TokenType: "Bearer", Expiry: cred.Expiry}
+}
+
+// GcloudConfig holds gcloud configuration values.
+type GcloudConfig struct {
+	Configuration struct {
+		Properties struct {
+			Core struct {
+				Project string `json:"project"`
+			} `json:"core"`
+		} `json:"properties"`
+	} `json:"configuration"`
+	Credential GcloudCredential `json:"credential"`
+}
+
+// GcloudCmdTokenSource holds the comamnd arguments. It is only intended to be set by the program.
+// TODO(deklerk): Can this be unexported?
+type GcloudCmdTokenSource struct {
+	Command string
+	Args    []string
+	// CacheFile, if set, is updated with each token retrieved, under
+	// CacheKey.
+	CacheFile string
+	CacheKey  string
+}
+
+// Token implements the oauth2.TokenSource interface
+func (g *GcloudCmdTokenSource) Token() (*oauth2.Token, error) {
+	gcloudConfig, err := LoadGcloudConfig(g.Command, g.Args)
+	if err != nil {
+		return nil, err
+	}
+	if g.CacheFile != "" {
+		writeGcloudConfigCache(g.CacheFile, g.CacheKey, gcloudConfig)
+	}
+	return gcloudConfig.Credential.Token(), nil
+}
+
+// tokenCacheMargin is how long before its expiry a cached gcloud token is no
+// longer used.
+const tokenCacheMargin = 5 * time.Minute
+
+// TokenCacheFilename returns the file in which the gcloud configuration,
+// including an access token, is cached between invocations.
+func TokenCacheFilename() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cbt", "gcloud-token.json"), nil
+}
+
+// gcloudConfigCache is the cached gcloud configuration, with the key of the
+// gcloud state it was read in.
+type gcloudConfigCache struct {
+	Key    string        `json:"key"`
+	Config *GcloudConfig `json:"config"`
+}
+
+// gcloudDir returns the directory of the gcloud configuration.
+func gcloudDir() (string, error) {
+	if dir := os.Getenv("CLOUDSDK_CONFIG"); dir != "" {
+		return dir, nil
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "gcloud"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "gcloud"), nil
+}
+
+// gcloudStateKey returns a digest of the gcloud state that selects the
+// project and account that config-helper reports: the active configuration,
+// its properties, and the environment variables that override them. It
+// changes with 'gcloud config set', 'gcloud auth login' and 'gcloud config
+// configurations activate', so that a cached configuration isn't used after
+// them. It reads the files rather than running gcloud, which is what the
+// cache saves.
+func gcloudStateKey() string {
+	h := sha256.New()
+	for _, env := range []string{"CLOUDSDK_CONFIG", "CLOUDSDK_ACTIVE_CONFIG_NAME", "CLOUDSDK_CORE_PROJECT", "CLOUDSDK_CORE_ACCOUNT"} {
+		fmt.Fprintf(h, "%s=%s\n", env, os.Getenv(env))
+	}
+	if dir, err := gcloudDir(); err == nil {
+		name := os.Getenv("CLOUDSDK_ACTIVE_CONFIG_NAME")
+		if name == "" {
+			data, _ := ioutil.ReadFile(filepath.Join(dir, "active_config"))
+			name = strings.TrimSpace(string(data))
+		}
+		if name == "" {
+			name = "default"
+		}
+		props, _ := ioutil.ReadFile(filepath.Join(dir, "configurations", "config_"+name))
+		fmt.Fprintf(h, "active_config=%s\n%s", name, props)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// readGcloudConfigCache returns the cached gcloud configuration, or nil if
+// there is none, it was cached under another key, or its token expires within
+// tokenCacheMargin of now.
+func readGcloudConfigCache(filename, key string, now time.Time) *GcloudConfig {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+	var cache gcloudConfigCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Key != key || cache.Config == nil {
+		return nil
+	}
+	cred := cache.Config.Credential
+	if cred.AccessToken == "" || cred.Expiry.Before(now.Add(tokenCacheMargin)) {
+		return nil
+	}
+	return cache.Config
+}
+
+// writeGcloudConfigCache caches a gcloud configuration under key in a file
+// only the user can read. The cache is an optimization, so errors are ignored.
+func writeGcloudConfigCache(filename, key string, gcloudConfig *GcloudConfig) {
+	data, err := json.Marshal(gcloudConfigCache{Key: key, Config: gcloudConfig})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return
+	}
+	f, err := ioutil.TempFile(filepath.Dir(filename), ".gcloud-token-")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return
+	}
+	if err := f.Close(); err != nil {
+		return
+	}
+	os.Rename(f.Name(), filename)
+}
+
+// LoadGcloudConfig retrieves the gcloud configuration values we need use via the
+// 'config-helper' command
+func LoadGcloudConfig(gcloudCmd string, gcloudCmdArgs []string) (*GcloudConfig, error) {
+	out, err := execabs.Command(gcloudCmd, gcloudCmdArgs...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve gcloud configuration")
+	}
+
+	var gcloudConfig GcloudConfig
+	if err := json.Unmarshal(out, &gcloudConfig); err != nil {
+		return nil, fmt.Errorf("could not parse gcloud configuration")
+	}
+
+	return &gcloudConfig, nil
+}
+
+// SetFromGcloud retrieves and sets any missing config values from the gcloud
+// configuration if possible possible
+func (c *Config) SetFromGcloud() error {
+
+	if c.AccessToken == "" && c.CredsJSON == nil {
+		if c.Creds == "" {
+			c.Creds = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+			if c.Creds == "" {
+				log.Printf("-creds flag unset, will use gcloud credential")
+			}
+		} else {
+			os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", c.Creds)
+		}
+	}
+
+	if c.Project == "" {
+		log.Printf("-project flag unset, will use gcloud active project")
+	}
+
+	if (c.Creds != "" || c.CredsJSON != nil) && c.Project != "" {
+		return nil
+	}
+
+	gcloudCmd := "gcloud"
+	if runtime.GOOS == "windows" {
+		gcloudCmd = gcloudCmd + ".cmd"
+	}
+
+	gcloudCmdArgs := []string{"config", "config-helper",
+		"--format=json(configuration.properties.core.project,credential)"}
+
+	var cacheFile, cacheKey string
+	if !c.NoTokenCache {
+		cacheFile, _ = TokenCacheFilename()
+		cacheKey = gcloudStateKey()
+	}
+	var gcloudConfig *GcloudConfig
+	if cacheFile != "" {
+		gcloudConfig = readGcloudConfigCache(cacheFile, cacheKey, time.Now())
+	}
+	if gcloudConfig == nil {
+		var err error
+		gcloudConfig, err = LoadGcloudConfig(gcloudCmd, gcloudCmdArgs)
+		if err != nil {
+			return err
+		}
+		if cacheFile != "" {
+			writeGcloudConfigCache(cacheFile, cacheKey, gcloudConfig)
+		}
+	}
+
+	if c.Project == "" && gcloudConfig.Configuration.Properties.Core.Project != "" {
+		log.Printf("gcloud active project is \"%s\"",
+			gcloudConfig.Configuration.Properties.Core.Project)
+		c.Project = gcloudConfig.Configuration.Properties.Core.Project
+	}
+
+	if c.AccessToken == "" && c.Creds == "" && c.CredsJSON == nil {
+		c.TokenSource = &refreshLoggingTokenSource{
+			src: oauth2.ReuseTokenSource(
+				gcloudConfig.Credential.Token(),
+				&GcloudCmdTokenSource{Command: gcloudCmd, Args: gcloudCmdArgs, CacheFile: cacheFile, CacheKey: cacheKey}),
+			name: "gcloud",
+		}
+	}
+
+	return nil
+}
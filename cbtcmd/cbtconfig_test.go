@@ -0,0 +1,381 @@
+/*
+Copyright 2019 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestReadConfig(t *testing.T) {
+	project := "test-project"
+	instance := "test-instance"
+	credentials := "test-credentials"
+	adminEndpoint := "test-admin-endpoint"
+	dataEndpoint := "test-data-endpoint"
+	certificateFile := "test-certificate-file"
+	userAgent := "test-user-agent"
+	authToken := "test-auth-token="
+	timeout := time.Duration(42e9)
+	table := "test-table"
+	// Read configuration from string containing spaces, tabs and empty lines.
+	validConfig := fmt.Sprintf(`
+        project=%s
+        instance=%s
+        creds=%s
+        timeout=42s
+
+        admin-endpoint =%s
+        data-endpoint= %s
+        cert-file=%s
+        	user-agent   =  %s
+           auth-token=%s  
+        table = %s
+        environment = staging
+        universe-domain = example-universe.com
+        audit-log = ~/.cbt/audit.jsonl
+        proxy = proxy.example.com:3128
+        gcpolicy.default = maxage=30d or maxversions=3`,
+		project, instance, credentials, adminEndpoint, dataEndpoint, certificateFile, userAgent, authToken, table)
+	c, err := readConfig(bufio.NewScanner(strings.NewReader(validConfig)), "testfile")
+	if err != nil {
+		t.Fatalf("got unexpected error while reading config: %v", err)
+	}
+	if g, w := c.Project, project; g != w {
+		t.Errorf("Project mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if g, w := c.Instance, instance; g != w {
+		t.Errorf("Instance mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if g, w := c.Creds, credentials; g != w {
+		t.Errorf("Credentials mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if g, w := c.AdminEndpoint, adminEndpoint; g != w {
+		t.Errorf("AdminEndpoint mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if g, w := c.DataEndpoint, dataEndpoint; g != w {
+		t.Errorf("DataEndpoint mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if g, w := c.CertFile, certificateFile; g != w {
+		t.Errorf("CertFile mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if g, w := c.UserAgent, userAgent; g != w {
+		t.Errorf("UserAgent mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if g, w := c.AuthToken, authToken; g != w {
+		t.Errorf("AuthToken mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if g, w := c.Timeout, timeout; g != w {
+		t.Errorf("AuthToken mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if g, w := c.Table, table; g != w {
+		t.Errorf("Table mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if g, w := c.Environment, "staging"; g != w {
+		t.Errorf("Environment mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if g, w := c.UniverseDomain, "example-universe.com"; g != w {
+		t.Errorf("UniverseDomain mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if g, w := c.AuditLog, "~/.cbt/audit.jsonl"; g != w {
+		t.Errorf("AuditLog mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if g, w := c.Proxy, "proxy.example.com:3128"; g != w {
+		t.Errorf("Proxy mismatch\nGot: %s\nWant: %s", g, w)
+	}
+	if g, w := c.GCPolicies["default"], "maxage=30d or maxversions=3"; g != w {
+		t.Errorf("GCPolicies[default] mismatch\nGot: %s\nWant: %s", g, w)
+	}
+
+	// Try to read an invalid config file and verify that it fails.
+	unknownKey := fmt.Sprintf("%s\nunknown-key=some-value", validConfig)
+	_, err = readConfig(bufio.NewScanner(strings.NewReader(unknownKey)), "unknown-key-testfile")
+	if err == nil {
+		t.Fatalf("missing expected error in unknown-key config file")
+	}
+	badLine := fmt.Sprintf("%s\nproject test-project", validConfig)
+	_, err = readConfig(bufio.NewScanner(strings.NewReader(badLine)), "bad-line-testfile")
+	if err == nil {
+		t.Fatalf("missing expected error in bad-line config file")
+	}
+}
+
+func TestApplyEnvironment(t *testing.T) {
+	for _, test := range []struct {
+		desc             string
+		config           Config
+		emulatorHost     string
+		want             Config
+		wantEmulatorHost string
+		wantErr          bool
+	}{
+		{
+			desc:             "unset keeps the emulator host",
+			config:           Config{Project: "p"},
+			emulatorHost:     "localhost:9000",
+			want:             Config{Project: "p"},
+			wantEmulatorHost: "localhost:9000",
+		},
+		{
+			desc:   "staging",
+			config: Config{Environment: "staging", DataEndpoint: "override:443"},
+			want: Config{Environment: "staging", AdminEndpoint: "test-bigtableadmin.sandbox.googleapis.com:443",
+				DataEndpoint: "override:443"},
+		},
+		{
+			desc:         "prod ignores the emulator host",
+			config:       Config{Environment: "prod"},
+			emulatorHost: "localhost:9000",
+			want: Config{Environment: "prod", AdminEndpoint: "bigtableadmin.googleapis.com:443",
+				DataEndpoint: "bigtable.googleapis.com:443"},
+		},
+		{
+			desc:   "prod in another universe",
+			config: Config{Environment: "prod", UniverseDomain: "example-universe.com"},
+			want: Config{Environment: "prod", AdminEndpoint: "bigtableadmin.example-universe.com:443",
+				DataEndpoint: "bigtable.example-universe.com:443"},
+		},
+		{
+			desc:    "staging in another universe",
+			config:  Config{Environment: "staging", UniverseDomain: "example-universe.com"},
+			wantErr: true,
+		},
+		{
+			desc:             "emulator at the default host",
+			config:           Config{Environment: "emulator", Instance: "i"},
+			want:             Config{Environment: "emulator", Project: "emulator", Instance: "i"},
+			wantEmulatorHost: "localhost:8086",
+		},
+		{
+			desc:             "emulator at the data endpoint",
+			config:           Config{Environment: "emulator", DataEndpoint: "localhost:9001", Project: "p", Instance: "i"},
+			want:             Config{Environment: "emulator", Project: "p", Instance: "i"},
+			wantEmulatorHost: "localhost:9001",
+		},
+		{
+			desc:    "custom without endpoints",
+			config:  Config{Environment: "custom", AdminEndpoint: "a:443"},
+			wantErr: true,
+		},
+		{
+			desc:    "unknown",
+			config:  Config{Environment: "dev"},
+			wantErr: true,
+		},
+	} {
+		t.Setenv(emulatorHostEnv, test.emulatorHost)
+		if test.emulatorHost == "" {
+			os.Unsetenv(emulatorHostEnv)
+		}
+		c := test.config
+		err := c.applyEnvironment()
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: applyEnvironment() = %v, wantErr %v", test.desc, err, test.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if c.Environment != test.want.Environment || c.Project != test.want.Project || c.Instance != test.want.Instance ||
+			c.AdminEndpoint != test.want.AdminEndpoint || c.DataEndpoint != test.want.DataEndpoint {
+			t.Errorf("%s: config = %+v, want %+v", test.desc, c, test.want)
+		}
+		if got := os.Getenv(emulatorHostEnv); got != test.wantEmulatorHost {
+			t.Errorf("%s: %s = %q, want %q", test.desc, emulatorHostEnv, got, test.wantEmulatorHost)
+		}
+	}
+}
+
+func TestGcloudConfigCache(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cbt", "gcloud-token.json")
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if got := readGcloudConfigCache(filename, "k1", now); got != nil {
+		t.Errorf("readGcloudConfigCache with no file = %+v, want nil", got)
+	}
+
+	gc := &GcloudConfig{Credential: GcloudCredential{AccessToken: "token", Expiry: now.Add(time.Hour)}}
+	gc.Configuration.Properties.Core.Project = "my-project"
+	writeGcloudConfigCache(filename, "k1", gc)
+	fi, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && fi.Mode().Perm() != 0600 {
+		t.Errorf("cache file mode = %v, want 0600", fi.Mode().Perm())
+	}
+	got := readGcloudConfigCache(filename, "k1", now)
+	if got == nil || got.Credential.AccessToken != "token" || !got.Credential.Expiry.Equal(gc.Credential.Expiry) ||
+		got.Configuration.Properties.Core.Project != "my-project" {
+		t.Errorf("readGcloudConfigCache = %+v, want %+v", got, gc)
+	}
+	if got := readGcloudConfigCache(filename, "k1", now.Add(time.Hour-tokenCacheMargin/2)); got != nil {
+		t.Errorf("readGcloudConfigCache near expiry = %+v, want nil", got)
+	}
+	if got := readGcloudConfigCache(filename, "k2", now); got != nil {
+		t.Errorf("readGcloudConfigCache with another key = %+v, want nil", got)
+	}
+}
+
+func TestGcloudStateKey(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CLOUDSDK_CONFIG", dir)
+	for _, env := range []string{"CLOUDSDK_ACTIVE_CONFIG_NAME", "CLOUDSDK_CORE_PROJECT", "CLOUDSDK_CORE_ACCOUNT"} {
+		t.Setenv(env, "")
+	}
+	write := func(name, data string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("active_config", "default")
+	write("configurations/config_default", "[core]\naccount = alice@example.com\nproject = p1\n")
+	write("configurations/config_other", "[core]\naccount = alice@example.com\nproject = p1\n")
+
+	keys := map[string]string{}
+	add := func(state string) {
+		t.Helper()
+		key := gcloudStateKey()
+		for s, k := range keys {
+			if k == key {
+				t.Errorf("gcloud state %q has the same key as %q", state, s)
+			}
+		}
+		keys[state] = key
+	}
+	add("initial")
+	if gcloudStateKey() != keys["initial"] {
+		t.Error("gcloudStateKey changed with no change to the gcloud state")
+	}
+	write("configurations/config_default", "[core]\naccount = alice@example.com\nproject = p2\n")
+	add("config set project")
+	write("configurations/config_default", "[core]\naccount = bob@example.com\nproject = p2\n")
+	add("auth login")
+	write("active_config", "other")
+	add("configurations activate")
+	t.Setenv("CLOUDSDK_CORE_PROJECT", "p3")
+	add("CLOUDSDK_CORE_PROJECT")
+}
+
+type fakeTokenSource struct {
+	tokens []string
+}
+
+func (f *fakeTokenSource) Token() (*oauth2.Token, error) {
+	if len(f.tokens) == 0 {
+		return nil, errors.New("no more tokens")
+	}
+	tok := &oauth2.Token{AccessToken: f.tokens[0]}
+	f.tokens = f.tokens[1:]
+	return tok, nil
+}
+
+func TestRefreshLoggingTokenSource(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	ts := &refreshLoggingTokenSource{src: &fakeTokenSource{tokens: []string{"a", "a", "b"}}, name: "test"}
+	for _, want := range []string{"a", "a", "b"} {
+		tok, err := ts.Token()
+		if err != nil || tok.AccessToken != want {
+			t.Fatalf("Token() = %v, %v, want %s", tok, err, want)
+		}
+	}
+	if _, err := ts.Token(); err == nil {
+		t.Error("Token() did not return the error of its source")
+	}
+	if got := strings.Count(buf.String(), "Refreshed the token from test"); got != 1 {
+		t.Errorf("logged %d refreshes, want 1:\n%s", got, buf.String())
+	}
+}
+
+func TestCredsTokenSource(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "creds.json")
+	if err := os.WriteFile(filename, []byte(`{"type": "unknown"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{filename, filepath.Join(dir, "missing.json")} {
+		if _, err := credsTokenSource(f, func() ([]byte, error) { return os.ReadFile(f) }).Token(); err == nil || !strings.Contains(err.Error(), "reading credentials") {
+			t.Errorf("Token() with credentials %s returned %v", f, err)
+		}
+	}
+}
+
+func TestLoadCredsJSON(t *testing.T) {
+	key := `{"type": "service_account"}`
+	c := &Config{Creds: "-"}
+	if err := c.loadCredsJSON(strings.NewReader(key)); err != nil {
+		t.Fatal(err)
+	}
+	if string(c.CredsJSON) != key || c.credsName != "stdin" {
+		t.Errorf("-creds=- read %q from %s", c.CredsJSON, c.credsName)
+	}
+
+	t.Setenv(credsJSONEnv, key)
+	c = &Config{}
+	if err := c.loadCredsJSON(strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	if string(c.CredsJSON) != key || c.credsName != "$"+credsJSONEnv {
+		t.Errorf("%s read %q from %s", credsJSONEnv, c.CredsJSON, c.credsName)
+	}
+
+	c = &Config{Creds: "key.json"}
+	if err := c.loadCredsJSON(strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	if c.CredsJSON != nil {
+		t.Errorf("-creds=key.json read %q, want the file to be used", c.CredsJSON)
+	}
+}
+
+func TestReadsStdin(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{"lookup", []string{"t", "-stdin"}, true},
+		{"lookup", []string{"t", "r1"}, false},
+		{"analyzekeys", []string{"-"}, true},
+		{"analyzekeys", []string{"keys.csv"}, false},
+		{"import", []string{"t", "/dev/stdin"}, true},
+		{"import", []string{"t", "data.csv"}, false},
+		{"read", []string{"t", "prefix=-"}, false},
+	} {
+		if got := readsStdin(test.name, test.args); got != test.want {
+			t.Errorf("readsStdin(%q, %q) = %t, want %t", test.name, test.args, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+// Tailing of a table's change stream. The bigtable package has no change
+// stream reader, so the data API is used directly: one stream is read per
+// partition, and partitions that are split or merged while reading are
+// followed to their new partitions.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	btpb "cloud.google.com/go/bigtable/apiv2/bigtablepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// changeStreamReader reads all partitions of a table's change stream,
+// printing each change as it arrives.
+type changeStreamReader struct {
+	rpc        btpb.BigtableClient
+	tableName  string
+	appProfile string
+	start      time.Time // zero to start now
+	end        time.Time // zero to read until interrupted
+	// key, if set, limits reading to the partitions containing it.
+	key *string
+
+	mu      sync.Mutex
+	w       io.Writer
+	pending map[string][]*btpb.StreamContinuationToken // by new partition
+	wg      sync.WaitGroup
+	err     error
+}
+
+// partitionName identifies a partition in messages and in r.pending.
+func partitionName(p *btpb.StreamPartition) string {
+	rr := p.GetRowRange()
+	end := string(rr.GetEndKeyOpen())
+	if end == "" {
+		end = "<end>"
+	}
+	return fmt.Sprintf("[%q, %q)", rr.GetStartKeyClosed(), end)
+}
+
+// partitionContains reports whether row key k is in partition p.
+func partitionContains(p *btpb.StreamPartition, k string) bool {
+	rr := p.GetRowRange()
+	end := string(rr.GetEndKeyOpen())
+	return k >= string(rr.GetStartKeyClosed()) && (end == "" || k < end)
+}
+
+// tokensCover reports whether the partitions of tokens together cover p, so
+// that reading p can resume from them.
+func tokensCover(tokens []*btpb.StreamContinuationToken, p *btpb.StreamPartition) bool {
+	sorted := append([]*btpb.StreamContinuationToken(nil), tokens...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return string(sorted[i].GetPartition().GetRowRange().GetStartKeyClosed()) <
+			string(sorted[j].GetPartition().GetRowRange().GetStartKeyClosed())
+	})
+	want := p.GetRowRange()
+	cur, wantEnd := string(want.GetStartKeyClosed()), string(want.GetEndKeyOpen())
+	for _, t := range sorted {
+		rr := t.GetPartition().GetRowRange()
+		if string(rr.GetStartKeyClosed()) > cur {
+			return false
+		}
+		end := string(rr.GetEndKeyOpen())
+		if end == "" {
+			return true
+		}
+		if end > cur {
+			cur = end
+		}
+		if wantEnd != "" && cur >= wantEnd {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *changeStreamReader) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// run reads the change stream until the end time is reached, ctx is done or
+// a stream fails.
+func (r *changeStreamReader) run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	params := "table_name=" + url.QueryEscape(r.tableName)
+	if r.appProfile != "" {
+		params += "&app_profile_id=" + url.QueryEscape(r.appProfile)
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-goog-request-params", params)
+	stream, err := r.rpc.GenerateInitialChangeStreamPartitions(ctx, &btpb.GenerateInitialChangeStreamPartitionsRequest{
+		TableName:    r.tableName,
+		AppProfileId: r.appProfile,
+	})
+	if err != nil {
+		return err
+	}
+	var partitions []*btpb.StreamPartition
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("listing change stream partitions: %v", err)
+		}
+		partitions = append(partitions, res.GetPartition())
+	}
+	r.pending = map[string][]*btpb.StreamContinuationToken{}
+	for _, p := range partitions {
+		if r.key == nil || partitionContains(p, *r.key) {
+			r.startPartition(ctx, cancel, p, nil)
+		}
+	}
+	r.wg.Wait()
+	if ctx.Err() != nil && r.err == nil {
+		return ctx.Err()
+	}
+	return r.err
+}
+
+func (r *changeStreamReader) startPartition(ctx context.Context, cancel func(), p *btpb.StreamPartition, tokens []*btpb.StreamContinuationToken) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		if err := r.readPartition(ctx, cancel, p, tokens); err != nil && ctx.Err() == nil {
+			r.fail(fmt.Errorf("reading partition %s: %v", partitionName(p), err))
+			cancel()
+		}
+	}()
+}
+
+func (r *changeStreamReader) readPartition(ctx context.Context, cancel func(), p *btpb.StreamPartition, tokens []*btpb.StreamContinuationToken) error {
+	req := &btpb.ReadChangeStreamRequest{
+		TableName:    r.tableName,
+		AppProfileId: r.appProfile,
+		Partition:    p,
+	}
+	switch {
+	case len(tokens) > 0:
+		req.StartFrom = &btpb.ReadChangeStreamRequest_ContinuationTokens{
+			ContinuationTokens: &btpb.StreamContinuationTokens{Tokens: tokens},
+		}
+	case !r.start.IsZero():
+		req.StartFrom = &btpb.ReadChangeStreamRequest_StartTime{StartTime: timestamppb.New(r.start)}
+	}
+	if !r.end.IsZero() {
+		req.EndTime = timestamppb.New(r.end)
+	}
+	stream, err := r.rpc.ReadChangeStream(ctx, req)
+	if err != nil {
+		return err
+	}
+	var change *btpb.ReadChangeStreamResponse_DataChange
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch rec := res.StreamRecord.(type) {
+		case *btpb.ReadChangeStreamResponse_DataChange_:
+			change = mergeDataChange(change, rec.DataChange)
+			if rec.DataChange.GetDone() {
+				r.mu.Lock()
+				printDataChange(r.w, change)
+				r.mu.Unlock()
+				change = nil
+			}
+		case *btpb.ReadChangeStreamResponse_CloseStream_:
+			return r.closePartition(ctx, cancel, rec.CloseStream)
+		}
+	}
+}
+
+// closePartition follows a closed partition to its new partitions. A new
+// partition formed by a merge is started once every partition merged into
+// it has closed.
+func (r *changeStreamReader) closePartition(ctx context.Context, cancel func(), cs *btpb.ReadChangeStreamResponse_CloseStream) error {
+	if s := cs.GetStatus(); s.GetCode() != int32(codes.OK) && len(cs.GetContinuationTokens()) == 0 {
+		return fmt.Errorf("stream closed: %s", s.GetMessage())
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, p := range cs.GetNewPartitions() {
+		if r.key != nil && !partitionContains(p, *r.key) {
+			continue
+		}
+		name := partitionName(p)
+		tokens := r.pending[name]
+		if i < len(cs.GetContinuationTokens()) {
+			tokens = append(tokens, cs.GetContinuationTokens()[i])
+		}
+		if !tokensCover(tokens, p) {
+			r.pending[name] = tokens
+			continue
+		}
+		delete(r.pending, name)
+		r.startPartition(ctx, cancel, p, tokens)
+	}
+	return nil
+}
+
+// mergeDataChange adds the chunks of next to a change split across several
+// messages, joining the pieces of chunked cell values.
+func mergeDataChange(change, next *btpb.ReadChangeStreamResponse_DataChange) *btpb.ReadChangeStreamResponse_DataChange {
+	if change == nil {
+		change = &btpb.ReadChangeStreamResponse_DataChange{
+			Type:            next.GetType(),
+			SourceClusterId: next.GetSourceClusterId(),
+			RowKey:          next.GetRowKey(),
+			CommitTimestamp: next.GetCommitTimestamp(),
+			Tiebreaker:      next.GetTiebreaker(),
+		}
+	}
+	for _, chunk := range next.GetChunks() {
+		if chunk.GetChunkInfo().GetChunkedValueOffset() > 0 && len(change.Chunks) > 0 {
+			last := change.Chunks[len(change.Chunks)-1].GetMutation().GetSetCell()
+			if last != nil {
+				last.Value = append(last.Value, chunk.GetMutation().GetSetCell().GetValue()...)
+				continue
+			}
+		}
+		change.Chunks = append(change.Chunks, chunk)
+	}
+	return change
+}
+
+// printDataChange prints a change in the style of 'cbt read'.
+func printDataChange(w io.Writer, change *btpb.ReadChangeStreamResponse_DataChange) {
+	fmt.Fprintln(w, strings.Repeat("-", 40))
+	typ := strings.ToLower(change.GetType().String())
+	if c := change.GetSourceClusterId(); c != "" {
+		typ += " from " + c
+	}
+	fmt.Fprintf(w, "%s  %s @ %s\n", change.GetRowKey(), typ,
+		change.GetCommitTimestamp().AsTime().Local().Format("2006/01/02-15:04:05.000000"))
+	for _, chunk := range change.GetChunks() {
+		fmt.Fprintf(w, "  %s\n", formatChangeMutation(chunk.GetMutation()))
+	}
+}
+
+// formatChangeMutation describes one mutation of a change.
+func formatChangeMutation(m *btpb.Mutation) string {
+	micros := func(ts int64) string {
+		return time.UnixMicro(ts).Local().Format("2006/01/02-15:04:05.000000")
+	}
+	switch mut := m.GetMutation().(type) {
+	case *btpb.Mutation_SetCell_:
+		sc := mut.SetCell
+		return fmt.Sprintf("set %s:%s @ %s = %q", sc.GetFamilyName(), sc.GetColumnQualifier(),
+			micros(sc.GetTimestampMicros()), sc.GetValue())
+	case *btpb.Mutation_AddToCell_:
+		ac := mut.AddToCell
+		return fmt.Sprintf("add %s:%s @ %s += %d", ac.GetFamilyName(), ac.GetColumnQualifier().GetRawValue(),
+			micros(ac.GetTimestamp().GetRawTimestampMicros()), ac.GetInput().GetIntValue())
+	case *btpb.Mutation_DeleteFromColumn_:
+		dc := mut.DeleteFromColumn
+		s := fmt.Sprintf("delete %s:%s", dc.GetFamilyName(), dc.GetColumnQualifier())
+		if tr := dc.GetTimeRange(); tr != nil {
+			s += " from " + micros(tr.GetStartTimestampMicros())
+			if tr.GetEndTimestampMicros() != 0 {
+				s += " to " + micros(tr.GetEndTimestampMicros())
+			}
+		}
+		return s
+	case *btpb.Mutation_DeleteFromFamily_:
+		return "delete family " + mut.DeleteFromFamily.GetFamilyName()
+	case *btpb.Mutation_DeleteFromRow_:
+		return "delete row"
+	default:
+		return fmt.Sprintf("%v", m)
+	}
+}
+
+func doReadChangeStream(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt readchangestream <table-id> [start-time=<timestamp>] [end-time=<timestamp>] " +
+		"[partitions=all|<row-key>] [app-profile=<app-profile-id>]"
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+	parsed, err := parseArgs(args[1:], []string{"start-time", "end-time", "partitions", "app-profile"})
+	if err != nil {
+		return err
+	}
+	rpc, err := e.getDataRPC()
+	if err != nil {
+		return err
+	}
+	r := &changeStreamReader{
+		rpc:        rpc,
+		tableName:  instanceName(e.config.Project, e.config.Instance) + "/tables/" + args[0],
+		appProfile: parsed["app-profile"],
+		w:          e.stdout,
+	}
+	now := time.Now()
+	for arg, t := range map[string]*time.Time{"start-time": &r.start, "end-time": &r.end} {
+		if s := parsed[arg]; s != "" {
+			ts, ok := parseCellTimestamp(s, now)
+			if !ok {
+				return fmt.Errorf("Bad %s %q", arg, s)
+			}
+			*t = ts.Time()
+		}
+	}
+	if p := parsed["partitions"]; p != "" && p != "all" {
+		r.key = &p
+	}
+	if err := r.run(ctx); err != nil {
+		return fmt.Errorf("Reading change stream: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	btpb "cloud.google.com/go/bigtable/apiv2/bigtablepb"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func streamPartition(start, end string) *btpb.StreamPartition {
+	return &btpb.StreamPartition{RowRange: &btpb.RowRange{
+		StartKey: &btpb.RowRange_StartKeyClosed{StartKeyClosed: []byte(start)},
+		EndKey:   &btpb.RowRange_EndKeyOpen{EndKeyOpen: []byte(end)},
+	}}
+}
+
+func setCellChange(row, value string, done bool) *btpb.ReadChangeStreamResponse {
+	return &btpb.ReadChangeStreamResponse{StreamRecord: &btpb.ReadChangeStreamResponse_DataChange_{
+		DataChange: &btpb.ReadChangeStreamResponse_DataChange{
+			Type:            btpb.ReadChangeStreamResponse_DataChange_USER,
+			RowKey:          []byte(row),
+			CommitTimestamp: timestamppb.New(time.Unix(1, 0)),
+			Chunks: []*btpb.ReadChangeStreamResponse_MutationChunk{{
+				Mutation: &btpb.Mutation{Mutation: &btpb.Mutation_SetCell_{SetCell: &btpb.Mutation_SetCell{
+					FamilyName: "f", ColumnQualifier: []byte("c"), Value: []byte(value),
+				}}},
+			}},
+			Done: done,
+		},
+	}}
+}
+
+func closeStream(tokens []*btpb.StreamContinuationToken, partitions ...*btpb.StreamPartition) *btpb.ReadChangeStreamResponse {
+	return &btpb.ReadChangeStreamResponse{StreamRecord: &btpb.ReadChangeStreamResponse_CloseStream_{
+		CloseStream: &btpb.ReadChangeStreamResponse_CloseStream{ContinuationTokens: tokens, NewPartitions: partitions},
+	}}
+}
+
+type fakeResponseStream[T any] struct {
+	grpc.ClientStream
+	res []*T
+}
+
+func (s *fakeResponseStream[T]) Recv() (*T, error) {
+	if len(s.res) == 0 {
+		return nil, io.EOF
+	}
+	res := s.res[0]
+	s.res = s.res[1:]
+	return res, nil
+}
+
+// fakeChangeStreamRPC serves a change stream whose two initial partitions are
+// merged into one.
+type fakeChangeStreamRPC struct {
+	btpb.BigtableClient
+	mu       sync.Mutex
+	requests []string
+}
+
+func (f *fakeChangeStreamRPC) GenerateInitialChangeStreamPartitions(ctx context.Context, req *btpb.GenerateInitialChangeStreamPartitionsRequest, opts ...grpc.CallOption) (btpb.Bigtable_GenerateInitialChangeStreamPartitionsClient, error) {
+	return &fakeResponseStream[btpb.GenerateInitialChangeStreamPartitionsResponse]{res: []*btpb.GenerateInitialChangeStreamPartitionsResponse{
+		{Partition: streamPartition("", "m")},
+		{Partition: streamPartition("m", "")},
+	}}, nil
+}
+
+func (f *fakeChangeStreamRPC) ReadChangeStream(ctx context.Context, req *btpb.ReadChangeStreamRequest, opts ...grpc.CallOption) (btpb.Bigtable_ReadChangeStreamClient, error) {
+	name := partitionName(req.GetPartition())
+	var tokens []string
+	for _, t := range req.GetContinuationTokens().GetTokens() {
+		tokens = append(tokens, t.GetToken())
+	}
+	sort.Strings(tokens)
+	f.mu.Lock()
+	f.requests = append(f.requests, name+" "+strings.Join(tokens, ","))
+	f.mu.Unlock()
+
+	merged := streamPartition("", "")
+	var res []*btpb.ReadChangeStreamResponse
+	switch name {
+	case partitionName(streamPartition("", "m")):
+		res = append(res,
+			setCellChange("a", "one", true),
+			closeStream([]*btpb.StreamContinuationToken{{Partition: streamPartition("", "m"), Token: "t1"}}, merged))
+	case partitionName(streamPartition("m", "")):
+		// The value of this change is split into two chunks.
+		first, last := setCellChange("n", "tw", false), setCellChange("n", "o", true)
+		first.GetDataChange().Chunks[0].ChunkInfo = &btpb.ReadChangeStreamResponse_MutationChunk_ChunkInfo{ChunkedValueSize: 3}
+		last.GetDataChange().Chunks[0].ChunkInfo = &btpb.ReadChangeStreamResponse_MutationChunk_ChunkInfo{ChunkedValueSize: 3, ChunkedValueOffset: 2, LastChunk: true}
+		res = append(res,
+			first,
+			last,
+			closeStream([]*btpb.StreamContinuationToken{{Partition: streamPartition("m", ""), Token: "t2"}}, merged))
+	default:
+		res = append(res, setCellChange("z", "three", true), closeStream(nil))
+	}
+	return &fakeResponseStream[btpb.ReadChangeStreamResponse]{res: res}, nil
+}
+
+func TestReadChangeStream(t *testing.T) {
+	rpc := &fakeChangeStreamRPC{}
+	var buf bytes.Buffer
+	r := &changeStreamReader{rpc: rpc, tableName: "projects/p/instances/i/tables/t", w: &buf}
+	if err := r.run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(rpc.requests)
+	wantRequests := []string{
+		`["", "<end>") t1,t2`,
+		`["", "m") `,
+		`["m", "<end>") `,
+	}
+	if diff := cmp.Diff(wantRequests, rpc.requests); diff != "" {
+		t.Errorf("requests mismatch (-want +got):\n%s", diff)
+	}
+
+	out := buf.String()
+	for _, want := range []string{`set f:c`, `= "one"`, `= "two"`, `= "three"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, strings.Repeat("-", 40)); n != 3 {
+		t.Errorf("printed %d changes, want 3:\n%s", n, out)
+	}
+}
+
+func TestTokensCover(t *testing.T) {
+	token := func(start, end string) *btpb.StreamContinuationToken {
+		return &btpb.StreamContinuationToken{Partition: streamPartition(start, end)}
+	}
+	for _, test := range []struct {
+		tokens []*btpb.StreamContinuationToken
+		p      *btpb.StreamPartition
+		want   bool
+	}{
+		{[]*btpb.StreamContinuationToken{token("", "")}, streamPartition("", ""), true},
+		{[]*btpb.StreamContinuationToken{token("", "m")}, streamPartition("", ""), false},
+		{[]*btpb.StreamContinuationToken{token("m", ""), token("", "m")}, streamPartition("", ""), true},
+		{[]*btpb.StreamContinuationToken{token("a", "c"), token("d", "f")}, streamPartition("a", "f"), false},
+		{[]*btpb.StreamContinuationToken{token("a", "c")}, streamPartition("b", "c"), true},
+	} {
+		if got := tokensCover(test.tokens, test.p); got != test.want {
+			t.Errorf("tokensCover(%v, %s) = %t, want %t", test.tokens, partitionName(test.p), got, test.want)
+		}
+	}
+}
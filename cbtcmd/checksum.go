@@ -0,0 +1,233 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// Row checksums are a polynomial rolling hash over the hashes of the rows in
+// key order, modulo a Mersenne prime. Unlike a plain hash, the digests of
+// adjacent ranges can be combined into the digest of the whole, so the total
+// doesn't depend on how the scan was split.
+const (
+	checksumModulus = 1<<61 - 1
+	checksumBase    = 0x2b1f6e3d5c4a9987 % checksumModulus
+)
+
+// mulMod returns a*b mod checksumModulus for a, b < checksumModulus.
+func mulMod(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return bits.Rem64(hi, lo, checksumModulus)
+}
+
+// powMod returns checksumBase**n mod checksumModulus.
+func powMod(n int64) uint64 {
+	result, base := uint64(1), uint64(checksumBase)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result = mulMod(result, base)
+		}
+		base = mulMod(base, base)
+	}
+	return result
+}
+
+// rowHash hashes the key, and the family, qualifier, timestamp and value of
+// every cell, of r. Each field is length-prefixed so that different rows
+// can't encode to the same bytes.
+func rowHash(r bigtable.Row) uint64 {
+	h := sha256.New()
+	var buf [8]byte
+	writeField := func(b []byte) {
+		binary.BigEndian.PutUint64(buf[:], uint64(len(b)))
+		h.Write(buf[:])
+		h.Write(b)
+	}
+	writeField([]byte(r.Key()))
+	families := make([]string, 0, len(r))
+	for fam := range r {
+		families = append(families, fam)
+	}
+	sort.Strings(families)
+	for _, fam := range families {
+		for _, item := range r[fam] {
+			writeField([]byte(item.Column))
+			binary.BigEndian.PutUint64(buf[:], uint64(item.Timestamp))
+			h.Write(buf[:])
+			writeField(item.Value)
+		}
+	}
+	return binary.BigEndian.Uint64(h.Sum(nil)) % checksumModulus
+}
+
+// rangeChecksum is the checksum of the rows of [Start, End). An empty End is
+// the end of the table.
+type rangeChecksum struct {
+	Start, End string
+	Rows       int64
+	Digest     uint64
+}
+
+func (c *rangeChecksum) add(r bigtable.Row) {
+	c.Digest = (mulMod(c.Digest, checksumBase) + rowHash(r)) % checksumModulus
+	c.Rows++
+}
+
+// combineChecksums returns the checksum of the concatenation of the adjacent
+// ranges cs, as if they had been read in a single scan.
+func combineChecksums(cs []rangeChecksum) rangeChecksum {
+	var total rangeChecksum
+	for i, c := range cs {
+		if i == 0 {
+			total.Start = c.Start
+		}
+		total.End = c.End
+		total.Digest = (mulMod(total.Digest, powMod(c.Rows)) + c.Digest) % checksumModulus
+		total.Rows += c.Rows
+	}
+	return total
+}
+
+// checksumRanges reads the ranges between consecutive bounds with at most
+// workers concurrent scans and returns their checksums.
+func checksumRanges(ctx context.Context, logger *log.Logger, tbl TableReader, bounds []string, workers int, opts []bigtable.ReadOption) ([]rangeChecksum, error) {
+	sums := make([]rangeChecksum, len(bounds)-1)
+	errs := make([]error, len(sums))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i := range sums {
+		sums[i].Start, sums[i].End = bounds[i], bounds[i+1]
+		wg.Add(1)
+		go func(c *rangeChecksum, err *error) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			rd := resumableRead{start: c.Start, end: c.End, opts: opts}
+			*err = rd.run(ctx, logger, tbl, func(r bigtable.Row) bool {
+				c.add(r)
+				return true
+			})
+		}(&sums[i], &errs[i])
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("reading [%q, %q): %v", sums[i].Start, sums[i].End, err)
+		}
+	}
+	return sums, nil
+}
+
+// checksumBounds returns the boundaries of the ranges that [start, end) is
+// checksummed in: the given split keys if any, otherwise up to workers ranges
+// at the table's sample row keys.
+func checksumBounds(ctx context.Context, tbl TableReader, start, end string, splits []string, workers int) ([]string, error) {
+	if len(splits) > 0 {
+		sort.Strings(splits)
+		bounds := []string{start}
+		for _, k := range splits {
+			if k > bounds[len(bounds)-1] && (end == "" || k < end) {
+				bounds = append(bounds, k)
+			}
+		}
+		return append(bounds, end), nil
+	}
+	if workers <= 1 {
+		return []string{start, end}, nil
+	}
+	sampler, ok := tbl.(rowKeySampler)
+	if !ok {
+		return []string{start, end}, nil
+	}
+	keys, err := sampler.SampleRowKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sampling row keys: %v", err)
+	}
+	return exportShards(keys, start, end, workers), nil
+}
+
+func formatRangeKey(key, open string) string {
+	if key == "" {
+		return open
+	}
+	return strconv.Quote(key)
+}
+
+func doChecksum(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt checksum <table-id> [start=<row-key>] [end=<row-key>] [prefix=<row-key-prefix>] [workers=<1>] [splits=<row-key>,...] [app-profile=<app-profile-id>]"
+	args = e.withDefaultTable(args, len(args) == 0 || isOptionArg(args[0], []string{"start", "end", "prefix", "workers", "splits", "app-profile"}))
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+	parsed, err := parseArgs(args[1:], []string{"start", "end", "prefix", "workers", "splits", "app-profile"})
+	if err != nil {
+		return err
+	}
+	start, end := parsed["start"], parsed["end"]
+	if prefix, ok := parsed["prefix"]; ok {
+		if start != "" || end != "" {
+			return errors.New("prefix can't be combined with start or end")
+		}
+		start, end = prefix, prefixEnd(prefix)
+	}
+	workers := 1
+	if v := parsed["workers"]; v != "" {
+		if workers, err = strconv.Atoi(v); err != nil || workers <= 0 {
+			return errors.New("workers must be > 0")
+		}
+	}
+	var splits []string
+	if v := parsed["splits"]; v != "" {
+		splits = strings.Split(v, ",")
+	}
+
+	tbl, err := e.getTable(bigtable.ClientConfig{AppProfile: parsed["app-profile"]}, args[0])
+	if err != nil {
+		return err
+	}
+	bounds, err := checksumBounds(ctx, tbl, start, end, splits, workers)
+	if err != nil {
+		return err
+	}
+	sums, err := checksumRanges(ctx, e.log, tbl, bounds, workers, nil)
+	if err != nil {
+		return fmt.Errorf("Reading rows: %v", err)
+	}
+	total := combineChecksums(sums)
+
+	var rows [][]string
+	for _, c := range sums {
+		rows = append(rows, []string{formatRangeKey(c.Start, "(start)"), formatRangeKey(c.End, "(end)"),
+			strconv.FormatInt(c.Rows, 10), fmt.Sprintf("%016x", c.Digest)})
+	}
+	rows = append(rows, []string{"TOTAL", "", strconv.FormatInt(total.Rows, 10), fmt.Sprintf("%016x", total.Digest)})
+	printTable(e.stdout, 0, []string{"Start", "End", "Rows", "Digest"}, rows)
+	return nil
+}
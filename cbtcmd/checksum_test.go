@@ -0,0 +1,97 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+)
+
+func TestChecksum(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"src", "dst"}, []string{"f", "g"})
+	src, dst := c.Open("src"), c.Open("dst")
+	for i := 0; i < 30; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", 1000, []byte(fmt.Sprint(i)))
+		mut.Set("g", "b", 2000, []byte("x"))
+		if err := src.Apply(ctx, fmt.Sprintf("r%02d", i), mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Write the copy in the opposite order; the digests only depend on the
+	// contents.
+	for i := 29; i >= 0; i-- {
+		mut := bigtable.NewMutation()
+		mut.Set("g", "b", 2000, []byte("x"))
+		mut.Set("f", "a", 1000, []byte(fmt.Sprint(i)))
+		if err := dst.Apply(ctx, fmt.Sprintf("r%02d", i), mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checksum := func(tbl TableReader, bounds []string) rangeChecksum {
+		t.Helper()
+		sums, err := checksumRanges(ctx, discardLog, tbl, bounds, 2, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return combineChecksums(sums)
+	}
+	whole := checksum(src, []string{"", ""})
+	if whole.Rows != 30 {
+		t.Errorf("Rows = %d, want 30", whole.Rows)
+	}
+	if split := checksum(src, []string{"", "r05", "r17", ""}); split != whole {
+		t.Errorf("split checksum = %+v, want %+v", split, whole)
+	}
+	if copied := checksum(dst, []string{"", "r10", ""}); copied.Digest != whole.Digest || copied.Rows != whole.Rows {
+		t.Errorf("checksum of the copy = %+v, want %+v", copied, whole)
+	}
+
+	mut := bigtable.NewMutation()
+	mut.Set("f", "a", 1000, []byte("changed"))
+	if err := dst.Apply(ctx, "r12", mut); err != nil {
+		t.Fatal(err)
+	}
+	sums, err := checksumRanges(ctx, discardLog, dst, []string{"", "r10", "r20", ""}, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := checksumRanges(ctx, discardLog, src, []string{"", "r10", "r20", ""}, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range sums {
+		if differ := sums[i] != want[i]; differ != (i == 1) {
+			t.Errorf("range %d: digests differ = %v, want %v", i, differ, i == 1)
+		}
+	}
+}
+
+func TestChecksumBounds(t *testing.T) {
+	got, err := checksumBounds(context.Background(), nil, "b", "y", []string{"z", "c", "a", "m"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"b", "c", "m", "y"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("checksumBounds = %q, want %q", got, want)
+	}
+}
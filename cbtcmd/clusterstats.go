@@ -0,0 +1,178 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/api/monitoring/v3"
+	"google.golang.org/api/option"
+)
+
+// clusterMetrics are the Cloud Monitoring metrics shown by clusterstats.
+var clusterMetrics = []struct {
+	name, metricType string
+	percent          bool
+}{
+	{"CPU", "bigtable.googleapis.com/cluster/cpu_load", true},
+	{"Storage", "bigtable.googleapis.com/cluster/storage_utilization", true},
+	{"Nodes", "bigtable.googleapis.com/cluster/node_count", false},
+}
+
+// metricSummary summarizes the points of a time series over a window.
+type metricSummary struct {
+	current, min, max float64
+}
+
+// summarizePoints summarizes points, which Cloud Monitoring returns newest
+// first.
+func summarizePoints(points []*monitoring.Point) (metricSummary, bool) {
+	var s metricSummary
+	n := 0
+	for _, p := range points {
+		var v float64
+		switch {
+		case p.Value == nil:
+			continue
+		case p.Value.DoubleValue != nil:
+			v = *p.Value.DoubleValue
+		case p.Value.Int64Value != nil:
+			v = float64(*p.Value.Int64Value)
+		default:
+			continue
+		}
+		if n == 0 {
+			s = metricSummary{current: v, min: v, max: v}
+		}
+		if v < s.min {
+			s.min = v
+		}
+		if v > s.max {
+			s.max = v
+		}
+		n++
+	}
+	return s, n > 0
+}
+
+// fetchClusterStats summarizes the clusterMetrics of the clusters of an
+// instance over the window ending at now, by cluster and metric name.
+func fetchClusterStats(ctx context.Context, svc *monitoring.Service, project, instance string, window time.Duration, now time.Time) (map[string]map[string]metricSummary, error) {
+	stats := map[string]map[string]metricSummary{}
+	for _, m := range clusterMetrics {
+		filter := fmt.Sprintf(`metric.type=%q AND resource.type="bigtable_cluster" AND resource.labels.instance=%q`, m.metricType, instance)
+		call := svc.Projects.TimeSeries.List("projects/" + project).
+			Filter(filter).
+			IntervalStartTime(now.Add(-window).UTC().Format(time.RFC3339)).
+			IntervalEndTime(now.UTC().Format(time.RFC3339))
+		err := call.Pages(ctx, func(res *monitoring.ListTimeSeriesResponse) error {
+			for _, ts := range res.TimeSeries {
+				cluster := ts.Resource.Labels["cluster"]
+				s, ok := summarizePoints(ts.Points)
+				if !ok {
+					continue
+				}
+				if stats[cluster] == nil {
+					stats[cluster] = map[string]metricSummary{}
+				}
+				stats[cluster][m.name] = s
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %v", m.metricType, err)
+		}
+	}
+	return stats, nil
+}
+
+// printClusterStats prints a line per cluster and metric.
+func printClusterStats(w io.Writer, stats map[string]map[string]metricSummary) {
+	var clusters []string
+	for c := range stats {
+		clusters = append(clusters, c)
+	}
+	sort.Strings(clusters)
+	tw := tabwriter.NewWriter(w, 10, 8, 4, '\t', 0)
+	fmt.Fprintf(tw, "Cluster\tMetric\tCurrent\tMin\tMax\n")
+	fmt.Fprintf(tw, "-------\t------\t-------\t---\t---\n")
+	for _, c := range clusters {
+		for _, m := range clusterMetrics {
+			s, ok := stats[c][m.name]
+			if !ok {
+				fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\n", c, m.name)
+				continue
+			}
+			format := func(v float64) string {
+				if m.percent {
+					return fmt.Sprintf("%.1f%%", v*100)
+				}
+				return fmt.Sprintf("%g", v)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c, m.name, format(s.current), format(s.min), format(s.max))
+		}
+	}
+	tw.Flush()
+}
+
+// getMonitoringService returns a client of the Cloud Monitoring API for
+// reading metrics.
+func (e *env) getMonitoringService(ctx context.Context) (*monitoring.Service, error) {
+	opts := []option.ClientOption{
+		option.WithScopes(monitoring.MonitoringReadScope),
+		option.WithUserAgent(e.userAgent),
+	}
+	opts = e.credentialOpts(opts)
+	svc, err := monitoring.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("Making Cloud Monitoring client: %v", err)
+	}
+	return svc, nil
+}
+
+func doClusterStats(ctx context.Context, e *env, args ...string) error {
+	parsed, err := parseArgs(args, []string{"window"})
+	if err != nil {
+		return errors.New("usage: cbt clusterstats [window=<duration>]")
+	}
+	window := time.Hour
+	if w := parsed["window"]; w != "" {
+		if window, err = parseDuration(w); err != nil || window <= 0 {
+			return fmt.Errorf("Bad window %q", w)
+		}
+	}
+	svc, err := e.getMonitoringService(ctx)
+	if err != nil {
+		return err
+	}
+	stats, err := fetchClusterStats(ctx, svc, e.config.Project, e.config.Instance, window, time.Now())
+	if err != nil {
+		return fmt.Errorf("Getting cluster metrics: %v", err)
+	}
+	if len(stats) == 0 {
+		return fmt.Errorf("No metrics were reported for the clusters of %s in the last %v", e.config.Instance, window)
+	}
+	fmt.Fprintf(e.stdout, "Cluster metrics of %s over the last %v\n\n", e.config.Instance, window)
+	printClusterStats(e.stdout, stats)
+	return nil
+}
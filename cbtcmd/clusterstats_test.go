@@ -0,0 +1,81 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/monitoring/v3"
+	"google.golang.org/api/option"
+)
+
+func TestFetchClusterStats(t *testing.T) {
+	var filters []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter := r.URL.Query().Get("filter")
+		filters = append(filters, filter)
+		switch {
+		case strings.Contains(filter, "cpu_load"):
+			fmt.Fprint(w, `{"timeSeries": [
+				{"resource": {"labels": {"cluster": "c1"}}, "points": [
+					{"value": {"doubleValue": 0.25}}, {"value": {"doubleValue": 0.5}}, {"value": {"doubleValue": 0.125}}]},
+				{"resource": {"labels": {"cluster": "c2"}}, "points": [{"value": {"doubleValue": 0.75}}]}]}`)
+		case strings.Contains(filter, "node_count"):
+			fmt.Fprint(w, `{"timeSeries": [
+				{"resource": {"labels": {"cluster": "c1"}}, "points": [{"value": {"int64Value": "3"}}, {"value": {"int64Value": "5"}}]}]}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	svc, err := monitoring.NewService(ctx, option.WithEndpoint(srv.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := fetchClusterStats(ctx, svc, "proj", "inst", time.Hour, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]map[string]metricSummary{
+		"c1": {"CPU": {0.25, 0.125, 0.5}, "Nodes": {3, 3, 5}},
+		"c2": {"CPU": {0.75, 0.75, 0.75}},
+	}
+	if diff := cmp.Diff(want, stats, cmp.AllowUnexported(metricSummary{})); diff != "" {
+		t.Errorf("stats mismatch (-want +got):\n%s", diff)
+	}
+	if len(filters) != 3 || !strings.Contains(filters[0], `resource.labels.instance="inst"`) {
+		t.Errorf("unexpected filters %q", filters)
+	}
+
+	var buf bytes.Buffer
+	printClusterStats(&buf, stats)
+	for _, line := range []string{"c1 CPU 25.0% 12.5% 50.0%", "c1 Storage - - -", "c1 Nodes 3 3 5", "c2 CPU 75.0% 75.0% 75.0%"} {
+		if !strings.Contains(strings.Join(strings.Fields(buf.String()), " "), line) {
+			t.Errorf("output does not contain %q:\n%s", line, buf.String())
+		}
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// ANSI colors of the parts of the output.
+const (
+	colorRowKey    = "\x1b[1;33m"
+	colorColumn    = "\x1b[36m"
+	colorTimestamp = "\x1b[32m"
+	colorError     = "\x1b[31m"
+	colorRemoved   = "\x1b[31m"
+	colorAdded     = "\x1b[32m"
+	colorHunk      = "\x1b[36m"
+	colorReset     = "\x1b[0m"
+)
+
+// useColor decides from the -color mode whether the output to w is colored.
+// In auto mode, it is colored if w is a terminal, unless NO_COLOR is set or
+// TERM is dumb.
+func useColor(mode string, w io.Writer) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "", "auto":
+		return os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" && isTerminal(w), nil
+	}
+	return false, fmt.Errorf("bad -color %q: must be auto, always or never", mode)
+}
+
+// colorize returns s in color if the output of the command is colored.
+func (e *env) colorize(color, s string) string {
+	if !e.color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// colorLog writes log messages to w in the error color.
+type colorLog struct {
+	w io.Writer
+}
+
+func (l colorLog) Write(p []byte) (int, error) {
+	msg := p
+	if n := len(msg); n > 0 && msg[n-1] == '\n' {
+		msg = msg[:n-1]
+	}
+	if _, err := fmt.Fprintf(l.w, "%s%s%s\n", colorError, msg, colorReset); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+)
+
+func TestUseColor(t *testing.T) {
+	// Files that are not terminals.
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, test := range []struct {
+		mode string
+		want bool
+	}{
+		{"always", true},
+		{"never", false},
+		{"auto", false},
+		{"", false},
+	} {
+		got, err := useColor(test.mode, f)
+		if err != nil || got != test.want {
+			t.Errorf("useColor(%q) = %t, %v, want %t", test.mode, got, err, test.want)
+		}
+	}
+	if _, err := useColor("sometimes", f); err == nil {
+		t.Error("useColor with a bad mode did not fail")
+	}
+}
+
+func TestPrintRowColor(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	row := bigtable.Row{"f": {{Row: "my-key", Column: "f:c", Timestamp: 1000, Value: []byte("v")}}}
+	var plain, colored strings.Builder
+	e.printRowAtTimezone(row, &plain, time.UTC)
+	e.color = true
+	e.printRowAtTimezone(row, &colored, time.UTC)
+
+	for _, want := range []string{colorRowKey + "my-key" + colorReset, colorColumn + "f:c", colorTimestamp + "1970/01/01"} {
+		if !strings.Contains(colored.String(), want) {
+			t.Errorf("colored row does not contain %q:\n%q", want, colored.String())
+		}
+	}
+	if strings.Contains(plain.String(), "\x1b[") {
+		t.Errorf("uncolored row contains escapes:\n%q", plain.String())
+	}
+
+	var buf bytes.Buffer
+	if _, err := (colorLog{&buf}).Write([]byte("failed\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), colorError+"failed"+colorReset+"\n"; got != want {
+		t.Errorf("colorLog wrote %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// defaultColStatsSample is the fraction of rows that colstats samples by
+// default.
+const defaultColStatsSample = 0.05
+
+// columnStats are the statistics of one family:qualifier over the sampled
+// rows.
+type columnStats struct {
+	Column          string
+	Rows, Cells     int64
+	Bytes, MaxBytes int64
+	Oldest, Newest  bigtable.Timestamp
+	sawTimestamp    bool
+	lastRow         string
+}
+
+func (s *columnStats) add(row string, item bigtable.ReadItem) {
+	if s.Cells == 0 || s.lastRow != row {
+		s.Rows++
+		s.lastRow = row
+	}
+	s.Cells++
+	n := int64(len(item.Value))
+	s.Bytes += n
+	if n > s.MaxBytes {
+		s.MaxBytes = n
+	}
+	if !s.sawTimestamp || item.Timestamp < s.Oldest {
+		s.Oldest = item.Timestamp
+	}
+	if !s.sawTimestamp || item.Timestamp > s.Newest {
+		s.Newest = item.Timestamp
+	}
+	s.sawTimestamp = true
+}
+
+// collectColumnStats returns the statistics of each column of the rows read
+// by rd, sorted by column, and the number of rows read.
+func collectColumnStats(ctx context.Context, logger *log.Logger, tbl TableReader, rd resumableRead) ([]*columnStats, int64, error) {
+	stats := map[string]*columnStats{}
+	var rows int64
+	err := rd.run(ctx, logger, tbl, func(r bigtable.Row) bool {
+		rows++
+		for _, items := range r {
+			for _, item := range items {
+				s, ok := stats[item.Column]
+				if !ok {
+					s = &columnStats{Column: item.Column}
+					stats[item.Column] = s
+				}
+				s.add(r.Key(), item)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	sorted := make([]*columnStats, 0, len(stats))
+	for _, s := range stats {
+		sorted = append(sorted, s)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Column < sorted[j].Column })
+	return sorted, rows, nil
+}
+
+func formatStatsTimestamp(ts bigtable.Timestamp) string {
+	return ts.Time().UTC().Format(time.RFC3339)
+}
+
+// printColumnStats prints the statistics of each column over rows sampled
+// rows.
+func printColumnStats(w io.Writer, stats []*columnStats, rows int64) {
+	fmt.Fprintf(w, "Sampled rows: %d\n", rows)
+	if len(stats) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	var table [][]string
+	for _, s := range stats {
+		table = append(table, []string{
+			s.Column,
+			strconv.FormatInt(s.Rows, 10),
+			strconv.FormatInt(s.Cells, 10),
+			formatBytes(s.Bytes / s.Cells),
+			formatBytes(s.MaxBytes),
+			formatStatsTimestamp(s.Oldest),
+			formatStatsTimestamp(s.Newest),
+		})
+	}
+	printTable(w, 0, []string{"Column", "Rows", "Cells", "Avg Size", "Max Size", "Oldest", "Newest"}, table)
+}
+
+func doColStats(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt colstats <table-id> [sample=<fraction>] [prefix=<row-key-prefix>] [app-profile=<app-profile-id>]"
+	valid := []string{"sample", "prefix", "app-profile"}
+	args = e.withDefaultTable(args, len(args) == 0 || isOptionArg(args[0], valid))
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+	parsed, err := parseArgs(args[1:], valid)
+	if err != nil {
+		return err
+	}
+	p := defaultColStatsSample
+	if v := parsed["sample"]; v != "" {
+		if p, err = strconv.ParseFloat(v, 64); err != nil || p <= 0 || p > 1 {
+			return fmt.Errorf("Bad sample %q: must be a fraction in (0, 1]", v)
+		}
+	}
+	var rd resumableRead
+	if prefix := parsed["prefix"]; prefix != "" {
+		rd.start, rd.end = prefix, prefixEnd(prefix)
+	}
+	if p < 1 {
+		rd.opts = []bigtable.ReadOption{bigtable.RowFilter(bigtable.RowSampleFilter(p))}
+	}
+
+	tbl, err := e.getTable(bigtable.ClientConfig{AppProfile: parsed["app-profile"]}, args[0])
+	if err != nil {
+		return err
+	}
+	stats, rows, err := collectColumnStats(ctx, e.log, tbl, rd)
+	if err != nil {
+		return fmt.Errorf("Reading rows: %v", err)
+	}
+	printColumnStats(e.stdout, stats, rows)
+	return nil
+}
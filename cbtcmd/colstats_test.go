@@ -0,0 +1,66 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+)
+
+func TestCollectColumnStats(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f", "g"})
+	tbl := c.Open("my-table")
+	for i := 0; i < 10; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", bigtable.Timestamp(1000*(i+2)), []byte("xx"))
+		mut.Set("f", "a", bigtable.Timestamp(1000), []byte("xxxxxx"))
+		if i%2 == 0 {
+			mut.Set("g", "big", 1000, bytes.Repeat([]byte("y"), 100*(i+1)))
+		}
+		if err := tbl.Apply(ctx, fmt.Sprintf("r%d", i), mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, rows, err := collectColumnStats(ctx, discardLog, tbl, resumableRead{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows != 10 || len(stats) != 2 {
+		t.Fatalf("collectColumnStats = %d rows, %d columns; want 10 rows, 2 columns", rows, len(stats))
+	}
+	a, big := stats[0], stats[1]
+	if a.Column != "f:a" || a.Rows != 10 || a.Cells != 20 || a.Bytes != 80 || a.MaxBytes != 6 ||
+		a.Oldest != 1000 || a.Newest != 11000 {
+		t.Errorf("stats of f:a = %+v", a)
+	}
+	if big.Column != "g:big" || big.Rows != 5 || big.Cells != 5 || big.MaxBytes != 900 {
+		t.Errorf("stats of g:big = %+v", big)
+	}
+
+	var buf bytes.Buffer
+	printColumnStats(&buf, stats, rows)
+	for _, want := range []string{"Sampled rows: 10", "f:a", "900 B", "1970-01-01T00:00:00Z"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("printColumnStats output is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
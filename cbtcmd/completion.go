@@ -0,0 +1,311 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/sys/execabs"
+	"google.golang.org/api/iterator"
+)
+
+// completionCacheTTL is how long cached resource names are offered without
+// being refreshed.
+const completionCacheTTL = 5 * time.Minute
+
+// completionKinds are the kinds of resource names that 'cbt completion
+// names' lists.
+var completionKinds = []string{"tables", "families", "app-profiles"}
+
+// completionEntry is a cached list of resource names.
+type completionEntry struct {
+	Names   []string  `json:"names"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// completionCacheFilename returns the file caching the resource names of an
+// instance.
+func completionCacheFilename(project, instance string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cbt", "completion", project, instance+".json"), nil
+}
+
+// readCompletionCache returns the cached entries in filename, or an empty
+// cache if it doesn't exist or can't be read.
+func readCompletionCache(filename string) map[string]completionEntry {
+	cache := map[string]completionEntry{}
+	if data, err := os.ReadFile(filename); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// updateCompletionCache stores names under key in filename. Concurrent
+// completions may refresh the cache at the same time, so the file is
+// replaced atomically.
+func updateCompletionCache(filename, key string, e completionEntry) error {
+	cache := readCompletionCache(filename)
+	cache[key] = e
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(filename), ".completion-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+// completionNames returns the names cached under key. Names cached more than
+// completionCacheTTL before now are still returned, so completion stays fast,
+// but refresh is called to update them in the background. If nothing is
+// cached, the names are fetched and cached.
+func completionNames(logger *log.Logger, filename, key string, now time.Time, fetch func() ([]string, error), refresh func()) ([]string, error) {
+	if e, ok := readCompletionCache(filename)[key]; ok {
+		if now.Sub(e.Fetched) > completionCacheTTL {
+			refresh()
+		}
+		return e.Names, nil
+	}
+	names, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	if err := updateCompletionCache(filename, key, completionEntry{Names: names, Fetched: now}); err != nil {
+		logger.Printf("Caching completions: %v", err)
+	}
+	return names, nil
+}
+
+// fetchCompletionNames lists the resource names of kind from the admin API.
+// Families are those of table.
+func (e *env) fetchCompletionNames(ctx context.Context, kind, table string) ([]string, error) {
+	var names []string
+	switch kind {
+	case "tables":
+		ac, err := e.getAdminClient()
+		if err != nil {
+			return nil, err
+		}
+		tables, err := ac.Tables(ctx)
+		if err != nil {
+			return nil, err
+		}
+		names = tables
+	case "families":
+		ac, err := e.getAdminClient()
+		if err != nil {
+			return nil, err
+		}
+		ti, err := ac.TableInfo(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		names = ti.Families
+	case "app-profiles":
+		iac, err := e.getInstanceAdminClient()
+		if err != nil {
+			return nil, err
+		}
+		it := iac.ListAppProfiles(ctx, e.config.Instance)
+		for {
+			profile, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, path.Base(profile.Name))
+		}
+	default:
+		return nil, fmt.Errorf("unknown kind %q: must be one of %s", kind, strings.Join(completionKinds, ", "))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// completionCommandNames returns the names of the commands to complete, and
+// of those that take a table as their first argument.
+func completionCommandNames() (all, tableFirst []string) {
+	for _, cmd := range commands {
+		all = append(all, cmd.Name)
+		if strings.HasPrefix(cmd.Usage, "cbt "+cmd.Name+" <table") {
+			tableFirst = append(tableFirst, cmd.Name)
+		}
+	}
+	return all, tableFirst
+}
+
+var bashCompletionTemplate = template.Must(template.New("bash").Parse(`# bash completion for cbt. Load it with:
+#   source <(cbt completion bash)
+
+_cbt() {
+	local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"
+	local i cmd="" cmdpos=0 key="" kind="" table=""
+	local -a flags=()
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		case "${COMP_WORDS[i]}" in
+		-*) flags+=("${COMP_WORDS[i]}") ;;
+		*) cmd="${COMP_WORDS[i]}"; cmdpos=$i; break ;;
+		esac
+	done
+	if [[ -z "$cmd" ]]; then
+		COMPREPLY=($(compgen -W "{{.Commands}}" -- "$cur"))
+		return
+	fi
+	if [[ "$cur" == "=" ]]; then
+		key="$prev"
+		cur=""
+	elif [[ "$prev" == "=" ]]; then
+		key="${COMP_WORDS[COMP_CWORD-2]}"
+	fi
+	case "$key" in
+	app-profile) kind=app-profiles ;;
+	columns|display|hide)
+		kind=families
+		table="${COMP_WORDS[cmdpos+1]}"
+		;;
+	"")
+		if ((COMP_CWORD == cmdpos + 1)) && [[ " {{.TableCommands}} " == *" $cmd "* ]]; then
+			kind=tables
+		fi
+		;;
+	esac
+	[[ -n "$kind" ]] || return
+	local names
+	names=$(cbt "${flags[@]}" completion names "$kind" ${table:+table="$table"} 2>/dev/null) || return
+	COMPREPLY=($(compgen -W "$names" -- "$cur"))
+}
+
+complete -F _cbt cbt
+`))
+
+func doCompletionReal(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt completion bash | cbt completion names <tables|families|app-profiles> [table=<table-id>] [refresh=<true|false>]"
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "bash":
+		all, tableFirst := completionCommandNames()
+		return bashCompletionTemplate.Execute(e.stdout, map[string]string{
+			"Commands":      strings.Join(all, " "),
+			"TableCommands": strings.Join(tableFirst, " "),
+		})
+	case "names":
+	default:
+		return errors.New(usage)
+	}
+
+	if len(args) < 2 {
+		return errors.New(usage)
+	}
+	kind := args[1]
+	parsed, err := parseArgs(args[2:], []string{"table", "refresh"})
+	if err != nil {
+		return errors.New(usage)
+	}
+	if !stringInSlice(kind, completionKinds) {
+		return fmt.Errorf("Unknown kind %q: must be one of %s", kind, strings.Join(completionKinds, ", "))
+	}
+	table := parsed["table"]
+	if kind == "families" && table == "" {
+		return errors.New("Completing families needs table=<table-id>")
+	}
+	if e.config.Project == "" || e.config.Instance == "" {
+		return errors.New("Completing names needs a project and an instance")
+	}
+	filename, err := completionCacheFilename(e.config.Project, e.config.Instance)
+	if err != nil {
+		return err
+	}
+	key := kind
+	if table != "" {
+		key += "/" + table
+	}
+	fetch := func() ([]string, error) { return e.fetchCompletionNames(ctx, kind, table) }
+
+	if parsed["refresh"] == "true" {
+		names, err := fetch()
+		if err != nil {
+			return fmt.Errorf("Listing %s: %v", kind, err)
+		}
+		if err := updateCompletionCache(filename, key, completionEntry{Names: names, Fetched: time.Now()}); err != nil {
+			return fmt.Errorf("Caching completions: %v", err)
+		}
+		return nil
+	}
+	// Refresh stale names in a separate process, so that the shell gets the
+	// cached names right away.
+	refresh := func() {
+		exe, err := os.Executable()
+		if err != nil {
+			return
+		}
+		cmdArgs := append(configFlagArgs(e.config), "completion", "names", kind, "refresh=true")
+		if table != "" {
+			cmdArgs = append(cmdArgs, "table="+table)
+		}
+		execabs.Command(exe, cmdArgs...).Start()
+	}
+	names, err := completionNames(e.log, filename, key, time.Now(), fetch, refresh)
+	if err != nil {
+		return fmt.Errorf("Listing %s: %v", kind, err)
+	}
+	for _, name := range names {
+		fmt.Fprintln(e.stdout, name)
+	}
+	return nil
+}
+
+// configFlagArgs returns the flags that select the project, instance and
+// credentials of this invocation, to pass on to a child cbt process.
+func configFlagArgs(cfg *Config) []string {
+	args := []string{"-project=" + cfg.Project, "-instance=" + cfg.Instance}
+	// Credentials read from stdin can't be passed on.
+	if cfg.Creds != "" && cfg.Creds != "-" {
+		args = append(args, "-creds="+cfg.Creds)
+	}
+	return args
+}
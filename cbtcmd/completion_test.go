@@ -0,0 +1,83 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompletionNames(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cache", "instance.json")
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	fetches, refreshes := 0, 0
+	fetch := func() ([]string, error) {
+		fetches++
+		return []string{"t1", "t2"}, nil
+	}
+	refresh := func() { refreshes++ }
+
+	for _, test := range []struct {
+		now                      time.Time
+		wantFetches, wantRefresh int
+	}{
+		// Nothing is cached, so the names are fetched.
+		{now, 1, 0},
+		// The cached names are fresh.
+		{now.Add(completionCacheTTL / 2), 1, 0},
+		// The cached names are stale: they are returned, and refreshed.
+		{now.Add(2 * completionCacheTTL), 1, 1},
+	} {
+		names, err := completionNames(discardLog, filename, "tables", test.now, fetch, refresh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(names, " ") != "t1 t2" {
+			t.Errorf("completionNames = %v, want [t1 t2]", names)
+		}
+		if fetches != test.wantFetches || refreshes != test.wantRefresh {
+			t.Errorf("at %v: %d fetches and %d refreshes, want %d and %d", test.now, fetches, refreshes, test.wantFetches, test.wantRefresh)
+		}
+	}
+
+	// Other keys are cached alongside.
+	if err := updateCompletionCache(filename, "families/t1", completionEntry{Names: []string{"f"}, Fetched: now}); err != nil {
+		t.Fatal(err)
+	}
+	cache := readCompletionCache(filename)
+	if len(cache) != 2 || len(cache["tables"].Names) != 2 || cache["families/t1"].Names[0] != "f" {
+		t.Errorf("readCompletionCache = %v", cache)
+	}
+
+	failing := func() ([]string, error) { return nil, errors.New("boom") }
+	if _, err := completionNames(discardLog, filename, "app-profiles", now, failing, refresh); err == nil {
+		t.Error("completionNames with a failing fetch succeeded")
+	}
+}
+
+func TestCompletionCommandNames(t *testing.T) {
+	all, tableFirst := completionCommandNames()
+	if len(all) != len(commands) {
+		t.Errorf("completionCommandNames returned %d commands, want %d", len(all), len(commands))
+	}
+	if !stringInSlice("read", tableFirst) || stringInSlice("ls", tableFirst) {
+		t.Errorf("table commands = %v, want read and not ls", tableFirst)
+	}
+}
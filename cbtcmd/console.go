@@ -0,0 +1,122 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"runtime"
+	"strings"
+
+	"golang.org/x/sys/execabs"
+)
+
+// consoleBaseURL is the Cloud Console page of Cloud Bigtable.
+const consoleBaseURL = "https://console.cloud.google.com/bigtable"
+
+// consolePages are the pages that 'cbt console' links to, with whether the
+// page is of a table.
+var consolePages = map[string]bool{
+	"instance":      false,
+	"tables":        false,
+	"monitoring":    false,
+	"table":         true,
+	"keyvisualizer": true,
+}
+
+// consoleURL returns the Cloud Console URL of a page of an instance, or of
+// one of its tables.
+func consoleURL(project, instance, page, table string) (string, error) {
+	tablePage, ok := consolePages[page]
+	if !ok {
+		return "", fmt.Errorf("unknown page %q", page)
+	}
+	if tablePage && table == "" {
+		return "", fmt.Errorf("the %s page needs a table", page)
+	}
+	path := "/instances/" + url.PathEscape(instance)
+	switch page {
+	case "instance":
+		path += "/overview"
+	case "tables":
+		path += "/tables"
+	case "monitoring":
+		path += "/monitoring"
+	case "table":
+		path += "/tables/" + url.PathEscape(table) + "/overview"
+	case "keyvisualizer":
+		path += "/tables/" + url.PathEscape(table) + "/key-visualizer"
+	}
+	return consoleBaseURL + path + "?project=" + url.QueryEscape(project), nil
+}
+
+// openBrowser opens u in the user's browser.
+func openBrowser(u string) error {
+	var cmd *execabs.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = execabs.Command("open", u)
+	case "windows":
+		cmd = execabs.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		cmd = execabs.Command("xdg-open", u)
+	}
+	return cmd.Start()
+}
+
+func doConsole(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt console [instance|tables|monitoring|table|keyvisualizer] [table=<table-id>] [-open]"
+	page, open := "", false
+	var rest []string
+	for i, arg := range args {
+		switch {
+		case arg == "-open" || arg == "--open":
+			open = true
+		case i == 0 && !strings.Contains(arg, "="):
+			page = arg
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	parsed, err := parseArgs(rest, []string{"table"})
+	if err != nil {
+		return errors.New(usage)
+	}
+	table := parsed["table"]
+	if page == "" {
+		page = "instance"
+		if table != "" {
+			page = "table"
+		}
+	}
+	if table == "" && consolePages[page] && e.config.Table != "" {
+		table = e.config.Table
+	}
+	u, err := consoleURL(e.config.Project, e.config.Instance, page, table)
+	if err != nil {
+		return fmt.Errorf("%v\n%s", err, usage)
+	}
+	fmt.Fprintln(e.stdout, u)
+	if open {
+		if err := openBrowser(u); err != nil {
+			return fmt.Errorf("Opening a browser: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import "testing"
+
+func TestConsoleURL(t *testing.T) {
+	const base = "https://console.cloud.google.com/bigtable/instances/my-instance"
+	for _, test := range []struct {
+		page, table, want string
+	}{
+		{"instance", "", base + "/overview?project=my-project"},
+		{"tables", "", base + "/tables?project=my-project"},
+		{"monitoring", "", base + "/monitoring?project=my-project"},
+		{"table", "my-table", base + "/tables/my-table/overview?project=my-project"},
+		{"keyvisualizer", "my table", base + "/tables/my%20table/key-visualizer?project=my-project"},
+	} {
+		got, err := consoleURL("my-project", "my-instance", test.page, test.table)
+		if err != nil || got != test.want {
+			t.Errorf("consoleURL(%q, %q) = %q, %v, want %q", test.page, test.table, got, err, test.want)
+		}
+	}
+	for _, page := range []string{"table", "nosuchpage"} {
+		if _, err := consoleURL("my-project", "my-instance", page, ""); err == nil {
+			t.Errorf("consoleURL(%q) without a table did not fail", page)
+		}
+	}
+}
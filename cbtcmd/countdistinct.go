@@ -0,0 +1,187 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+	"cloud.google.com/go/cbt/cli"
+)
+
+// defaultDistinctExactLimit is the number of distinct values counted exactly
+// before countdistinct switches to an approximate count.
+const defaultDistinctExactLimit = 1000000
+
+// hllPrecision is the number of hash bits that select a HyperLogLog
+// register. 2^14 registers give a standard error of about 0.8%.
+const hllPrecision = 14
+
+// hyperLogLog approximates the number of distinct values added to it.
+type hyperLogLog struct {
+	registers [1 << hllPrecision]uint8
+}
+
+// hashValue returns a 64-bit hash of v with well-mixed bits.
+func hashValue(v []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(v)
+	// FNV's high bits are poorly mixed, so finish with the splitmix64 mixer.
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func (h *hyperLogLog) add(v []byte) {
+	x := hashValue(v)
+	i := x >> (64 - hllPrecision)
+	rank := uint8(bits.LeadingZeros64(x<<hllPrecision|1<<(hllPrecision-1))) + 1
+	if rank > h.registers[i] {
+		h.registers[i] = rank
+	}
+}
+
+func (h *hyperLogLog) estimate() int64 {
+	m := float64(len(h.registers))
+	var sum float64
+	zeros := 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	e := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		// Linear counting is more accurate for small cardinalities.
+		e = m * math.Log(m/float64(zeros))
+	}
+	return int64(e + 0.5)
+}
+
+// distinctCounter counts distinct values exactly until it has seen more than
+// limit of them, then approximately.
+type distinctCounter struct {
+	limit  int
+	values map[string]struct{}
+	hll    *hyperLogLog
+}
+
+func newDistinctCounter(limit int) *distinctCounter {
+	return &distinctCounter{limit: limit, values: map[string]struct{}{}}
+}
+
+func (c *distinctCounter) add(v []byte) {
+	if c.hll != nil {
+		c.hll.add(v)
+		return
+	}
+	c.values[string(v)] = struct{}{}
+	if len(c.values) > c.limit {
+		c.hll = &hyperLogLog{}
+		for v := range c.values {
+			c.hll.add([]byte(v))
+		}
+		c.values = nil
+	}
+}
+
+// count returns the number of distinct values, and whether it is exact.
+func (c *distinctCounter) count() (n int64, exact bool) {
+	if c.hll != nil {
+		return c.hll.estimate(), false
+	}
+	return int64(len(c.values)), true
+}
+
+// countDistinct counts the distinct latest values of column in [start, end)
+// of tbl, and the rows that have the column.
+func countDistinct(ctx context.Context, logger *log.Logger, tbl TableReader, column, start, end string, limit int) (rows int64, c *distinctCounter, err error) {
+	colFilter, err := cli.ParseColumnFilter(column)
+	if err != nil {
+		return 0, nil, err
+	}
+	c = newDistinctCounter(limit)
+	rd := resumableRead{start: start, end: end, opts: []bigtable.ReadOption{
+		bigtable.RowFilter(bigtable.ChainFilters(colFilter, bigtable.LatestNFilter(1))),
+	}}
+	err = rd.run(ctx, logger, tbl, func(r bigtable.Row) bool {
+		for _, items := range r {
+			for _, item := range items {
+				c.add(item.Value)
+			}
+		}
+		rows++
+		return true
+	})
+	return rows, c, err
+}
+
+func doCountDistinct(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt countdistinct <table-id> <family>:<qualifier> [prefix=<row-key-prefix>] [exact-limit=<n>] [app-profile=<app-profile-id>]"
+	valid := []string{"prefix", "exact-limit", "app-profile"}
+	args = e.withDefaultTable(args, len(args) == 1 || len(args) > 1 && isOptionArg(args[1], valid))
+	if len(args) < 2 {
+		return errors.New(usage)
+	}
+	column := args[1]
+	if fam, qual, ok := strings.Cut(column, ":"); !ok || fam == "" || qual == "" {
+		return fmt.Errorf("Bad column %q: must be <family>:<qualifier>\n%s", column, usage)
+	}
+	parsed, err := parseArgs(args[2:], valid)
+	if err != nil {
+		return err
+	}
+	limit := defaultDistinctExactLimit
+	if v := parsed["exact-limit"]; v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 0 {
+			return fmt.Errorf("Bad exact-limit %q: must be a number >= 0", v)
+		}
+	}
+	var start, end string
+	if prefix := parsed["prefix"]; prefix != "" {
+		start, end = prefix, prefixEnd(prefix)
+	}
+
+	tbl, err := e.getTable(bigtable.ClientConfig{AppProfile: parsed["app-profile"]}, args[0])
+	if err != nil {
+		return err
+	}
+	rows, c, err := countDistinct(ctx, e.log, tbl, column, start, end, limit)
+	if err != nil {
+		return fmt.Errorf("Reading rows: %v", err)
+	}
+	fmt.Fprintf(e.stdout, "Rows with %s: %d\n", column, rows)
+	if n, exact := c.count(); exact {
+		fmt.Fprintf(e.stdout, "Distinct values: %d\n", n)
+	} else {
+		fmt.Fprintf(e.stdout, "Distinct values: ~%d (approximate, more than %d distinct values)\n", n, limit)
+	}
+	return nil
+}
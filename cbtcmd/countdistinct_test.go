@@ -0,0 +1,78 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+)
+
+func TestCountDistinct(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	tbl := c.Open("my-table")
+	for i := 0; i < 40; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "os", 1000, []byte(fmt.Sprint("os-", i%7)))
+		mut.Set("f", "other", 1000, []byte(fmt.Sprint(i)))
+		if i < 10 {
+			// An older value of the column isn't counted.
+			mut.Set("f", "os", 500, []byte("old"))
+		}
+		if err := tbl.Apply(ctx, fmt.Sprintf("r%02d", i), mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mut := bigtable.NewMutation()
+	mut.Set("f", "other", 1000, []byte("x"))
+	if err := tbl.Apply(ctx, "without-os", mut); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, counter, err := countDistinct(ctx, discardLog, tbl, "f:os", "", "", defaultDistinctExactLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, exact := counter.count(); rows != 40 || n != 7 || !exact {
+		t.Errorf("countDistinct = %d rows, %d distinct (exact %v); want 40 rows, 7 distinct (exact)", rows, n, exact)
+	}
+
+	rows, counter, err = countDistinct(ctx, discardLog, tbl, "f:os", "r0", "r1", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, exact := counter.count(); rows != 10 || n != 7 || exact {
+		t.Errorf("countDistinct past the limit = %d rows, %d distinct (exact %v); want 10 rows, ~7 distinct (approximate)", rows, n, exact)
+	}
+}
+
+func TestHyperLogLog(t *testing.T) {
+	for _, n := range []int{1000, 100000, 1000000} {
+		var h hyperLogLog
+		for i := 0; i < n; i++ {
+			h.add([]byte(fmt.Sprint("value-", i)))
+			// Duplicates don't count.
+			h.add([]byte(fmt.Sprint("value-", i/2)))
+		}
+		got := h.estimate()
+		if errRate := math.Abs(float64(got-int64(n))) / float64(n); errRate > 0.03 {
+			t.Errorf("estimate of %d distinct values = %d, off by %.1f%%", n, got, 100*errRate)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/execabs"
+)
+
+// Imports too large to write from a single client can be run by the
+// Google-provided Dataflow templates, which are launched and monitored with
+// gcloud.
+
+const (
+	dataflowDefaultRegion              = "us-central1"
+	dataflowSequenceFileImportTemplate = "GCS_SequenceFile_to_Cloud_Bigtable"
+)
+
+// dataflowPollInterval is how often a launched job's state is checked.
+var dataflowPollInterval = 30 * time.Second
+
+// runSDKTool runs a Cloud SDK command line tool and returns its trimmed
+// standard output.
+func runSDKTool(ctx context.Context, tool string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := execabs.CommandContext(ctx, tool, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// Name the command, skipping leading global flags.
+		command := args[0]
+		for _, a := range args {
+			if !strings.HasPrefix(a, "-") {
+				command = a
+				break
+			}
+		}
+		return "", fmt.Errorf("%s %s: %v: %s", tool, command, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// runGcloud runs gcloud and returns its trimmed standard output.
+var runGcloud = func(ctx context.Context, args ...string) (string, error) {
+	return runSDKTool(ctx, "gcloud", args...)
+}
+
+var dataflowJobNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// dataflowJobName returns a valid, unique-enough job name for an import.
+func dataflowJobName(table string, now time.Time) string {
+	name := dataflowJobNameChars.ReplaceAllString(strings.ToLower(table), "-")
+	return fmt.Sprintf("cbt-import-%s-%d", strings.Trim(name, "-"), now.Unix())
+}
+
+// dataflowParameters formats template parameters for gcloud, switching to an
+// alternate delimiter if a value contains a comma.
+func dataflowParameters(params [][2]string) string {
+	sep := ","
+	var kvs []string
+	for _, p := range params {
+		if strings.Contains(p[1], ",") {
+			sep = "~"
+		}
+		kvs = append(kvs, p[0]+"="+p[1])
+	}
+	if sep == "," {
+		return strings.Join(kvs, sep)
+	}
+	return "^" + sep + "^" + strings.Join(kvs, sep)
+}
+
+// dataflowImportArgs returns the gcloud arguments launching an import of
+// input into table of the instance of cfg.
+func dataflowImportArgs(cfg *Config, table, input string, ia importerArgs, now time.Time) []string {
+	region := ia.region
+	if region == "" {
+		region = dataflowDefaultRegion
+	}
+	params := [][2]string{
+		{"bigtableProject", cfg.Project},
+		{"bigtableInstanceId", cfg.Instance},
+		{"bigtableTableId", table},
+		{"sourcePattern", input},
+	}
+	if ia.appProfile != "" {
+		params = append(params, [2]string{"bigtableAppProfileId", ia.appProfile})
+	}
+	return []string{
+		"dataflow", "jobs", "run", dataflowJobName(table, now),
+		"--project=" + cfg.Project,
+		"--region=" + region,
+		"--gcs-location=gs://dataflow-templates-" + region + "/latest/" + dataflowSequenceFileImportTemplate,
+		"--staging-location=" + ia.gcsTemp,
+		"--parameters=" + dataflowParameters(params),
+		"--format=value(id)",
+	}
+}
+
+// validateDataflowImport checks the arguments of an engine=dataflow import.
+func validateDataflowImport(input string, ia importerArgs) error {
+	if ia.format != "hbase-sequencefile" {
+		return fmt.Errorf("engine=dataflow requires format=hbase-sequencefile; there is no Google-provided template for CSV imports")
+	}
+	if !strings.HasPrefix(input, "gs://") {
+		return fmt.Errorf("engine=dataflow requires an input file pattern in Cloud Storage (gs://...)")
+	}
+	if !strings.HasPrefix(ia.gcsTemp, "gs://") {
+		return fmt.Errorf("engine=dataflow requires gcs-temp=gs://<bucket>/<path>")
+	}
+	return nil
+}
+
+// runDataflowImport launches an import job and waits for it to finish.
+func runDataflowImport(ctx context.Context, e *env, table, input string, ia importerArgs) error {
+	if err := validateDataflowImport(input, ia); err != nil {
+		return err
+	}
+	region := ia.region
+	if region == "" {
+		region = dataflowDefaultRegion
+	}
+	id, err := runGcloud(ctx, dataflowImportArgs(e.config, table, input, ia, time.Now())...)
+	if err != nil {
+		return err
+	}
+	e.log.Printf("Launched Dataflow job %s: https://console.cloud.google.com/dataflow/jobs/%s/%s?project=%s\n", id, region, id, e.config.Project)
+
+	last := ""
+	for {
+		state, err := runGcloud(ctx, "dataflow", "jobs", "describe", id,
+			"--project="+e.config.Project, "--region="+region, "--format=value(currentState)")
+		if err != nil {
+			return err
+		}
+		if state != last {
+			e.log.Printf("Dataflow job %s: %s\n", id, state)
+			last = state
+		}
+		switch state {
+		case "JOB_STATE_DONE":
+			return nil
+		case "JOB_STATE_FAILED", "JOB_STATE_CANCELLED", "JOB_STATE_DRAINED", "JOB_STATE_UPDATED":
+			return fmt.Errorf("Dataflow job %s ended in state %s", id, state)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(dataflowPollInterval):
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDataflowImportArgs(t *testing.T) {
+	cfg := &Config{Project: "my-project", Instance: "my-instance"}
+
+	ia := importerArgs{appProfile: "batch", format: "hbase-sequencefile", engine: "dataflow", gcsTemp: "gs://b/tmp", region: "europe-west1"}
+	got := dataflowImportArgs(cfg, "My_Table", "gs://b/export/part-*", ia, time.Unix(1700000000, 0))
+	want := []string{
+		"dataflow", "jobs", "run", "cbt-import-my-table-1700000000",
+		"--project=my-project",
+		"--region=europe-west1",
+		"--gcs-location=gs://dataflow-templates-europe-west1/latest/GCS_SequenceFile_to_Cloud_Bigtable",
+		"--staging-location=gs://b/tmp",
+		"--parameters=bigtableProject=my-project,bigtableInstanceId=my-instance,bigtableTableId=My_Table," +
+			"sourcePattern=gs://b/export/part-*,bigtableAppProfileId=batch",
+		"--format=value(id)",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("dataflowImportArgs mismatch (-want +got):\n%s", diff)
+	}
+
+	if got, want := dataflowParameters([][2]string{{"a", "1"}, {"b", "x,y"}}), "^~^a=1~b=x,y"; got != want {
+		t.Errorf("dataflowParameters = %q, want %q", got, want)
+	}
+
+	for _, bad := range []struct {
+		input string
+		ia    importerArgs
+	}{
+		{"gs://b/in", importerArgs{format: "csv", gcsTemp: "gs://b/tmp"}},
+		{"local.seq", importerArgs{format: "hbase-sequencefile", gcsTemp: "gs://b/tmp"}},
+		{"gs://b/in", importerArgs{format: "hbase-sequencefile"}},
+	} {
+		if err := validateDataflowImport(bad.input, bad.ia); err == nil {
+			t.Errorf("validateDataflowImport(%q, %+v) did not fail", bad.input, bad.ia)
+		}
+	}
+}
+
+func TestRunDataflowImport(t *testing.T) {
+	cfg := &Config{Project: "my-project", Instance: "my-instance"}
+	defer func(f func(context.Context, ...string) (string, error), d time.Duration) {
+		runGcloud, dataflowPollInterval = f, d
+	}(runGcloud, dataflowPollInterval)
+	dataflowPollInterval = time.Millisecond
+
+	for _, test := range []struct {
+		states  []string
+		wantErr bool
+	}{
+		{[]string{"JOB_STATE_PENDING", "JOB_STATE_RUNNING", "JOB_STATE_DONE"}, false},
+		{[]string{"JOB_STATE_RUNNING", "JOB_STATE_FAILED"}, true},
+	} {
+		states := test.states
+		var calls []string
+		runGcloud = func(ctx context.Context, args ...string) (string, error) {
+			calls = append(calls, strings.Join(args[:3], " "))
+			if args[2] == "run" {
+				return "job-1", nil
+			}
+			state := states[0]
+			states = states[1:]
+			return state, nil
+		}
+		ia := importerArgs{format: "hbase-sequencefile", engine: "dataflow", gcsTemp: "gs://b/tmp"}
+		e := newEnv(cfg, Deps{Stdout: io.Discard, Stderr: io.Discard})
+		err := runDataflowImport(context.Background(), e, "t", "gs://b/in", ia)
+		if (err != nil) != test.wantErr {
+			t.Errorf("runDataflowImport with states %v: err = %v, wantErr %v", test.states, err, test.wantErr)
+		}
+		if len(calls) != len(test.states)+1 {
+			t.Errorf("runDataflowImport made gcloud calls %q", calls)
+		}
+	}
+}
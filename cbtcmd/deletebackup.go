@@ -0,0 +1,193 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// deleteBackupOptions are the options of deleterow and deletecolumn that save
+// the cells before they are deleted.
+var deleteBackupOptions = []string{"backup-before-delete", "backup-file", "backup-table"}
+
+// deleteBackupHelp documents deleteBackupOptions in the usage of deleterow and
+// deletecolumn.
+const deleteBackupHelp = "  backup-before-delete=<true|false>   Read the cells and save them before deleting them, to\n" +
+	"                                      " + deleteBackupDir + "/<table-id>-<time>.json by default\n" +
+	"  backup-file=<file.json>             Save the cells to this JSON file; implies backup-before-delete=true\n" +
+	"  backup-table=<table-id>             Copy the cells, with their timestamps, to the same row of this table,\n" +
+	"                                      which must have the same families; implies backup-before-delete=true\n\n" +
+	"  The JSON file holds the table, row key, deletion time and the family, qualifier, timestamp in\n" +
+	"  microseconds and base64 encoded value of each cell. Cells written between the backup and\n" +
+	"  the delete are not saved.\n\n"
+
+// deleteBackupDir is where backup-before-delete saves cells when no
+// backup-file or backup-table is given.
+const deleteBackupDir = "~/.cbt/deleted"
+
+// deletedRow is the JSON form of the cells saved by backup-before-delete.
+type deletedRow struct {
+	Table   string        `json:"table"`
+	Row     string        `json:"row"`
+	Deleted time.Time     `json:"deleted"`
+	Cells   []deletedCell `json:"cells"`
+}
+
+// deletedCell is a saved cell. TS is in microseconds, and Value is base64
+// encoded.
+type deletedCell struct {
+	Family    string `json:"family"`
+	Qualifier string `json:"qualifier"`
+	TS        int64  `json:"ts"`
+	Value     []byte `json:"value"`
+}
+
+// deleteBackup says where to save cells before deleting them. Both fields are
+// empty if they are not saved.
+type deleteBackup struct {
+	file, table string
+}
+
+// parseDeleteBackup returns where the backup options in parsed save the cells
+// of table. backup-file and backup-table imply backup-before-delete=true.
+func parseDeleteBackup(parsed map[string]string, table string, now time.Time) (deleteBackup, error) {
+	b := deleteBackup{file: parsed["backup-file"], table: parsed["backup-table"]}
+	if b.file != "" && b.table != "" {
+		return deleteBackup{}, fmt.Errorf("only one of backup-file and backup-table can be set")
+	}
+	on := b.file != "" || b.table != ""
+	if v, ok := parsed["backup-before-delete"]; ok {
+		set, err := strconv.ParseBool(v)
+		if err != nil {
+			return deleteBackup{}, fmt.Errorf("bad backup-before-delete %q: %v", v, err)
+		}
+		if !set && on {
+			return deleteBackup{}, fmt.Errorf("backup-file and backup-table require backup-before-delete=true")
+		}
+		on = set
+	}
+	if on && b.file == "" && b.table == "" {
+		b.file = filepath.Join(deleteBackupDir, fmt.Sprintf("%s-%s.json", table, now.UTC().Format("20060102T150405.000000Z")))
+	}
+	return b, nil
+}
+
+// enabled reports whether cells are saved before they are deleted.
+func (b deleteBackup) enabled() bool {
+	return b.file != "" || b.table != ""
+}
+
+// deletedColumnFilter selects the cells of a column, within [start, end) if either
+// is set, as deletecolumn deletes them.
+func deletedColumnFilter(family, column string, start, end bigtable.Timestamp) bigtable.Filter {
+	filters := []bigtable.Filter{
+		bigtable.FamilyFilter("^" + regexp.QuoteMeta(family) + "$"),
+		bigtable.ColumnFilter("^" + regexp.QuoteMeta(column) + "$"),
+	}
+	if start != 0 || end != 0 {
+		filters = append(filters, bigtable.TimestampRangeFilterMicros(start, end))
+	}
+	return bigtable.ChainFilters(filters...)
+}
+
+// readDeletedCells reads the cells of row that a delete is about to remove,
+// selected by filter if it is not nil.
+func readDeletedCells(ctx context.Context, tbl bigtable.TableAPI, row string, filter bigtable.Filter) ([]deletedCell, error) {
+	var opts []bigtable.ReadOption
+	if filter != nil {
+		opts = append(opts, bigtable.RowFilter(filter))
+	}
+	r, err := tbl.ReadRow(ctx, row, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var cells []deletedCell
+	forEachCell(r, func(fam, qual string, item bigtable.ReadItem) error {
+		cells = append(cells, deletedCell{Family: fam, Qualifier: qual, TS: int64(item.Timestamp), Value: item.Value})
+		return nil
+	})
+	return cells, nil
+}
+
+// writeDeletedRow writes d as JSON to a new file at path, readable only by the
+// user.
+func writeDeletedRow(path string, d deletedRow) error {
+	path, err := expandHome(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(f, d); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// copyDeletedCells writes cells to row of the shadow table dst, keeping their
+// timestamps.
+func copyDeletedCells(ctx context.Context, dst bigtable.TableAPI, row string, cells []deletedCell) error {
+	mut := bigtable.NewMutation()
+	for _, c := range cells {
+		mut.Set(c.Family, c.Qualifier, bigtable.Timestamp(c.TS), c.Value)
+	}
+	return dst.Apply(ctx, row, mut)
+}
+
+// save saves the cells of row selected by filter from tbl, the table named
+// table, before they are deleted.
+func (b deleteBackup) save(ctx context.Context, e *env, tbl bigtable.TableAPI, table, row string, filter bigtable.Filter, appProfile string) error {
+	cells, err := readDeletedCells(ctx, tbl, row, filter)
+	if err != nil {
+		return fmt.Errorf("reading the cells to back up: %v", err)
+	}
+	if len(cells) == 0 {
+		e.log.Printf("Row %q has no cells to back up", row)
+		return nil
+	}
+	if b.table != "" {
+		dst, err := e.openTableAPI(appProfile, b.table, "")
+		if err != nil {
+			return err
+		}
+		if err := copyDeletedCells(ctx, dst, row, cells); err != nil {
+			return fmt.Errorf("copying %d cells to %s: %v", len(cells), b.table, err)
+		}
+		e.log.Printf("Copied %d cells of row %q to table %s", len(cells), row, b.table)
+		return nil
+	}
+	d := deletedRow{Table: table, Row: row, Deleted: time.Now().UTC(), Cells: cells}
+	if err := writeDeletedRow(b.file, d); err != nil {
+		return fmt.Errorf("backing up %d cells: %v", len(cells), err)
+	}
+	e.log.Printf("Saved %d cells of row %q to %s", len(cells), row, b.file)
+	return nil
+}
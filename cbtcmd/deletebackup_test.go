@@ -0,0 +1,118 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDeleteBackup(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 30, 0, 0, time.UTC)
+	for _, test := range []struct {
+		parsed  map[string]string
+		want    deleteBackup
+		wantErr bool
+	}{
+		{parsed: map[string]string{}},
+		{parsed: map[string]string{"backup-before-delete": "false"}},
+		{
+			parsed: map[string]string{"backup-before-delete": "true"},
+			want:   deleteBackup{file: filepath.Join(deleteBackupDir, "my-table-20260501T093000.000000Z.json")},
+		},
+		{parsed: map[string]string{"backup-file": "row.json"}, want: deleteBackup{file: "row.json"}},
+		{parsed: map[string]string{"backup-table": "shadow"}, want: deleteBackup{table: "shadow"}},
+		{parsed: map[string]string{"backup-before-delete": "maybe"}, wantErr: true},
+		{parsed: map[string]string{"backup-before-delete": "false", "backup-file": "row.json"}, wantErr: true},
+		{parsed: map[string]string{"backup-file": "row.json", "backup-table": "shadow"}, wantErr: true},
+	} {
+		got, err := parseDeleteBackup(test.parsed, "my-table", now)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseDeleteBackup(%v) error = %v, want error %t", test.parsed, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseDeleteBackup(%v) = %+v, want %+v", test.parsed, got, test.want)
+		}
+	}
+}
+
+func TestDeleteWithBackup(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table", "shadow"}, []string{"f1", "f2"})
+	e, _ := newTestEnv(Deps{Client: c})
+
+	tbl := c.Open("my-table")
+	mut := bigtable.NewMutation()
+	for _, ts := range []bigtable.Timestamp{1000, 2000} {
+		mut.Set("f1", "a", ts, []byte("x"))
+		mut.Set("f2", "b", ts, []byte{0, 1})
+	}
+	for _, row := range []string{"r1", "r2"} {
+		if err := tbl.Apply(ctx, row, mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "r1.json")
+	if err := doDeleteRow(ctx, e, "my-table", "r1", "backup-file="+file); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved deletedRow
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	wantCells := []deletedCell{
+		{Family: "f1", Qualifier: "a", TS: 2000, Value: []byte("x")},
+		{Family: "f1", Qualifier: "a", TS: 1000, Value: []byte("x")},
+		{Family: "f2", Qualifier: "b", TS: 2000, Value: []byte{0, 1}},
+		{Family: "f2", Qualifier: "b", TS: 1000, Value: []byte{0, 1}},
+	}
+	if saved.Table != "my-table" || saved.Row != "r1" || saved.Deleted.IsZero() {
+		t.Errorf("saved row = %q of %q at %v, want r1 of my-table", saved.Row, saved.Table, saved.Deleted)
+	}
+	if diff := cmp.Diff(wantCells, saved.Cells); diff != "" {
+		t.Errorf("saved cells mismatch (-want +got):\n%s", diff)
+	}
+	if r, err := tbl.ReadRow(ctx, "r1"); err != nil || len(r) != 0 {
+		t.Errorf("r1 after deleterow = %v, %v, want no cells", r, err)
+	}
+
+	if err := doDeleteColumn(ctx, e, "my-table", "r2", "f2", "b", "from=2000", "backup-table=shadow"); err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.Open("shadow").ReadRow(ctx, "r2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bigtable.Row{"f2": {{Row: "r2", Column: "f2:b", Timestamp: 2000, Value: []byte{0, 1}}}}
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Errorf("shadow row mismatch (-want +got):\n%s", diff)
+	}
+	if r, err := tbl.ReadRow(ctx, "r2", bigtable.RowFilter(bigtable.FamilyFilter("f2"))); err != nil || len(r["f2"]) != 1 {
+		t.Errorf("r2 after deletecolumn = %v, %v, want one f2 cell", r, err)
+	}
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	btapb "cloud.google.com/go/bigtable/admin/apiv2/adminpb"
+	"cloud.google.com/go/cbt/cli"
+	lroauto "cloud.google.com/go/longrunning/autogen"
+	"google.golang.org/api/option"
+	gtransport "google.golang.org/api/transport/grpc"
+	"google.golang.org/grpc"
+)
+
+// TableReader is the part of *bigtable.Table that lookup and the commands
+// reading single rows use.
+type TableReader interface {
+	ReadRows(ctx context.Context, arg bigtable.RowSet, f func(bigtable.Row) bool, opts ...bigtable.ReadOption) (err error)
+	ReadRow(context.Context, string, ...bigtable.ReadOption) (bigtable.Row, error)
+}
+
+// AdminAPI is the part of *bigtable.AdminClient that commands use.
+type AdminAPI interface {
+	Close() error
+	Tables(ctx context.Context) ([]string, error)
+	TableInfo(ctx context.Context, table string) (*bigtable.TableInfo, error)
+	CreateTable(ctx context.Context, table string) error
+	CreateTableFromConf(ctx context.Context, conf *bigtable.TableConf) error
+	DeleteTable(ctx context.Context, table string) error
+	DropAllRows(ctx context.Context, table string) error
+	WaitForReplication(ctx context.Context, table string) error
+	CreateColumnFamily(ctx context.Context, table, family string) error
+	CreateColumnFamilyWithConfig(ctx context.Context, table, family string, config bigtable.Family) error
+	DeleteColumnFamily(ctx context.Context, table, family string) error
+	UpdateFamily(ctx context.Context, table, familyName string, family bigtable.Family, opts ...bigtable.UpdateFamilyOption) error
+	SetGCPolicy(ctx context.Context, table, family string, policy bigtable.GCPolicy) error
+	SetGCPolicyWithOptions(ctx context.Context, table, family string, policy bigtable.GCPolicy, opts ...bigtable.GCPolicyOption) error
+	Backups(ctx context.Context, cluster string) *bigtable.BackupIterator
+	BackupInfo(ctx context.Context, cluster, backup string) (*bigtable.BackupInfo, error)
+	CreateBackup(ctx context.Context, table, cluster, backup string, expireTime time.Time) error
+	CopyBackup(ctx context.Context, sourceCluster, sourceBackup, destProject, destInstance, destCluster, destBackup string, expireTime time.Time) error
+	UpdateBackup(ctx context.Context, cluster, backup string, expireTime time.Time) error
+	DeleteBackup(ctx context.Context, cluster, backup string) error
+	RestoreTable(ctx context.Context, table, cluster, backup string) error
+	RestoreTableFrom(ctx context.Context, sourceInstance, table, sourceCluster, backup string) error
+}
+
+// InstanceAdminAPI is the part of *bigtable.InstanceAdminClient that
+// commands use.
+type InstanceAdminAPI interface {
+	Close() error
+	Instances(ctx context.Context) ([]*bigtable.InstanceInfo, error)
+	InstanceInfo(ctx context.Context, instanceID string) (*bigtable.InstanceInfo, error)
+	CreateInstanceWithClusters(ctx context.Context, conf *bigtable.InstanceWithClustersConfig) error
+	DeleteInstance(ctx context.Context, instanceID string) error
+	Clusters(ctx context.Context, instanceID string) ([]*bigtable.ClusterInfo, error)
+	CreateCluster(ctx context.Context, conf *bigtable.ClusterConfig) error
+	UpdateCluster(ctx context.Context, instanceID, clusterID string, serveNodes int32) error
+	DeleteCluster(ctx context.Context, instanceID, clusterID string) error
+	ListAppProfiles(ctx context.Context, instanceID string) *bigtable.ProfileIterator
+	GetAppProfile(ctx context.Context, instanceID, name string) (*btapb.AppProfile, error)
+	CreateAppProfile(ctx context.Context, profile bigtable.ProfileConf) (*btapb.AppProfile, error)
+	UpdateAppProfile(ctx context.Context, instanceID, profileID string, updateAttrs bigtable.ProfileAttrsToUpdate) error
+	DeleteAppProfile(ctx context.Context, instanceID, name string) error
+}
+
+// Deps are the clients and output streams that RunCommand runs a command
+// with. Clients left nil are made from the Config when the command needs
+// them, and closed when it ends; the clients in Deps are left open.
+type Deps struct {
+	Client *bigtable.Client
+	// Table, if set, is read by the commands that read single rows, such as
+	// lookup, instead of a table opened with Client.
+	Table               TableReader
+	AdminClient         AdminAPI
+	InstanceAdminClient InstanceAdminAPI
+	// Stdout gets the output of the command, and Stderr its log messages.
+	// They default to os.Stdout and os.Stderr.
+	Stdout, Stderr io.Writer
+}
+
+func (d Deps) hasClients() bool {
+	return d.Client != nil || d.Table != nil || d.AdminClient != nil || d.InstanceAdminClient != nil
+}
+
+// ExitError is the error of a command that reports its result with an exit
+// status other than 1, such as exists when the row is missing.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// env is what a command runs with: the configuration, the clients and the
+// output of one run.
+type env struct {
+	config *Config
+	// stdout gets the output of the command, and log its messages.
+	stdout io.Writer
+	log    *log.Logger
+	// color is set if stdout is colored.
+	color     bool
+	userAgent string
+	// formatting formats the cell values printed by read, lookup and diffrow.
+	formatting cli.ValueFormatting
+	// writeStats is set when a write command was run with include-stats.
+	writeStats *mutationStats
+
+	// deps holds the clients given to the run, which aren't closed.
+	deps                Deps
+	client              *bigtable.Client
+	table               TableReader
+	adminClient         AdminAPI
+	instanceAdminClient InstanceAdminAPI
+	// dataConn, adminConn and operations serve the requests that the
+	// bigtable package has no methods for.
+	dataConn   gtransport.ConnPool
+	adminConn  gtransport.ConnPool
+	operations *lroauto.OperationsClient
+}
+
+// newEnv returns the env of a command run with cfg and deps.
+func newEnv(cfg *Config, deps Deps) *env {
+	if deps.Stdout == nil {
+		deps.Stdout = os.Stdout
+	}
+	if deps.Stderr == nil {
+		deps.Stderr = os.Stderr
+	}
+	return &env{
+		config:              cfg,
+		stdout:              deps.Stdout,
+		log:                 log.New(deps.Stderr, "", log.LstdFlags),
+		userAgent:           cliUserAgent,
+		formatting:          cli.NewValueFormatting(),
+		deps:                deps,
+		client:              deps.Client,
+		table:               deps.Table,
+		adminClient:         deps.AdminClient,
+		instanceAdminClient: deps.InstanceAdminClient,
+	}
+}
+
+// close closes the clients made for the run.
+func (e *env) close() {
+	if e.client != nil && e.client != e.deps.Client {
+		e.client.Close()
+	}
+	if e.adminClient != nil && e.adminClient != e.deps.AdminClient {
+		e.adminClient.Close()
+	}
+	if e.instanceAdminClient != nil && e.instanceAdminClient != e.deps.InstanceAdminClient {
+		e.instanceAdminClient.Close()
+	}
+	if e.dataConn != nil {
+		e.dataConn.Close()
+	}
+	if e.adminConn != nil {
+		e.adminConn.Close()
+	}
+}
+
+// forInstance returns the env of a run of a command on another instance of the
+// project, whose output goes to stdout and whose log messages are prefixed
+// with the instance. It shares the instance admin client of e, and its other
+// clients are its own.
+func (e *env) forInstance(instance string, stdout io.Writer) *env {
+	cfg := *e.config
+	cfg.Instance = instance
+	ie := newEnv(&cfg, Deps{
+		InstanceAdminClient: e.instanceAdminClient,
+		Stdout:              stdout,
+		Stderr:              e.log.Writer(),
+	})
+	ie.log.SetFlags(e.log.Flags() | log.Lmsgprefix)
+	ie.log.SetPrefix(instance + ": ")
+	ie.color = e.color
+	ie.userAgent = e.userAgent
+	return ie
+}
+
+func (e *env) credentialOpts(opts []option.ClientOption) []option.ClientOption {
+	// The universe domain selects the default endpoints, and must match the
+	// universe of the credentials.
+	if ud := e.config.UniverseDomain; ud != "" {
+		opts = append(opts, option.WithUniverseDomain(ud))
+	}
+	if ts := e.config.TokenSource; ts != nil {
+		opts = append(opts, option.WithTokenSource(ts))
+	}
+	if tlsCreds := e.config.TLSCreds; tlsCreds != nil {
+		opts = append(opts, option.WithGRPCDialOption(grpc.WithTransportCredentials(tlsCreds)))
+	}
+	return e.proxyOpts(opts)
+}
+
+func (e *env) getClient(clientConf bigtable.ClientConfig) (*bigtable.Client, error) {
+	if e.client == nil {
+		var opts []option.ClientOption
+		if ep := e.config.DataEndpoint; ep != "" {
+			opts = append(opts, option.WithEndpoint(ep))
+		}
+		opts = append(opts, option.WithUserAgent(e.userAgent))
+		opts = append(opts, e.mutationStatsOptions()...)
+		opts = workloadTagOpts(e.config, opts)
+		opts = e.credentialOpts(opts)
+		c, err := bigtable.NewClientWithConfig(context.Background(), e.config.Project, e.config.Instance, clientConf, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("Making bigtable.Client: %v", err)
+		}
+		e.client = c
+	}
+	return e.client, nil
+}
+
+func (e *env) getTable(clientConf bigtable.ClientConfig, tableName string) (TableReader, error) {
+	if e.table == nil {
+		c, err := e.getClient(clientConf)
+		if err != nil {
+			return nil, err
+		}
+		e.table = c.Open(tableName)
+	}
+	return e.table, nil
+}
+
+// openTableAPI opens a table, or the named authorized view of it, for data
+// requests using appProfile.
+func (e *env) openTableAPI(appProfile, tableID, authorizedView string) (bigtable.TableAPI, error) {
+	c, err := e.getClient(bigtable.ClientConfig{AppProfile: appProfile})
+	if err != nil {
+		return nil, err
+	}
+	if authorizedView != "" {
+		return c.OpenAuthorizedView(tableID, authorizedView), nil
+	}
+	return c.OpenTable(tableID), nil
+}
+
+func (e *env) adminClientOpts() []option.ClientOption {
+	var opts []option.ClientOption
+	if ep := e.config.AdminEndpoint; ep != "" {
+		opts = append(opts, option.WithEndpoint(ep))
+	}
+	opts = append(opts, option.WithUserAgent(e.userAgent))
+	opts = workloadTagOpts(e.config, opts)
+	return e.credentialOpts(opts)
+}
+
+func (e *env) getAdminClient() (AdminAPI, error) {
+	if e.adminClient == nil {
+		ac, err := bigtable.NewAdminClient(context.Background(), e.config.Project, e.config.Instance, e.adminClientOpts()...)
+		if err != nil {
+			return nil, fmt.Errorf("Making bigtable.AdminClient: %v", err)
+		}
+		e.adminClient = ac
+	}
+	return e.adminClient, nil
+}
+
+// getAdminClientForInstance returns an AdminClient for an instance other
+// than the configured one. Callers are responsible for closing it.
+func (e *env) getAdminClientForInstance(ctx context.Context, instance string) (AdminAPI, error) {
+	if instance == e.config.Instance {
+		return e.getAdminClient()
+	}
+	return bigtable.NewAdminClient(ctx, e.config.Project, instance, e.adminClientOpts()...)
+}
+
+func (e *env) getInstanceAdminClient() (InstanceAdminAPI, error) {
+	if e.instanceAdminClient == nil {
+		var opts []option.ClientOption
+		if ep := e.config.AdminEndpoint; ep != "" {
+			opts = append(opts, option.WithEndpoint(ep))
+		}
+		opts = append(opts, option.WithUserAgent(e.userAgent))
+		opts = workloadTagOpts(e.config, opts)
+		opts = e.credentialOpts(opts)
+		iac, err := bigtable.NewInstanceAdminClient(context.Background(), e.config.Project, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("Making bigtable.InstanceAdminClient: %v", err)
+		}
+		e.instanceAdminClient = iac
+	}
+	return e.instanceAdminClient, nil
+}
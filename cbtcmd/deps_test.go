@@ -0,0 +1,141 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeAdmin is an AdminAPI with tables and no data. Methods it doesn't
+// implement panic.
+type fakeAdmin struct {
+	AdminAPI
+	tables  []string
+	dropped []string
+}
+
+func (f *fakeAdmin) Tables(ctx context.Context) ([]string, error) {
+	return f.tables, nil
+}
+
+func (f *fakeAdmin) DropAllRows(ctx context.Context, table string) error {
+	for _, t := range f.tables {
+		if t == table {
+			f.dropped = append(f.dropped, table)
+			return nil
+		}
+	}
+	return errors.New("table not found")
+}
+
+// discardLog gets the messages of the helpers that tests call directly.
+var discardLog = log.New(io.Discard, "", 0)
+
+// newTestEnv returns the env of a command run on my-instance of my-project
+// with the clients of deps, and the buffer that gets its output. Its log
+// messages are discarded unless deps has a Stderr.
+func newTestEnv(deps Deps) (*env, *bytes.Buffer) {
+	var out bytes.Buffer
+	deps.Stdout = &out
+	if deps.Stderr == nil {
+		deps.Stderr = io.Discard
+	}
+	return newEnv(&Config{Project: "my-project", Instance: "my-instance"}, deps), &out
+}
+
+func TestRunCommand(t *testing.T) {
+	cfg := &Config{Project: "my-project", Instance: "my-instance"}
+	ac := &fakeAdmin{tables: []string{"t2", "t1"}}
+
+	for _, test := range []struct {
+		args        []string
+		wantOut     string
+		wantErr     string
+		wantDropped []string
+	}{
+		{args: []string{"ls"}, wantOut: "t1\nt2\n"},
+		{args: []string{"deleteallrows", "t1"}, wantDropped: []string{"t1"}},
+		{args: []string{"deleteallrows", "t3"}, wantErr: "Deleting all rows: table not found"},
+		{args: []string{"deleteallrows"}, wantErr: "Can't do `cbt deleteallrows []`"},
+		{args: []string{"nosuchcommand"}, wantErr: `Unknown command "nosuchcommand"`},
+	} {
+		ac.dropped = nil
+		var out, logged bytes.Buffer
+		err := RunCommand(cfg, test.args, Deps{AdminClient: ac, Stdout: &out, Stderr: &logged})
+		if gotErr := ""; err != nil {
+			gotErr = err.Error()
+			if gotErr != test.wantErr {
+				t.Errorf("RunCommand(%q) = %v, want error %q", test.args, err, test.wantErr)
+			}
+		} else if test.wantErr != "" {
+			t.Errorf("RunCommand(%q) succeeded, want error %q", test.args, test.wantErr)
+		}
+		if got := out.String(); got != test.wantOut {
+			t.Errorf("RunCommand(%q) printed %q, want %q", test.args, got, test.wantOut)
+		}
+		if got := logged.String(); got != "" {
+			t.Errorf("RunCommand(%q) logged %q, want nothing", test.args, got)
+		}
+		if diff := cmp.Diff(test.wantDropped, ac.dropped); diff != "" {
+			t.Errorf("RunCommand(%q) dropped rows of (-want +got):\n%s", test.args, diff)
+		}
+	}
+}
+
+func TestRunCommandExitStatus(t *testing.T) {
+	_, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	var out bytes.Buffer
+	err := RunCommand(&Config{}, []string{"exists", "my-table", "missing"}, Deps{Client: c, Stdout: &out})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 {
+		t.Errorf("exists of a missing row = %v, want exit status 3", err)
+	}
+	if got := exitStatus(err); got != 3 {
+		t.Errorf("exitStatus(%v) = %d, want 3", err, got)
+	}
+}
+
+func TestRunCommandConcurrent(t *testing.T) {
+	cfg := &Config{Project: "my-project", Instance: "my-instance"}
+	var wg sync.WaitGroup
+	outs := make([]bytes.Buffer, 8)
+	for i := range outs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ac := &fakeAdmin{tables: []string{fmt.Sprintf("t%d", i)}}
+			if err := RunCommand(cfg, []string{"ls"}, Deps{AdminClient: ac, Stdout: &outs[i]}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i := range outs {
+		if got, want := outs[i].String(), fmt.Sprintf("t%d\n", i); got != want {
+			t.Errorf("concurrent ls %d printed %q, want %q", i, got, want)
+		}
+	}
+}
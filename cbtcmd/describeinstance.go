@@ -0,0 +1,236 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+	btapb "cloud.google.com/go/bigtable/admin/apiv2/adminpb"
+	"google.golang.org/api/iterator"
+)
+
+// instanceDescription is everything describeinstance prints about an
+// instance.
+type instanceDescription struct {
+	Name        string            `json:"name"`
+	DisplayName string            `json:"displayName"`
+	State       string            `json:"state"`
+	Type        string            `json:"type"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	// CMEK is "enabled" if every cluster is encrypted with a customer-managed
+	// key, "partial" if some are, and "disabled" otherwise.
+	CMEK        string               `json:"cmek"`
+	Tables      int                  `json:"tables"`
+	Clusters    []clusterDescription `json:"clusters"`
+	AppProfiles []appProfileInfo     `json:"appProfiles"`
+}
+
+type clusterDescription struct {
+	Name        string `json:"name"`
+	Zone        string `json:"zone"`
+	State       string `json:"state"`
+	ServeNodes  int    `json:"serveNodes"`
+	Storage     string `json:"storage"`
+	Autoscaling string `json:"autoscaling,omitempty"`
+	KMSKeyName  string `json:"kmsKeyName,omitempty"`
+}
+
+// clusterStorage returns the storage type of a cluster.
+func clusterStorage(ci *bigtable.ClusterInfo) string {
+	if ci.StorageType == bigtable.HDD {
+		return "HDD"
+	}
+	return "SSD"
+}
+
+// clusterAutoscaling summarizes the autoscaling limits and target of a
+// cluster, or returns "" if it has a fixed number of nodes.
+func clusterAutoscaling(ci *bigtable.ClusterInfo) string {
+	a := ci.AutoscalingConfig
+	if a == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d nodes, %d%% CPU", a.MinNodes, a.MaxNodes, a.CPUTargetPercent)
+}
+
+// appProfileID returns the ID of an app profile, the last part of its name.
+func appProfileID(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+func newInstanceDescription(info *bigtable.InstanceInfo, clusters []*bigtable.ClusterInfo, profiles []*btapb.AppProfile, tables int) instanceDescription {
+	d := instanceDescription{
+		Name:        info.Name,
+		DisplayName: info.DisplayName,
+		State:       btapb.Instance_State(info.InstanceState).String(),
+		Type:        btapb.Instance_Type(info.InstanceType).String(),
+		Labels:      info.Labels,
+		Tables:      tables,
+		Clusters:    []clusterDescription{},
+		AppProfiles: []appProfileInfo{},
+	}
+	encrypted := 0
+	for _, ci := range clusters {
+		if ci.KMSKeyName != "" {
+			encrypted++
+		}
+		d.Clusters = append(d.Clusters, clusterDescription{
+			Name:        ci.Name,
+			Zone:        ci.Zone,
+			State:       ci.State,
+			ServeNodes:  ci.ServeNodes,
+			Storage:     clusterStorage(ci),
+			Autoscaling: clusterAutoscaling(ci),
+			KMSKeyName:  ci.KMSKeyName,
+		})
+	}
+	sort.Slice(d.Clusters, func(i, j int) bool { return d.Clusters[i].Name < d.Clusters[j].Name })
+	switch {
+	case encrypted == 0:
+		d.CMEK = "disabled"
+	case encrypted == len(clusters):
+		d.CMEK = "enabled"
+	default:
+		d.CMEK = "partial"
+	}
+	for _, p := range profiles {
+		info := describeAppProfile(p)
+		info.Name = appProfileID(info.Name)
+		d.AppProfiles = append(d.AppProfiles, info)
+	}
+	return d
+}
+
+func printInstanceDescription(w io.Writer, d instanceDescription) {
+	var labels []string
+	for k, v := range d.Labels {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+	if len(labels) == 0 {
+		labels = []string{"-"}
+	}
+	encrypted := 0
+	for _, c := range d.Clusters {
+		if c.KMSKeyName != "" {
+			encrypted++
+		}
+	}
+	fmt.Fprintf(w, "Instance: %s (%s)\n", d.Name, d.DisplayName)
+	fmt.Fprintf(w, "State: %s\n", d.State)
+	fmt.Fprintf(w, "Type: %s\n", d.Type)
+	fmt.Fprintf(w, "Labels: %s\n", strings.Join(labels, ", "))
+	fmt.Fprintf(w, "Tables: %d\n", d.Tables)
+	fmt.Fprintf(w, "CMEK: %s (%d of %d clusters)\n", d.CMEK, encrypted, len(d.Clusters))
+
+	fmt.Fprintf(w, "\nClusters:\n")
+	var rows [][]string
+	for _, c := range d.Clusters {
+		autoscaling := c.Autoscaling
+		if autoscaling == "" {
+			autoscaling = "-"
+		}
+		kms := c.KMSKeyName
+		if kms == "" {
+			kms = "-"
+		}
+		rows = append(rows, []string{c.Name, c.Zone, c.State, strconv.Itoa(c.ServeNodes), c.Storage, autoscaling, kms})
+	}
+	printTable(w, 0, []string{"Cluster", "Zone", "State", "Nodes", "Storage", "Autoscaling", "KMS Key"}, rows)
+
+	fmt.Fprintf(w, "\nApp profiles:\n")
+	rows = nil
+	for _, p := range d.AppProfiles {
+		rows = append(rows, []string{p.Name, p.routingSummary(), p.isolationSummary(), p.Description})
+	}
+	printTable(w, 0, []string{"AppProfile", "Routing", "Isolation", "Description"}, rows)
+}
+
+// describeInstance fetches the description of an instance.
+func (e *env) describeInstance(ctx context.Context, instance string) (instanceDescription, error) {
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return instanceDescription{}, err
+	}
+	info, err := iac.InstanceInfo(ctx, instance)
+	if err != nil {
+		return instanceDescription{}, fmt.Errorf("getting instance: %v", err)
+	}
+	clusters, err := iac.Clusters(ctx, instance)
+	if err != nil {
+		return instanceDescription{}, fmt.Errorf("getting clusters: %v", err)
+	}
+	var profiles []*btapb.AppProfile
+	it := iac.ListAppProfiles(ctx, instance)
+	for {
+		p, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return instanceDescription{}, fmt.Errorf("getting app profiles: %v", err)
+		}
+		profiles = append(profiles, p)
+	}
+	ac, err := e.getAdminClientForInstance(ctx, instance)
+	if err != nil {
+		return instanceDescription{}, err
+	}
+	if instance != e.config.Instance {
+		defer ac.Close()
+	}
+	tables, err := ac.Tables(ctx)
+	if err != nil {
+		return instanceDescription{}, fmt.Errorf("getting tables: %v", err)
+	}
+	return newInstanceDescription(info, clusters, profiles, len(tables)), nil
+}
+
+func doDescribeInstance(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt describeinstance [instance-id] [format=<text|json>]"
+	instance := e.config.Instance
+	if len(args) > 0 && !isOptionArg(args[0], []string{"format"}) && !strings.HasPrefix(args[0], "-") {
+		instance, args = args[0], args[1:]
+	}
+	if instance == "" {
+		return errors.New(usage)
+	}
+	parsed, err := parseFormatArgs(args, []string{"format"})
+	if err != nil {
+		return errors.New(usage)
+	}
+	format := parsed["format"]
+	if format != "" && format != "text" && format != "json" {
+		return fmt.Errorf("Bad format %q: must be text or json", format)
+	}
+	d, err := e.describeInstance(ctx, instance)
+	if err != nil {
+		return fmt.Errorf("Describing instance %s: %v", instance, err)
+	}
+	if format == "json" {
+		return writeJSON(e.stdout, d)
+	}
+	printInstanceDescription(e.stdout, d)
+	return nil
+}
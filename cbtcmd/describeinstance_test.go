@@ -0,0 +1,78 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	btapb "cloud.google.com/go/bigtable/admin/apiv2/adminpb"
+)
+
+func TestDescribeInstance(t *testing.T) {
+	info := &bigtable.InstanceInfo{
+		Name:          "my-instance",
+		DisplayName:   "My Instance",
+		InstanceState: bigtable.InstanceState(btapb.Instance_READY),
+		InstanceType:  bigtable.InstanceType(btapb.Instance_PRODUCTION),
+		Labels:        map[string]string{"team": "ads", "env": "prod"},
+	}
+	clusters := []*bigtable.ClusterInfo{
+		{Name: "c2", Zone: "us-east1-b", State: "READY", ServeNodes: 3, StorageType: bigtable.HDD},
+		{Name: "c1", Zone: "us-central1-a", State: "READY", ServeNodes: 5, KMSKeyName: "projects/p/locations/l/keyRings/r/cryptoKeys/k",
+			AutoscalingConfig: &bigtable.AutoscalingConfig{MinNodes: 2, MaxNodes: 10, CPUTargetPercent: 60}},
+	}
+	profiles := []*btapb.AppProfile{{
+		Name:          "projects/p/instances/my-instance/appProfiles/batch",
+		Description:   "batch jobs",
+		RoutingPolicy: &btapb.AppProfile_MultiClusterRoutingUseAny_{MultiClusterRoutingUseAny: &btapb.AppProfile_MultiClusterRoutingUseAny{}},
+	}}
+	d := newInstanceDescription(info, clusters, profiles, 12)
+	if d.CMEK != "partial" || d.Clusters[0].Name != "c1" || d.AppProfiles[0].Name != "batch" {
+		t.Errorf("newInstanceDescription = %+v", d)
+	}
+
+	var buf bytes.Buffer
+	printInstanceDescription(&buf, d)
+	got := strings.Join(strings.Fields(buf.String()), " ")
+	for _, want := range []string{
+		"Instance: my-instance (My Instance)",
+		"State: READY Type: PRODUCTION Labels: env=prod, team=ads Tables: 12",
+		"CMEK: partial (1 of 2 clusters)",
+		"c1 us-central1-a READY 5 SSD 2-10 nodes, 60% CPU projects/p/locations/l/keyRings/r/cryptoKeys/k",
+		"c2 us-east1-b READY 3 HDD - -",
+		"batch multi-cluster",
+		"batch jobs",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	empty := newInstanceDescription(&bigtable.InstanceInfo{Name: "empty"}, nil, nil, 0)
+	buf.Reset()
+	if err := writeJSON(&buf, empty); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded["cmek"] != "disabled" || len(decoded["clusters"].([]interface{})) != 0 {
+		t.Errorf("JSON description of an instance without clusters = %s, %v", buf.String(), err)
+	}
+}
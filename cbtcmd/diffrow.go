@@ -0,0 +1,281 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// defaultDiffContext is the number of unchanged lines shown around each
+// change by diffrow.
+const defaultDiffContext = 3
+
+// rowLines renders the cells of r as lines to diff: a family:qualifier line,
+// with the timestamp if timestamps is set, followed by the formatted value.
+// Values are formatted with e.formatting, so that protocol buffer
+// and JSON values diff field by field.
+func (e *env) rowLines(r bigtable.Row, timestamps bool) ([]string, error) {
+	var lines []string
+	for _, ri := range e.formatting.DisplayItems(r) {
+		fam := ri.Column
+		if i := strings.Index(fam, ":"); i >= 0 {
+			fam = fam[:i]
+		}
+		header := ri.Column
+		if timestamps {
+			header += " @ " + time.UnixMicro(int64(ri.Timestamp)).UTC().Format("2006/01/02-15:04:05.000000")
+		}
+		formatted, err := e.formatting.Format("    ", fam, ri.Column, ri.Value)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, header)
+		lines = append(lines, strings.Split(strings.TrimSuffix(formatted, "\n"), "\n")...)
+	}
+	return lines, nil
+}
+
+// diffOp is a line of a diff: kept (' '), removed ('-') or added ('+').
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines returns the shortest edit turning a into b, found from the
+// longest common subsequence of the lines. Rows are small enough for the
+// quadratic table.
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	return ops
+}
+
+// writeUnifiedDiff writes the differences between a and b to w as a unified
+// diff with contextLines unchanged lines around each change, and reports whether
+// there were any.
+func (e *env) writeUnifiedDiff(w io.Writer, fromName, toName string, a, b []string, contextLines int) bool {
+	ops := diffLines(a, b)
+	// Find the hunks: runs of ops with changes no more than 2*contextLines
+	// lines apart, widened by contextLines lines on each side.
+	type hunk struct{ start, end int }
+	var hunks []hunk
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+		start, end := max(i-contextLines, 0), min(i+contextLines+1, len(ops))
+		if n := len(hunks); n > 0 && start <= hunks[n-1].end {
+			hunks[n-1].end = end
+		} else {
+			hunks = append(hunks, hunk{start, end})
+		}
+	}
+	if len(hunks) == 0 {
+		return false
+	}
+	fmt.Fprintln(w, e.colorize(colorRemoved, "--- "+fromName))
+	fmt.Fprintln(w, e.colorize(colorAdded, "+++ "+toName))
+	// aLine and bLine are the line numbers, from 1, of ops[k] in a and b.
+	aLine, bLine, k := 1, 1, 0
+	for _, h := range hunks {
+		for ; k < h.start; k++ {
+			aLine, bLine = advanceDiffLines(ops[k].kind, aLine, bLine)
+		}
+		aCount, bCount := 0, 0
+		for _, op := range ops[h.start:h.end] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+		fmt.Fprintln(w, e.colorize(colorHunk, fmt.Sprintf("@@ -%s +%s @@", hunkRange(aLine, aCount), hunkRange(bLine, bCount))))
+		for ; k < h.end; k++ {
+			op := ops[k]
+			line := string(op.kind) + op.line
+			switch op.kind {
+			case '-':
+				line = e.colorize(colorRemoved, line)
+			case '+':
+				line = e.colorize(colorAdded, line)
+			}
+			fmt.Fprintln(w, line)
+			aLine, bLine = advanceDiffLines(op.kind, aLine, bLine)
+		}
+	}
+	return true
+}
+
+func advanceDiffLines(kind byte, aLine, bLine int) (int, int) {
+	if kind != '+' {
+		aLine++
+	}
+	if kind != '-' {
+		bLine++
+	}
+	return aLine, bLine
+}
+
+// hunkRange formats the start and length of a side of a hunk as diff -u
+// does: an empty side starts at the line before it.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func doDiffRow(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt diffrow <table-id> <row-key> [<table-id-2>] <row-key-2> [columns=<family>:<qualifier>,...] " +
+		"[cells-per-column=<n>] [timestamps=<true|false>] [context=<n>] [app-profile=<app-profile-id>] [format-file=<path>]"
+	valid := []string{"columns", "cells-per-column", "timestamps", "context", "app-profile", "format-file", "display", "hide", "decode-aggregates"}
+	var positional, options []string
+	for _, arg := range args {
+		if isOptionArg(arg, valid) {
+			options = append(options, arg)
+		} else {
+			positional = append(positional, arg)
+		}
+	}
+	var fromTable, fromKey, toTable, toKey string
+	switch len(positional) {
+	case 3:
+		fromTable, fromKey, toTable, toKey = positional[0], positional[1], positional[0], positional[2]
+	case 4:
+		fromTable, fromKey, toTable, toKey = positional[0], positional[1], positional[2], positional[3]
+	default:
+		return errors.New(usage)
+	}
+	parsed, err := parseArgs(options, valid)
+	if err != nil {
+		return errors.New(usage)
+	}
+
+	filters := []bigtable.Filter{bigtable.LatestNFilter(1)}
+	if s := parsed["cells-per-column"]; s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return fmt.Errorf("Bad number of cells per column %q", s)
+		}
+		filters[0] = bigtable.LatestNFilter(n)
+	}
+	if columns := parsed["columns"]; columns != "" {
+		f, err := parseColumnsFilter(columns)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, f)
+	}
+	var timestamps bool
+	if s := parsed["timestamps"]; s != "" {
+		if timestamps, err = strconv.ParseBool(s); err != nil {
+			return fmt.Errorf("Bad timestamps %q: %v", s, err)
+		}
+	}
+	contextLines := defaultDiffContext
+	if s := parsed["context"]; s != "" {
+		if contextLines, err = strconv.Atoi(s); err != nil || contextLines < 0 {
+			return fmt.Errorf("Bad context %q", s)
+		}
+	}
+
+	if err := e.formatting.Setup(parsed["format-file"]); err != nil {
+		return fmt.Errorf("Reading format file: %v", err)
+	}
+	if err := e.formatting.SetColumnSelection(parsed["display"], parsed["hide"]); err != nil {
+		return err
+	}
+	if err := e.setupAggregateDecoding(ctx, fromTable, parsed["decode-aggregates"]); err != nil {
+		return err
+	}
+
+	filter := filters[0]
+	if len(filters) > 1 {
+		filter = bigtable.ChainFilters(filters...)
+	}
+	read := func(table, key string) ([]string, error) {
+		tbl, err := e.openTableAPI(parsed["app-profile"], table, "")
+		if err != nil {
+			return nil, err
+		}
+		r, err := tbl.ReadRow(ctx, key, bigtable.RowFilter(filter))
+		if err != nil {
+			return nil, fmt.Errorf("Reading row %q of %s: %v", key, table, err)
+		}
+		lines, err := e.rowLines(r, timestamps)
+		if err != nil {
+			return nil, fmt.Errorf("Formatting row %q of %s: %v", key, table, err)
+		}
+		return lines, nil
+	}
+	from, err := read(fromTable, fromKey)
+	if err != nil {
+		return err
+	}
+	to, err := read(toTable, toKey)
+	if err != nil {
+		return err
+	}
+	if e.writeUnifiedDiff(e.stdout, fromTable+"/"+fromKey, toTable+"/"+toKey, from, to, contextLines) {
+		// Exit code 1 is already used for errors.
+		return &ExitError{Code: 3}
+	}
+	return nil
+}
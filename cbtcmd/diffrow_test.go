@@ -0,0 +1,118 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"cloud.google.com/go/cbt/cli"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	lines := strings.Fields("a b c d e f g h i j")
+	changed := strings.Fields("a B c d e f g h j k")
+	for _, test := range []struct {
+		desc    string
+		a, b    []string
+		context int
+		want    string
+	}{
+		{desc: "same", a: lines, b: lines, context: 3},
+		{
+			desc: "two hunks", a: lines, b: changed, context: 1,
+			want: "--- t/r1\n+++ t/r2\n" +
+				"@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n" +
+				"@@ -8,3 +8,3 @@\n h\n-i\n j\n+k\n",
+		},
+		{
+			desc: "one hunk", a: lines, b: changed, context: 3,
+			want: "--- t/r1\n+++ t/r2\n" +
+				"@@ -1,10 +1,10 @@\n a\n-b\n+B\n c\n d\n e\n f\n g\n h\n-i\n j\n+k\n",
+		},
+		{
+			desc: "empty row", a: nil, b: []string{"f:a", "    x"}, context: 3,
+			want: "--- t/r1\n+++ t/r2\n@@ -0,0 +1,2 @@\n+f:a\n+    x\n",
+		},
+	} {
+		e, _ := newTestEnv(Deps{})
+		var buf bytes.Buffer
+		differ := e.writeUnifiedDiff(&buf, "t/r1", "t/r2", test.a, test.b, test.context)
+		if differ != (test.want != "") {
+			t.Errorf("%s: writeUnifiedDiff reported differences %t", test.desc, differ)
+		}
+		if diff := cmp.Diff(test.want, buf.String()); diff != "" {
+			t.Errorf("%s: diff mismatch (-want +got):\n%s", test.desc, diff)
+		}
+	}
+}
+
+func TestRowLines(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	e.formatting = cli.NewValueFormatting()
+	e.formatting.Settings.Columns["size"] = cli.ValueFormatColumn{Encoding: "BigEndian", Type: "uint16"}
+	if err := e.formatting.Setup(""); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bigtable.Row{
+		"f": {
+			{Row: "r", Column: "f:name", Timestamp: 1700000000000000, Value: []byte("phone")},
+			{Row: "r", Column: "f:size", Timestamp: 1000, Value: []byte{1, 2}},
+		},
+	}
+	got, err := e.rowLines(r, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"f:name", `    "phone"`, "f:size", "    258"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("rowLines mismatch (-want +got):\n%s", diff)
+	}
+	got, err = e.rowLines(r, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "f:name @ 2023/11/14-22:13:20.000000"; got[0] != want {
+		t.Errorf("rowLines with timestamps starts with %q, want %q", got[0], want)
+	}
+}
+
+func TestDoDiffRow(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	e, out := newTestEnv(Deps{Client: c})
+	tbl := c.Open("my-table")
+	for row, value := range map[string]string{"r1": "a", "r2": "a", "r3": "b"} {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "c", 1000, []byte(value))
+		if err := tbl.Apply(ctx, row, mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := doDiffRow(ctx, e, "my-table", "r1", "r2")
+	if err != nil || out.Len() != 0 {
+		t.Errorf("diffrow of equal rows printed %q and returned %v, want nothing", out, err)
+	}
+	err = doDiffRow(ctx, e, "my-table", "r2", "my-table", "r3")
+	want := "--- my-table/r2\n+++ my-table/r3\n@@ -1,2 +1,2 @@\n f:c\n-    \"a\"\n+    \"b\"\n"
+	if out.String() != want || exitStatus(err) != 3 {
+		t.Errorf("diffrow of different rows printed %q and returned %v, want a diff and exit status 3", out, err)
+	}
+}
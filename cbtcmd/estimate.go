@@ -0,0 +1,135 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+// Estimation of the cost of a read before running it. The first rows of the
+// requested range are read unfiltered to measure their size, the request's
+// filter is applied to the same rows, and both are scaled up to the size of
+// the range reported by SampleRowKeys.
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// estimateSampleRows is the number of rows read to estimate a scan.
+const estimateSampleRows = 1000
+
+// largeScanBytes is the estimated scan size above which a warning is printed.
+const largeScanBytes = 1 << 30
+
+// scanEstimate is the estimated cost of a read.
+type scanEstimate struct {
+	sampleRows                                 int64
+	rowsScanned, cellsScanned, bytesScanned    int64
+	rowsReturned, cellsReturned, bytesReturned int64
+	// exact is set if the sample covered the whole range.
+	exact bool
+}
+
+// rangeBytes returns the approximate size of the data in [start, end), from
+// the ranges between sample row keys. Ranges partly inside are counted in
+// full.
+func rangeBytes(ranges []keyRange, start, end string) int64 {
+	var n int64
+	for _, r := range ranges {
+		if (r.End == "" || r.End > start) && (end == "" || r.Start < end) {
+			n += r.Bytes
+		}
+	}
+	return n
+}
+
+// estimateRead estimates the cost of reading [start, end) of tbl with filter,
+// which may be nil, given the approximate size of the range.
+func estimateRead(ctx context.Context, tbl bigtable.TableAPI, start, end string, size int64, filter bigtable.Filter) (scanEstimate, error) {
+	var e scanEstimate
+	var last string
+	err := tbl.ReadRows(ctx, rowRange(start, end), func(r bigtable.Row) bool {
+		e.rowsScanned++
+		e.bytesScanned += rowSize(r)
+		for _, items := range r {
+			e.cellsScanned += int64(len(items))
+		}
+		last = r.Key()
+		return true
+	}, bigtable.LimitRows(estimateSampleRows))
+	if err != nil {
+		return e, err
+	}
+	e.sampleRows = e.rowsScanned
+	e.exact = e.rowsScanned < estimateSampleRows
+	if e.rowsScanned == 0 {
+		return e, nil
+	}
+
+	var opts []bigtable.ReadOption
+	if filter != nil {
+		opts = append(opts, bigtable.RowFilter(filter))
+	}
+	err = tbl.ReadRows(ctx, bigtable.NewRange(start, last+"\x00"), func(r bigtable.Row) bool {
+		e.rowsReturned++
+		e.bytesReturned += rowSize(r)
+		for _, items := range r {
+			e.cellsReturned += int64(len(items))
+		}
+		return true
+	}, opts...)
+	if err != nil || e.exact || size <= e.bytesScanned {
+		return e, err
+	}
+	e.scale(float64(size) / float64(e.bytesScanned))
+	e.bytesScanned = size
+	return e, nil
+}
+
+func (e *scanEstimate) scale(f float64) {
+	for _, n := range []*int64{&e.rowsScanned, &e.cellsScanned, &e.bytesScanned, &e.rowsReturned, &e.cellsReturned, &e.bytesReturned} {
+		*n = int64(float64(*n) * f)
+	}
+}
+
+// limitRows adjusts the estimate for a read that stops after n rows.
+func (e *scanEstimate) limitRows(n int64) {
+	if e.rowsReturned > n {
+		e.scale(float64(n) / float64(e.rowsReturned))
+		e.rowsReturned = n
+	}
+}
+
+func printScanEstimate(w io.Writer, table string, e scanEstimate) {
+	approx := "~"
+	if e.exact {
+		approx = ""
+		fmt.Fprintf(w, "The read covers %d rows of %s:\n", e.sampleRows, table)
+	} else {
+		fmt.Fprintf(w, "Estimated cost of the read of %s, from a sample of %d rows:\n", table, e.sampleRows)
+	}
+	fmt.Fprintf(w, "  Rows scanned:    %s%d\n", approx, e.rowsScanned)
+	fmt.Fprintf(w, "  Cells scanned:   %s%d\n", approx, e.cellsScanned)
+	fmt.Fprintf(w, "  Data scanned:    %s%s\n", approx, formatBytes(e.bytesScanned))
+	fmt.Fprintf(w, "  Rows returned:   %s%d\n", approx, e.rowsReturned)
+	fmt.Fprintf(w, "  Cells returned:  %s%d\n", approx, e.cellsReturned)
+	fmt.Fprintf(w, "  Data returned:   %s%s\n", approx, formatBytes(e.bytesReturned))
+	if e.bytesScanned > largeScanBytes {
+		fmt.Fprintf(w, "\nWarning: this read scans about %s. Narrow it with start=, end= or prefix= if a full scan is not intended.\n",
+			formatBytes(e.bytesScanned))
+	}
+}
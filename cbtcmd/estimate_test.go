@@ -0,0 +1,94 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+)
+
+func TestRangeBytes(t *testing.T) {
+	ranges := keyRanges([]keySample{{"b", 100}, {"d", 300}, {"", 600}})
+	for _, test := range []struct {
+		start, end string
+		want       int64
+	}{
+		{"", "", 600},
+		{"a", "c", 300},
+		{"b", "d", 200},
+		{"c", "", 500},
+		{"e", "", 300},
+	} {
+		if got := rangeBytes(ranges, test.start, test.end); got != test.want {
+			t.Errorf("rangeBytes(%q, %q) = %d, want %d", test.start, test.end, got, test.want)
+		}
+	}
+}
+
+func TestEstimateRead(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	tbl := c.Open("my-table")
+	var keys []string
+	var muts []*bigtable.Mutation
+	for i := 0; i < 2500; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", 1000, []byte("value"))
+		mut.Set("f", "b", 1000, []byte("value"))
+		keys = append(keys, fmt.Sprintf("row%05d", i))
+		muts = append(muts, mut)
+	}
+	if errs, err := tbl.ApplyBulk(ctx, keys, muts); err != nil || errs != nil {
+		t.Fatal(err, errs)
+	}
+
+	e, err := estimateRead(ctx, tbl, "row000", "row001", 0, bigtable.ColumnFilter("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.exact || e.rowsScanned != 100 || e.cellsScanned != 200 || e.rowsReturned != 100 || e.cellsReturned != 100 {
+		t.Errorf("estimate of a small range = %+v, want 100 rows and 200 cells scanned, 100 cells returned", e)
+	}
+
+	sample, err := estimateRead(ctx, tbl, "", "", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sample.exact || sample.rowsScanned != estimateSampleRows {
+		t.Fatalf("estimate without a size = %+v, want the sample only", sample)
+	}
+	e, err = estimateRead(ctx, tbl, "", "", sample.bytesScanned*5/2, bigtable.ColumnFilter("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.exact || e.rowsScanned != 2500 || e.cellsScanned != 5000 || e.rowsReturned != 2500 || e.cellsReturned != 2500 {
+		t.Errorf("estimate of the whole table = %+v, want 2500 rows and 5000 cells scanned, 2500 cells returned", e)
+	}
+	e.limitRows(500)
+	if e.rowsReturned != 500 || e.rowsScanned != 500 || e.cellsReturned != 500 {
+		t.Errorf("estimate limited to 500 rows = %+v", e)
+	}
+
+	var buf bytes.Buffer
+	printScanEstimate(&buf, "my-table", scanEstimate{sampleRows: 1000, rowsScanned: 1e7, bytesScanned: 5 << 30})
+	if !strings.Contains(buf.String(), "Warning") {
+		t.Errorf("no warning for a large scan:\n%s", buf.String())
+	}
+}
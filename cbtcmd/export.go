@@ -0,0 +1,757 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// exporterArgs holds the parsed arguments of the export command.
+type exporterArgs struct {
+	appProfile        string
+	priority          string
+	columns           []string // "<family>:<qualifier>", in output order
+	includeTimestamps bool
+	cellsPerColumn    int
+	start, end        string // the exported row range; an empty end is unbounded
+	workers           int
+	parts             bool
+	resume            bool
+	format            string // "csv" or "sqlite"
+	dest              string // a BigQuery table to load, bq://[<project>.]<dataset>.<table>
+	bqReplace         bool
+	filters           []bigtable.Filter // further filters of the exported cells
+}
+
+const exportUsage = "usage: cbt export <table-id> <output-file> [columns=<family>:<qualifier>,...] [include-timestamps=<true|false>]" +
+	" [cells-per-column=<1>] [start=<row-key>] [end=<row-key>] [prefix=<row-key-prefix>] [workers=<1>] [parts=<true|false>]" +
+	" [resume=<true|false>] [format=<csv|sqlite>] [dest=bq://[<project>.]<dataset>.<table>] [bq-replace=<true|false>] [regex=<regex>] [value-regex=<regex>] [from=<timestamp>] [to=<timestamp>]" +
+	" [any-of=<filter>;...] [all-of=<filter>;...] [if=<filter>;... [then=<filter>;...] [else=<filter>;...]]" +
+	" [app-profile=<app-profile-id>] [priority=<low|medium|high>]"
+
+func parseExporterArgs(args []string) (exporterArgs, error) {
+	ea := exporterArgs{cellsPerColumn: 1, workers: 1}
+	if len(args) < 2 {
+		return ea, fmt.Errorf(exportUsage)
+	}
+	parsed, err := parseArgs(args[2:], append([]string{
+		"app-profile", "priority", "columns", "include-timestamps", "cells-per-column", "start", "end", "prefix",
+		"workers", "parts", "resume", "format", "dest", "bq-replace",
+	}, cellFilterArgs...))
+	if err != nil {
+		return ea, err
+	}
+	if ea.filters, err = parseCellFilters(parsed, time.Now()); err != nil {
+		return ea, err
+	}
+	ea.appProfile, ea.priority = parsed["app-profile"], parsed["priority"]
+	if columns := parsed["columns"]; columns != "" {
+		for _, c := range strings.Split(columns, ",") {
+			if fam, qual, ok := strings.Cut(c, ":"); !ok || fam == "" || qual == "" {
+				return ea, fmt.Errorf("bad column %q: want <family>:<qualifier>", c)
+			}
+			ea.columns = append(ea.columns, c)
+		}
+	}
+	if v := parsed["include-timestamps"]; v != "" {
+		if ea.includeTimestamps, err = strconv.ParseBool(v); err != nil {
+			return ea, fmt.Errorf("bad include-timestamps %q: %v", v, err)
+		}
+	}
+	if v := parsed["cells-per-column"]; v != "" {
+		if ea.cellsPerColumn, err = strconv.Atoi(v); err != nil || ea.cellsPerColumn <= 0 {
+			return ea, fmt.Errorf("cells-per-column must be > 0")
+		}
+	}
+	if v := parsed["workers"]; v != "" {
+		if ea.workers, err = strconv.Atoi(v); err != nil || ea.workers <= 0 {
+			return ea, fmt.Errorf("workers must be > 0")
+		}
+	}
+	if v := parsed["parts"]; v != "" {
+		if ea.parts, err = strconv.ParseBool(v); err != nil {
+			return ea, fmt.Errorf("bad parts %q: %v", v, err)
+		}
+		if ea.parts && args[1] == "-" {
+			return ea, fmt.Errorf("parts=true requires an output file")
+		}
+	}
+	if v := parsed["resume"]; v != "" {
+		if ea.resume, err = strconv.ParseBool(v); err != nil {
+			return ea, fmt.Errorf("bad resume %q: %v", v, err)
+		}
+		if ea.resume && (args[1] == "-" || ea.workers > 1 || ea.parts) {
+			return ea, fmt.Errorf("resume=true requires an output file and a single worker")
+		}
+	}
+	switch ea.format = parsed["format"]; ea.format {
+	case "":
+		ea.format = "csv"
+	case "csv":
+	case "sqlite":
+		switch {
+		case args[1] == "-":
+			return ea, fmt.Errorf("format=sqlite requires an output file")
+		case ea.parts || ea.resume:
+			return ea, fmt.Errorf("parts and resume are not supported with format=sqlite")
+		case ea.includeTimestamps:
+			return ea, fmt.Errorf("include-timestamps is not needed with format=sqlite, which always stores timestamps")
+		}
+	default:
+		return ea, fmt.Errorf("bad format %q: want csv or sqlite", ea.format)
+	}
+	if v := parsed["bq-replace"]; v != "" {
+		if ea.bqReplace, err = strconv.ParseBool(v); err != nil {
+			return ea, fmt.Errorf("bad bq-replace %q: %v", v, err)
+		}
+	}
+	if ea.dest = parsed["dest"]; ea.dest != "" {
+		if err := validateBigQueryExport(args[1], ea); err != nil {
+			return ea, err
+		}
+	}
+	if ea.format == "csv" && ea.dest == "" && ea.cellsPerColumn > 1 && !ea.includeTimestamps {
+		// Import writes every line at the same time, so only the last
+		// version of each column would survive.
+		return ea, fmt.Errorf("cells-per-column > 1 requires include-timestamps=true, so that import timestamp=value-encoded can restore the versions")
+	}
+	ea.start, ea.end = parsed["start"], parsed["end"]
+	if prefix := parsed["prefix"]; prefix != "" {
+		if ea.start != "" || ea.end != "" {
+			return ea, fmt.Errorf(`"start"/"end" may not be mixed with "prefix"`)
+		}
+		ea.start, ea.end = prefix, prefixEnd(prefix)
+	}
+	return ea, nil
+}
+
+// prefixEnd returns the first key after all keys with the given prefix, or ""
+// if there is none.
+func prefixEnd(prefix string) string {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xff {
+			return prefix[:i] + string([]byte{prefix[i] + 1})
+		}
+	}
+	return ""
+}
+
+func rowRange(start, end string) bigtable.RowRange {
+	if end == "" {
+		return bigtable.InfiniteRange(start)
+	}
+	return bigtable.NewRange(start, end)
+}
+
+// exportShards splits [start, end) into at most n contiguous ranges at the
+// given sample row keys, returning the boundaries between them.
+func exportShards(keys []string, start, end string, n int) []string {
+	var inner []string
+	for _, k := range keys {
+		if k > start && (end == "" || k < end) {
+			inner = append(inner, k)
+		}
+	}
+	shards := n
+	if shards > len(inner)+1 {
+		shards = len(inner) + 1
+	}
+	bounds := []string{start}
+	for i := 1; i < shards; i++ {
+		bounds = append(bounds, inner[i*len(inner)/shards])
+	}
+	return append(bounds, end)
+}
+
+// partName returns the name of the i'th of n part files of an export.
+func partName(output string, i, n int) string {
+	ext := filepath.Ext(output)
+	return fmt.Sprintf("%s-%05d-of-%05d%s", strings.TrimSuffix(output, ext), i, n, ext)
+}
+
+// readFilter returns the filter selecting the cells that are exported. The
+// cells per column are limited last, so that they count the cells matching
+// the other filters.
+func (ea exporterArgs) readFilter() bigtable.Filter {
+	var columnFilters []bigtable.Filter
+	for _, c := range ea.columns {
+		fam, qual, _ := strings.Cut(c, ":")
+		columnFilters = append(columnFilters, bigtable.ChainFilters(
+			bigtable.FamilyFilter("^"+regexp.QuoteMeta(fam)+"$"),
+			bigtable.ColumnFilter("^"+regexp.QuoteMeta(qual)+"$"),
+		))
+	}
+	var filters []bigtable.Filter
+	switch len(columnFilters) {
+	case 0:
+	case 1:
+		filters = append(filters, columnFilters[0])
+	default:
+		filters = append(filters, bigtable.InterleaveFilters(columnFilters...))
+	}
+	filters = append(filters, ea.filters...)
+	latest := bigtable.LatestNFilter(ea.cellsPerColumn)
+	if len(filters) == 0 {
+		return latest
+	}
+	return bigtable.ChainFilters(append(filters, latest)...)
+}
+
+// discoverColumns scans the exported range for the columns of the cells that
+// are exported, sorted by family and qualifier.
+func (ea exporterArgs) discoverColumns(ctx context.Context, tbl TableReader) ([]string, error) {
+	seen := map[string]bool{}
+	err := tbl.ReadRows(ctx, rowRange(ea.start, ea.end), func(r bigtable.Row) bool {
+		for _, items := range r {
+			for _, item := range items {
+				seen[item.Column] = true
+			}
+		}
+		return true
+	}, bigtable.RowFilter(bigtable.ChainFilters(ea.readFilter(), bigtable.StripValueFilter())))
+	if err != nil {
+		return nil, err
+	}
+	var columns []string
+	for c := range seen {
+		columns = append(columns, c)
+	}
+	sort.Strings(columns)
+	return columns, nil
+}
+
+// writeCSVHeader writes the family and qualifier header rows read by import.
+// Each family is named once, above its first column.
+func writeCSVHeader(w *csv.Writer, columns []string) error {
+	fams := []string{""}
+	quals := []string{""}
+	last := ""
+	for _, c := range columns {
+		fam, qual, _ := strings.Cut(c, ":")
+		if fam == last {
+			fam = ""
+		} else {
+			last = fam
+		}
+		fams = append(fams, fam)
+		quals = append(quals, qual)
+	}
+	if err := w.Write(fams); err != nil {
+		return err
+	}
+	return w.Write(quals)
+}
+
+// rowRecords converts a row into CSV records: one record for each version up
+// to cellsPerColumn, the oldest first, so that the newest is written last.
+// Import treats repeated row keys as further writes to the same row, and with
+// timestamp=value-encoded, restores each version at its timestamp.
+func (ea exporterArgs) rowRecords(r bigtable.Row, columns []string) [][]string {
+	index := make(map[string]int, len(columns))
+	for i, c := range columns {
+		index[c] = i + 1
+	}
+	var records [][]string
+	for _, items := range r {
+		// The cells of each column are read newest first.
+		versions := map[string]int{}
+		for _, item := range items {
+			versions[item.Column]++
+		}
+		for _, item := range items {
+			i, ok := index[item.Column]
+			if !ok {
+				continue
+			}
+			versions[item.Column]--
+			version := versions[item.Column]
+			for len(records) <= version {
+				rec := make([]string, len(columns)+1)
+				rec[0] = r.Key()
+				records = append(records, rec)
+			}
+			v := string(item.Value)
+			if ea.includeTimestamps {
+				v += "@" + strconv.FormatInt(int64(item.Timestamp), 10)
+			}
+			records[version][i] = v
+		}
+	}
+	return records
+}
+
+// exportColumns returns the columns to export, in order.
+func (ea exporterArgs) exportColumns(ctx context.Context, tbl TableReader) ([]string, error) {
+	if len(ea.columns) > 0 {
+		return ea.columns, nil
+	}
+	columns, err := ea.discoverColumns(ctx, tbl)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns to export")
+	}
+	return columns, nil
+}
+
+// writeRows writes the rows of [start, end) to cw, returning the number
+// written. If afterRow is not nil, it is called with the key of each row once
+// the row has been written to cw. Resumed reads are logged to logger.
+func (ea exporterArgs) writeRows(ctx context.Context, logger *log.Logger, tbl TableReader, cw *csv.Writer, start, end string, columns []string, afterRow func(key string) error) (int, error) {
+	n := 0
+	var werr error
+	rd := resumableRead{start: start, end: end, opts: []bigtable.ReadOption{bigtable.RowFilter(ea.readFilter())}}
+	err := rd.run(ctx, logger, tbl, func(r bigtable.Row) bool {
+		for _, rec := range ea.rowRecords(r, columns) {
+			if werr = cw.Write(rec); werr != nil {
+				return false
+			}
+		}
+		n++
+		if afterRow != nil {
+			if werr = afterRow(r.Key()); werr != nil {
+				return false
+			}
+		}
+		return true
+	})
+	if werr != nil {
+		return n, werr
+	}
+	if err != nil {
+		return n, err
+	}
+	cw.Flush()
+	return n, cw.Error()
+}
+
+// exportCSV writes the selected rows of tbl to w, returning the number of
+// rows written.
+func exportCSV(ctx context.Context, e *env, tbl TableReader, w io.Writer, ea exporterArgs) (int, error) {
+	columns, err := ea.exportColumns(ctx, tbl)
+	if err != nil {
+		return 0, err
+	}
+	cw := csv.NewWriter(w)
+	if err := writeCSVHeader(cw, columns); err != nil {
+		return 0, err
+	}
+	return ea.writeRows(ctx, e.log, tbl, cw, ea.start, ea.end, columns, nil)
+}
+
+// sqliteCellsTable is the SQL creating the table written by format=sqlite,
+// which has a row for each exported cell.
+const sqliteCellsTable = "CREATE TABLE cells(key TEXT, family TEXT, qualifier TEXT, ts INTEGER, value BLOB)"
+
+// forEachCell calls f with each cell of r, ordered by family, then as read.
+func forEachCell(r bigtable.Row, f func(fam, qual string, item bigtable.ReadItem) error) error {
+	fams := make([]string, 0, len(r))
+	for fam := range r {
+		fams = append(fams, fam)
+	}
+	sort.Strings(fams)
+	for _, fam := range fams {
+		for _, item := range r[fam] {
+			if err := f(fam, strings.TrimPrefix(item.Column, fam+":"), item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readShards reads the selected rows of tbl, calling f with each row in
+// turn until it returns an error, and returns the number of rows read. With
+// more than one worker, the range is split at the table's sample row keys and
+// the shards are read concurrently, so rows are not in key order. Resumed
+// reads are logged to logger.
+func (ea exporterArgs) readShards(ctx context.Context, logger *log.Logger, tbl TableReader, f func(bigtable.Row) error) (int, error) {
+	bounds := []string{ea.start, ea.end}
+	if ea.workers > 1 {
+		var err error
+		if bounds, err = shardBounds(ctx, tbl, ea); err != nil {
+			return 0, err
+		}
+	}
+	var mu sync.Mutex
+	var ferr error
+	total := 0
+	errs := make([]error, len(bounds)-1)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rd := resumableRead{start: bounds[i], end: bounds[i+1], opts: []bigtable.ReadOption{bigtable.RowFilter(ea.readFilter())}}
+			errs[i] = rd.run(ctx, logger, tbl, func(r bigtable.Row) bool {
+				mu.Lock()
+				defer mu.Unlock()
+				if ferr != nil {
+					return false
+				}
+				if ferr = f(r); ferr != nil {
+					return false
+				}
+				total++
+				return true
+			})
+		}(i)
+	}
+	wg.Wait()
+	if ferr != nil {
+		return total, ferr
+	}
+	for _, err := range errs {
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// exportSQLite writes the selected cells of tbl to a new SQLite database at
+// output, returning the number of rows read.
+func exportSQLite(ctx context.Context, e *env, tbl TableReader, output string, ea exporterArgs) (int, error) {
+	f, err := os.Create(output)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	sw := newSQLiteWriter(f, "cells", sqliteCellsTable)
+	n, err := ea.readShards(ctx, e.log, tbl, func(r bigtable.Row) error {
+		return forEachCell(r, func(fam, qual string, item bigtable.ReadItem) error {
+			return sw.insert(r.Key(), fam, qual, int64(item.Timestamp), item.Value)
+		})
+	})
+	if err != nil {
+		return n, err
+	}
+	if err := sw.close(); err != nil {
+		return n, err
+	}
+	return n, f.Close()
+}
+
+// exportCheckpoint is saved beside an export's output file so that an
+// interrupted export can be resumed.
+type exportCheckpoint struct {
+	Table      string   `json:"table"`
+	Columns    []string `json:"columns"`
+	LastRowKey []byte   `json:"last_row_key"`
+	// Offset is the size of the output file when LastRowKey was written.
+	Offset int64 `json:"offset"`
+}
+
+// checkpointInterval is the number of rows exported between checkpoints.
+const checkpointInterval = 1000
+
+func checkpointName(output string) string { return output + ".checkpoint" }
+
+func loadExportCheckpoint(path string) (*exportCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cp exportCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint %s: %v", path, err)
+	}
+	return &cp, nil
+}
+
+// save atomically replaces the checkpoint file.
+func (cp *exportCheckpoint) save(path string) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// exportToFile writes the selected rows of tbl to the output file, saving a
+// checkpoint every checkpointInterval rows. With ea.resume, it continues from
+// the row after the one recorded in an existing checkpoint. The checkpoint is
+// removed when the export completes.
+func exportToFile(ctx context.Context, e *env, tbl TableReader, table, output string, ea exporterArgs) (int, error) {
+	cpPath := checkpointName(output)
+	var cp *exportCheckpoint
+	var f *os.File
+	if ea.resume {
+		var err error
+		if cp, err = loadExportCheckpoint(cpPath); err != nil {
+			return 0, fmt.Errorf("resuming: %v", err)
+		}
+		if cp.Table != table {
+			return 0, fmt.Errorf("resuming: checkpoint %s is for table %q", cpPath, cp.Table)
+		}
+		if f, err = os.OpenFile(output, os.O_WRONLY, 0); err != nil {
+			return 0, fmt.Errorf("resuming: %v", err)
+		}
+		// Drop anything written after the checkpoint.
+		if err := f.Truncate(cp.Offset); err != nil {
+			return 0, err
+		}
+		if _, err := f.Seek(cp.Offset, io.SeekStart); err != nil {
+			return 0, err
+		}
+		if cp.LastRowKey != nil {
+			ea.start = string(cp.LastRowKey) + "\x00"
+		}
+	} else {
+		columns, err := ea.exportColumns(ctx, tbl)
+		if err != nil {
+			return 0, err
+		}
+		if f, err = os.Create(output); err != nil {
+			return 0, err
+		}
+		cp = &exportCheckpoint{Table: table, Columns: columns}
+	}
+	defer f.Close()
+
+	cw := csv.NewWriter(f)
+	checkpoint := func(key string) error {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		offset, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		cp.LastRowKey, cp.Offset = nil, offset
+		if key != "" {
+			cp.LastRowKey = []byte(key)
+		}
+		return cp.save(cpPath)
+	}
+	if !ea.resume {
+		if err := writeCSVHeader(cw, cp.Columns); err != nil {
+			return 0, err
+		}
+		if err := checkpoint(""); err != nil {
+			return 0, err
+		}
+	}
+	if ea.end != "" && ea.start >= ea.end {
+		// The checkpoint was saved after the last row in the range.
+		return 0, os.Remove(cpPath)
+	}
+
+	var n int
+	var last string
+	n, err := ea.writeRows(ctx, e.log, tbl, cw, ea.start, ea.end, cp.Columns, func(key string) error {
+		last = key
+		if n++; n%checkpointInterval == 0 {
+			return checkpoint(key)
+		}
+		return nil
+	})
+	if err != nil {
+		if interrupted(ctx) && last != "" {
+			// Save the rows written since the last checkpoint, so that
+			// resume=true continues after them.
+			if cerr := checkpoint(last); cerr != nil {
+				e.log.Printf("Saving checkpoint: %v", cerr)
+			}
+		}
+		return n, err
+	}
+	if err := f.Close(); err != nil {
+		return n, err
+	}
+	return n, os.Remove(cpPath)
+}
+
+type rowKeySampler interface {
+	SampleRowKeys(ctx context.Context) ([]string, error)
+}
+
+// shardBounds splits the exported range into ea.workers shards at the table's
+// sample row keys, returning the boundaries between them.
+func shardBounds(ctx context.Context, tbl TableReader, ea exporterArgs) ([]string, error) {
+	sampler, ok := tbl.(rowKeySampler)
+	if !ok {
+		return nil, fmt.Errorf("table does not support sampling row keys")
+	}
+	keys, err := sampler.SampleRowKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sampling row keys: %v", err)
+	}
+	return exportShards(keys, ea.start, ea.end, ea.workers), nil
+}
+
+// exportSharded splits the export at the table's sample row keys and reads
+// the shards concurrently. Each shard is written to its own part file if
+// ea.parts is set; otherwise shards are buffered in temporary files and then
+// copied to output, or the stdout of e if output is "-", in key order. It
+// returns the number of rows written.
+func exportSharded(ctx context.Context, e *env, tbl TableReader, output string, ea exporterArgs) (int, error) {
+	bounds, err := shardBounds(ctx, tbl, ea)
+	if err != nil {
+		return 0, err
+	}
+	columns, err := ea.exportColumns(ctx, tbl)
+	if err != nil {
+		return 0, err
+	}
+	shards := len(bounds) - 1
+
+	files := make([]*os.File, shards)
+	defer func() {
+		for _, f := range files {
+			if f != nil {
+				f.Close()
+				if !ea.parts {
+					os.Remove(f.Name())
+				}
+			}
+		}
+	}()
+	for i := range files {
+		if ea.parts {
+			files[i], err = os.Create(partName(output, i, shards))
+		} else {
+			files[i], err = os.CreateTemp("", "cbt-export-*.csv")
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	counts := make([]int, shards)
+	errs := make([]error, shards)
+	var wg sync.WaitGroup
+	for i := 0; i < shards; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cw := csv.NewWriter(files[i])
+			if ea.parts {
+				if errs[i] = writeCSVHeader(cw, columns); errs[i] != nil {
+					return
+				}
+			}
+			counts[i], errs[i] = ea.writeRows(ctx, e.log, tbl, cw, bounds[i], bounds[i+1], columns, nil)
+		}(i)
+	}
+	wg.Wait()
+	total := 0
+	for i := range counts {
+		if errs[i] != nil {
+			return total, errs[i]
+		}
+		total += counts[i]
+	}
+	if ea.parts {
+		for i, f := range files {
+			files[i] = nil
+			if err := f.Close(); err != nil {
+				return total, err
+			}
+		}
+		return total, nil
+	}
+
+	var out io.Writer = e.stdout
+	var file *os.File
+	if output != "-" {
+		if file, err = os.Create(output); err != nil {
+			return total, err
+		}
+		out = file
+	}
+	cw := csv.NewWriter(out)
+	if err := writeCSVHeader(cw, columns); err != nil {
+		return total, err
+	}
+	if cw.Flush(); cw.Error() != nil {
+		return total, cw.Error()
+	}
+	for _, f := range files {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return total, err
+		}
+		if _, err := io.Copy(out, f); err != nil {
+			return total, err
+		}
+	}
+	if file != nil {
+		return total, file.Close()
+	}
+	return total, nil
+}
+
+func doExport(ctx context.Context, e *env, args ...string) error {
+	ea, err := parseExporterArgs(args)
+	if err != nil {
+		return fmt.Errorf("error parsing exporter args: %s", err)
+	}
+	if ea.appProfile, err = e.priorityAppProfile(ctx, ea.appProfile, ea.priority); err != nil {
+		return fmt.Errorf("error parsing exporter args: %s", err)
+	}
+	tbl, err := e.getTable(bigtable.ClientConfig{AppProfile: ea.appProfile}, args[0])
+	if err != nil {
+		return err
+	}
+	var n int
+	var resumable bool // whether the export saves a checkpoint
+	switch {
+	case ea.dest != "":
+		n, err = exportBigQuery(ctx, e, tbl, args[1], ea)
+	case ea.format == "sqlite":
+		n, err = exportSQLite(ctx, e, tbl, args[1], ea)
+	case ea.workers > 1 || ea.parts:
+		n, err = exportSharded(ctx, e, tbl, args[1], ea)
+	case args[1] == "-":
+		n, err = exportCSV(ctx, e, tbl, e.stdout, ea)
+	default:
+		n, err = exportToFile(ctx, e, tbl, args[0], args[1], ea)
+		resumable = true
+	}
+	if err != nil && interrupted(ctx) {
+		if resumable {
+			return interruptedAfter("exporting %d rows; run the same command with resume=true to continue", n)
+		}
+		return interruptedAfter("exporting %d rows", n)
+	}
+	if err != nil {
+		return fmt.Errorf("Exporting rows: %v", err)
+	}
+	e.log.Printf("Done exporting %d rows.\n", n)
+	return nil
+}
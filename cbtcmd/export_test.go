@@ -0,0 +1,352 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseExporterArgs(t *testing.T) {
+	ea, err := parseExporterArgs([]string{"my-table", "out.csv", "columns=f:a,g:b", "include-timestamps=true", "cells-per-column=3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ea.columns) != 2 || !ea.includeTimestamps || ea.cellsPerColumn != 3 {
+		t.Errorf("parseExporterArgs = %+v", ea)
+	}
+	for _, args := range [][]string{
+		{"my-table"},
+		{"my-table", "out.csv", "columns=f"},
+		{"my-table", "out.csv", "columns=f:"},
+		{"my-table", "out.csv", "cells-per-column=0"},
+		{"my-table", "out.csv", "include-timestamps=maybe"},
+		{"my-table", "out.csv", "prefix=a", "start=b"},
+		{"my-table", "out.csv", "bogus=1"},
+		{"my-table", "out.csv", "format=parquet"},
+		{"my-table", "-", "format=sqlite"},
+		{"my-table", "out.db", "format=sqlite", "parts=true"},
+		{"my-table", "out.db", "format=sqlite", "include-timestamps=true"},
+		{"my-table", "out.csv", "cells-per-column=2"},
+	} {
+		if _, err := parseExporterArgs(args); err == nil {
+			t.Errorf("parseExporterArgs(%q) did not fail", args)
+		}
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f", "g"})
+	tbl := c.Open("my-table")
+	for _, row := range []string{"r1", "r2"} {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", 1000, []byte(row+"-a-old"))
+		mut.Set("f", "a", 2000, []byte(row+"-a"))
+		mut.Set("g", "b", 1000, []byte(row+"-b"))
+		if err := tbl.Apply(ctx, row, mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, test := range []struct {
+		args []string
+		want string
+	}{
+		{
+			args: nil,
+			want: ",f,g\n,a,b\nr1,r1-a,r1-b\nr2,r2-a,r2-b\n",
+		},
+		{
+			args: []string{"columns=g:b,f:a", "prefix=r2"},
+			want: ",g,f\n,b,a\nr2,r2-b,r2-a\n",
+		},
+		{
+			args: []string{"include-timestamps=true", "cells-per-column=2", "end=r2"},
+			want: ",f,g\n,a,b\nr1,r1-a-old@1000,r1-b@1000\nr1,r1-a@2000,\n",
+		},
+		{
+			args: []string{"columns=f:a", "to=2000"},
+			want: ",f\n,a\nr1,r1-a-old\nr2,r2-a-old\n",
+		},
+		{
+			args: []string{"value-regex=.*-a", "regex=r1"},
+			want: ",f\n,a\nr1,r1-a\n",
+		},
+		{
+			args: []string{"any-of=column:g:b;value:r2-a"},
+			want: ",f,g\n,a,b\nr1,,r1-b\nr2,r2-a,r2-b\n",
+		},
+	} {
+		ea, err := parseExporterArgs(append([]string{"my-table", "-"}, test.args...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if _, err := exportCSV(ctx, e, tbl, &buf, ea); err != nil {
+			t.Fatalf("exportCSV(%q) failed: %v", test.args, err)
+		}
+		if got := buf.String(); got != test.want {
+			t.Errorf("exportCSV(%q) =\n%s\nwant\n%s", test.args, got, test.want)
+		}
+	}
+}
+
+func TestExportSQLite(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f", "g"})
+	tbl := c.Open("my-table")
+	for i := 0; i < 20; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", 1000, []byte("old"))
+		mut.Set("f", "a", 2000, []byte(fmt.Sprint(i)))
+		mut.Set("g", "b", 1000, []byte("b"))
+		if err := tbl.Apply(ctx, fmt.Sprintf("r%02d", i), mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := filepath.Join(t.TempDir(), "rows.db")
+	ea, err := parseExporterArgs([]string{"my-table", out, "format=sqlite", "cells-per-column=2", "end=r02"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := exportSQLite(ctx, e, tbl, out, ea)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("exportSQLite read %d rows, want 2", n)
+	}
+	sql, got := readSQLiteDB(t, out)
+	if sql != sqliteCellsTable {
+		t.Errorf("table SQL = %q", sql)
+	}
+	var want [][]interface{}
+	for i := 0; i < 2; i++ {
+		key := fmt.Sprintf("r%02d", i)
+		want = append(want,
+			[]interface{}{key, "f", "a", int64(2000), []byte(fmt.Sprint(i))},
+			[]interface{}{key, "f", "a", int64(1000), []byte("old")},
+			[]interface{}{key, "g", "b", int64(1000), []byte("b")})
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("cells mismatch (-want +got):\n%s", diff)
+	}
+
+	// Sharded reads write the same cells, though not necessarily in order.
+	if ea, err = parseExporterArgs([]string{"my-table", out, "format=sqlite", "workers=4", "columns=f:a"}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err = exportSQLite(ctx, e, tbl, out, ea); err != nil || n != 20 {
+		t.Fatalf("exportSQLite(workers=4) = %d, %v, want 20 rows", n, err)
+	}
+	if _, got = readSQLiteDB(t, out); len(got) != 20 {
+		t.Errorf("exportSQLite(workers=4) wrote %d cells, want 20", len(got))
+	}
+}
+
+func TestExportShards(t *testing.T) {
+	keys := []string{"b", "d", "f", "h", ""}
+	for _, test := range []struct {
+		start, end string
+		n          int
+		want       []string
+	}{
+		{"", "", 1, []string{"", ""}},
+		{"", "", 2, []string{"", "f", ""}},
+		{"", "", 10, []string{"", "b", "d", "f", "h", ""}},
+		{"c", "g", 10, []string{"c", "d", "f", "g"}},
+		{"x", "", 4, []string{"x", ""}},
+	} {
+		if got := exportShards(keys, test.start, test.end, test.n); !cmp.Equal(got, test.want) {
+			t.Errorf("exportShards(%q, %q, %d) = %q, want %q", test.start, test.end, test.n, got, test.want)
+		}
+	}
+	if got, want := partName("out/data.csv", 3, 12), "out/data-00003-of-00012.csv"; got != want {
+		t.Errorf("partName = %q, want %q", got, want)
+	}
+	if got, want := prefixEnd("ab\xff"), "ac"; got != want {
+		t.Errorf("prefixEnd = %q, want %q", got, want)
+	}
+}
+
+func TestExportSharded(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	tbl := c.Open("my-table")
+	for i := 0; i < 50; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", 1000, []byte(fmt.Sprint(i)))
+		if err := tbl.Apply(ctx, fmt.Sprintf("r%02d", i), mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ea, err := parseExporterArgs([]string{"my-table", "-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want bytes.Buffer
+	if _, err := exportCSV(ctx, e, tbl, &want, ea); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.csv")
+	ea.workers = 4
+	n, err := exportSharded(ctx, e, tbl, out, ea)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 50 {
+		t.Errorf("exportSharded wrote %d rows, want 50", n)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want.String() {
+		t.Errorf("merged sharded export =\n%s\nwant\n%s", got, want.String())
+	}
+}
+
+// failingTable fails a scan after delivering a number of rows.
+type failingTable struct {
+	TableReader
+	after int
+}
+
+func (ft *failingTable) ReadRows(ctx context.Context, arg bigtable.RowSet, f func(bigtable.Row) bool, opts ...bigtable.ReadOption) error {
+	n := 0
+	err := ft.TableReader.ReadRows(ctx, arg, func(r bigtable.Row) bool {
+		if n++; n > ft.after {
+			return false
+		}
+		return f(r)
+	}, opts...)
+	if err == nil && n > ft.after {
+		err = errors.New("interrupted")
+	}
+	return err
+}
+
+func TestExportResume(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	tbl := c.Open("my-table")
+	var keys []string
+	var muts []*bigtable.Mutation
+	for i := 0; i < 2500; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", 1000, []byte(fmt.Sprint(i)))
+		keys, muts = append(keys, fmt.Sprintf("r%04d", i)), append(muts, mut)
+	}
+	if errs, err := tbl.ApplyBulk(ctx, keys, muts); err != nil || errs != nil {
+		t.Fatal(err, errs)
+	}
+
+	// Name the columns so that the interruption is not in column discovery.
+	ea, err := parseExporterArgs([]string{"my-table", "-", "columns=f:a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want bytes.Buffer
+	if _, err := exportCSV(ctx, e, tbl, &want, ea); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.csv")
+	if _, err := exportToFile(ctx, e, &failingTable{tbl, 1500}, "my-table", out, ea); err == nil {
+		t.Fatal("interrupted export did not fail")
+	}
+	cp, err := loadExportCheckpoint(checkpointName(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(cp.LastRowKey), "r0999"; got != want {
+		t.Errorf("checkpoint last row key = %q, want %q", got, want)
+	}
+
+	ea.resume = true
+	n, err := exportToFile(ctx, e, tbl, "my-table", out, ea)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1500 {
+		t.Errorf("resumed export wrote %d rows, want 1500", n)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want.String() {
+		t.Errorf("resumed export differs from a complete export")
+	}
+	if _, err := os.Stat(checkpointName(out)); !os.IsNotExist(err) {
+		t.Errorf("checkpoint not removed after export completed: %v", err)
+	}
+}
+
+func TestExportImportVersions(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	ctx, c := setupEmulator(t, []string{"src", "dst"}, []string{"f"})
+	src := c.Open("src")
+	mut := bigtable.NewMutation()
+	mut.Set("f", "a", 1000, []byte("old"))
+	mut.Set("f", "a", 2000, []byte("new"))
+	if err := src.Apply(ctx, "r1", mut); err != nil {
+		t.Fatal(err)
+	}
+
+	ea, err := parseExporterArgs([]string{"src", "-", "include-timestamps=true", "cells-per-column=2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := exportCSV(ctx, e, src, &buf, ea); err != nil {
+		t.Fatal(err)
+	}
+	ia, err := parseImporterArgs(ctx, e.config, []string{"dst", "data.csv", "timestamp=value-encoded"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ia.sz, ia.workers = 1, 1
+	dst := c.Open("dst")
+	if err := importCSV(ctx, e, dst, csv.NewReader(&buf), ia); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := dst.ReadRow(ctx, "r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, item := range r["f"] {
+		got = append(got, fmt.Sprintf("%s@%d", item.Value, item.Timestamp))
+	}
+	if want := []string{"new@2000", "old@1000"}; !cmp.Equal(got, want) {
+		t.Errorf("after export and import, f:a = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// tableRequestWorkers is the number of tables that commands working across
+// many tables query at once.
+const tableRequestWorkers = 8
+
+// matchTables returns the sorted names of the tables matching a glob
+// pattern, or of all tables if pattern is empty.
+func matchTables(ctx context.Context, ac AdminAPI, pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("bad pattern %q: %v", pattern, err)
+	}
+	tables, err := ac.Tables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var matched []string
+	for _, t := range tables {
+		if ok, _ := path.Match(pattern, t); pattern == "" || ok {
+			matched = append(matched, t)
+		}
+	}
+	sort.Strings(matched)
+	return matched, nil
+}
+
+// progress counts finished items on a line of w that is rewritten in place.
+// A nil *progress prints nothing.
+type progress struct {
+	mu          sync.Mutex
+	w           io.Writer
+	what        string
+	done, total int
+	width       int
+}
+
+// newTableProgress returns a progress for total tables shown on w, or nil if
+// there are too few to be worth it or w isn't a terminal.
+func newTableProgress(w io.Writer, total int) *progress {
+	if total <= tableRequestWorkers || !isTerminal(w) {
+		return nil
+	}
+	return &progress{w: w, what: "tables", total: total}
+}
+
+func (p *progress) step() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	line := fmt.Sprintf("%d/%d %s", p.done, p.total, p.what)
+	p.width = len(line)
+	fmt.Fprintf(p.w, "\r%s", line)
+}
+
+// finish erases the progress line.
+func (p *progress) finish() {
+	if p == nil || p.width == 0 {
+		return
+	}
+	fmt.Fprintf(p.w, "\r%s\r", strings.Repeat(" ", p.width))
+}
+
+// forEachTable calls f for each table, running up to tableRequestWorkers
+// calls at once, and shows the progress on w if it is a terminal.
+func forEachTable(w io.Writer, tables []string, f func(i int, table string)) {
+	p := newTableProgress(w, len(tables))
+	defer p.finish()
+	sem := make(chan struct{}, tableRequestWorkers)
+	var wg sync.WaitGroup
+	for i, t := range tables {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, t string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			f(i, t)
+			p.step()
+		}(i, t)
+	}
+	wg.Wait()
+}
+
+// tableFamilies holds the column families of a table, or the error getting
+// them.
+type tableFamilies struct {
+	instance, table string
+	families        []bigtable.FamilyInfo
+	err             error
+}
+
+// fetchFamilies gets the column families of tables concurrently, showing the
+// progress on progress.
+func fetchFamilies(ctx context.Context, progress io.Writer, ac AdminAPI, instance string, tables []string) []tableFamilies {
+	results := make([]tableFamilies, len(tables))
+	forEachTable(progress, tables, func(i int, table string) {
+		results[i] = tableFamilies{instance: instance, table: table}
+		ti, err := ac.TableInfo(ctx, table)
+		if err != nil {
+			results[i].err = err
+			return
+		}
+		sort.Sort(byFamilyName(ti.FamilyInfos))
+		results[i].families = ti.FamilyInfos
+	})
+	return results
+}
+
+// printFamilies prints one line per family, width columns wide, logs the
+// tables whose families could not be read to logger and returns their number.
+func printFamilies(w io.Writer, logger *log.Logger, width int, results []tableFamilies, showInstance bool) (int, error) {
+	header := []string{"Table", "Family Name", "GC Policy", "Value Type"}
+	if showInstance {
+		header = append([]string{"Instance"}, header...)
+	}
+	var rows [][]string
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			logger.Printf("Getting table info of %s: %v", r.table, r.err)
+			failed++
+			continue
+		}
+		for _, fam := range r.families {
+			jsonString, err := bigtable.MarshalJSON(fam.ValueType)
+			if err != nil {
+				return 0, fmt.Errorf("Getting table info: %v", err)
+			}
+			row := []string{r.table, fam.Name, fam.GCPolicy, string(jsonString)}
+			if showInstance {
+				row = append([]string{r.instance}, row...)
+			}
+			rows = append(rows, row)
+		}
+	}
+	printTable(w, width, header, rows)
+	return failed, nil
+}
+
+func doFamilies(ctx context.Context, e *env, args ...string) error {
+	parsed, err := parseArgs(args, []string{"pattern", "all-instances"})
+	if err != nil {
+		return errors.New("usage: cbt families [pattern=<table-glob>] [all-instances=<true|false>]")
+	}
+	instances := []string{e.config.Instance}
+	allInstances := parsed["all-instances"] == "true"
+	if allInstances {
+		iac, err := e.getInstanceAdminClient()
+		if err != nil {
+			return err
+		}
+		infos, err := iac.Instances(ctx)
+		if err != nil {
+			return fmt.Errorf("Getting list of instances: %v", err)
+		}
+		instances = nil
+		for _, info := range infos {
+			instances = append(instances, info.Name)
+		}
+		sort.Strings(instances)
+	}
+
+	var results []tableFamilies
+	for _, instance := range instances {
+		if instance == "" {
+			return errors.New("missing -instance")
+		}
+		ac, err := e.getAdminClientForInstance(ctx, instance)
+		if err != nil {
+			return fmt.Errorf("Making admin client for %s: %v", instance, err)
+		}
+		tables, err := matchTables(ctx, ac, parsed["pattern"])
+		if err == nil {
+			results = append(results, fetchFamilies(ctx, e.log.Writer(), ac, instance, tables)...)
+		}
+		if ac != e.adminClient {
+			ac.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("Getting list of tables: %v", err)
+		}
+	}
+	failed, err := printFamilies(e.stdout, e.log, e.outputWidth(), results, allInstances)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("Could not get the families of %d table(s)", failed)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFamilies(t *testing.T) {
+	ctx, ac, _ := newEmulatorClients(t)
+	for _, table := range []string{"prod-b", "prod-a", "test-a"} {
+		if err := ac.CreateTableFromConf(ctx, &bigtable.TableConf{
+			TableID: table,
+			ColumnFamilies: map[string]bigtable.Family{
+				"g": {GCPolicy: bigtable.NoGcPolicy()},
+				"f": {GCPolicy: bigtable.MaxVersionsPolicy(1)},
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tables, err := matchTables(ctx, ac, "prod-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"prod-a", "prod-b"}, tables); diff != "" {
+		t.Errorf("matchTables mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := matchTables(ctx, ac, "["); err == nil {
+		t.Error("matchTables with a bad pattern did not fail")
+	}
+
+	results := fetchFamilies(ctx, io.Discard, ac, "instance", tables)
+	results = append(results, tableFamilies{table: "broken", err: errors.New("boom")})
+	var buf bytes.Buffer
+	if failed, err := printFamilies(&buf, discardLog, 0, results, false); err != nil || failed != 1 {
+		t.Errorf("printFamilies reported %d failures and error %v, want 1 failure", failed, err)
+	}
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n")[2:] {
+		got = append(got, strings.Join(strings.Fields(line)[:2], " "))
+	}
+	want := []string{"prod-a f", "prod-a g", "prod-b f", "prod-b g"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("printed families mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	var buf bytes.Buffer
+	p := &progress{w: &buf, what: "tables", total: 10}
+	p.step()
+	p.step()
+	p.finish()
+	if got, want := buf.String(), "\r1/10 tables\r2/10 tables\r           \r"; got != want {
+		t.Errorf("progress printed %q, want %q", got, want)
+	}
+
+	// A nil progress prints nothing.
+	var none *progress
+	none.step()
+	none.finish()
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// allInstancesOption is the option of fan-out commands that runs them on every
+// instance of the project.
+const allInstancesOption = "all-instances"
+
+// parseAllInstances removes all-instances=<true|false> from the arguments of
+// a fan-out command, and reports whether it was true.
+func parseAllInstances(args []string) (bool, []string, error) {
+	var all bool
+	var rest []string
+	for _, arg := range args {
+		if v := strings.TrimPrefix(arg, allInstancesOption+"="); v != arg {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return false, nil, fmt.Errorf("bad %s: %v", arg, err)
+			}
+			all = b
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return all, rest, nil
+}
+
+// fanOutInstances returns the sorted instances named by -instances, or all
+// instances of the project if all is set.
+func fanOutInstances(ctx context.Context, e *env, instances string, all bool) ([]string, error) {
+	var names []string
+	if all {
+		iac, err := e.getInstanceAdminClient()
+		if err != nil {
+			return nil, err
+		}
+		infos, err := iac.Instances(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("getting list of instances: %v", err)
+		}
+		for _, info := range infos {
+			names = append(names, info.Name)
+		}
+	} else {
+		for _, name := range strings.Split(instances, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no instances to run on")
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// runFanOut runs do with args on each instance in turn and merges the output
+// into e.stdout. Lines of text are prefixed with an instance column. If the
+// output is JSON, e.stdout gets a JSON object of each instance's output. log
+// messages name the instance, and the first instance that fails stops the
+// command.
+func runFanOut(ctx context.Context, e *env, instances []string, do func(context.Context, *env, ...string) error, args []string) error {
+	width := 0
+	for _, instance := range instances {
+		if len(instance) > width {
+			width = len(instance)
+		}
+	}
+	var merged map[string]json.RawMessage
+	for i, instance := range instances {
+		var buf bytes.Buffer
+		ie := e.forInstance(instance, &buf)
+		err := do(ctx, ie, args...)
+		ie.close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", instance, err)
+		}
+		out := buf.Bytes()
+		if i == 0 && json.Valid(out) {
+			merged = map[string]json.RawMessage{}
+		}
+		if merged != nil {
+			if !json.Valid(out) {
+				return fmt.Errorf("%s: output is not JSON", instance)
+			}
+			merged[instance] = bytes.TrimSpace(out)
+			continue
+		}
+		if len(out) == 0 {
+			continue
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(string(out), "\n"), "\n") {
+			fmt.Fprintf(e.stdout, "%-*s  %s\n", width, instance, line)
+		}
+	}
+	if merged != nil {
+		return writeJSON(e.stdout, merged)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"cloud.google.com/go/bigtable/bttest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseAllInstances(t *testing.T) {
+	for _, test := range []struct {
+		args     []string
+		wantAll  bool
+		wantArgs []string
+		wantErr  bool
+	}{
+		{args: []string{"my-table"}, wantArgs: []string{"my-table"}},
+		{args: []string{"all-instances=true", "format=json"}, wantAll: true, wantArgs: []string{"format=json"}},
+		{args: []string{"all-instances=false"}},
+		{args: []string{"all-instances=everywhere"}, wantErr: true},
+	} {
+		all, args, err := parseAllInstances(test.args)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseAllInstances(%q) error = %v, want error %t", test.args, err, test.wantErr)
+			continue
+		}
+		if all != test.wantAll || !cmp.Equal(args, test.wantArgs) {
+			t.Errorf("parseAllInstances(%q) = %t, %q, want %t, %q", test.args, all, args, test.wantAll, test.wantArgs)
+		}
+	}
+}
+
+func TestRunFanOut(t *testing.T) {
+	srv, err := bttest.NewServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	t.Setenv(emulatorHostEnv, srv.Addr)
+	ctx := context.Background()
+	for instance, tables := range map[string][]string{"a": {"t1"}, "bb": {"t2", "t3"}} {
+		ac, err := bigtable.NewAdminClient(ctx, "proj", instance)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, table := range tables {
+			if err := ac.CreateTable(ctx, table); err != nil {
+				t.Fatal(err)
+			}
+		}
+		ac.Close()
+	}
+
+	e, out := newTestEnv(Deps{})
+	e.config = &Config{Project: "proj", Instance: "orig"}
+	if err := runFanOut(ctx, e, []string{"a", "bb"}, doLS, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "a   t1\nbb  t2\nbb  t3\n"; got != want {
+		t.Errorf("fan-out ls = %q, want %q", got, want)
+	}
+	if e.config.Instance != "orig" || e.adminClient != nil {
+		t.Errorf("after fan-out, instance = %q and admin client = %v, want orig and nil", e.config.Instance, e.adminClient)
+	}
+
+	out.Reset()
+	printInstance := func(ctx context.Context, e *env, args ...string) error {
+		_, err := fmt.Fprintf(e.stdout, "{\"name\": %q}\n", e.config.Instance)
+		return err
+	}
+	if err := runFanOut(ctx, e, []string{"a", "bb"}, printInstance, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"a\": {\n    \"name\": \"a\"\n  },\n  \"bb\": {\n    \"name\": \"bb\"\n  }\n}\n"
+	if got := out.String(); got != want {
+		t.Errorf("fan-out JSON = %q, want %q", got, want)
+	}
+}
+
+func TestFanOutInstances(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	got, err := fanOutInstances(context.Background(), e, "prod-us, prod-eu,,", false)
+	if err != nil || !cmp.Equal(got, []string{"prod-eu", "prod-us"}) {
+		t.Errorf("fanOutInstances = %q, %v, want [prod-eu prod-us]", got, err)
+	}
+	if _, err := fanOutInstances(context.Background(), e, " , ", false); err == nil {
+		t.Error("fanOutInstances with no instances succeeded")
+	}
+}
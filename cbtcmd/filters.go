@@ -0,0 +1,183 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"cloud.google.com/go/cbt/cli"
+)
+
+// filterGroupArgs are the arguments that compose filters: any-of interleaves
+// its filters, so cells matching any of them are returned, and all-of chains
+// them, so only cells matching all of them are.
+var filterGroupArgs = []string{"any-of", "all-of"}
+
+// parseFilterTerm parses one filter of an any-of or all-of group.
+func parseFilterTerm(term string) (bigtable.Filter, error) {
+	kind, arg, ok := strings.Cut(term, ":")
+	if !ok || arg == "" {
+		return nil, fmt.Errorf("bad filter %q: want <kind>:<argument>", term)
+	}
+	switch kind {
+	case "column":
+		return cli.ParseColumnFilter(arg)
+	case "family":
+		return bigtable.FamilyFilter(arg), nil
+	case "qualifier":
+		return bigtable.ColumnFilter(arg), nil
+	case "value":
+		return bigtable.ValueFilter(arg), nil
+	case "key":
+		return bigtable.RowKeyFilter(arg), nil
+	case "latest":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("bad filter %q: latest needs a positive number of cells", term)
+		}
+		return bigtable.LatestNFilter(n), nil
+	case "label":
+		return bigtable.LabelFilter(arg), nil
+	}
+	return nil, fmt.Errorf("bad filter %q: unknown kind %q", term, kind)
+}
+
+// parseFilterGroup parses the ';'-separated filters of an any-of or all-of
+// group and composes them.
+func parseFilterGroup(group, spec string) (bigtable.Filter, error) {
+	var filters []bigtable.Filter
+	for _, term := range strings.Split(spec, ";") {
+		if term = strings.TrimSpace(term); term == "" {
+			continue
+		}
+		f, err := parseFilterTerm(term)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", group, err)
+		}
+		filters = append(filters, f)
+	}
+	switch {
+	case len(filters) == 0:
+		return nil, fmt.Errorf("%s needs at least one filter", group)
+	case len(filters) == 1:
+		return filters[0], nil
+	case group == "any-of":
+		return bigtable.InterleaveFilters(filters...), nil
+	}
+	return bigtable.ChainFilters(filters...), nil
+}
+
+// parseFilterGroups returns the filters of the any-of and all-of arguments in
+// parsed, to be chained with a command's other filters.
+func parseFilterGroups(parsed map[string]string) ([]bigtable.Filter, error) {
+	var filters []bigtable.Filter
+	for _, group := range filterGroupArgs {
+		spec, ok := parsed[group]
+		if !ok {
+			continue
+		}
+		f, err := parseFilterGroup(group, spec)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// cellFilterArgs are the filter arguments shared by the commands that scan
+// rows, such as read and export.
+var cellFilterArgs = []string{"regex", "value-regex", "from", "to", "any-of", "all-of", "if", "then", "else"}
+
+// parseCellFilters returns the filters of the cellFilterArgs in parsed, to be
+// chained with a command's other filters. Timestamps are parsed relative to
+// now.
+func parseCellFilters(parsed map[string]string, now time.Time) ([]bigtable.Filter, error) {
+	var filters []bigtable.Filter
+	if regex := parsed["regex"]; regex != "" {
+		filters = append(filters, bigtable.RowKeyFilter(regex))
+	}
+	if regex := parsed["value-regex"]; regex != "" {
+		filters = append(filters, bigtable.ValueFilter(regex))
+	}
+	if parsed["from"] != "" || parsed["to"] != "" {
+		start, end, err := parseTimestampRange(parsed["from"], parsed["to"], now)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, bigtable.TimestampRangeFilterMicros(start, end))
+	}
+	groups, err := parseFilterGroups(parsed)
+	if err != nil {
+		return nil, err
+	}
+	filters = append(filters, groups...)
+	cond, err := parseConditionFilter(parsed)
+	if err != nil {
+		return nil, err
+	}
+	if cond != nil {
+		filters = append(filters, cond)
+	}
+	return filters, nil
+}
+
+// parseConditionFilter returns the condition filter of the if, then and else
+// arguments in parsed, or nil if there are none. Each is a ';'-separated
+// chain of filters, like all-of. A missing then or else returns no cells.
+func parseConditionFilter(parsed map[string]string) (bigtable.Filter, error) {
+	var branches [3]bigtable.Filter
+	for i, arg := range []string{"if", "then", "else"} {
+		spec, ok := parsed[arg]
+		if !ok {
+			continue
+		}
+		f, err := parseFilterGroup(arg, spec)
+		if err != nil {
+			return nil, err
+		}
+		branches[i] = f
+	}
+	switch {
+	case branches[0] == nil && branches[1] == nil && branches[2] == nil:
+		return nil, nil
+	case branches[0] == nil:
+		return nil, fmt.Errorf("then and else need an if")
+	case branches[1] == nil && branches[2] == nil:
+		return nil, fmt.Errorf("if needs a then or an else")
+	}
+	return bigtable.ConditionFilter(branches[0], branches[1], branches[2]), nil
+}
+
+// sinkLabel labels the cells returned by sink=true.
+const sinkLabel = "sink"
+
+// withSink makes a read with filter also return every cell as read, labelled
+// sinkLabel, so that the filter's output can be compared with its input. The
+// client library has no sink filter, but at the top of a read, interleaving
+// the labelled input with the filter's output has the same effect as a chain
+// that starts with a labelled sink.
+func withSink(filter bigtable.Filter) bigtable.Filter {
+	if filter == nil {
+		filter = bigtable.PassAllFilter()
+	}
+	return bigtable.InterleaveFilters(bigtable.LabelFilter(sinkLabel), filter)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+)
+
+func TestParseFilterGroups(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f", "g"})
+	tbl := c.Open("my-table")
+	mut := bigtable.NewMutation()
+	mut.Set("f", "a", 1000, []byte("apple"))
+	mut.Set("f", "b", 1000, []byte("banana"))
+	mut.Set("g", "a", 1000, []byte("avocado"))
+	mut.Set("g", "c", 1000, []byte("cherry"))
+	if err := tbl.Apply(ctx, "r1", mut); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		parsed map[string]string
+		want   string
+	}{
+		{map[string]string{"any-of": "value:cherry;column:f:b"}, "f:b g:c"},
+		{map[string]string{"any-of": "value:a.*"}, "f:a g:a"},
+		{map[string]string{"all-of": "qualifier:a;family:g"}, "g:a"},
+		{map[string]string{"any-of": "family:f;value:cherry", "all-of": "qualifier:a|c; value:.*r.*"}, "g:c"},
+	} {
+		filters, err := parseFilterGroups(test.parsed)
+		if err != nil {
+			t.Fatalf("parseFilterGroups(%v): %v", test.parsed, err)
+		}
+		filter := filters[0]
+		if len(filters) > 1 {
+			filter = bigtable.ChainFilters(filters...)
+		}
+		r, err := tbl.ReadRow(ctx, "r1", bigtable.RowFilter(filter))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, items := range r {
+			for _, item := range items {
+				got = append(got, item.Column)
+			}
+		}
+		sort.Strings(got)
+		if strings.Join(got, " ") != test.want {
+			t.Errorf("%v: got columns %v, want %s", test.parsed, got, test.want)
+		}
+	}
+
+	for _, spec := range []string{"", ";", "value", "latest:0", "size:3", "column:a:b:c"} {
+		if _, err := parseFilterGroups(map[string]string{"any-of": spec}); err == nil {
+			t.Errorf("any-of=%s: got no error", spec)
+		}
+	}
+}
+
+func TestWithSink(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	tbl := c.Open("my-table")
+	mut := bigtable.NewMutation()
+	mut.Set("f", "a", 1000, []byte("apple"))
+	mut.Set("f", "b", 1000, []byte("banana"))
+	if err := tbl.Apply(ctx, "r1", mut); err != nil {
+		t.Fatal(err)
+	}
+	labelled, err := parseFilterGroup("all-of", "qualifier:b;label:kept")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		filter bigtable.Filter
+		want   string
+	}{
+		{labelled, "f:a[sink] f:b[kept] f:b[sink]"},
+		{nil, "f:a[] f:a[sink] f:b[] f:b[sink]"},
+	} {
+		r, err := tbl.ReadRow(ctx, "r1", bigtable.RowFilter(withSink(test.filter)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, item := range r["f"] {
+			got = append(got, fmt.Sprintf("%s%v", item.Column, item.Labels))
+		}
+		sort.Strings(got)
+		if strings.Join(got, " ") != test.want {
+			t.Errorf("withSink(%v): got cells %v, want %s", test.filter, got, test.want)
+		}
+	}
+}
+
+func TestParseConditionFilter(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f", "g"})
+	tbl := c.Open("my-table")
+	for row, value := range map[string]string{"r1": "apple", "r2": "banana"} {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", 1000, []byte(value))
+		mut.Set("g", "b", 1000, []byte("v"))
+		if err := tbl.Apply(ctx, row, mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, test := range []struct {
+		parsed map[string]string
+		want   string
+	}{
+		{map[string]string{"if": "value:apple", "then": "family:f", "else": "family:g"}, "r1/f:a r2/g:b"},
+		{map[string]string{"if": "value:apple", "then": "family:f;qualifier:a"}, "r1/f:a"},
+		{map[string]string{"if": "value:apple", "else": "family:g"}, "r2/g:b"},
+	} {
+		filter, err := parseConditionFilter(test.parsed)
+		if err != nil {
+			t.Fatalf("parseConditionFilter(%v): %v", test.parsed, err)
+		}
+		var got []string
+		err = tbl.ReadRows(ctx, bigtable.InfiniteRange(""), func(r bigtable.Row) bool {
+			for _, items := range r {
+				for _, item := range items {
+					got = append(got, r.Key()+"/"+item.Column)
+				}
+			}
+			return true
+		}, bigtable.RowFilter(filter))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(got)
+		if strings.Join(got, " ") != test.want {
+			t.Errorf("%v: got cells %v, want %s", test.parsed, got, test.want)
+		}
+	}
+
+	if f, err := parseConditionFilter(map[string]string{}); f != nil || err != nil {
+		t.Errorf("parseConditionFilter with no arguments = %v, %v, want nil, nil", f, err)
+	}
+	for _, parsed := range []map[string]string{
+		{"then": "family:f"},
+		{"if": "value:apple"},
+		{"if": "size:3", "then": "family:f"},
+	} {
+		if _, err := parseConditionFilter(parsed); err == nil {
+			t.Errorf("parseConditionFilter(%v): got no error", parsed)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// findRow checks which of tables contain row, querying them concurrently and
+// showing the progress on progress. It returns, in the order of tables,
+// whether each contains the row and the error checking it.
+func findRow(ctx context.Context, progress io.Writer, c *bigtable.Client, tables []string, row string) ([]bool, []error) {
+	found := make([]bool, len(tables))
+	errs := make([]error, len(tables))
+	forEachTable(progress, tables, func(i int, table string) {
+		found[i], errs[i] = rowExists(ctx, c.Open(table), row)
+	})
+	return found, errs
+}
+
+func doFind(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt find <row-key> [tables=<table-id>,... | pattern=<table-glob>] [app-profile=<app profile id>]"
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+	parsed, err := parseArgs(args[1:], []string{"tables", "pattern", "app-profile"})
+	if err != nil {
+		return errors.New(usage)
+	}
+	var tables []string
+	if t := parsed["tables"]; t != "" {
+		if parsed["pattern"] != "" {
+			return errors.New("tables= and pattern= cannot both be given")
+		}
+		tables = strings.Split(t, ",")
+	} else {
+		ac, err := e.getAdminClient()
+		if err != nil {
+			return err
+		}
+		if tables, err = matchTables(ctx, ac, parsed["pattern"]); err != nil {
+			return fmt.Errorf("Getting list of tables: %v", err)
+		}
+	}
+
+	c, err := e.getClient(bigtable.ClientConfig{AppProfile: parsed["app-profile"]})
+	if err != nil {
+		return err
+	}
+	found, errs := findRow(ctx, e.log.Writer(), c, tables, args[0])
+	n, failed := 0, 0
+	for i, table := range tables {
+		switch {
+		case errs[i] != nil:
+			e.log.Printf("Reading row from %s: %v", table, errs[i])
+			failed++
+		case found[i]:
+			fmt.Fprintln(e.stdout, table)
+			n++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("Could not check %d of %d table(s)", failed, len(tables))
+	}
+	if n == 0 {
+		// As with 'cbt exists', exit code 1 is already used for errors.
+		return &ExitError{Code: 3}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"io"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindRow(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"users", "orders", "events"}, []string{"f"})
+	for _, table := range []string{"users", "events"} {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "c", bigtable.Now(), []byte("v"))
+		if err := c.Open(table).Apply(ctx, "key#1", mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, errs := findRow(ctx, io.Discard, c, []string{"users", "orders", "events", "missing"}, "key#1")
+	if diff := cmp.Diff([]bool{true, false, true, false}, found); diff != "" {
+		t.Errorf("found mismatch (-want +got):\n%s", diff)
+	}
+	for i, err := range errs[:3] {
+		if err != nil {
+			t.Errorf("table %d: unexpected error %v", i, err)
+		}
+	}
+	if errs[3] == nil {
+		t.Error("reading from a missing table did not fail")
+	}
+}
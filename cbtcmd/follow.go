@@ -0,0 +1,95 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// defaultFollowInterval is how often read follow=true polls for new cells.
+const defaultFollowInterval = 5 * time.Second
+
+// isFollowing reports whether args request follow mode, whose output should
+// not be paged.
+func isFollowing(args []string) bool {
+	for _, arg := range args {
+		if v, ok := strings.CutPrefix(arg, "follow="); ok {
+			b, err := strconv.ParseBool(v)
+			return err == nil && b
+		}
+	}
+	return false
+}
+
+// newerThan returns filter restricted to cells with timestamps after ts.
+// Bigtable timestamps have millisecond granularity, so the range starts at
+// the next millisecond.
+func newerThan(ts bigtable.Timestamp, filter bigtable.Filter) bigtable.Filter {
+	after := bigtable.TimestampRangeFilterMicros(ts.TruncateToMilliseconds()+1000, 0)
+	if filter == nil {
+		return after
+	}
+	return bigtable.ChainFilters(after, filter)
+}
+
+// followRead calls f with the rows read by rd, and then every interval with
+// the rows that have cells newer than any seen so far, holding only those
+// cells, until ctx is done or f returns false. filter must be the filter of
+// rd. Cells written
+// with timestamps older than ones already seen are missed, so this suits
+// time-series rows whose cells are written at the current time.
+func followRead(ctx context.Context, logger *log.Logger, tbl TableReader, rd resumableRead, filter bigtable.Filter, interval time.Duration, f func(bigtable.Row) bool) error {
+	seen := false
+	var newest bigtable.Timestamp
+	for {
+		poll := rd
+		if seen {
+			poll.opts = []bigtable.ReadOption{bigtable.RowFilter(newerThan(newest, filter))}
+		}
+		latest := newest
+		stopped := false
+		err := poll.run(ctx, logger, tbl, func(r bigtable.Row) bool {
+			for _, items := range r {
+				for _, item := range items {
+					if !seen || item.Timestamp > latest {
+						latest, seen = item.Timestamp, true
+					}
+				}
+			}
+			stopped = !f(r)
+			return !stopped
+		})
+		if ctx.Err() != nil || stopped {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		newest = latest
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+)
+
+func TestIsFollowing(t *testing.T) {
+	for _, test := range []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"my-table", "prefix=a"}, false},
+		{[]string{"my-table", "follow=true"}, true},
+		{[]string{"my-table", "follow=false"}, false},
+		{[]string{"my-table", "follow=bogus"}, false},
+	} {
+		if got := isFollowing(test.args); got != test.want {
+			t.Errorf("isFollowing(%q) = %v, want %v", test.args, got, test.want)
+		}
+	}
+}
+
+func TestFollowRead(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	tbl := c.Open("my-table")
+	set := func(row string, ts bigtable.Timestamp, value string) {
+		t.Helper()
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", ts, []byte(value))
+		if err := tbl.Apply(ctx, row, mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	set("p#1", 1000, "old")
+	set("q#1", 5000, "other prefix")
+
+	rows := make(chan bigtable.Row)
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() {
+		rd := resumableRead{start: "p#", end: prefixEnd("p#")}
+		done <- followRead(ctx, discardLog, tbl, rd, nil, 10*time.Millisecond, func(r bigtable.Row) bool {
+			rows <- r
+			return true
+		})
+	}()
+	next := func() bigtable.Row {
+		t.Helper()
+		select {
+		case r := <-rows:
+			return r
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for a row")
+			return nil
+		}
+	}
+	cells := func(r bigtable.Row) []string {
+		var values []string
+		for _, item := range r["f"] {
+			values = append(values, string(item.Value))
+		}
+		return values
+	}
+
+	if r := next(); r.Key() != "p#1" || len(cells(r)) != 1 {
+		t.Errorf("first poll returned %s %q", r.Key(), cells(r))
+	}
+	set("p#1", 2000, "new")
+	set("p#2", 3000, "newer")
+	if r := next(); r.Key() != "p#1" || len(cells(r)) != 1 || cells(r)[0] != "new" {
+		t.Errorf("got %s %q, want only the new cell of p#1", r.Key(), cells(r))
+	}
+	if r := next(); r.Key() != "p#2" || cells(r)[0] != "newer" {
+		t.Errorf("got %s %q, want p#2", r.Key(), cells(r))
+	}
+	select {
+	case r := <-rows:
+		t.Errorf("unchanged rows were read again: %s %q", r.Key(), cells(r))
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("followRead returned %v after cancellation", err)
+	}
+}
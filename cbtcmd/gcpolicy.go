@@ -0,0 +1,119 @@
+/*
+Copyright 2015 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"cloud.google.com/go/cbt/cli"
+)
+
+// parseGCPolicy parses a GC policy; see cli.ParseGCPolicy.
+func parseGCPolicy(s string) (bigtable.GCPolicy, error) {
+	return cli.ParseGCPolicy(s)
+}
+
+// gcEligible reports which cells of a single column would be eligible for
+// garbage collection under policy at time now. The cells must be ordered
+// newest first, as they are returned by ReadRows.
+func gcEligible(policy bigtable.GCPolicy, cells []bigtable.ReadItem, now time.Time) []bool {
+	eligible := make([]bool, len(cells))
+	switch p := policy.(type) {
+	case bigtable.MaxVersionsGCPolicy:
+		for i := int(p); i < len(cells); i++ {
+			eligible[i] = true
+		}
+	case bigtable.MaxAgeGCPolicy:
+		cutoff := bigtable.Time(now.Add(-time.Duration(p)))
+		for i, c := range cells {
+			eligible[i] = c.Timestamp < cutoff
+		}
+	case bigtable.IntersectionGCPolicy:
+		for i := range eligible {
+			eligible[i] = len(p.Children) > 0
+		}
+		for _, child := range p.Children {
+			for i, e := range gcEligible(child, cells, now) {
+				eligible[i] = eligible[i] && e
+			}
+		}
+	case bigtable.UnionGCPolicy:
+		for _, child := range p.Children {
+			for i, e := range gcEligible(child, cells, now) {
+				eligible[i] = eligible[i] || e
+			}
+		}
+	}
+	return eligible
+}
+
+// gcPendingLabel is the label that read with gc-pending=true adds to cells
+// past their family's GC policy.
+const gcPendingLabel = "gc-pending"
+
+// familyGCPolicies returns the GC policy of each family of table that has
+// one.
+func (e *env) familyGCPolicies(ctx context.Context, table string) (map[string]bigtable.GCPolicy, error) {
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return nil, err
+	}
+	ti, err := ac.TableInfo(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	policies := map[string]bigtable.GCPolicy{}
+	for _, fi := range ti.FamilyInfos {
+		if fi.FullGCPolicy != nil {
+			policies[fi.Name] = fi.FullGCPolicy
+		}
+	}
+	return policies, nil
+}
+
+// markGCPending labels the cells of r that are eligible for garbage
+// collection under policies at time now, and returns the number labelled.
+// Garbage collection runs in the background, so such cells can be read for
+// up to a week after they expire. Versions are counted among the cells of r,
+// so filters that skip newer cells make older ones look current.
+func markGCPending(r bigtable.Row, policies map[string]bigtable.GCPolicy, now time.Time) int {
+	n := 0
+	for fam, items := range r {
+		policy, ok := policies[fam]
+		if !ok {
+			continue
+		}
+		// Policies apply to each column separately.
+		for start := 0; start < len(items); {
+			end := start + 1
+			for end < len(items) && items[end].Column == items[start].Column {
+				end++
+			}
+			for i, e := range gcEligible(policy, items[start:end], now) {
+				if e {
+					item := &items[start+i]
+					item.Labels = append(item.Labels[:len(item.Labels):len(item.Labels)], gcPendingLabel)
+					n++
+				}
+			}
+			start = end
+		}
+	}
+	return n
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2015 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGCEligible(t *testing.T) {
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	// Newest first, one cell per day.
+	var cells []bigtable.ReadItem
+	for i := 0; i < 5; i++ {
+		cells = append(cells, bigtable.ReadItem{Timestamp: bigtable.Time(now.Add(-time.Duration(i)*24*time.Hour - time.Hour))})
+	}
+	for _, test := range []struct {
+		policy string
+		want   []bool
+	}{
+		{"never", []bool{false, false, false, false, false}},
+		{"maxversions=2", []bool{false, false, true, true, true}},
+		{"maxage=3d", []bool{false, false, false, true, true}},
+		{"maxage=3d || maxversions=2", []bool{false, false, true, true, true}},
+		{"maxage=1d && maxversions=3", []bool{false, false, false, true, true}},
+		{"maxage=10d || (maxage=1d && maxversions=4)", []bool{false, false, false, false, true}},
+	} {
+		pol, err := parseGCPolicy(test.policy)
+		if err != nil {
+			t.Fatalf("%s: %v", test.policy, err)
+		}
+		if got := gcEligible(pol, cells, now); !cmp.Equal(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.policy, got, test.want)
+		}
+	}
+}
+
+func TestMarkGCPending(t *testing.T) {
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	ts := func(d time.Duration) bigtable.Timestamp { return bigtable.Time(now.Add(-d)) }
+	r := bigtable.Row{
+		"f": {
+			{Row: "r", Column: "f:a", Timestamp: ts(time.Hour)},
+			{Row: "r", Column: "f:a", Timestamp: ts(2 * time.Hour), Labels: []string{"l"}},
+			{Row: "r", Column: "f:b", Timestamp: ts(3 * time.Hour)},
+		},
+		"g": {
+			{Row: "r", Column: "g:a", Timestamp: ts(48 * time.Hour)},
+		},
+		"h": {
+			{Row: "r", Column: "h:a", Timestamp: ts(48 * time.Hour)},
+		},
+	}
+	policies := map[string]bigtable.GCPolicy{
+		"f": bigtable.MaxVersionsPolicy(1),
+		"g": bigtable.MaxAgePolicy(24 * time.Hour),
+	}
+	if got := markGCPending(r, policies, now); got != 2 {
+		t.Errorf("markGCPending = %d, want 2", got)
+	}
+	labels := func(fam string) [][]string {
+		var ls [][]string
+		for _, item := range r[fam] {
+			ls = append(ls, item.Labels)
+		}
+		return ls
+	}
+	if got, want := labels("f"), [][]string{nil, {"l", gcPendingLabel}, nil}; !cmp.Equal(got, want) {
+		t.Errorf("labels of f = %v, want %v", got, want)
+	}
+	if got, want := labels("g"), [][]string{{gcPendingLabel}}; !cmp.Equal(got, want) {
+		t.Errorf("labels of g = %v, want %v", got, want)
+	}
+	if got, want := labels("h"), [][]string{nil}; !cmp.Equal(got, want) {
+		t.Errorf("labels of h, which has no policy = %v, want %v", got, want)
+	}
+}
+
+// gcPolicyAdmin reports families with GC policies that the emulator hasn't
+// applied.
+type gcPolicyAdmin struct {
+	AdminAPI
+	families []bigtable.FamilyInfo
+}
+
+func (a *gcPolicyAdmin) TableInfo(ctx context.Context, table string) (*bigtable.TableInfo, error) {
+	return &bigtable.TableInfo{FamilyInfos: a.families}, nil
+}
+
+func TestReadGCPending(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	ac := &gcPolicyAdmin{families: []bigtable.FamilyInfo{{Name: "f", FullGCPolicy: bigtable.MaxVersionsPolicy(1)}}}
+	e, out := newTestEnv(Deps{Client: c, AdminClient: ac})
+	mut := bigtable.NewMutation()
+	mut.Set("f", "c", 1000, []byte("old"))
+	mut.Set("f", "c", 2000, []byte("new"))
+	if err := c.Open("my-table").Apply(ctx, "r", mut); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := doRead(ctx, e, "my-table", "gc-pending=true"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if len(lines) < 6 || strings.Contains(lines[2], gcPendingLabel) || !strings.HasSuffix(lines[4], "["+gcPendingLabel+"]") {
+		t.Errorf("read printed\n%s\nwant only the old cell labelled %s", out, gcPendingLabel)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// generator produces deterministic synthetic rows: the same arguments always
+// produce the same keys and values.
+type generator struct {
+	keyPattern string
+	families   []string
+	columns    int
+	valueSize  int
+}
+
+var seqPlaceholder = regexp.MustCompile(`\{seq(?::(\d+))?\}`)
+
+// rowKey expands the {seq} and {seq:<width>} placeholders of the key pattern.
+func (g *generator) rowKey(seq int64) string {
+	return seqPlaceholder.ReplaceAllStringFunc(g.keyPattern, func(m string) string {
+		width := seqPlaceholder.FindStringSubmatch(m)[1]
+		if width == "" {
+			return strconv.FormatInt(seq, 10)
+		}
+		return fmt.Sprintf("%0"+width+"d", seq)
+	})
+}
+
+const generatedValueChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// value returns the printable value of one generated cell.
+func (g *generator) value(key, family, column string) []byte {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%s\x00%s", key, family, column)
+	rnd := rand.New(rand.NewSource(int64(h.Sum64())))
+	v := make([]byte, g.valueSize)
+	for i := range v {
+		v[i] = generatedValueChars[rnd.Intn(len(generatedValueChars))]
+	}
+	return v
+}
+
+func (g *generator) mutation(key string, ts bigtable.Timestamp) *bigtable.Mutation {
+	mut := bigtable.NewMutation()
+	for _, fam := range g.families {
+		for c := 0; c < g.columns; c++ {
+			col := "col" + strconv.Itoa(c)
+			mut.Set(fam, col, ts, g.value(key, fam, col))
+		}
+	}
+	return mut
+}
+
+func doGenerate(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt generate <table> rows=<n> [families=<family>,...] [columns=<1>] [value-size=<16>] [key-pattern=<row{seq}>] [workers=<1>] [app-profile=<app profile id>]"
+	if len(args) < 2 {
+		return errors.New(usage)
+	}
+	parsed, err := parseArgs(args[1:], []string{"rows", "families", "columns", "value-size", "key-pattern", "workers", "app-profile"})
+	if err != nil {
+		return err
+	}
+	// argErr is the error of the first bad argument given to intArg.
+	var argErr error
+	intArg := func(name string, def, min int64) int64 {
+		v, ok := parsed[name]
+		if !ok || argErr != nil {
+			return def
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < min {
+			argErr = fmt.Errorf("Bad %s %q: must be an integer >= %d", name, v, min)
+		}
+		return n
+	}
+	if _, ok := parsed["rows"]; !ok {
+		return errors.New(usage)
+	}
+	rows := intArg("rows", 0, 1)
+	workers := int(intArg("workers", 1, 1))
+	g := &generator{
+		keyPattern: "row{seq}",
+		columns:    int(intArg("columns", 1, 1)),
+		valueSize:  int(intArg("value-size", 16, 0)),
+	}
+	if argErr != nil {
+		return argErr
+	}
+	if p := parsed["key-pattern"]; p != "" {
+		if !seqPlaceholder.MatchString(p) {
+			return fmt.Errorf("Bad key-pattern %q: must contain {seq} or {seq:<width>}", p)
+		}
+		g.keyPattern = p
+	}
+	if fams := parsed["families"]; fams != "" {
+		g.families = strings.Split(fams, ",")
+	} else {
+		ac, err := e.getAdminClient()
+		if err != nil {
+			return err
+		}
+		ti, err := ac.TableInfo(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("Getting table info: %v", err)
+		}
+		g.families = ti.Families
+		sort.Strings(g.families)
+		if len(g.families) == 0 {
+			return fmt.Errorf("Table %s has no column families", args[0])
+		}
+	}
+
+	c, err := e.getClient(bigtable.ClientConfig{AppProfile: parsed["app-profile"]})
+	if err != nil {
+		return err
+	}
+	tbl := c.Open(args[0])
+	ts := bigtable.Now().TruncateToMilliseconds()
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			const batchSize = 500
+			var keys []string
+			var muts []*bigtable.Mutation
+			for seq := int64(worker); seq < rows; seq += int64(workers) {
+				key := g.rowKey(seq)
+				keys, muts = append(keys, key), append(muts, g.mutation(key, ts))
+				if len(keys) == batchSize || seq+int64(workers) >= rows {
+					if _, err := e.batchWrite(ctx, tbl, keys, muts, worker); err != nil {
+						errs <- err
+						return
+					}
+					keys, muts = nil, nil
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	select {
+	case err := <-errs:
+		return fmt.Errorf("Writing rows: %v", err)
+	default:
+	}
+	fmt.Fprintf(e.stdout, "Generated %d rows.\n", rows)
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+)
+
+func TestGeneratorRowKey(t *testing.T) {
+	for pattern, want := range map[string]string{
+		"row{seq}":           "row42",
+		"user{seq:06}":       "user000042",
+		"{seq:3}#{seq}#tail": "042#42#tail",
+	} {
+		g := &generator{keyPattern: pattern}
+		if got := g.rowKey(42); got != want {
+			t.Errorf("rowKey(42) with pattern %q = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f1", "f2"})
+	e, _ := newTestEnv(Deps{Client: c})
+
+	if err := doGenerate(ctx, e, "my-table", "rows=25", "families=f1,f2", "columns=2", "value-size=8", "key-pattern=k{seq:03}", "workers=3"); err != nil {
+		t.Fatal(err)
+	}
+
+	g := &generator{valueSize: 8}
+	n := 0
+	err := c.Open("my-table").ReadRows(ctx, bigtable.InfiniteRange(""), func(r bigtable.Row) bool {
+		n++
+		for fam, items := range r {
+			if len(items) != 2 {
+				t.Errorf("row %s family %s has %d cells, want 2", r.Key(), fam, len(items))
+			}
+			for _, item := range items {
+				col := item.Column[len(fam)+1:]
+				if want := g.value(r.Key(), fam, col); !bytes.Equal(item.Value, want) {
+					t.Errorf("row %s column %s = %q, want %q", r.Key(), item.Column, item.Value, want)
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 25 {
+		t.Errorf("generated %d rows, want 25", n)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	btapb "cloud.google.com/go/bigtable/admin/apiv2/adminpb"
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+)
+
+// heartbeatInterval is how often a long operation that prints nothing while
+// it runs, such as a restore, reports that it is still running, so that
+// users and CI jobs that time out silent steps can tell cbt is alive.
+var heartbeatInterval = 30 * time.Second
+
+// withHeartbeat calls f, logging every heartbeatInterval until it returns
+// that what is still in progress, and for how long. If status is not nil, its
+// result, such as the progress of the operation, is added to the message
+// unless it is empty.
+func withHeartbeat(ctx context.Context, e *env, what string, status func(context.Context) string, f func() error) error {
+	start := time.Now()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		t := time.NewTicker(heartbeatInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+			}
+			msg := fmt.Sprintf("Still %s after %v", what, time.Since(start).Round(time.Second))
+			if status != nil {
+				if s := status(ctx); s != "" {
+					msg += ": " + s
+				}
+			}
+			e.log.Print(msg)
+		}
+	}()
+	defer wg.Wait()
+	defer close(done)
+	return f()
+}
+
+// backupStatus returns a status function for withHeartbeat reporting the
+// state and size of a backup being created.
+func backupStatus(ac AdminAPI, cluster, backup string) func(context.Context) string {
+	return func(ctx context.Context) string {
+		bi, err := ac.BackupInfo(ctx, cluster, backup)
+		if err != nil {
+			return ""
+		}
+		return fmt.Sprintf("backup %s is %s, %s so far", backup, bi.State, formatBytes(bi.SizeBytes))
+	}
+}
+
+// restoreStatus returns a status function for withHeartbeat reporting the
+// progress of the restore operation op.
+func restoreStatus(e *env, op *longrunningpb.Operation) func(context.Context) string {
+	return func(ctx context.Context) string {
+		ops, err := e.getOperationsClient()
+		if err != nil {
+			return ""
+		}
+		cur, err := ops.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: op.GetName()})
+		if err != nil || cur.GetMetadata() == nil {
+			return ""
+		}
+		var md btapb.RestoreTableMetadata
+		if err := cur.GetMetadata().UnmarshalTo(&md); err != nil || md.GetProgress() == nil {
+			return ""
+		}
+		return fmt.Sprintf("%d%% done", md.GetProgress().GetProgressPercent())
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestWithHeartbeat(t *testing.T) {
+	defer func(d time.Duration) { heartbeatInterval = d }(heartbeatInterval)
+	heartbeatInterval = 10 * time.Millisecond
+	var logged bytes.Buffer
+	e, _ := newTestEnv(Deps{Stderr: &logged})
+
+	want := errors.New("done")
+	calls := 0
+	err := withHeartbeat(context.Background(), e, "restoring t", func(context.Context) string {
+		calls++
+		if calls == 1 {
+			return ""
+		}
+		return "50% done"
+	}, func() error {
+		time.Sleep(55 * time.Millisecond)
+		return want
+	})
+	if err != want {
+		t.Errorf("withHeartbeat = %v, want the error of f, %v", err, want)
+	}
+	out := logged.String()
+	if !regexp.MustCompile(`Still restoring t after \d+s\n`).MatchString(out) {
+		t.Errorf("no heartbeat without a status in %q", out)
+	}
+	if !regexp.MustCompile(`Still restoring t after \d+s: 50% done\n`).MatchString(out) {
+		t.Errorf("no heartbeat with the status in %q", out)
+	}
+
+	// Nothing is logged once f returns.
+	logged.Reset()
+	time.Sleep(30 * time.Millisecond)
+	if logged.Len() != 0 {
+		t.Errorf("logged %q after withHeartbeat returned", logged.String())
+	}
+}
+
+func TestWithHeartbeatQuick(t *testing.T) {
+	var logged bytes.Buffer
+	e, _ := newTestEnv(Deps{Stderr: &logged})
+	if err := withHeartbeat(context.Background(), e, "deleting all rows of t", nil, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if logged.Len() != 0 {
+		t.Errorf("a quick operation logged %q", logged.String())
+	}
+}
@@ -0,0 +1,370 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// importRetryAttempts is the number of times the rows of a batch that failed
+// with transient errors are retried.
+const importRetryAttempts = 5
+
+// importRetryBackoff is the delay before retrying the failed rows of a batch.
+// It doubles with each attempt.
+var importRetryBackoff = 500 * time.Millisecond
+
+// rowError is the error writing a row.
+type rowError struct {
+	key string
+	err error
+}
+
+// isTransientWriteError reports whether writing a row may succeed if retried.
+func isTransientWriteError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// applyBulkWithRetry writes rows with ApplyBulk, retrying with backoff only
+// the rows that failed with transient errors. It returns the rows that could
+// not be written, or the error of a whole ApplyBulk call.
+func (e *env) applyBulkWithRetry(ctx context.Context, tbl *bigtable.Table, keys []string, muts []*bigtable.Mutation, worker int) ([]rowError, error) {
+	e.log.Printf("[%d] Writing batch:: size: %d, firstRowKey: %s, lastRowKey: %s\n", worker, len(keys), keys[0], keys[len(keys)-1])
+	var failed []rowError
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		errs, err := tbl.ApplyBulk(ctx, keys, muts)
+		if e.writeStats != nil {
+			e.writeStats.record(time.Since(start))
+		}
+		if err != nil {
+			return nil, err
+		}
+		var retryKeys []string
+		var retryMuts []*bigtable.Mutation
+		for i, err := range errs {
+			switch {
+			case err == nil:
+			case isTransientWriteError(err) && attempt < importRetryAttempts:
+				retryKeys, retryMuts = append(retryKeys, keys[i]), append(retryMuts, muts[i])
+			default:
+				failed = append(failed, rowError{keys[i], err})
+			}
+		}
+		if len(retryKeys) == 0 {
+			return failed, nil
+		}
+		e.log.Printf("[%d] Retrying %d of %d rows after transient errors", worker, len(retryKeys), len(keys))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(importRetryBackoff << attempt):
+		}
+		keys, muts = retryKeys, retryMuts
+	}
+}
+
+// importBatch is a batch of rows for an import worker to write.
+type importBatch struct {
+	keys []string
+	muts []*bigtable.Mutation
+}
+
+// importWorkerStats counts what an import worker wrote, and the time it spent
+// writing.
+type importWorkerStats struct {
+	rows, batches int
+	busy          time.Duration
+}
+
+// importPipeline writes the batches sent to it with a pool of workers. At most
+// inFlight parsed batches wait for a worker, so that memory stays bounded
+// however large the input is.
+type importPipeline struct {
+	env     *env
+	batches chan importBatch
+	ctx     context.Context
+	cancel  func()
+	wg      sync.WaitGroup
+	stats   []importWorkerStats
+
+	mu       sync.Mutex
+	err      error // the first write error
+	failures map[codes.Code]*rowFailures
+}
+
+// startImportPipeline starts workers writing to tbl. If inFlight is not
+// positive, twice as many batches as workers may wait.
+func startImportPipeline(ctx context.Context, e *env, tbl *bigtable.Table, workers, inFlight int) *importPipeline {
+	if inFlight <= 0 {
+		inFlight = 2 * workers
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	p := &importPipeline{
+		env:      e,
+		batches:  make(chan importBatch, inFlight),
+		ctx:      ctx,
+		cancel:   cancel,
+		stats:    make([]importWorkerStats, workers),
+		failures: map[codes.Code]*rowFailures{},
+	}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go func(worker int) {
+			defer p.wg.Done()
+			for b := range p.batches {
+				if p.failed() != nil {
+					continue
+				}
+				start := time.Now()
+				failed, err := e.applyBulkWithRetry(ctx, tbl, b.keys, b.muts, worker)
+				if err != nil {
+					p.fail(fmt.Errorf("applying bulk mutations process error: %v", err))
+					continue
+				}
+				p.recordFailures(failed)
+				s := &p.stats[worker]
+				s.rows += len(b.keys) - len(failed)
+				s.batches++
+				s.busy += time.Since(start)
+			}
+		}(i)
+	}
+	return p
+}
+
+func (p *importPipeline) failed() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// fail records the first error and stops the other workers' writes.
+func (p *importPipeline) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = err
+		p.cancel()
+	}
+}
+
+// recordFailures records rows that could not be written. They don't stop
+// the import, but are summarized at the end.
+func (p *importPipeline) recordFailures(failed []rowError) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, f := range failed {
+		code := status.Code(f.err)
+		if p.failures[code] == nil {
+			p.failures[code] = &rowFailures{first: f}
+		}
+		p.failures[code].count++
+	}
+}
+
+// send queues a batch, waiting while inFlight batches are queued. It returns
+// the first write error instead once there is one, or the context's error
+// once the import is canceled, so that no more input is read.
+func (p *importPipeline) send(b importBatch) error {
+	if err := p.failed(); err != nil {
+		return err
+	}
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+	p.batches <- b
+	return nil
+}
+
+// close waits for the queued batches to be written, and returns the number of
+// rows written and the first write error. If rows failed, it logs the number
+// that failed with each error code and returns an error.
+func (p *importPipeline) close() (int, error) {
+	close(p.batches)
+	p.wg.Wait()
+	p.cancel()
+	total := 0
+	for _, s := range p.stats {
+		total += s.rows
+	}
+	if err := p.failed(); err != nil {
+		return total, err
+	}
+	if n := logRowFailures(p.env.log, p.failures); n > 0 {
+		return total, fmt.Errorf("%d rows could not be written", n)
+	}
+	return total, nil
+}
+
+// rowFailures counts the rows that failed with an error code.
+type rowFailures struct {
+	count int
+	first rowError
+}
+
+// logRowFailures logs the number of rows that failed with each error code,
+// and the first of each, to logger, and returns the total.
+func logRowFailures(logger *log.Logger, failures map[codes.Code]*rowFailures) int {
+	var codeList []codes.Code
+	for code := range failures {
+		codeList = append(codeList, code)
+	}
+	sort.Slice(codeList, func(i, j int) bool { return codeList[i] < codeList[j] })
+	total := 0
+	for _, code := range codeList {
+		f := failures[code]
+		total += f.count
+		logger.Printf("%s: %d rows, first %q: %v", code, f.count, f.first.key, f.first.err)
+	}
+	return total
+}
+
+// logThroughput logs the rows each worker wrote and its rate while writing.
+func (p *importPipeline) logThroughput() {
+	for i, s := range p.stats {
+		rate := 0.0
+		if s.busy > 0 {
+			rate = float64(s.rows) / s.busy.Seconds()
+		}
+		p.env.log.Printf("[%d] Wrote %d rows in %d batches, %.0f rows/s", i, s.rows, s.batches, rate)
+	}
+}
+
+// csvValueOptions control how the values of CSV cells are imported.
+type csvValueOptions struct {
+	// trimSpace removes leading and trailing white space from values.
+	trimSpace bool
+	// nullString, if set, is the value of NULL cells, such as \N in MySQL
+	// exports. NULL cells are treated as empty.
+	nullString string
+	// emptyAsDelete deletes the column of empty cells, instead of leaving
+	// it as it is.
+	emptyAsDelete bool
+}
+
+// csvMutation returns the mutation setting the cells of a CSV line, and
+// whether it changes any.
+func csvMutation(line []string, tstype string, fams, cols []string, ts bigtable.Timestamp, vo csvValueOptions) (*bigtable.Mutation, bool) {
+	mut := bigtable.NewMutation()
+	empty := true
+	for i, val := range line {
+		if i == 0 {
+			continue
+		}
+		if vo.trimSpace {
+			val = strings.TrimSpace(val)
+		}
+		if vo.nullString != "" && val == vo.nullString {
+			val = ""
+		}
+		if val == "" && vo.emptyAsDelete {
+			mut.DeleteCellsInColumn(fams[i], cols[i])
+			empty = false
+		}
+		if val != "" {
+			setts := ts
+			if tstype == "value-encoded" {
+				if i := strings.LastIndex(val, "@"); i >= 0 {
+					// Try parsing a timestamp.
+					n, err := strconv.ParseInt(val[i+1:], 0, 64)
+					if err == nil {
+						val = val[:i]
+						setts = bigtable.Timestamp(n)
+					}
+				}
+			}
+			mut.Set(fams[i], cols[i], setts, []byte(val))
+			empty = false
+		}
+	}
+	return mut, !empty
+}
+
+// safeReader parses the rows of a CSV file into batches for import workers.
+// Only one goroutine reads the file, so the workers never wait on each other
+// for their input.
+type safeReader struct {
+	r *csv.Reader
+	t int // total rows
+	// inFlight is the most parsed batches waiting for a worker; see
+	// startImportPipeline.
+	inFlight int
+	// logThroughput logs each worker's throughput when the import is done.
+	logThroughput bool
+	values        csvValueOptions
+}
+
+// parseAndWrite reads the remaining rows of the CSV file and writes them in
+// batches of max rows with the given number of workers.
+func (sr *safeReader) parseAndWrite(ctx context.Context, e *env, tbl *bigtable.Table, tstype string, fams, cols []string, ts bigtable.Timestamp, max, workers int) error {
+	p := startImportPipeline(ctx, e, tbl, workers, sr.inFlight)
+	var b importBatch
+	var err error
+	for err == nil {
+		var line []string
+		if line, err = sr.r.Read(); err != nil {
+			break
+		}
+		mut, ok := csvMutation(line, tstype, fams, cols, ts, sr.values)
+		if !ok {
+			e.log.Printf("RowKey '%s' has no mutations, skipping", line[0])
+			continue
+		}
+		if line[0] == "" {
+			e.log.Printf("RowKey not present, skipping line")
+			continue
+		}
+		b.keys, b.muts = append(b.keys, line[0]), append(b.muts, mut)
+		if len(b.keys) == max {
+			err = p.send(b)
+			b = importBatch{}
+		}
+	}
+	if err == io.EOF {
+		err = nil
+		if len(b.keys) > 0 {
+			err = p.send(b)
+		}
+	}
+	n, werr := p.close()
+	sr.t += n
+	if sr.logThroughput {
+		p.logThroughput()
+	}
+	if err == nil {
+		err = werr
+	}
+	return err
+}
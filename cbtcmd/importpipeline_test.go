@@ -0,0 +1,211 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	btpb "cloud.google.com/go/bigtable/apiv2/bigtablepb"
+	"cloud.google.com/go/bigtable/bttest"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseAndWriteWorkers(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	ctx, client := setupEmulator(t, []string{"my-table"}, []string{"my-family"})
+	tbl := client.Open("my-table")
+	fams := []string{"", "my-family"}
+	cols := []string{"", "col"}
+	var rowData [][]string
+	for i := 0; i < 250; i++ {
+		rowData = append(rowData, []string{fmt.Sprintf("rk-%03d", i), fmt.Sprint(i)})
+	}
+	byteData, err := transformToCsvBuffer(rowData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sr := safeReader{r: csv.NewReader(bytes.NewReader(byteData)), inFlight: 1, logThroughput: true}
+	if err := sr.parseAndWrite(ctx, e, tbl, "now", fams, cols, 1, 7, 4); err != nil {
+		t.Fatalf("parseAndWrite() failed unexpectedly, error:%s", err)
+	}
+	if sr.t != len(rowData) {
+		t.Errorf("parseAndWrite() wrote %d rows, want %d", sr.t, len(rowData))
+	}
+	if err := validateData(ctx, tbl, "now", fams, cols, rowData); err != nil {
+		t.Fatalf("Read back validation error:%s", err)
+	}
+
+	// A write error stops the import rather than leaving the reader waiting
+	// for workers.
+	sr = safeReader{r: csv.NewReader(bytes.NewReader(byteData)), inFlight: 1}
+	if err := sr.parseAndWrite(ctx, e, tbl, "now", []string{"", "not-my-family"}, cols, 1, 7, 4); err == nil {
+		t.Fatal("parseAndWrite() should have failed with non-existent column family")
+	}
+}
+
+// exhaustingStream reports the second entry of a MutateRows response as
+// failed with ResourceExhausted.
+type exhaustingStream struct {
+	grpc.ClientStream
+}
+
+func (s exhaustingStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if resp, ok := m.(*btpb.MutateRowsResponse); ok && err == nil {
+		for _, e := range resp.Entries {
+			if e.Index == 1 {
+				e.Status = status.New(codes.ResourceExhausted, "too many writes").Proto()
+			}
+		}
+	}
+	return err
+}
+
+func TestApplyBulkWithRetry(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	defer func(d time.Duration) { importRetryBackoff = d }(importRetryBackoff)
+	importRetryBackoff = time.Millisecond
+
+	srv, err := bttest.NewServer("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	// Fail the second row of the first MutateRows call only.
+	calls := 0
+	interceptor := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err == nil && strings.HasSuffix(method, "/MutateRows") {
+			if calls++; calls == 1 {
+				return exhaustingStream{cs}, nil
+			}
+		}
+		return cs, err
+	}
+	conn, err := grpc.Dial(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithStreamInterceptor(interceptor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	ac, err := bigtable.NewAdminClient(ctx, "proj", "instance", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.CreateTableFromConf(ctx, &bigtable.TableConf{TableID: "my-table", Families: map[string]bigtable.GCPolicy{"f": bigtable.NoGcPolicy()}}); err != nil {
+		t.Fatal(err)
+	}
+	c, err := bigtable.NewClient(ctx, "proj", "instance", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl := c.Open("my-table")
+
+	keys := []string{"r1", "r2", "r3"}
+	var muts []*bigtable.Mutation
+	for range keys {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", 1000, []byte("v"))
+		muts = append(muts, mut)
+	}
+	failed, err := e.applyBulkWithRetry(ctx, tbl, keys, muts, 0)
+	if err != nil || len(failed) != 0 {
+		t.Fatalf("applyBulkWithRetry = %v, %v, want no failures", failed, err)
+	}
+	if calls != 2 {
+		t.Errorf("applyBulkWithRetry made %d MutateRows calls, want 2", calls)
+	}
+
+	// Permanent errors are returned without retrying.
+	bad := bigtable.NewMutation()
+	bad.Set("no-such-family", "a", 1000, []byte("v"))
+	failed, err = e.applyBulkWithRetry(ctx, tbl, []string{"r4", "r5"}, []*bigtable.Mutation{muts[0], bad}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].key != "r5" || isTransientWriteError(failed[0].err) {
+		t.Errorf("applyBulkWithRetry failed rows = %v, want r5 with a permanent error", failed)
+	}
+	if calls != 3 {
+		t.Errorf("applyBulkWithRetry made %d MutateRows calls in all, want 3", calls)
+	}
+
+	var buf bytes.Buffer
+	if n := logRowFailures(log.New(&buf, "", 0), map[codes.Code]*rowFailures{codes.NotFound: {count: 2, first: failed[0]}}); n != 2 {
+		t.Errorf("logRowFailures returned %d, want 2", n)
+	}
+	if !strings.Contains(buf.String(), `NotFound: 2 rows, first "r5"`) {
+		t.Errorf("logRowFailures logged %q", buf.String())
+	}
+}
+
+func TestParseAndWriteValueOptions(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	ctx, client := setupEmulator(t, []string{"my-table"}, []string{"cf"})
+	tbl := client.Open("my-table")
+	fams := []string{"", "cf", "cf", "cf"}
+	cols := []string{"", "a", "b", "c"}
+	// Every row starts with all three columns set.
+	for _, key := range []string{"r1", "r2"} {
+		mut := bigtable.NewMutation()
+		for _, col := range cols[1:] {
+			mut.Set("cf", col, 1, []byte("old"))
+		}
+		if err := tbl.Apply(ctx, key, mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	byteData, err := transformToCsvBuffer([][]string{
+		{"r1", "  x ", `\N`, ""},
+		{"r2", " ", "y", `\N`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr := safeReader{
+		r:      csv.NewReader(bytes.NewReader(byteData)),
+		values: csvValueOptions{trimSpace: true, nullString: `\N`, emptyAsDelete: true},
+	}
+	if err := sr.parseAndWrite(ctx, e, tbl, "now", fams, cols, 2, 10, 1); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	if err := tbl.ReadRows(ctx, bigtable.InfiniteRange(""), func(r bigtable.Row) bool {
+		for _, item := range r["cf"] {
+			got[r.Key()+" "+item.Column] = string(item.Value)
+		}
+		return true
+	}, bigtable.RowFilter(bigtable.LatestNFilter(1))); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"r1 cf:a": "x", "r2 cf:b": "y"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("cells = %v, want %v", got, want)
+	}
+}
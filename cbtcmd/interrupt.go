@@ -0,0 +1,91 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// interruptedExitCode is the exit status of a command stopped by SIGINT or
+// SIGTERM: the status a shell reports for a process killed by SIGINT.
+const interruptedExitCode = 130
+
+// errInterrupted is the cause of the cancellation of a command's context by
+// SIGINT or SIGTERM.
+var errInterrupted = errors.New("interrupted")
+
+// withInterrupt returns a context that is canceled when cbt receives SIGINT
+// or SIGTERM, so that long-running commands can stop between batches, save
+// their progress and report it. A second signal exits at once. stop stops
+// catching the signals.
+func withInterrupt(ctx context.Context) (_ context.Context, stop func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sigs:
+		case <-done:
+			return
+		}
+		log.Print("Interrupted, stopping; interrupt again to exit at once")
+		cancel(errInterrupted)
+		select {
+		case <-sigs:
+			os.Exit(interruptedExitCode)
+		case <-done:
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(sigs)
+		close(done)
+		cancel(nil)
+	}
+}
+
+// interrupted reports whether ctx was canceled by SIGINT or SIGTERM.
+func interrupted(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errInterrupted)
+}
+
+// interruptedError is the error of a command stopped by SIGINT or SIGTERM,
+// saying what it completed. cbt exits with interruptedExitCode after logging
+// it.
+type interruptedError struct {
+	msg string
+}
+
+func (e *interruptedError) Error() string {
+	return e.msg
+}
+
+func (e *interruptedError) Unwrap() error {
+	return errInterrupted
+}
+
+// interruptedAfter returns the error of an interrupted command that completed
+// the work described by format, as "Interrupted after " followed by format.
+func interruptedAfter(format string, args ...interface{}) error {
+	return &interruptedError{fmt.Sprintf("Interrupted after "+format, args...)}
+}
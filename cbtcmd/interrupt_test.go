@@ -0,0 +1,163 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// interruptingTable cancels the context of a scan as SIGINT would after
+// delivering after rows, and fails the scan with the context's error.
+type interruptingTable struct {
+	TableReader
+	after  int
+	cancel context.CancelCauseFunc
+}
+
+func (it *interruptingTable) ReadRows(ctx context.Context, arg bigtable.RowSet, f func(bigtable.Row) bool, opts ...bigtable.ReadOption) error {
+	n := 0
+	err := it.TableReader.ReadRows(ctx, arg, func(r bigtable.Row) bool {
+		if n == it.after {
+			it.cancel(errInterrupted)
+			return false
+		}
+		n++
+		return f(r)
+	}, opts...)
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
+
+func TestWithInterrupt(t *testing.T) {
+	ctx, stop := withInterrupt(context.Background())
+	defer stop()
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Skipf("can't send SIGINT: %v", err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("context not canceled by SIGINT")
+	}
+	if !interrupted(ctx) {
+		t.Errorf("interrupted = false after SIGINT, cause %v", context.Cause(ctx))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	if interrupted(ctx) {
+		t.Error("interrupted = true after a timeout")
+	}
+}
+
+func TestCountInterrupted(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	tbl := c.Open("my-table")
+	for i := 0; i < 10; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", 1000, []byte("v"))
+		if err := tbl.Apply(ctx, fmt.Sprintf("r%02d", i), mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	e, out := newTestEnv(Deps{Table: &interruptingTable{TableReader: tbl, after: 5, cancel: cancel}})
+
+	err := doCount(ctx, e, "my-table", "progress-interval=0")
+	if !errors.Is(err, errInterrupted) {
+		t.Errorf("count returned %v, want the interrupted error", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("count printed %q after an interruption, want nothing", out)
+	}
+	if want := `Interrupted after counting 5 rows, up to "r04"`; err == nil || err.Error() != want {
+		t.Errorf("count returned %v, want %q", err, want)
+	}
+}
+
+func TestExportToFileInterrupted(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	tbl := c.Open("my-table")
+	var keys []string
+	var muts []*bigtable.Mutation
+	for i := 0; i < 2500; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", 1000, []byte(fmt.Sprint(i)))
+		keys, muts = append(keys, fmt.Sprintf("r%04d", i)), append(muts, mut)
+	}
+	if errs, err := tbl.ApplyBulk(ctx, keys, muts); err != nil || errs != nil {
+		t.Fatal(err, errs)
+	}
+	ea, err := parseExporterArgs([]string{"my-table", "-", "columns=f:a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want bytes.Buffer
+	if _, err := exportCSV(ctx, e, tbl, &want, ea); err != nil {
+		t.Fatal(err)
+	}
+
+	// The interruption comes between checkpoints, so the rows written since
+	// the last one must be saved in a new one.
+	out := filepath.Join(t.TempDir(), "out.csv")
+	ictx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	n, err := exportToFile(ictx, e, &interruptingTable{TableReader: tbl, after: 1500, cancel: cancel}, "my-table", out, ea)
+	if err == nil || !interrupted(ictx) {
+		t.Fatalf("interrupted export returned %v", err)
+	}
+	if n != 1500 {
+		t.Errorf("interrupted export wrote %d rows, want 1500", n)
+	}
+	cp, err := loadExportCheckpoint(checkpointName(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(cp.LastRowKey), "r1499"; got != want {
+		t.Errorf("checkpoint last row key = %q, want %q", got, want)
+	}
+
+	ea.resume = true
+	if n, err := exportToFile(ctx, e, tbl, "my-table", out, ea); err != nil || n != 1000 {
+		t.Fatalf("resumed export = %d, %v; want 1000 rows", n, err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want.String() {
+		t.Errorf("resumed export differs from a complete export")
+	}
+}
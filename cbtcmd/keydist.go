@@ -0,0 +1,326 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+	btpb "cloud.google.com/go/bigtable/apiv2/bigtablepb"
+	"google.golang.org/api/option"
+	gtransport "google.golang.org/api/transport/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// getDataRPC returns a raw data API client. The bigtable package drops the
+// offsets returned by SampleRowKeys, which keydist and tablesize need.
+func (e *env) getDataRPC() (btpb.BigtableClient, error) {
+	if e.dataConn == nil {
+		var opts []option.ClientOption
+		if addr := os.Getenv("BIGTABLE_EMULATOR_HOST"); addr != "" {
+			opts = append(opts,
+				option.WithEndpoint(addr),
+				option.WithoutAuthentication(),
+				option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())))
+		} else {
+			ep := e.config.DataEndpoint
+			if ep == "" {
+				ep = e.config.endpoint("bigtable")
+			}
+			opts = append(opts,
+				option.WithEndpoint(ep),
+				option.WithScopes(bigtable.Scope),
+				option.WithUserAgent(e.userAgent))
+			opts = workloadTagOpts(e.config, opts)
+			opts = e.credentialOpts(opts)
+		}
+		conn, err := gtransport.DialPool(context.Background(), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("Dialing data API: %v", err)
+		}
+		e.dataConn = conn
+	}
+	return btpb.NewBigtableClient(e.dataConn), nil
+}
+
+// keySample is a row key returned by SampleRowKeys, along with the
+// approximate number of bytes stored in the table before it.
+type keySample struct {
+	Key    string
+	Offset int64
+}
+
+func (e *env) sampleRowKeys(ctx context.Context, table string) ([]keySample, error) {
+	rpc, err := e.getDataRPC()
+	if err != nil {
+		return nil, err
+	}
+	name := instanceName(e.config.Project, e.config.Instance) + "/tables/" + table
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-goog-request-params", "table_name="+url.QueryEscape(name))
+	stream, err := rpc.SampleRowKeys(ctx, &btpb.SampleRowKeysRequest{TableName: name})
+	if err != nil {
+		return nil, err
+	}
+	var samples []keySample
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, keySample{Key: string(res.RowKey), Offset: res.OffsetBytes})
+	}
+	return samples, nil
+}
+
+// keyRange is a contiguous range of row keys [Start, End). An empty End is
+// the end of the table.
+type keyRange struct {
+	Start, End string
+	Bytes      int64
+	Rows       int64
+}
+
+// keyRanges converts key samples into the ranges between them.
+func keyRanges(samples []keySample) []keyRange {
+	var ranges []keyRange
+	start, prev := "", int64(0)
+	for _, s := range samples {
+		ranges = append(ranges, keyRange{Start: start, End: s.Key, Bytes: s.Offset - prev})
+		start, prev = s.Key, s.Offset
+	}
+	if start != "" {
+		// The last sample normally has an empty key marking the end of the
+		// table; if it doesn't, keep the tail so scans can be attributed.
+		ranges = append(ranges, keyRange{Start: start})
+	}
+	return ranges
+}
+
+// groupKeyRanges merges adjacent ranges whose start keys share their first
+// prefixLength bytes, then merges neighbours until at most buckets remain.
+func groupKeyRanges(ranges []keyRange, buckets, prefixLength int) []keyRange {
+	if prefixLength > 0 {
+		var grouped []keyRange
+		for _, r := range ranges {
+			if n := len(grouped); n > 0 && truncateKey(grouped[n-1].Start, prefixLength) == truncateKey(r.Start, prefixLength) {
+				grouped[n-1].End = r.End
+				grouped[n-1].Bytes += r.Bytes
+				grouped[n-1].Rows += r.Rows
+				continue
+			}
+			grouped = append(grouped, r)
+		}
+		ranges = grouped
+	}
+	if buckets <= 0 || len(ranges) <= buckets {
+		return ranges
+	}
+	merged := make([]keyRange, buckets)
+	for i := range merged {
+		lo, hi := i*len(ranges)/buckets, (i+1)*len(ranges)/buckets
+		merged[i] = keyRange{Start: ranges[lo].Start, End: ranges[hi-1].End}
+		for _, r := range ranges[lo:hi] {
+			merged[i].Bytes += r.Bytes
+			merged[i].Rows += r.Rows
+		}
+	}
+	return merged
+}
+
+func truncateKey(key string, n int) string {
+	if len(key) > n {
+		return key[:n]
+	}
+	return key
+}
+
+// rangeIndex returns the index of the range containing key.
+func rangeIndex(ranges []keyRange, key string) int {
+	return sort.Search(len(ranges), func(i int) bool {
+		return ranges[i].End == "" || key < ranges[i].End
+	})
+}
+
+// formatBytes formats a byte count using binary units.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func printKeyDistribution(w io.Writer, ranges []keyRange, withRows bool) {
+	const barWidth = 40
+	var max int64
+	width := 0
+	for _, r := range ranges {
+		if r.Bytes > max {
+			max = r.Bytes
+		}
+		if l := len(fmt.Sprintf("%q", r.Start)); l > width {
+			width = l
+		}
+	}
+	for _, r := range ranges {
+		bar := 0
+		if max > 0 {
+			bar = int(r.Bytes * barWidth / max)
+		}
+		fmt.Fprintf(w, "%-*q %10s ", width, r.Start, formatBytes(r.Bytes))
+		if withRows {
+			fmt.Fprintf(w, "%10d ", r.Rows)
+		}
+		fmt.Fprintln(w, strings.Repeat("#", bar))
+	}
+}
+
+func doKeyDist(ctx context.Context, e *env, args ...string) error {
+	if len(args) < 1 {
+		return errors.New("usage: cbt keydist <table-id> [buckets=<50>] [prefix-length=<n>] [sample=<fraction>]")
+	}
+	parsed, err := parseArgs(args[1:], []string{"buckets", "prefix-length", "sample"})
+	if err != nil {
+		return err
+	}
+	buckets, prefixLength := 50, 0
+	if v := parsed["buckets"]; v != "" {
+		if _, err := fmt.Sscan(v, &buckets); err != nil || buckets <= 0 {
+			return fmt.Errorf("Bad buckets %q", v)
+		}
+	}
+	if v := parsed["prefix-length"]; v != "" {
+		if _, err := fmt.Sscan(v, &prefixLength); err != nil || prefixLength < 0 {
+			return fmt.Errorf("Bad prefix-length %q", v)
+		}
+	}
+	var sample float64
+	if v := parsed["sample"]; v != "" {
+		if _, err := fmt.Sscan(v, &sample); err != nil || sample <= 0 || sample > 1 {
+			return fmt.Errorf("Bad sample %q: must be in (0, 1]", v)
+		}
+	}
+
+	samples, err := e.sampleRowKeys(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("Sampling row keys: %v", err)
+	}
+	ranges := groupKeyRanges(keyRanges(samples), buckets, prefixLength)
+	if len(ranges) == 0 {
+		fmt.Fprintln(e.stdout, "No data.")
+		return nil
+	}
+
+	if sample > 0 {
+		filter := bigtable.ChainFilters(
+			bigtable.RowSampleFilter(sample),
+			bigtable.CellsPerRowLimitFilter(1),
+			bigtable.StripValueFilter(),
+		)
+		tbl, err := e.getTable(bigtable.ClientConfig{}, args[0])
+		if err != nil {
+			return err
+		}
+		err = tbl.ReadRows(ctx, bigtable.InfiniteRange(""), func(r bigtable.Row) bool {
+			ranges[rangeIndex(ranges, r.Key())].Rows++
+			return true
+		}, bigtable.RowFilter(filter))
+		if err != nil {
+			return fmt.Errorf("Reading rows: %v", err)
+		}
+		// Scale sampled counts back up to an estimate for the whole range.
+		for i := range ranges {
+			ranges[i].Rows = int64(float64(ranges[i].Rows)/sample + 0.5)
+		}
+	}
+	printKeyDistribution(e.stdout, ranges, sample > 0)
+	return nil
+}
+
+// rowSize returns the number of bytes in a row's keys and values.
+func rowSize(r bigtable.Row) int64 {
+	n := int64(len(r.Key()))
+	for fam, items := range r {
+		for _, item := range items {
+			n += int64(len(item.Column) - len(fam) - 1 + len(item.Value))
+		}
+	}
+	return n
+}
+
+func doTableSize(ctx context.Context, e *env, args ...string) error {
+	if len(args) != 1 {
+		return errors.New("usage: cbt tablesize <table-id>")
+	}
+	samples, err := e.sampleRowKeys(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("Sampling row keys: %v", err)
+	}
+	var total int64
+	if len(samples) > 0 {
+		total = samples[len(samples)-1].Offset
+	}
+	fmt.Fprintf(e.stdout, "Approximate size: %s (%d bytes)\n", formatBytes(total), total)
+	if total == 0 {
+		fmt.Fprintln(e.stdout, "Approximate rows: 0")
+		return nil
+	}
+
+	// Estimate the row count from the average size of a few rows read at
+	// evenly spaced sample keys, rather than counting every row.
+	const probes, rowsPerProbe = 10, 100
+	var rows, bytes int64
+	tbl, err := e.getTable(bigtable.ClientConfig{}, args[0])
+	if err != nil {
+		return err
+	}
+	ranges := keyRanges(samples)
+	for i := 0; i < probes && i < len(ranges); i++ {
+		start := ranges[i*len(ranges)/probes].Start
+		err := tbl.ReadRows(ctx, bigtable.InfiniteRange(start), func(r bigtable.Row) bool {
+			rows++
+			bytes += rowSize(r)
+			return true
+		}, bigtable.LimitRows(rowsPerProbe))
+		if err != nil {
+			return fmt.Errorf("Reading rows: %v", err)
+		}
+	}
+	if rows == 0 || bytes == 0 {
+		fmt.Fprintln(e.stdout, "Approximate rows: unknown")
+		return nil
+	}
+	fmt.Fprintf(e.stdout, "Approximate rows: %d (from %d sampled rows)\n", total*rows/bytes, rows)
+	return nil
+}
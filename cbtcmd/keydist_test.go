@@ -0,0 +1,90 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKeyRanges(t *testing.T) {
+	samples := []keySample{{"b", 10}, {"bb", 30}, {"c", 60}, {"", 100}}
+	want := []keyRange{
+		{Start: "", End: "b", Bytes: 10},
+		{Start: "b", End: "bb", Bytes: 20},
+		{Start: "bb", End: "c", Bytes: 30},
+		{Start: "c", End: "", Bytes: 40},
+	}
+	got := keyRanges(samples)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("keyRanges mismatch (-want +got):\n%s", diff)
+	}
+
+	grouped := groupKeyRanges(got, 50, 1)
+	want = []keyRange{
+		{Start: "", End: "b", Bytes: 10},
+		{Start: "b", End: "c", Bytes: 50},
+		{Start: "c", End: "", Bytes: 40},
+	}
+	if diff := cmp.Diff(want, grouped); diff != "" {
+		t.Errorf("groupKeyRanges by prefix mismatch (-want +got):\n%s", diff)
+	}
+
+	merged := groupKeyRanges(got, 2, 0)
+	want = []keyRange{
+		{Start: "", End: "bb", Bytes: 30},
+		{Start: "bb", End: "", Bytes: 70},
+	}
+	if diff := cmp.Diff(want, merged); diff != "" {
+		t.Errorf("groupKeyRanges by count mismatch (-want +got):\n%s", diff)
+	}
+
+	for key, want := range map[string]int{"": 0, "b": 0, "bb": 1, "bz": 1, "zzz": 1} {
+		if got := rangeIndex(merged, key); got != want {
+			t.Errorf("rangeIndex(%q) = %d, want %d", key, got, want)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{
+		0:           "0 B",
+		1023:        "1023 B",
+		1536:        "1.5 KiB",
+		5 << 30:     "5.0 GiB",
+		3 << 40 / 2: "1.5 TiB",
+	} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestRowSize(t *testing.T) {
+	r := bigtable.Row{
+		"f1": {
+			{Row: "row", Column: "f1:a", Value: []byte("12345")},
+			{Row: "row", Column: "f1:bb", Value: []byte("1")},
+		},
+		"f2": {{Row: "row", Column: "f2:", Value: nil}},
+	}
+	if got, want := rowSize(r), int64(3+1+5+2+1+0); got != want {
+		t.Errorf("rowSize = %d, want %d", got, want)
+	}
+}
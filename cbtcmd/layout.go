@@ -0,0 +1,131 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// defaultOutputWidth is the width tables are fitted to with -truncate when
+// the terminal width is unknown.
+const defaultOutputWidth = 80
+
+// tableColumnGap is the number of spaces between the columns of a fitted
+// table.
+const tableColumnGap = 2
+
+// outputWidth returns the width that listing commands fit tables to, or 0 if
+// tables are not fitted. -output-width sets the width; -truncate fits tables
+// to $COLUMNS or defaultOutputWidth.
+func (e *env) outputWidth() int {
+	if e.config == nil {
+		return 0
+	}
+	if e.config.OutputWidth > 0 {
+		return e.config.OutputWidth
+	}
+	if !e.config.Truncate {
+		return 0
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultOutputWidth
+}
+
+// printTable prints rows under a header and a line of dashes. If width is
+// positive, the widest columns are narrowed until the table fits, and cells
+// that do not fit are cut short with "…". Otherwise the columns are aligned
+// with tabs.
+func printTable(w io.Writer, width int, header []string, rows [][]string) {
+	dashes := make([]string, len(header))
+	for i, h := range header {
+		dashes[i] = strings.Repeat("-", utf8.RuneCountInString(h))
+	}
+	all := append([][]string{header, dashes}, rows...)
+	if width <= 0 {
+		tw := tabwriter.NewWriter(w, 10, 8, 4, '\t', 0)
+		for _, row := range all {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		tw.Flush()
+		return
+	}
+
+	widths := fitColumns(all, width)
+	for _, row := range all {
+		var line strings.Builder
+		for i, cell := range row {
+			cell = truncateCell(cell, widths[i])
+			if i == len(row)-1 {
+				line.WriteString(cell)
+				break
+			}
+			line.WriteString(cell)
+			line.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+tableColumnGap))
+		}
+		fmt.Fprintln(w, line.String())
+	}
+}
+
+// fitColumns returns the widths of the columns of rows, narrowing the widest
+// column until the table fits in width or every column is as narrow as its
+// header allows.
+func fitColumns(rows [][]string, width int) []int {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	total := tableColumnGap * (len(widths) - 1)
+	for _, n := range widths {
+		total += n
+	}
+	for total > width {
+		widest := 0
+		for i, n := range widths {
+			if n > widths[widest] {
+				widest = i
+			}
+		}
+		// Keep enough of the widest column to recognize it.
+		if widths[widest] <= 4 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// truncateCell cuts s to n characters, ending in "…" if it was cut.
+func truncateCell(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n-1]) + "…"
+}
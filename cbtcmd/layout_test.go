@@ -0,0 +1,77 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPrintTable(t *testing.T) {
+	header := []string{"Cluster Name", "Zone", "State"}
+	rows := [][]string{
+		{"a-very-long-cluster-name-in-production", "us-central1-b", "READY (3 serve nodes)"},
+		{"short", "europe-west1-c", "READY (1 serve nodes)"},
+	}
+
+	var buf bytes.Buffer
+	printTable(&buf, 0, header, rows)
+	if !strings.Contains(buf.String(), "a-very-long-cluster-name-in-production\t") || !strings.Contains(buf.String(), "\n-----") {
+		t.Errorf("unfitted table is not aligned with tabs:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	printTable(&buf, 60, header, rows)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("fitted table has %d lines, want 4:\n%s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		if n := utf8.RuneCountInString(line); n > 60 {
+			t.Errorf("line is %d wide, want at most 60: %q", n, line)
+		}
+	}
+	if !strings.HasPrefix(lines[2], "a-very-long-cluster-") || !strings.Contains(lines[2], "…  us-central1-b") {
+		t.Errorf("long cluster name was not cut short: %q", lines[2])
+	}
+	if !strings.HasPrefix(lines[3], "short ") {
+		t.Errorf("short cluster name was changed: %q", lines[3])
+	}
+}
+
+func TestOutputWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	for _, test := range []struct {
+		c       Config
+		columns string
+		want    int
+	}{
+		{Config{}, "120", 0},
+		{Config{OutputWidth: 100}, "", 100},
+		{Config{Truncate: true}, "", defaultOutputWidth},
+		{Config{Truncate: true}, "120", 120},
+	} {
+		t.Setenv("COLUMNS", test.columns)
+		c := test.c
+		e := newEnv(&c, Deps{})
+		if got := e.outputWidth(); got != test.want {
+			t.Errorf("outputWidth with %+v and COLUMNS=%q = %d, want %d", test.c, test.columns, got, test.want)
+		}
+	}
+}
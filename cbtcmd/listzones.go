@@ -0,0 +1,120 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	bigtableadmin "google.golang.org/api/bigtableadmin/v2"
+	"google.golang.org/api/option"
+)
+
+// zoneInfo is a zone where the project can create Bigtable clusters.
+type zoneInfo struct {
+	Zone, Region, DisplayName string
+	Labels                    map[string]string
+}
+
+// zoneRegion returns the region of a zone, such as us-central1 for
+// us-central1-a.
+func zoneRegion(zone string) string {
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return zone
+}
+
+// fetchZones lists the zones where project can create Bigtable clusters,
+// sorted by name. If region is not empty, only zones in it are returned.
+func fetchZones(ctx context.Context, svc *bigtableadmin.Service, project, region string) ([]zoneInfo, error) {
+	var zones []zoneInfo
+	err := svc.Projects.Locations.List("projects/"+project).Pages(ctx, func(res *bigtableadmin.ListLocationsResponse) error {
+		for _, l := range res.Locations {
+			z := zoneInfo{Zone: l.LocationId, Region: zoneRegion(l.LocationId), DisplayName: l.DisplayName, Labels: l.Labels}
+			if region != "" && z.Region != region {
+				continue
+			}
+			zones = append(zones, z)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Zone < zones[j].Zone })
+	return zones, nil
+}
+
+// getLocationsService returns a client of the admin API's REST interface,
+// which lists locations.
+func (e *env) getLocationsService(ctx context.Context) (*bigtableadmin.Service, error) {
+	opts := []option.ClientOption{
+		option.WithScopes(bigtableadmin.CloudPlatformReadOnlyScope),
+		option.WithUserAgent(e.userAgent),
+	}
+	opts = e.credentialOpts(opts)
+	svc, err := bigtableadmin.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("Making Bigtable admin client: %v", err)
+	}
+	return svc, nil
+}
+
+func doListZones(ctx context.Context, e *env, args ...string) error {
+	parsed, err := parseArgs(args, []string{"region", "wide"})
+	if err != nil {
+		return errors.New("usage: cbt listzones [region=<region>] [wide=<true|false>]")
+	}
+	wide := parsed["wide"] == "true"
+	svc, err := e.getLocationsService(ctx)
+	if err != nil {
+		return err
+	}
+	zones, err := fetchZones(ctx, svc, e.config.Project, parsed["region"])
+	if err != nil {
+		return fmt.Errorf("Listing zones: %v", err)
+	}
+	if len(zones) == 0 {
+		if r := parsed["region"]; r != "" {
+			return fmt.Errorf("No zones found in region %q", r)
+		}
+		return errors.New("No zones found")
+	}
+	header := []string{"Zone", "Region", "Location"}
+	if wide {
+		header = append(header, "Labels")
+	}
+	var rows [][]string
+	for _, z := range zones {
+		row := []string{z.Zone, z.Region, z.DisplayName}
+		if wide {
+			var labels []string
+			for k, v := range z.Labels {
+				labels = append(labels, fmt.Sprintf("%s=%s", k, v))
+			}
+			sort.Strings(labels)
+			row = append(row, strings.Join(labels, ","))
+		}
+		rows = append(rows, row)
+	}
+	e.printList(header, rows, wide)
+	return nil
+}
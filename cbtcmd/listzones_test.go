@@ -0,0 +1,69 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	bigtableadmin "google.golang.org/api/bigtableadmin/v2"
+	"google.golang.org/api/option"
+)
+
+func TestFetchZones(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Query().Get("pageToken") == "" {
+			fmt.Fprint(w, `{"nextPageToken": "p2", "locations": [
+				{"locationId": "us-east1-b", "displayName": "South Carolina"},
+				{"locationId": "europe-west1-c", "displayName": "Belgium"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"locations": [{"locationId": "us-east1-c", "displayName": "South Carolina", "labels": {"a": "b"}}]}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	svc, err := bigtableadmin.NewService(ctx, option.WithEndpoint(srv.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	zones, err := fetchZones(ctx, svc, "proj", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []zoneInfo{
+		{Zone: "europe-west1-c", Region: "europe-west1", DisplayName: "Belgium"},
+		{Zone: "us-east1-b", Region: "us-east1", DisplayName: "South Carolina"},
+		{Zone: "us-east1-c", Region: "us-east1", DisplayName: "South Carolina", Labels: map[string]string{"a": "b"}},
+	}
+	if diff := cmp.Diff(want, zones); diff != "" {
+		t.Errorf("zones mismatch (-want +got):\n%s", diff)
+	}
+	if len(paths) != 2 || paths[0] != "/v2/projects/proj/locations" {
+		t.Errorf("requested paths %q", paths)
+	}
+
+	if zones, err = fetchZones(ctx, svc, "proj", "us-east1"); err != nil || len(zones) != 2 {
+		t.Errorf("fetchZones(region=us-east1) = %v, %v, want the 2 us-east1 zones", zones, err)
+	}
+}
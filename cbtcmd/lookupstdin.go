@@ -0,0 +1,115 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// lookupBatchSize is the most row keys read from stdin that are looked up
+// with a single request.
+const lookupBatchSize = 100
+
+// readKeyBatches reads row keys, one per line, from r and sends them in
+// batches of up to lookupBatchSize. A batch is sent as soon as no more keys
+// are immediately available, so a program writing keys one at a time gets
+// each answer without waiting for a full batch.
+func readKeyBatches(r io.Reader) (<-chan []string, <-chan error) {
+	lines := make(chan string, lookupBatchSize)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for sc.Scan() {
+			if key := strings.TrimSuffix(sc.Text(), "\r"); key != "" {
+				lines <- key
+			}
+		}
+		errc <- sc.Err()
+	}()
+	batches := make(chan []string)
+	go func() {
+		defer close(batches)
+		for key := range lines {
+			batch := []string{key}
+		drain:
+			for len(batch) < lookupBatchSize {
+				select {
+				case key, ok := <-lines:
+					if !ok {
+						break drain
+					}
+					batch = append(batch, key)
+				default:
+					break drain
+				}
+			}
+			batches <- batch
+		}
+	}()
+	return batches, errc
+}
+
+// lookupKeys looks up the rows of the keys read from in, one request per
+// batch, and writes them to out in the order of the keys. A key without a
+// row is printed as an empty row, or an empty line with format=value.
+func (e *env) lookupKeys(ctx context.Context, tbl TableReader, in io.Reader, out io.Writer, format string, opts []bigtable.ReadOption) error {
+	batches, errc := readKeyBatches(in)
+	for batch := range batches {
+		rows := make(map[string]bigtable.Row, len(batch))
+		err := tbl.ReadRows(ctx, bigtable.RowList(batch), func(r bigtable.Row) bool {
+			rows[r.Key()] = r
+			return true
+		}, opts...)
+		if err != nil {
+			return fmt.Errorf("reading rows: %v", err)
+		}
+		var buf bytes.Buffer
+		for _, key := range batch {
+			r, ok := rows[key]
+			switch {
+			case format == "value" && !ok:
+				fmt.Fprintln(&buf)
+			case format == "value":
+				val, err := e.rowValue(r)
+				if err != nil {
+					return fmt.Errorf("row %q: %v", key, err)
+				}
+				fmt.Fprintln(&buf, val)
+			case !ok:
+				fmt.Fprintln(&buf, strings.Repeat("-", 40))
+				fmt.Fprintln(&buf, e.colorize(colorRowKey, key))
+			default:
+				if err := e.printRow(r, &buf); err != nil {
+					return fmt.Errorf("row %q: %v", key, err)
+				}
+			}
+		}
+		if _, err := out.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return <-errc
+}
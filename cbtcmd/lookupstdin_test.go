@@ -0,0 +1,101 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+)
+
+func TestLookupKeys(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	tbl := c.Open("my-table")
+	for _, key := range []string{"a", "b", "c"} {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "col", 1000, []byte("value-"+key))
+		if err := tbl.Apply(ctx, key, mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e, _ := newTestEnv(Deps{})
+	if err := e.formatting.Setup(""); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := e.lookupKeys(ctx, tbl, strings.NewReader("c\nmissing\r\n\na\n"), &out, "value", nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "value-c\n\nvalue-a\n"; out.String() != want {
+		t.Errorf("lookupKeys format=value = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := e.lookupKeys(ctx, tbl, strings.NewReader("b\nmissing\n"), &out, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	if !strings.Contains(got, "\nb\n") || !strings.Contains(got, "value-b") || !strings.HasSuffix(got, "\nmissing\n") {
+		t.Errorf("lookupKeys = %q, want row b and an empty row for missing", got)
+	}
+}
+
+func TestLookupKeysIncremental(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	tbl := c.Open("my-table")
+	for i := 0; i < 3; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "col", 1000, []byte(fmt.Sprint(i)))
+		if err := tbl.Apply(ctx, fmt.Sprint("r", i), mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e, _ := newTestEnv(Deps{})
+	if err := e.formatting.Setup(""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each key is answered before the next one is written, as when cbt is
+	// used as a resolver subprocess.
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- e.lookupKeys(ctx, tbl, inR, outW, "value", nil)
+		outW.Close()
+	}()
+	answers := bufio.NewScanner(outR)
+	for i := 0; i < 3; i++ {
+		fmt.Fprintf(inW, "r%d\n", i)
+		if !answers.Scan() {
+			t.Fatalf("no answer for r%d: %v", i, answers.Err())
+		}
+		if got, want := answers.Text(), fmt.Sprint(i); got != want {
+			t.Errorf("answer for r%d = %q, want %q", i, got, want)
+		}
+	}
+	inW.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
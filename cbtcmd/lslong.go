@@ -0,0 +1,161 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// tableDetail holds the description of a table, or the error getting it.
+type tableDetail struct {
+	table       string
+	info        *bigtable.TableInfo
+	replication *tableReplication
+	err         error
+}
+
+// fetchTableDetails describes tables concurrently, including their
+// replication state if replication is set, showing the progress on progress.
+func fetchTableDetails(ctx context.Context, progress io.Writer, ac AdminAPI, tables []string, replication replicationFetcher) []tableDetail {
+	results := make([]tableDetail, len(tables))
+	forEachTable(progress, tables, func(i int, table string) {
+		results[i].table = table
+		results[i].info, results[i].err = ac.TableInfo(ctx, table)
+		if results[i].err == nil && replication != nil {
+			results[i].replication, results[i].err = replication(ctx, table)
+		}
+	})
+	return results
+}
+
+// formatOptionalDuration prints an optional duration, or "-" if it is unset.
+func formatOptionalDuration(d interface{}) string {
+	if d, ok := d.(time.Duration); ok {
+		return d.String()
+	}
+	return "-"
+}
+
+// printTableDetails prints one line per table, width columns wide, logs the
+// tables that could not be described to logger and returns their number.
+func printTableDetails(w io.Writer, logger *log.Logger, width int, results []tableDetail) int {
+	header := []string{"Table", "Families", "Deletion Protection", "Change Stream Retention", "Automated Backup"}
+	withReplication := false
+	for _, r := range results {
+		if r.replication != nil {
+			withReplication = true
+			header = append(header, "Replication")
+			break
+		}
+	}
+	var rows [][]string
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			logger.Printf("Getting table info of %s: %v", r.table, r.err)
+			failed++
+			continue
+		}
+		var fams []string
+		for _, fam := range r.info.FamilyInfos {
+			fams = append(fams, fam.Name)
+		}
+		sort.Strings(fams)
+		protection := "-"
+		switch r.info.DeletionProtection {
+		case bigtable.Protected:
+			protection = "protected"
+		case bigtable.Unprotected:
+			protection = "unprotected"
+		}
+		backup := "-"
+		if policy, ok := r.info.AutomatedBackupConfig.(*bigtable.TableAutomatedBackupPolicy); ok {
+			backup = fmt.Sprintf("every %s, kept %s", formatOptionalDuration(policy.Frequency), formatOptionalDuration(policy.RetentionPeriod))
+		}
+		row := []string{r.table, strings.Join(fams, ","), protection,
+			formatOptionalDuration(r.info.ChangeStreamRetention), backup}
+		if withReplication {
+			row = append(row, r.replication.String())
+		}
+		rows = append(rows, row)
+	}
+	printTable(w, width, header, rows)
+	return failed
+}
+
+// warnInitializing logs the tables that some cluster is still copying to
+// logger.
+func warnInitializing(logger *log.Logger, results []tableDetail) {
+	for _, r := range results {
+		if r.replication == nil {
+			continue
+		}
+		if clusters := r.replication.initializing(); len(clusters) > 0 {
+			logger.Printf("Warning: table %s is still being replicated to %s; reads there may miss data",
+				r.table, strings.Join(clusters, ", "))
+		}
+	}
+}
+
+func doLSLong(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt ls -l [pattern=<table-glob>] [replication=<true|false>] [consistency=<true|false>]"
+	parsed, err := parseArgs(args, []string{"pattern", "replication", "consistency"})
+	if err != nil {
+		return errors.New(usage)
+	}
+	var replication, consistency bool
+	for name, v := range map[string]*bool{"replication": &replication, "consistency": &consistency} {
+		if s := parsed[name]; s != "" {
+			if *v, err = strconv.ParseBool(s); err != nil {
+				return fmt.Errorf("Bad %s %q: must be true or false", name, s)
+			}
+		}
+	}
+	var fetch replicationFetcher
+	if replication || consistency {
+		rpc, err := e.getTableAdminRPC()
+		if err != nil {
+			return err
+		}
+		fetch = tableReplicationFetcher(rpc, instanceName(e.config.Project, e.config.Instance), consistency)
+	}
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	tables, err := matchTables(ctx, ac, parsed["pattern"])
+	if err != nil {
+		return fmt.Errorf("Getting list of tables: %v", err)
+	}
+	results := fetchTableDetails(ctx, e.log.Writer(), ac, tables, fetch)
+	failed := printTableDetails(e.stdout, e.log, e.outputWidth(), results)
+	warnInitializing(e.log, results)
+	if failed > 0 {
+		return fmt.Errorf("Could not describe %d table(s)", failed)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTableDetails(t *testing.T) {
+	ctx, ac, _ := newEmulatorClients(t)
+	var tables []string
+	for _, table := range []string{"t1", "t2", "t3"} {
+		if err := ac.CreateTableFromConf(ctx, &bigtable.TableConf{
+			TableID:        table,
+			ColumnFamilies: map[string]bigtable.Family{"g": {}, "f": {}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		tables = append(tables, table)
+	}
+
+	results := fetchTableDetails(ctx, io.Discard, ac, tables, nil)
+	results = append(results, tableDetail{table: "broken", err: errors.New("boom")})
+	var buf bytes.Buffer
+	if failed := printTableDetails(&buf, discardLog, 0, results); failed != 1 {
+		t.Errorf("printTableDetails reported %d failures, want 1", failed)
+	}
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n")[2:] {
+		got = append(got, strings.Join(strings.Fields(line)[:2], " "))
+	}
+	want := []string{"t1 f,g", "t2 f,g", "t3 f,g"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("printed tables mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+)
+
+// mutationStats records client-observed latencies of Apply and ApplyBulk
+// calls, along with the number of MutateRow(s) RPC attempts made on their
+// behalf, so that retries can be reported for write commands.
+type mutationStats struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	attempts  int
+}
+
+// parseWriteStatsArg handles the include-stats argument of write commands.
+func parseWriteStatsArg(val string) (*mutationStats, error) {
+	switch val {
+	case "":
+		return nil, nil
+	case "full":
+		return &mutationStats{}, nil
+	}
+	return nil, fmt.Errorf("bad include-stats value: %q is not one of the supported stats views", val)
+}
+
+// record adds the latency of one Apply or ApplyBulk call.
+func (ms *mutationStats) record(d time.Duration) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.latencies = append(ms.latencies, d)
+}
+
+// countAttempt counts an outgoing RPC if it is a mutation.
+func (ms *mutationStats) countAttempt(method string) {
+	if !strings.HasSuffix(method, "/MutateRow") && !strings.HasSuffix(method, "/MutateRows") {
+		return
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.attempts++
+}
+
+// percentile returns the nearest-rank percentile p (0-100) of the recorded
+// latencies.
+func (ms *mutationStats) percentile(p float64) time.Duration {
+	ms.mu.Lock()
+	sorted := append([]time.Duration(nil), ms.latencies...)
+	ms.mu.Unlock()
+	if len(sorted) == 0 {
+		return 0
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// retries returns the number of RPC attempts beyond the first for each call.
+func (ms *mutationStats) retries() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if r := ms.attempts - len(ms.latencies); r > 0 {
+		return r
+	}
+	return 0
+}
+
+func (ms *mutationStats) print(w io.Writer) {
+	ms.mu.Lock()
+	calls := len(ms.latencies)
+	ms.mu.Unlock()
+	fmt.Fprintln(w, "Mutation Stats")
+	fmt.Fprintln(w, strings.Repeat("=", 20))
+	fmt.Fprintf(w, "calls: %d\n", calls)
+	fmt.Fprintf(w, "retries: %d\n", ms.retries())
+	fmt.Fprintf(w, "latency_p50: %dms\n", ms.percentile(50).Milliseconds())
+	fmt.Fprintf(w, "latency_p95: %dms\n", ms.percentile(95).Milliseconds())
+	fmt.Fprintf(w, "latency_p99: %dms\n", ms.percentile(99).Milliseconds())
+	fmt.Fprintln(w, "")
+}
+
+// mutationStatsOptions returns client options that count mutation RPC
+// attempts into e.writeStats, when it is set.
+func (e *env) mutationStatsOptions() []option.ClientOption {
+	unary := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if e.writeStats != nil {
+			e.writeStats.countAttempt(method)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	stream := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if e.writeStats != nil {
+			e.writeStats.countAttempt(method)
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+	return []option.ClientOption{
+		option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(unary)),
+		option.WithGRPCDialOption(grpc.WithChainStreamInterceptor(stream)),
+	}
+}
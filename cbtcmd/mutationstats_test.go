@@ -0,0 +1,76 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMutationStats(t *testing.T) {
+	ms := &mutationStats{}
+	for i := 1; i <= 100; i++ {
+		ms.record(time.Duration(i) * time.Millisecond)
+		ms.countAttempt("/google.bigtable.v2.Bigtable/MutateRows")
+	}
+	ms.countAttempt("/google.bigtable.v2.Bigtable/MutateRows")
+	ms.countAttempt("/google.bigtable.v2.Bigtable/MutateRow")
+	ms.countAttempt("/google.bigtable.v2.Bigtable/ReadRows")
+
+	for _, tc := range []struct {
+		p    float64
+		want time.Duration
+	}{
+		{50, 50 * time.Millisecond},
+		{95, 95 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+	} {
+		if got := ms.percentile(tc.p); got != tc.want {
+			t.Errorf("percentile(%v) = %v, want %v", tc.p, got, tc.want)
+		}
+	}
+	if got, want := ms.retries(), 2; got != want {
+		t.Errorf("retries() = %d, want %d", got, want)
+	}
+
+	var buf bytes.Buffer
+	ms.print(&buf)
+	for _, want := range []string{"calls: 100\n", "retries: 2\n", "latency_p95: 95ms\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("print() output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	if got := (&mutationStats{}).percentile(50); got != 0 {
+		t.Errorf("percentile of no calls = %v, want 0", got)
+	}
+}
+
+func TestParseWriteStatsArg(t *testing.T) {
+	if ms, err := parseWriteStatsArg(""); ms != nil || err != nil {
+		t.Errorf(`parseWriteStatsArg("") = %v, %v; want nil, nil`, ms, err)
+	}
+	if ms, err := parseWriteStatsArg("full"); ms == nil || err != nil {
+		t.Errorf(`parseWriteStatsArg("full") = %v, %v; want stats, nil`, ms, err)
+	}
+	if _, err := parseWriteStatsArg("json"); err == nil {
+		t.Error(`parseWriteStatsArg("json") did not fail`)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// noticesSeparator surrounds the module path and license name of each notice
+// in THIRD_PARTY_NOTICES.txt.
+var noticesSeparator = strings.Repeat("-", 80)
+
+// moduleNotice is the notice of one third-party module.
+type moduleNotice struct {
+	Module  string `json:"module"`
+	Version string `json:"version,omitempty"`
+	License string `json:"license"`
+	Text    string `json:"text,omitempty"`
+}
+
+// parseNotices splits THIRD_PARTY_NOTICES.txt into the notices of each
+// module. Each notice is a separator line, the module path, the license
+// name and another separator line, followed by the license text.
+func parseNotices(data string) []moduleNotice {
+	lines := strings.Split(data, "\n")
+	var notices []moduleNotice
+	var text []string
+	flush := func() {
+		if n := len(notices); n > 0 {
+			notices[n-1].Text = strings.Trim(strings.Join(text, "\n"), "\n") + "\n"
+		}
+		text = nil
+	}
+	for i := 0; i < len(lines); i++ {
+		if lines[i] == noticesSeparator && i+3 < len(lines) && lines[i+3] == noticesSeparator {
+			flush()
+			notices = append(notices, moduleNotice{Module: lines[i+1], License: lines[i+2]})
+			i += 3
+			continue
+		}
+		text = append(text, lines[i])
+	}
+	flush()
+	return notices
+}
+
+// moduleVersions returns the versions of the modules built into cbt.
+func moduleVersions() map[string]string {
+	versions := map[string]string{}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			versions[dep.Path] = dep.Version
+		}
+	}
+	return versions
+}
+
+func doNotices(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt notices [<module>] [format=<text|json>]"
+	var module string
+	if len(args) > 0 && !strings.Contains(args[0], "=") {
+		module, args = args[0], args[1:]
+	}
+	parsed, err := parseArgs(args, []string{"format"})
+	if err != nil {
+		return errors.New(usage)
+	}
+	format := parsed["format"]
+	if format != "" && format != "text" && format != "json" {
+		return fmt.Errorf("Bad format %q: must be text or json", format)
+	}
+	if module == "" && format != "json" {
+		fmt.Fprintln(e.stdout, string(noticesContents))
+		return nil
+	}
+
+	notices := parseNotices(string(noticesContents))
+	versions := moduleVersions()
+	var selected []moduleNotice
+	for _, n := range notices {
+		n.Version = versions[n.Module]
+		if module == "" {
+			// The inventory lists licenses without their text.
+			n.Text = ""
+		} else if n.Module != module {
+			continue
+		}
+		selected = append(selected, n)
+	}
+	if module != "" && len(selected) == 0 {
+		return fmt.Errorf("No notice for module %q; run 'cbt notices format=json' to list the modules", module)
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		var v interface{} = selected
+		if module != "" {
+			v = selected[0]
+		}
+		return enc.Encode(v)
+	}
+	n := selected[0]
+	fmt.Fprintf(e.stdout, "%s %s\nLicense: %s\n\n%s", n.Module, n.Version, n.License, n.Text)
+	return nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseNotices(t *testing.T) {
+	sep := strings.Repeat("-", 80)
+	data := "NOTICES AND INFORMATION\n\n" +
+		sep + "\nexample.com/a\nMIT\n" + sep + "\n\nCopyright A\n\n" +
+		sep + "\nexample.com/b\nApache 2.0\n" + sep + "\n\n  Apache License\n\n" +
+		"----------------------------------------\nNOTICES\n----------------------------------------\n\nCopyright B\n"
+	want := []moduleNotice{
+		{Module: "example.com/a", License: "MIT", Text: "Copyright A\n"},
+		{Module: "example.com/b", License: "Apache 2.0", Text: "  Apache License\n\n" +
+			"----------------------------------------\nNOTICES\n----------------------------------------\n\nCopyright B\n"},
+	}
+	if diff := cmp.Diff(want, parseNotices(data)); diff != "" {
+		t.Errorf("parseNotices mismatch (-want +got):\n%s", diff)
+	}
+
+	found := false
+	for _, n := range parseNotices(string(noticesContents)) {
+		if n.Module == "gopkg.in/yaml.v2" {
+			found = n.License == "Apache 2.0" && strings.Contains(n.Text, "Apache License")
+		}
+	}
+	if !found {
+		t.Error("the embedded notices have no Apache 2.0 notice for gopkg.in/yaml.v2")
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+// operations lists, describes and cancels the long-running admin operations
+// of the instance, such as table restores, cluster creation and backup
+// copies, whether they were started by cbt or elsewhere.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// operationInfo is the summary of an operation printed by cbt operations.
+type operationInfo struct {
+	Name     string `json:"name"`
+	Type     string `json:"type,omitempty"`
+	Target   string `json:"target,omitempty"`
+	Started  string `json:"started,omitempty"`
+	Status   string `json:"status"`
+	Progress int32  `json:"progress,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// timestampField returns the first set google.protobuf.Timestamp field of m
+// with one of the given names.
+func timestampField(m protoreflect.Message, names ...string) *timestamppb.Timestamp {
+	for _, name := range names {
+		fd := m.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil || fd.Message() == nil || !m.Has(fd) {
+			continue
+		}
+		if ts, ok := m.Get(fd).Message().Interface().(*timestamppb.Timestamp); ok {
+			return ts
+		}
+	}
+	return nil
+}
+
+// describeOperation summarizes op. The admin API's metadata types differ
+// per operation, so the start time and progress are found by field name.
+func describeOperation(op *longrunningpb.Operation) operationInfo {
+	info := operationInfo{Name: op.GetName(), Status: "running"}
+	// Operation names are the name of the resource operated on followed by
+	// /operations/<id>.
+	if i := strings.LastIndex(info.Name, "/operations/"); i >= 0 {
+		info.Target = strings.TrimPrefix(info.Name[:i], "operations/")
+	}
+	if md := op.GetMetadata(); md != nil {
+		typ := md.GetTypeUrl()
+		typ = typ[strings.LastIndexAny(typ, "/.")+1:]
+		info.Type = strings.TrimSuffix(typ, "Metadata")
+		if m, err := md.UnmarshalNew(); err == nil {
+			r := m.ProtoReflect()
+			ts := timestampField(r, "start_time", "request_time")
+			if fd := r.Descriptor().Fields().ByName("progress"); fd != nil && fd.Message() != nil && r.Has(fd) {
+				progress := r.Get(fd).Message()
+				if ts == nil {
+					ts = timestampField(progress, "start_time")
+				}
+				if fd := progress.Descriptor().Fields().ByName("progress_percent"); fd != nil {
+					info.Progress = int32(progress.Get(fd).Int())
+				}
+			}
+			if ts != nil {
+				info.Started = ts.AsTime().UTC().Format(time.RFC3339)
+			}
+		}
+	}
+	if op.GetDone() {
+		info.Status = "done"
+		if err := op.GetError(); err != nil {
+			info.Status = "failed"
+			if codes.Code(err.GetCode()) == codes.Canceled {
+				info.Status = "cancelled"
+			}
+			info.Error = err.GetMessage()
+		}
+	}
+	return info
+}
+
+func (info operationInfo) statusSummary() string {
+	switch {
+	case info.Status == "running" && info.Progress > 0:
+		return fmt.Sprintf("running, %d%% done", info.Progress)
+	case info.Error != "":
+		return info.Status + ": " + info.Error
+	}
+	return info.Status
+}
+
+// instanceOperations is the name of the collection of operations of the
+// instance of cfg.
+func instanceOperations(cfg *Config) string {
+	return "operations/" + instanceName(cfg.Project, cfg.Instance)
+}
+
+const operationsUsage = "usage: cbt operations (list [filter=<filter>] [running=<true|false>] [format=<table|json>] | " +
+	"describe <operation> [format=<text|json>] | cancel <operation> [force])"
+
+func doOperations(ctx context.Context, e *env, args ...string) error {
+	usage := operationsUsage
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "list":
+		return e.listOperations(ctx, e.stdout, args[1:])
+	case "describe":
+		if len(args) < 2 {
+			return errors.New(usage)
+		}
+		return e.showOperation(ctx, e.stdout, args[1], args[2:])
+	case "cancel":
+		// Only cancel changes anything, so it is audited here rather than
+		// marking the whole command as mutating.
+		if e.config.AuditLog != "" {
+			cmd := &command{Name: "operations", Usage: usage, do: cancelOperation}
+			return runAudited(ctx, e, e.config.AuditLog, cmd, args)
+		}
+		return cancelOperation(ctx, e, args...)
+	}
+	return errors.New(usage)
+}
+
+// cancelOperation runs 'cbt operations cancel', with args starting with
+// "cancel".
+func cancelOperation(ctx context.Context, e *env, args ...string) error {
+	if len(args) < 2 || len(args) > 3 || (len(args) == 3 && args[2] != "force") {
+		return errors.New(operationsUsage)
+	}
+	name := args[1]
+	if len(args) < 3 && !e.confirm(fmt.Sprintf("Cancel operation %q?", name)) {
+		return errors.New("Cancellation aborted")
+	}
+	oc, err := e.getOperationsClient()
+	if err != nil {
+		return err
+	}
+	if err := oc.CancelOperation(ctx, &longrunningpb.CancelOperationRequest{Name: name}); err != nil {
+		return fmt.Errorf("Cancelling operation: %v", err)
+	}
+	fmt.Fprintf(e.stdout, "Requested cancellation of %s; check its status with 'cbt operations describe %s'\n", name, name)
+	return nil
+}
+
+func (e *env) listOperations(ctx context.Context, w io.Writer, args []string) error {
+	parsed, err := parseFormatArgs(args, []string{"filter", "running", "format"})
+	if err != nil {
+		return errors.New(operationsUsage)
+	}
+	format := parsed["format"]
+	if format != "" && format != "table" && format != "json" {
+		return fmt.Errorf("Bad format %q: must be table or json", format)
+	}
+	runningOnly := false
+	if v := parsed["running"]; v != "" {
+		if runningOnly, err = strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("Bad running %q: must be true or false", v)
+		}
+	}
+
+	oc, err := e.getOperationsClient()
+	if err != nil {
+		return err
+	}
+	it := oc.ListOperations(ctx, &longrunningpb.ListOperationsRequest{
+		Name:   instanceOperations(e.config),
+		Filter: parsed["filter"],
+	})
+	infos := []operationInfo{}
+	for {
+		op, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("Listing operations: %v", err)
+		}
+		if runningOnly && op.GetDone() {
+			continue
+		}
+		infos = append(infos, describeOperation(op))
+	}
+	if format == "json" {
+		return writeJSON(w, infos)
+	}
+	var rows [][]string
+	for _, info := range infos {
+		rows = append(rows, []string{info.Name, info.Type, info.Started, info.statusSummary()})
+	}
+	printTable(w, e.outputWidth(), []string{"Operation", "Type", "Started", "Status"}, rows)
+	return nil
+}
+
+func (e *env) showOperation(ctx context.Context, w io.Writer, name string, args []string) error {
+	parsed, err := parseFormatArgs(args, []string{"format"})
+	if err != nil {
+		return errors.New(operationsUsage)
+	}
+	format := parsed["format"]
+	if format != "" && format != "text" && format != "json" {
+		return fmt.Errorf("Bad format %q: must be text or json", format)
+	}
+	oc, err := e.getOperationsClient()
+	if err != nil {
+		return err
+	}
+	op, err := oc.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: name})
+	if err != nil {
+		return fmt.Errorf("Getting operation: %v", err)
+	}
+	if format == "json" {
+		fmt.Fprintln(w, protojson.MarshalOptions{Multiline: true}.Format(op))
+		return nil
+	}
+	info := describeOperation(op)
+	fmt.Fprintf(w, "Name: %s\n", info.Name)
+	fmt.Fprintf(w, "Type: %s\n", info.Type)
+	fmt.Fprintf(w, "Target: %s\n", info.Target)
+	fmt.Fprintf(w, "Started: %s\n", info.Started)
+	fmt.Fprintf(w, "Status: %s\n", info.statusSummary())
+	if md := op.GetMetadata(); md != nil {
+		if m, err := md.UnmarshalNew(); err == nil {
+			fmt.Fprintf(w, "Metadata: %s\n", protojson.MarshalOptions{Multiline: true}.Format(m))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	btapb "cloud.google.com/go/bigtable/admin/apiv2/adminpb"
+	lroauto "cloud.google.com/go/longrunning/autogen"
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"google.golang.org/api/option"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeOperations serves a fixed set of operations and records the names
+// of the collection listed and the operations cancelled.
+type fakeOperations struct {
+	longrunningpb.UnimplementedOperationsServer
+	ops       []*longrunningpb.Operation
+	listed    string
+	cancelled []string
+}
+
+func (f *fakeOperations) ListOperations(ctx context.Context, req *longrunningpb.ListOperationsRequest) (*longrunningpb.ListOperationsResponse, error) {
+	f.listed = req.Name
+	return &longrunningpb.ListOperationsResponse{Operations: f.ops}, nil
+}
+
+func (f *fakeOperations) GetOperation(ctx context.Context, req *longrunningpb.GetOperationRequest) (*longrunningpb.Operation, error) {
+	for _, op := range f.ops {
+		if op.Name == req.Name {
+			return op, nil
+		}
+	}
+	return nil, grpcStatusNotFound(req.Name)
+}
+
+func (f *fakeOperations) CancelOperation(ctx context.Context, req *longrunningpb.CancelOperationRequest) (*emptypb.Empty, error) {
+	f.cancelled = append(f.cancelled, req.Name)
+	return &emptypb.Empty{}, nil
+}
+
+func grpcStatusNotFound(name string) error {
+	return grpcstatus.Errorf(codes.NotFound, "operation %s not found", name)
+}
+
+// startFakeOperations serves fake as the operations API, and returns the env
+// of a command on instance i of project p whose operations client uses it,
+// and the buffer that gets its output.
+func startFakeOperations(t *testing.T, fake *fakeOperations) (*env, *bytes.Buffer) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	longrunningpb.RegisterOperationsServer(srv, fake)
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := lroauto.NewOperationsClient(context.Background(), option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	e, out := newTestEnv(Deps{})
+	e.config = &Config{Project: "p", Instance: "i"}
+	e.operations = c
+	return e, out
+}
+
+func testOperations(t *testing.T) []*longrunningpb.Operation {
+	start := timestamppb.New(time.Date(2026, 5, 1, 9, 30, 0, 0, time.UTC))
+	restore, err := anypb.New(&btapb.RestoreTableMetadata{
+		Name:     "projects/p/instances/i/tables/t",
+		Progress: &btapb.OperationProgress{ProgressPercent: 42, StartTime: start},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cluster, err := anypb.New(&btapb.CreateClusterMetadata{RequestTime: start})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []*longrunningpb.Operation{
+		{Name: "operations/projects/p/instances/i/tables/t/operations/1", Metadata: restore},
+		{Name: "operations/projects/p/instances/i/clusters/c2/operations/2", Metadata: cluster, Done: true,
+			Result: &longrunningpb.Operation_Error{Error: &status.Status{Code: int32(codes.Canceled), Message: "cancelled by user"}}},
+	}
+}
+
+func TestDescribeOperation(t *testing.T) {
+	ops := testOperations(t)
+	got := []operationInfo{describeOperation(ops[0]), describeOperation(ops[1])}
+	want := []operationInfo{
+		{Name: ops[0].Name, Type: "RestoreTable", Target: "projects/p/instances/i/tables/t",
+			Started: "2026-05-01T09:30:00Z", Status: "running", Progress: 42},
+		{Name: ops[1].Name, Type: "CreateCluster", Target: "projects/p/instances/i/clusters/c2",
+			Started: "2026-05-01T09:30:00Z", Status: "cancelled", Error: "cancelled by user"},
+	}
+	if !Equal(got, want) {
+		t.Errorf("describeOperation = %+v, want %+v", got, want)
+	}
+	if got, want := got[0].statusSummary(), "running, 42% done"; got != want {
+		t.Errorf("statusSummary = %q, want %q", got, want)
+	}
+}
+
+func TestOperationsCommand(t *testing.T) {
+	fake := &fakeOperations{ops: testOperations(t)}
+	e, stdout := startFakeOperations(t, fake)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	if err := e.listOperations(ctx, &buf, []string{"running=true"}); err != nil {
+		t.Fatal(err)
+	}
+	if fake.listed != "operations/projects/p/instances/i" {
+		t.Errorf("listed %q, want operations/projects/p/instances/i", fake.listed)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "tables/t/operations/1") || strings.Contains(out, "clusters/c2") {
+		t.Errorf("list running=true printed:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := e.showOperation(ctx, &buf, fake.ops[1].Name, nil); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Status: cancelled: cancelled by user") {
+		t.Errorf("describe printed:\n%s", out)
+	}
+
+	// Only cancel is recorded in the audit log.
+	e.config.AuditLog = filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := doOperations(ctx, e, "list"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doOperations(ctx, e, "describe", fake.ops[1].Name); err != nil {
+		t.Fatal(err)
+	}
+	if err := doOperations(ctx, e, "cancel", fake.ops[0].Name, "force"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "Requested cancellation of "+fake.ops[0].Name) {
+		t.Errorf("cancel printed:\n%s", stdout)
+	}
+	if len(fake.cancelled) != 1 || fake.cancelled[0] != fake.ops[0].Name {
+		t.Errorf("cancelled %q, want %q", fake.cancelled, fake.ops[0].Name)
+	}
+	data, err := os.ReadFile(e.config.AuditLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec auditRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		got = append(got, rec.Status+" "+strings.Join(rec.Args, " "))
+	}
+	want := []string{"started cancel " + fake.ops[0].Name + " force", "ok cancel " + fake.ops[0].Name + " force"}
+	if !Equal(got, want) {
+		t.Errorf("audit log statuses = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/execabs"
+)
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	if l, ok := w.(colorLog); ok {
+		w = l.w
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// pagerCommand returns the pager to use: $CBT_PAGER, then $PAGER, then less.
+// An empty result means no pager.
+func pagerCommand() []string {
+	pager, ok := os.LookupEnv("CBT_PAGER")
+	if !ok {
+		pager, ok = os.LookupEnv("PAGER")
+	}
+	if !ok {
+		pager = "less"
+	}
+	if args := strings.Fields(pager); len(args) > 0 && args[0] != "cat" {
+		return args
+	}
+	return nil
+}
+
+// startPager starts the pager on stdout, returning the pipe to the pager and
+// a function that closes it and waits for the pager to exit, or a nil pipe if
+// there is no pager. As with git, less is run with LESS=FRX unless LESS is
+// set, so that it exits at once when the output fits on one screen.
+func startPager(stdout, stderr io.Writer) (pipe io.Writer, stop func()) {
+	args := pagerCommand()
+	if args == nil {
+		return nil, nil
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, nil
+	}
+	cmd := execabs.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = r, stdout, stderr
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := cmd.Start(); err != nil {
+		r.Close()
+		w.Close()
+		return nil, nil
+	}
+	r.Close()
+
+	stopping := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+		select {
+		case <-stopping:
+		default:
+			// The user quit the pager before the output ended.
+			os.Exit(0)
+		}
+	}()
+	var once sync.Once
+	return w, func() {
+		once.Do(func() {
+			close(stopping)
+			w.Close()
+			<-exited
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPagerCommand(t *testing.T) {
+	for _, test := range []struct {
+		cbtPager, pager *string
+		want            []string
+	}{
+		{nil, nil, []string{"less"}},
+		{nil, strPtr("more -d"), []string{"more", "-d"}},
+		{strPtr("most"), strPtr("more"), []string{"most"}},
+		{strPtr(""), strPtr("more"), nil},
+		{nil, strPtr("cat"), nil},
+	} {
+		for name, v := range map[string]*string{"CBT_PAGER": test.cbtPager, "PAGER": test.pager} {
+			if v == nil {
+				t.Setenv(name, "")
+				os.Unsetenv(name)
+			} else {
+				t.Setenv(name, *v)
+			}
+		}
+		if diff := cmp.Diff(test.want, pagerCommand()); diff != "" {
+			t.Errorf("CBT_PAGER=%v PAGER=%v: pagerCommand mismatch (-want +got):\n%s", test.cbtPager, test.pager, diff)
+		}
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestStartPager(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test pager is a shell script")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	script := filepath.Join(dir, "pager")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncat > "+out+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CBT_PAGER", script)
+
+	pipe, stop := startPager(io.Discard, io.Discard)
+	if pipe == nil {
+		t.Fatal("startPager did not start the pager")
+	}
+	fmt.Fprintln(pipe, "paged output")
+	stop()
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "paged output\n" {
+		t.Errorf("pager got %q, want %q", got, "paged output\n")
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// plannedTablet is a range between sample row keys that a scan touches.
+// Partial is whether the scan covers only part of it.
+type plannedTablet struct {
+	keyRange
+	Partial bool
+}
+
+// planScan returns the ranges between sample row keys that a scan of
+// [start, end) touches. An empty end is the end of the table.
+func planScan(ranges []keyRange, start, end string) []plannedTablet {
+	var planned []plannedTablet
+	for _, r := range ranges {
+		if (r.End != "" && r.End <= start) || (end != "" && r.Start >= end) {
+			continue
+		}
+		partial := r.Start < start || (end != "" && (r.End == "" || r.End > end))
+		planned = append(planned, plannedTablet{keyRange: r, Partial: partial})
+	}
+	return planned
+}
+
+// printScanPlan prints the tablets touched by a scan of [start, end) of
+// table, and a summary of their number and size.
+func printScanPlan(w io.Writer, table, start, end string, ranges []keyRange, planned []plannedTablet) {
+	var rows [][]string
+	var bytes int64
+	for _, p := range planned {
+		coverage := "full"
+		if p.Partial {
+			coverage = "partial"
+		}
+		rows = append(rows, []string{formatRangeKey(p.Start, "(start)"), formatRangeKey(p.End, "(end)"), formatBytes(p.Bytes), coverage})
+		bytes += p.Bytes
+	}
+	if len(rows) > 0 {
+		printTable(w, 0, []string{"Start", "End", "Size", "Coverage"}, rows)
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "Scan of [%s, %s) in %s touches %d of %d tablets, about %s", formatRangeKey(start, "(start)"),
+		formatRangeKey(end, "(end)"), table, len(planned), len(ranges), formatBytes(bytes))
+	fmt.Fprintln(w, " (tablets partly in the range are counted in full).")
+	if start == "" && end == "" {
+		fmt.Fprintln(w, "Warning: this is a full table scan. Set start, end or prefix to narrow it.")
+	}
+}
+
+func doPlan(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt plan read <table-id> [start=<row-key>] [end=<row-key>] [prefix=<row-key-prefix>]"
+	if len(args) < 1 || args[0] != "read" {
+		return errors.New(usage)
+	}
+	valid := []string{"start", "end", "prefix"}
+	args = e.withDefaultTable(args[1:], len(args) == 1 || isOptionArg(args[1], valid))
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+	parsed, err := parseArgs(args[1:], valid)
+	if err != nil {
+		return err
+	}
+	start, end := parsed["start"], parsed["end"]
+	if prefix := parsed["prefix"]; prefix != "" {
+		if start != "" || end != "" {
+			return errors.New(`"start"/"end" may not be mixed with "prefix"`)
+		}
+		start, end = prefix, prefixEnd(prefix)
+	}
+	samples, err := e.sampleRowKeys(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("Sampling row keys: %v", err)
+	}
+	ranges := keyRanges(samples)
+	printScanPlan(e.stdout, args[0], start, end, ranges, planScan(ranges, start, end))
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPlanScan(t *testing.T) {
+	ranges := keyRanges([]keySample{{"c", 100}, {"f", 300}, {"m", 600}, {"", 1000}})
+	for _, test := range []struct {
+		start, end string
+		want       []plannedTablet
+	}{
+		{"", "", []plannedTablet{
+			{keyRange{Start: "", End: "c", Bytes: 100}, false},
+			{keyRange{Start: "c", End: "f", Bytes: 200}, false},
+			{keyRange{Start: "f", End: "m", Bytes: 300}, false},
+			{keyRange{Start: "m", End: "", Bytes: 400}, false},
+		}},
+		{"c", "f", []plannedTablet{
+			{keyRange{Start: "c", End: "f", Bytes: 200}, false},
+		}},
+		{"d", "g", []plannedTablet{
+			{keyRange{Start: "c", End: "f", Bytes: 200}, true},
+			{keyRange{Start: "f", End: "m", Bytes: 300}, true},
+		}},
+		{"n", "", []plannedTablet{
+			{keyRange{Start: "m", End: "", Bytes: 400}, true},
+		}},
+	} {
+		got := planScan(ranges, test.start, test.end)
+		if len(got) != len(test.want) {
+			t.Errorf("planScan(%q, %q) = %+v, want %+v", test.start, test.end, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("planScan(%q, %q)[%d] = %+v, want %+v", test.start, test.end, i, got[i], test.want[i])
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	printScanPlan(&buf, "my-table", "", "", ranges, planScan(ranges, "", ""))
+	out := buf.String()
+	for _, want := range []string{"touches 4 of 4 tablets, about 1000 B", "full table scan"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("printScanPlan output is missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+// Bigtable takes the priority of data requests from the app profile they are
+// sent with, so priority= arguments are honored by choosing, or checking, an
+// app profile of the instance with that priority.
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	btapb "cloud.google.com/go/bigtable/admin/apiv2/adminpb"
+	"google.golang.org/api/iterator"
+)
+
+// profilePriority returns the priority of the requests sent with p, and
+// false if p uses Data Boost, which has no priority. Standard profiles that
+// leave the priority unset run at high priority.
+func profilePriority(p *btapb.AppProfile) (btapb.AppProfile_Priority, bool) {
+	if p.GetDataBoostIsolationReadOnly() != nil {
+		return btapb.AppProfile_PRIORITY_UNSPECIFIED, false
+	}
+	priority := p.GetStandardIsolation().GetPriority()
+	if priority == btapb.AppProfile_PRIORITY_UNSPECIFIED {
+		priority = p.GetPriority()
+	}
+	if priority == btapb.AppProfile_PRIORITY_UNSPECIFIED {
+		priority = btapb.AppProfile_PRIORITY_HIGH
+	}
+	return priority, true
+}
+
+// checkProfilePriority returns an error unless requests sent with p run at
+// priority want, the value of a priority= argument.
+func checkProfilePriority(p *btapb.AppProfile, want string) error {
+	got, ok := profilePriority(p)
+	if !ok {
+		return fmt.Errorf("app profile %s uses Data Boost, which has no priority", path.Base(p.Name))
+	}
+	if got != appProfilePriorities[want] {
+		return fmt.Errorf("app profile %s has priority %s, not %s", path.Base(p.Name), enumName(got.String(), "PRIORITY_"), want)
+	}
+	return nil
+}
+
+// choosePriorityProfile returns the ID of the first of profiles, the app
+// profiles of instance, by ID, whose requests run at priority want.
+func choosePriorityProfile(instance string, profiles []*btapb.AppProfile, want string) (string, error) {
+	var ids []string
+	for _, p := range profiles {
+		if checkProfilePriority(p, want) == nil {
+			ids = append(ids, path.Base(p.Name))
+		}
+	}
+	if len(ids) == 0 {
+		return "", fmt.Errorf("no app profile of instance %s has priority %s; set one up with 'cbt updateappprofile %s <profile-id> priority=%s'",
+			instance, want, instance, want)
+	}
+	sort.Strings(ids)
+	return ids[0], nil
+}
+
+// priorityAppProfile returns the app profile to send the requests of a data
+// command with, given its app-profile and priority arguments. Without a
+// priority, it is appProfile. With one, appProfile must have that priority,
+// and if it is empty an app profile of the instance with that priority is
+// chosen.
+func (e *env) priorityAppProfile(ctx context.Context, appProfile, priority string) (string, error) {
+	if priority == "" {
+		return appProfile, nil
+	}
+	if _, ok := appProfilePriorities[priority]; !ok {
+		return "", fmt.Errorf("bad priority %q: must be low, medium or high", priority)
+	}
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return "", err
+	}
+	if appProfile != "" {
+		p, err := iac.GetAppProfile(ctx, e.config.Instance, appProfile)
+		if err != nil {
+			return "", fmt.Errorf("getting app profile %s: %v", appProfile, err)
+		}
+		return appProfile, checkProfilePriority(p, priority)
+	}
+	var profiles []*btapb.AppProfile
+	it := iac.ListAppProfiles(ctx, e.config.Instance)
+	for {
+		p, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("listing app profiles: %v", err)
+		}
+		profiles = append(profiles, p)
+	}
+	id, err := choosePriorityProfile(e.config.Instance, profiles, priority)
+	if err != nil {
+		return "", err
+	}
+	e.log.Printf("Using app profile %s, which has priority %s", id, priority)
+	return id, nil
+}
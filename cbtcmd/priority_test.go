@@ -0,0 +1,90 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"testing"
+
+	btapb "cloud.google.com/go/bigtable/admin/apiv2/adminpb"
+)
+
+func standardProfile(name string, priority btapb.AppProfile_Priority) *btapb.AppProfile {
+	return &btapb.AppProfile{
+		Name: "projects/p/instances/i/appProfiles/" + name,
+		Isolation: &btapb.AppProfile_StandardIsolation_{
+			StandardIsolation: &btapb.AppProfile_StandardIsolation{Priority: priority},
+		},
+	}
+}
+
+func TestChoosePriorityProfile(t *testing.T) {
+	dataBoost := &btapb.AppProfile{
+		Name: "projects/p/instances/i/appProfiles/adhoc",
+		Isolation: &btapb.AppProfile_DataBoostIsolationReadOnly_{
+			DataBoostIsolationReadOnly: &btapb.AppProfile_DataBoostIsolationReadOnly{},
+		},
+	}
+	deprecated := &btapb.AppProfile{
+		Name:      "projects/p/instances/i/appProfiles/legacy",
+		Isolation: &btapb.AppProfile_Priority_{Priority: btapb.AppProfile_PRIORITY_MEDIUM},
+	}
+	profiles := []*btapb.AppProfile{
+		dataBoost,
+		standardProfile("serving", btapb.AppProfile_PRIORITY_UNSPECIFIED),
+		standardProfile("scans", btapb.AppProfile_PRIORITY_LOW),
+		standardProfile("batch", btapb.AppProfile_PRIORITY_LOW),
+		deprecated,
+	}
+	for _, test := range []struct {
+		priority, want string
+	}{
+		{"low", "batch"},
+		{"medium", "legacy"},
+		{"high", "serving"},
+	} {
+		got, err := choosePriorityProfile("i", profiles, test.priority)
+		if err != nil || got != test.want {
+			t.Errorf("choosePriorityProfile(%s) = %q, %v; want %q", test.priority, got, err, test.want)
+		}
+	}
+	if _, err := choosePriorityProfile("i", profiles[:2], "low"); err == nil {
+		t.Error("choosePriorityProfile without a low priority profile succeeded")
+	}
+}
+
+func TestCheckProfilePriority(t *testing.T) {
+	p := standardProfile("scans", btapb.AppProfile_PRIORITY_LOW)
+	if err := checkProfilePriority(p, "low"); err != nil {
+		t.Errorf("checkProfilePriority(low) = %v", err)
+	}
+	err := checkProfilePriority(p, "high")
+	if want := "app profile scans has priority low, not high"; err == nil || err.Error() != want {
+		t.Errorf("checkProfilePriority(high) = %v, want %q", err, want)
+	}
+}
+
+func TestPriorityAppProfileUnchanged(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	got, err := e.priorityAppProfile(context.Background(), "default", "")
+	if err != nil || got != "default" {
+		t.Errorf("priorityAppProfile without priority = %q, %v; want default", got, err)
+	}
+	if _, err := e.priorityAppProfile(context.Background(), "", "urgent"); err == nil {
+		t.Error("priorityAppProfile(urgent) succeeded")
+	}
+}
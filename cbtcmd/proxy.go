@@ -0,0 +1,157 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+// gRPC connections already go through the proxy in $HTTPS_PROXY, unless
+// $NO_PROXY excludes the endpoint. -proxy sets the proxy explicitly, for
+// users who can't or don't want to change their environment.
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+)
+
+// parseProxyURL parses a -proxy value: an http or https URL, or a bare
+// host:port of an HTTP proxy. It may carry a user name and password for
+// basic authentication.
+func parseProxyURL(s string) (*url.URL, error) {
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("bad -proxy %q: %v", s, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("bad -proxy %q: the scheme must be http or https", s)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("bad -proxy %q: no host", s)
+	}
+	return u, nil
+}
+
+// proxyAddr returns the host:port to dial for proxy, defaulting the port
+// as net/http does.
+func proxyAddr(proxy *url.URL) string {
+	if port := proxy.Port(); port != "" {
+		return net.JoinHostPort(proxy.Hostname(), port)
+	}
+	if proxy.Scheme == "https" {
+		return net.JoinHostPort(proxy.Hostname(), "443")
+	}
+	return net.JoinHostPort(proxy.Hostname(), "80")
+}
+
+// proxyDialer returns a gRPC dialer that tunnels connections through proxy
+// with HTTP CONNECT, sent with userAgent.
+func proxyDialer(proxy *url.URL, userAgent string) func(context.Context, string) (net.Conn, error) {
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", proxyAddr(proxy))
+		if err != nil {
+			return nil, fmt.Errorf("dialing proxy: %v", err)
+		}
+		if proxy.Scheme == "https" {
+			tc := tls.Client(conn, &tls.Config{ServerName: proxy.Hostname()})
+			if err := tc.HandshakeContext(ctx); err != nil {
+				conn.Close()
+				return nil, fmt.Errorf("dialing proxy: %v", err)
+			}
+			conn = tc
+		}
+		tunnel, err := proxyConnect(ctx, conn, proxy, addr, userAgent)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tunnel, nil
+	}
+}
+
+// proxyConnect asks the proxy at the other end of conn to connect it to
+// addr.
+func proxyConnect(ctx context.Context, conn net.Conn, proxy *url.URL, addr, userAgent string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: addr},
+		Host:   addr,
+		Header: http.Header{"User-Agent": {userAgent}},
+	}
+	if u := proxy.User; u != nil {
+		password, _ := u.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(u.Username()+":"+password)))
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("sending CONNECT to proxy: %v", err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, fmt.Errorf("reading CONNECT response from proxy: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("proxy refused to connect to %s: %s", addr, resp.Status)
+	}
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a net.Conn whose first bytes were read ahead into r.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// proxyOpts adds an option that connects through the -proxy, if there is
+// one. The emulator is always connected to directly.
+func (e *env) proxyOpts(opts []option.ClientOption) []option.ClientOption {
+	if e.config.ProxyURL == nil || e.config.Environment == "emulator" {
+		return opts
+	}
+	return append(opts, option.WithGRPCDialOption(grpc.WithContextDialer(proxyDialer(e.config.ProxyURL, e.userAgent))))
+}
+
+// useHTTPProxy sends the HTTP requests of cbt, such as those to BigQuery,
+// Cloud Storage and the token endpoint, through proxy.
+func useHTTPProxy(proxy *url.URL) {
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		t.Proxy = http.ProxyURL(proxy)
+	}
+}
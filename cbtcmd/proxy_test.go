@@ -0,0 +1,132 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseProxyURL(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"proxy.example.com:3128", "proxy.example.com:3128"},
+		{"http://proxy.example.com", "proxy.example.com:80"},
+		{"https://user:pw@proxy.example.com", "proxy.example.com:443"},
+	} {
+		u, err := parseProxyURL(test.in)
+		if err != nil {
+			t.Errorf("parseProxyURL(%q): %v", test.in, err)
+			continue
+		}
+		if got := proxyAddr(u); got != test.want {
+			t.Errorf("proxyAddr(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+	for _, in := range []string{"socks5://proxy.example.com:1080", "http://:3128"} {
+		if _, err := parseProxyURL(in); err == nil {
+			t.Errorf("parseProxyURL(%q) succeeded", in)
+		}
+	}
+}
+
+// serveTCP starts a server on a local port that handles each connection with
+// serve, and returns its address.
+func serveTCP(t *testing.T, serve func(net.Conn)) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serve(conn)
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestProxyDialer(t *testing.T) {
+	echo := serveTCP(t, func(conn net.Conn) { io.Copy(conn, conn) })
+	proxy := serveTCP(t, func(conn net.Conn) {
+		br := bufio.NewReader(conn)
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			return
+		}
+		if req.Method != http.MethodConnect || req.Host != echo {
+			io.WriteString(conn, "HTTP/1.1 400 Bad Request\r\n\r\n")
+			return
+		}
+		// dXNlcjpwdw== is user:pw.
+		if req.Header.Get("Proxy-Authorization") != "Basic dXNlcjpwdw==" {
+			io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+			return
+		}
+		target, err := net.Dial("tcp", req.Host)
+		if err != nil {
+			return
+		}
+		defer target.Close()
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go io.Copy(target, br)
+		io.Copy(conn, target)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	u, err := parseProxyURL("http://user:pw@" + proxy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := proxyDialer(u, "cbt-test")(ctx, echo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := io.WriteString(conn, "ping"); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "ping" {
+		t.Errorf("read %q through the proxy, want ping", buf)
+	}
+
+	u, err = parseProxyURL(proxy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := proxyDialer(u, "cbt-test")(ctx, echo); err == nil {
+		t.Error("dialing without proxy credentials succeeded")
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+// largeReadCount is the count= above which a read of whole rows over the
+// whole table is worth a warning.
+const largeReadCount = 100000
+
+// readProblem is a combination of read arguments that is wrong or likely to
+// be expensive. Errors stop the read unless it is forced; contradictions stop
+// it even then.
+type readProblem struct {
+	msg           string
+	fix           string
+	fatal         bool
+	contradictory bool
+}
+
+func (p readProblem) String() string {
+	return fmt.Sprintf("%s; %s", p.msg, p.fix)
+}
+
+// readFilterArgs are the read arguments that narrow what each row returns.
+var readFilterArgs = []string{
+	"columns", "regex", "value-regex", "from", "to", "any-of", "all-of",
+	"cells-per-column", "sample", "keys-only",
+}
+
+// checkRead finds the problems with the arguments of a read.
+func checkRead(parsed map[string]string) []readProblem {
+	var problems []readProblem
+	ranged := parsed["start"] != "" || parsed["end"] != "" || parsed["prefix"] != ""
+	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
+		problems = append(problems, readProblem{
+			msg:           `"start"/"end" may not be mixed with "prefix"`,
+			fix:           "give either prefix=, or start= and end=",
+			fatal:         true,
+			contradictory: true,
+		})
+	}
+	if reversed, _ := strconv.ParseBool(parsed["reversed"]); reversed && parsed["regex"] != "" && !ranged {
+		problems = append(problems, readProblem{
+			msg:   "reversed=true with regex= and no range scans the whole table backwards, filtering every row",
+			fix:   "add prefix= or start=/end= to bound the scan, or give -force to read anyway",
+			fatal: true,
+		})
+	}
+	filtered := false
+	for _, arg := range readFilterArgs {
+		if parsed[arg] != "" {
+			filtered = true
+		}
+	}
+	if n, err := strconv.ParseInt(parsed["count"], 0, 64); err == nil && n > largeReadCount && !ranged && !filtered {
+		problems = append(problems, readProblem{
+			msg: fmt.Sprintf("count=%d with no range or filter reads up to %d whole rows", n, n),
+			fix: "narrow it with prefix=, start=/end=, columns= or keys-only=true",
+		})
+	}
+	return problems
+}
+
+// reportReadProblems logs the problems to logger as warnings, and returns an
+// error if any is an error, unless force is set and none is a contradiction.
+func reportReadProblems(logger *log.Logger, problems []readProblem, force bool) error {
+	failed, contradictory := false, false
+	for _, p := range problems {
+		if p.contradictory || (p.fatal && !force) {
+			logger.Printf("Error: %v", p)
+			failed = true
+			contradictory = contradictory || p.contradictory
+			continue
+		}
+		logger.Printf("Warning: %v", p)
+	}
+	switch {
+	case contradictory:
+		return errors.New("Not reading")
+	case failed:
+		return errors.New("Not reading; give -force to read anyway")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"io"
+	"log"
+	"testing"
+)
+
+func TestCheckRead(t *testing.T) {
+	for _, test := range []struct {
+		parsed     map[string]string
+		wantErrors int
+		wantWarns  int
+	}{
+		{parsed: map[string]string{}},
+		{parsed: map[string]string{"prefix": "a", "count": "10"}},
+		{parsed: map[string]string{"prefix": "a", "start": "b"}, wantErrors: 1},
+		{parsed: map[string]string{"reversed": "true", "regex": "a.*"}, wantErrors: 1},
+		{parsed: map[string]string{"reversed": "true", "regex": "a.*", "prefix": "a"}},
+		{parsed: map[string]string{"reversed": "false", "regex": "a.*"}},
+		{parsed: map[string]string{"count": "1000000"}, wantWarns: 1},
+		{parsed: map[string]string{"count": "1000000", "keys-only": "true"}},
+		{parsed: map[string]string{"count": "1000000", "start": "a"}},
+		{parsed: map[string]string{"count": "1000"}},
+	} {
+		var errors, warns int
+		for _, p := range checkRead(test.parsed) {
+			if p.fatal {
+				errors++
+			} else {
+				warns++
+			}
+		}
+		if errors != test.wantErrors || warns != test.wantWarns {
+			t.Errorf("checkRead(%v) found %d errors and %d warnings, want %d and %d", test.parsed, errors, warns, test.wantErrors, test.wantWarns)
+		}
+	}
+}
+
+func TestReportReadProblems(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(io.Discard)
+	for _, test := range []struct {
+		parsed  map[string]string
+		force   bool
+		wantErr bool
+	}{
+		{parsed: map[string]string{"count": "1000000"}},
+		{parsed: map[string]string{"reversed": "true", "regex": "a.*"}, wantErr: true},
+		{parsed: map[string]string{"reversed": "true", "regex": "a.*"}, force: true},
+		{parsed: map[string]string{"prefix": "a", "start": "b"}, wantErr: true},
+		{parsed: map[string]string{"prefix": "a", "start": "b"}, force: true, wantErr: true},
+	} {
+		err := reportReadProblems(discardLog, checkRead(test.parsed), test.force)
+		if (err != nil) != test.wantErr {
+			t.Errorf("reportReadProblems(%v, force=%t) = %v, want error: %t", test.parsed, test.force, err, test.wantErr)
+		}
+	}
+}
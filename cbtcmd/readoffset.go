@@ -0,0 +1,55 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"log"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// skipRows advances rd past the first n rows it would return. The skipped
+// rows are read with one cell per row and no values, so that skipping costs
+// little more than reading their keys. filter is the read's row filter, or
+// nil, so that the same rows are skipped as would be returned. It reports
+// false if the read has no rows left.
+func skipRows(ctx context.Context, logger *log.Logger, tbl TableReader, rd resumableRead, n int64, filter bigtable.Filter) (resumableRead, bool, error) {
+	filters := []bigtable.Filter{bigtable.CellsPerRowLimitFilter(1), bigtable.StripValueFilter()}
+	if filter != nil {
+		filters = append([]bigtable.Filter{filter}, filters...)
+	}
+	skip := rd
+	skip.limit = n
+	skip.opts = []bigtable.ReadOption{bigtable.RowFilter(bigtable.ChainFilters(filters...))}
+	var skipped int64
+	var last string
+	err := skip.run(ctx, logger, tbl, func(r bigtable.Row) bool {
+		skipped++
+		last = r.Key()
+		return true
+	})
+	if err != nil || skipped < n {
+		return rd, false, err
+	}
+	if rd.reversed {
+		rd.end = last
+	} else {
+		rd.start = last + "\x00"
+	}
+	return rd, true, nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSkipRows(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	tbl := c.Open("my-table")
+	var keys []string
+	var muts []*bigtable.Mutation
+	for i := 0; i < 10; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "b", 1000, []byte("v"))
+		if i%2 == 0 {
+			mut.Set("f", "a", 1000, []byte("v"))
+		}
+		keys, muts = append(keys, fmt.Sprintf("r%d", i)), append(muts, mut)
+	}
+	if errs, err := tbl.ApplyBulk(ctx, keys, muts); err != nil || errs != nil {
+		t.Fatal(err, errs)
+	}
+	onlyA := bigtable.ColumnFilter("a")
+
+	for _, test := range []struct {
+		desc   string
+		rd     resumableRead
+		n      int64
+		filter bigtable.Filter
+		want   []string
+	}{
+		{desc: "all rows", rd: resumableRead{limit: 3}, n: 4, want: []string{"r4", "r5", "r6"}},
+		{desc: "filtered", rd: resumableRead{}, n: 2, filter: onlyA, want: []string{"r4", "r6", "r8"}},
+		{desc: "reversed", rd: resumableRead{reversed: true, limit: 2}, n: 3, want: []string{"r6", "r5"}},
+		{desc: "in a range", rd: resumableRead{start: "r2", end: "r6"}, n: 1, want: []string{"r3", "r4", "r5"}},
+		{desc: "past the end", rd: resumableRead{}, n: 10},
+	} {
+		if test.filter != nil {
+			test.rd.opts = []bigtable.ReadOption{bigtable.RowFilter(test.filter)}
+		}
+		rd, more, err := skipRows(ctx, discardLog, tbl, test.rd, test.n, test.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", test.desc, err)
+		}
+		var got []string
+		if more {
+			if err := rd.run(ctx, discardLog, tbl, func(r bigtable.Row) bool {
+				got = append(got, r.Key())
+				return true
+			}); err != nil {
+				t.Fatalf("%s: %v", test.desc, err)
+			}
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("%s: rows after skipping mismatch (-want +got):\n%s", test.desc, diff)
+		}
+	}
+}
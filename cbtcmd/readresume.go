@@ -0,0 +1,119 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// readResumeAttempts is the number of times a read is resumed without any
+// row being delivered in between.
+const readResumeAttempts = 5
+
+// readResumeBackoff is the delay before resuming a read. It doubles with each
+// attempt that delivers no rows.
+var readResumeBackoff = time.Second
+
+// isResumableReadError reports whether err is a transient failure of a read
+// stream, after which the read can be reissued.
+func isResumableReadError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Aborted:
+		return true
+	case codes.Internal:
+		msg := status.Convert(err).Message()
+		return strings.Contains(msg, "RST_STREAM") || strings.Contains(msg, "stream terminated") ||
+			strings.Contains(msg, "stream removed")
+	}
+	return false
+}
+
+// isStreamDeadline reports whether err is the deadline of a single read
+// stream, such as the server's limit on how long a stream lasts, rather than
+// of ctx, so that the read can be reissued.
+func isStreamDeadline(ctx context.Context, err error) bool {
+	return status.Code(err) == codes.DeadlineExceeded && ctx.Err() == nil
+}
+
+// resumableRead reads the rows of [start, end). If the stream fails with a
+// transient error, the read is reissued from the row after the last one
+// delivered, so that long scans survive flaky networks.
+type resumableRead struct {
+	start, end string
+	reversed   bool
+	// limit, if positive, is the number of rows to read.
+	limit int64
+	// attempts, if positive, overrides readResumeAttempts.
+	attempts int
+	opts     []bigtable.ReadOption
+}
+
+// run reads the rows into f, logging the resumed reads to logger.
+func (rd resumableRead) run(ctx context.Context, logger *log.Logger, tbl TableReader, f func(bigtable.Row) bool) error {
+	var delivered int64
+	attempts := 0
+	for {
+		opts := append([]bigtable.ReadOption(nil), rd.opts...)
+		if rd.reversed {
+			opts = append(opts, bigtable.ReverseScan())
+		}
+		if rd.limit > 0 {
+			opts = append(opts, bigtable.LimitRows(rd.limit-delivered))
+		}
+		progressed, stopped := false, false
+		err := tbl.ReadRows(ctx, rowRange(rd.start, rd.end), func(r bigtable.Row) bool {
+			progressed = true
+			delivered++
+			if rd.reversed {
+				rd.end = r.Key()
+			} else {
+				rd.start = r.Key() + "\x00"
+			}
+			stopped = !f(r)
+			return !stopped
+		}, opts...)
+		if err == nil || stopped || !(isResumableReadError(err) || isStreamDeadline(ctx, err)) {
+			return err
+		}
+		if (rd.limit > 0 && delivered >= rd.limit) || (rd.end != "" && rd.start >= rd.end) {
+			return nil
+		}
+		if progressed {
+			attempts = 0
+		}
+		maxAttempts := rd.attempts
+		if maxAttempts <= 0 {
+			maxAttempts = readResumeAttempts
+		}
+		if attempts++; attempts > maxAttempts {
+			return err
+		}
+		logger.Printf("Read interrupted after %d rows: %v; resuming", delivered, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(readResumeBackoff << (attempts - 1)):
+		}
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flakyTable fails each scan with err after delivering the next number of
+// rows in after, until after is empty.
+type flakyTable struct {
+	TableReader
+	after []int
+	err   error
+}
+
+func (ft *flakyTable) ReadRows(ctx context.Context, arg bigtable.RowSet, f func(bigtable.Row) bool, opts ...bigtable.ReadOption) error {
+	if len(ft.after) == 0 {
+		return ft.TableReader.ReadRows(ctx, arg, f, opts...)
+	}
+	after := ft.after[0]
+	ft.after = ft.after[1:]
+	n := 0
+	err := ft.TableReader.ReadRows(ctx, arg, func(r bigtable.Row) bool {
+		if n++; n > after {
+			return false
+		}
+		return f(r)
+	}, opts...)
+	if err == nil {
+		err = ft.err
+	}
+	return err
+}
+
+func TestResumableRead(t *testing.T) {
+	defer func(d time.Duration) { readResumeBackoff = d }(readResumeBackoff)
+	readResumeBackoff = time.Millisecond
+
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	tbl := c.Open("my-table")
+	var keys []string
+	var muts []*bigtable.Mutation
+	for i := 0; i < 100; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", 1000, []byte("v"))
+		keys, muts = append(keys, fmt.Sprintf("r%03d", i)), append(muts, mut)
+	}
+	if errs, err := tbl.ApplyBulk(ctx, keys, muts); err != nil || errs != nil {
+		t.Fatal(err, errs)
+	}
+	unavailable := status.Error(codes.Unavailable, "connection reset")
+
+	for _, test := range []struct {
+		desc  string
+		rd    resumableRead
+		after []int
+		err   error
+		want  []string
+		fail  bool
+	}{
+		{
+			desc:  "forward",
+			rd:    resumableRead{start: "r010", end: "r090"},
+			after: []int{30, 0, 20},
+			err:   unavailable,
+			want:  keys[10:90],
+		},
+		{
+			desc:  "reversed with limit",
+			rd:    resumableRead{reversed: true, limit: 50},
+			after: []int{25, 24},
+			err:   unavailable,
+			want:  reverse(keys[50:]),
+		},
+		{
+			desc:  "interrupted at the end of the range",
+			rd:    resumableRead{start: "r090"},
+			after: []int{10},
+			err:   status.Error(codes.Internal, "stream terminated by RST_STREAM with error code: INTERNAL_ERROR"),
+			want:  keys[90:],
+		},
+		{
+			desc:  "permanent error",
+			rd:    resumableRead{},
+			after: []int{10},
+			err:   status.Error(codes.PermissionDenied, "denied"),
+			want:  keys[:10],
+			fail:  true,
+		},
+		{
+			desc:  "unknown error",
+			rd:    resumableRead{},
+			after: []int{10},
+			err:   errors.New("other"),
+			want:  keys[:10],
+			fail:  true,
+		},
+		{
+			desc:  "retry budget exhausted",
+			rd:    resumableRead{},
+			after: []int{0, 0, 0, 0, 0, 0},
+			err:   unavailable,
+			fail:  true,
+		},
+		{
+			desc:  "larger retry budget",
+			rd:    resumableRead{start: "r095", attempts: 8},
+			after: []int{0, 0, 0, 0, 0, 0},
+			err:   unavailable,
+			want:  keys[95:],
+		},
+		{
+			desc:  "smaller retry budget exhausted",
+			rd:    resumableRead{attempts: 1},
+			after: []int{0, 0},
+			err:   unavailable,
+			fail:  true,
+		},
+		{
+			desc:  "stream deadline",
+			rd:    resumableRead{start: "r080"},
+			after: []int{15},
+			err:   status.Error(codes.DeadlineExceeded, "stream deadline"),
+			want:  keys[80:],
+		},
+	} {
+		ft := &flakyTable{TableReader: tbl, after: test.after, err: test.err}
+		var got []string
+		err := test.rd.run(ctx, discardLog, ft, func(r bigtable.Row) bool {
+			got = append(got, r.Key())
+			return true
+		})
+		if (err != nil) != test.fail {
+			t.Errorf("%s: run returned %v, want failure %t", test.desc, err, test.fail)
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("%s: rows mismatch (-want +got):\n%s", test.desc, diff)
+		}
+	}
+}
+
+func reverse(keys []string) []string {
+	var r []string
+	for i := len(keys) - 1; i >= 0; i-- {
+		r = append(r, keys[i])
+	}
+	return r
+}
+
+func TestCountResumes(t *testing.T) {
+	defer func(d time.Duration) { readResumeBackoff = d }(readResumeBackoff)
+	readResumeBackoff = time.Millisecond
+
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"f"})
+	tbl := c.Open("my-table")
+	for i := 0; i < 30; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", 1000, []byte("v"))
+		if err := tbl.Apply(ctx, fmt.Sprintf("r%02d", i), mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e, out := newTestEnv(Deps{Table: &flakyTable{TableReader: tbl, after: []int{10, 0, 0, 5}, err: status.Error(codes.Unavailable, "reset")}})
+
+	if err := doCount(ctx, e, "my-table", "retries=3", "progress-interval=0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "30\n" {
+		t.Errorf("count printed %q, want 30", got)
+	}
+}
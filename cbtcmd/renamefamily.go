@@ -0,0 +1,259 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+// Bigtable has no API to rename a column family, so renamefamily creates the
+// new family with the old family's settings, copies every cell to it, checks
+// the copy and then optionally deletes the old family.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// familyFilter returns a filter matching the cells of the given families.
+func familyFilter(fams ...string) bigtable.Filter {
+	quoted := make([]string, len(fams))
+	for i, f := range fams {
+		quoted[i] = regexp.QuoteMeta(f)
+	}
+	return bigtable.FamilyFilter("^(" + strings.Join(quoted, "|") + ")$")
+}
+
+// runShards calls f for each of the ranges between consecutive bounds, on up
+// to workers ranges at a time, and returns the first error.
+func runShards(bounds []string, workers int, f func(start, end string) error) error {
+	errs := make([]error, len(bounds)-1)
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := f(bounds[i], bounds[i+1]); err != nil {
+				errs[i] = fmt.Errorf("[%s, %s): %v", formatRangeKey(bounds[i], "(start)"), formatRangeKey(bounds[i+1], "(end)"), err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFamily copies every cell of family src of tbl to family dst, keeping
+// qualifiers and timestamps. Cells of aggregate families are merged into
+// dst rather than set. It returns the number of rows and cells copied.
+func (e *env) copyFamily(ctx context.Context, tbl *bigtable.Table, bounds []string, workers int, src, dst string, aggregate bool) (int64, int64, error) {
+	var rows, cells atomic.Int64
+	err := runShards(bounds, workers, func(start, end string) error {
+		var rowKeys []string
+		var muts []*bigtable.Mutation
+		flush := func() error {
+			if len(rowKeys) == 0 {
+				return nil
+			}
+			written, err := e.batchWrite(ctx, tbl, rowKeys, muts, 0)
+			rows.Add(int64(written))
+			rowKeys, muts = nil, nil
+			return err
+		}
+		var writeErr error
+		rd := resumableRead{start: start, end: end, opts: []bigtable.ReadOption{bigtable.RowFilter(familyFilter(src))}}
+		err := rd.run(ctx, e.log, tbl, func(r bigtable.Row) bool {
+			mut := bigtable.NewMutation()
+			for _, item := range r[src] {
+				col := strings.TrimPrefix(item.Column, src+":")
+				if aggregate {
+					mut.MergeBytesToCell(dst, col, item.Timestamp, item.Value)
+				} else {
+					mut.Set(dst, col, item.Timestamp, item.Value)
+				}
+			}
+			cells.Add(int64(len(r[src])))
+			rowKeys, muts = append(rowKeys, r.Key()), append(muts, mut)
+			if len(rowKeys) == copyBatchSize {
+				writeErr = flush()
+			}
+			return writeErr == nil
+		})
+		if err == nil {
+			err = writeErr
+		}
+		if err == nil {
+			err = flush()
+		}
+		return err
+	})
+	return rows.Load(), cells.Load(), err
+}
+
+// sameCells reports whether the cells of family a in row r have the same
+// qualifiers, timestamps and values as those of family b.
+func sameCells(r bigtable.Row, a, b string) bool {
+	as, bs := r[a], r[b]
+	if len(as) != len(bs) {
+		return false
+	}
+	for i := range as {
+		if strings.TrimPrefix(as[i].Column, a+":") != strings.TrimPrefix(bs[i].Column, b+":") ||
+			as[i].Timestamp != bs[i].Timestamp || !bytes.Equal(as[i].Value, bs[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyFamilyCopy compares the cells of families src and dst of tbl row by
+// row. It returns the number of rows compared and the keys of up to limit
+// rows whose cells differ, along with the total number of such rows.
+func verifyFamilyCopy(ctx context.Context, logger *log.Logger, tbl TableReader, bounds []string, workers int, src, dst string, limit int) (int64, []string, int64, error) {
+	var rows, bad atomic.Int64
+	var mu sync.Mutex
+	var badKeys []string
+	err := runShards(bounds, workers, func(start, end string) error {
+		rd := resumableRead{start: start, end: end, opts: []bigtable.ReadOption{bigtable.RowFilter(familyFilter(src, dst))}}
+		return rd.run(ctx, logger, tbl, func(r bigtable.Row) bool {
+			rows.Add(1)
+			if !sameCells(r, src, dst) {
+				bad.Add(1)
+				mu.Lock()
+				if len(badKeys) < limit {
+					badKeys = append(badKeys, r.Key())
+				}
+				mu.Unlock()
+			}
+			return true
+		})
+	})
+	return rows.Load(), badKeys, bad.Load(), err
+}
+
+func doRenameFamily(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt renamefamily <table-id> <old-family> <new-family> [workers=<1>] [delete-old=<true|false>] [force]"
+	if len(args) < 3 {
+		return errors.New(usage)
+	}
+	tableID, src, dst := args[0], args[1], args[2]
+	rest := args[3:]
+	force := false
+	if len(rest) > 0 && rest[len(rest)-1] == "force" {
+		force = true
+		rest = rest[:len(rest)-1]
+	}
+	parsed, err := parseArgs(rest, []string{"workers", "delete-old"})
+	if err != nil {
+		return errors.New(usage)
+	}
+	workers := 1
+	if v := parsed["workers"]; v != "" {
+		if workers, err = strconv.Atoi(v); err != nil || workers <= 0 {
+			return errors.New("workers must be > 0")
+		}
+	}
+	deleteOld := false
+	if v := parsed["delete-old"]; v != "" {
+		if deleteOld, err = strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("Bad delete-old %q: must be true or false", v)
+		}
+	}
+	if src == dst {
+		return errors.New("The old and new family names must differ")
+	}
+
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	ti, err := ac.TableInfo(ctx, tableID)
+	if err != nil {
+		return fmt.Errorf("Getting table info: %v", err)
+	}
+	var fam *bigtable.FamilyInfo
+	for i, fi := range ti.FamilyInfos {
+		if fi.Name == dst {
+			return fmt.Errorf("Family %s already exists in table %s", dst, tableID)
+		}
+		if fi.Name == src {
+			fam = &ti.FamilyInfos[i]
+		}
+	}
+	if fam == nil {
+		return fmt.Errorf("Table %s has no family %s", tableID, src)
+	}
+	conf := bigtable.Family{GCPolicy: fam.FullGCPolicy, ValueType: fam.ValueType}
+	if conf.GCPolicy == nil {
+		conf.GCPolicy = bigtable.NoGcPolicy()
+	}
+	_, aggregate := fam.ValueType.(bigtable.AggregateType)
+	if err := ac.CreateColumnFamilyWithConfig(ctx, tableID, dst, conf); err != nil {
+		return fmt.Errorf("Creating column family: %v", err)
+	}
+	fmt.Fprintf(e.stdout, "Created family %s with the settings of %s\n", dst, src)
+
+	c, err := e.getClient(bigtable.ClientConfig{})
+	if err != nil {
+		return err
+	}
+	tbl := c.Open(tableID)
+	bounds, err := checksumBounds(ctx, tbl, "", "", nil, workers)
+	if err != nil {
+		return err
+	}
+	rows, cells, err := e.copyFamily(ctx, tbl, bounds, workers, src, dst, aggregate)
+	if err != nil {
+		return fmt.Errorf("Copying cells: %v", err)
+	}
+	fmt.Fprintf(e.stdout, "Copied %d cells in %d rows from %s to %s\n", cells, rows, src, dst)
+
+	if !deleteOld {
+		fmt.Fprintf(e.stdout, "Family %s was kept; delete it with 'cbt deletefamily %s %s' once %s is in use\n", src, tableID, src, dst)
+		return nil
+	}
+	checked, badKeys, bad, err := verifyFamilyCopy(ctx, e.log, tbl, bounds, workers, src, dst, 10)
+	if err != nil {
+		return fmt.Errorf("Verifying copy: %v", err)
+	}
+	if bad > 0 {
+		return fmt.Errorf("%d of %d rows differ between %s and %s, keeping %s; first differing rows: %s",
+			bad, checked, src, dst, src, strings.Join(badKeys, ", "))
+	}
+	fmt.Fprintf(e.stdout, "Verified %d rows\n", checked)
+	if !force && !e.confirm(fmt.Sprintf("Delete family %q of table %q and all of its data?", src, tableID)) {
+		return errors.New("Deletion cancelled")
+	}
+	if err := ac.DeleteColumnFamily(ctx, tableID, src); err != nil {
+		return fmt.Errorf("Deleting column family: %v", err)
+	}
+	fmt.Fprintf(e.stdout, "Deleted family %s\n", src)
+	return nil
+}
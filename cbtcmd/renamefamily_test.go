@@ -0,0 +1,120 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+)
+
+func TestRenameFamily(t *testing.T) {
+	ctx, ac, c := newEmulatorClients(t)
+	if err := ac.CreateTableFromConf(ctx, &bigtable.TableConf{
+		TableID: "t",
+		ColumnFamilies: map[string]bigtable.Family{
+			"old":   {GCPolicy: bigtable.MaxVersionsPolicy(2)},
+			"other": {GCPolicy: bigtable.NoGcPolicy()},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tbl := c.Open("t")
+	var keys []string
+	var muts []*bigtable.Mutation
+	for i := 0; i < 2500; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("old", "a", 1000, []byte("v1"))
+		mut.Set("old", "a", 2000, []byte(fmt.Sprint(i)))
+		if i%2 == 0 {
+			mut.Set("other", "b", 3000, []byte("w"))
+		}
+		keys, muts = append(keys, fmt.Sprintf("r%04d", i)), append(muts, mut)
+	}
+	if errs, err := tbl.ApplyBulk(ctx, keys, muts); err != nil || errs != nil {
+		t.Fatalf("ApplyBulk: %v %v", err, errs)
+	}
+
+	e, out := newTestEnv(Deps{Client: c, AdminClient: ac})
+	if err := doRenameFamily(ctx, e, "t", "old", "new", "workers=4", "delete-old=true", "force"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Copied 5000 cells in 2500 rows from old to new",
+		"Verified 2500 rows",
+		"Deleted family old",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output %q does not contain %q", out, want)
+		}
+	}
+
+	ti, err := ac.TableInfo(ctx, "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policies := map[string]string{}
+	for _, fi := range ti.FamilyInfos {
+		policies[fi.Name] = fi.GCPolicy
+	}
+	if want := map[string]string{"new": "versions() > 2", "other": ""}; fmt.Sprint(policies) != fmt.Sprint(want) {
+		t.Errorf("families = %v, want %v", policies, want)
+	}
+	r, err := tbl.ReadRow(ctx, "r0042")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(r["new"]), fmt.Sprint([]bigtable.ReadItem{
+		{Row: "r0042", Column: "new:a", Timestamp: 2000, Value: []byte("42")},
+		{Row: "r0042", Column: "new:a", Timestamp: 1000, Value: []byte("v1")},
+	}); got != want {
+		t.Errorf("r0042 new = %s, want %s", got, want)
+	}
+}
+
+func TestVerifyFamilyCopy(t *testing.T) {
+	ctx, ac, c := newEmulatorClients(t)
+	if err := ac.CreateTableFromConf(ctx, &bigtable.TableConf{
+		TableID:        "t",
+		ColumnFamilies: map[string]bigtable.Family{"a": {}, "b": {}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tbl := c.Open("t")
+	for key, cells := range map[string][][2]string{
+		"same":    {{"a", "x"}, {"b", "x"}},
+		"value":   {{"a", "x"}, {"b", "y"}},
+		"missing": {{"a", "x"}},
+	} {
+		mut := bigtable.NewMutation()
+		for _, cell := range cells {
+			mut.Set(cell[0], "q", 1000, []byte(cell[1]))
+		}
+		if err := tbl.Apply(ctx, key, mut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows, badKeys, bad, err := verifyFamilyCopy(ctx, discardLog, tbl, []string{"", ""}, 1, "a", "b", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows != 3 || bad != 2 || len(badKeys) != 1 || badKeys[0] != "missing" {
+		t.Errorf("verifyFamilyCopy = %d, %q, %d; want 3, [missing], 2", rows, badKeys, bad)
+	}
+}
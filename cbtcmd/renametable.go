@@ -0,0 +1,236 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+// Bigtable has no API to rename a table, so renametable makes a copy of the
+// table under the new name, either by restoring a backup of it or by copying
+// its schema and rows, and then optionally deletes the old table.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// renameBackupTTL is how long the intermediate backup is kept if it cannot be
+// deleted after the restore.
+const renameBackupTTL = 24 * time.Hour
+
+// copyBatchSize is the number of rows written per ApplyBulk call when
+// copying a table.
+const copyBatchSize = 1000
+
+var backupIDChars = regexp.MustCompile(`[^-_.a-zA-Z0-9]+`)
+
+// renameBackupID returns a backup ID for renaming table.
+func renameBackupID(table string, now time.Time) string {
+	id := fmt.Sprintf("cbt-rename-%s-%d", backupIDChars.ReplaceAllString(table, "-"), now.Unix())
+	if len(id) > 50 {
+		id = id[len(id)-50:]
+		id = strings.TrimLeft(id, "-.")
+	}
+	return id
+}
+
+// renameViaBackup backs up src on cluster, restores the backup as dst and
+// deletes the backup.
+func renameViaBackup(ctx context.Context, e *env, ac AdminAPI, src, dst, cluster string) error {
+	backup := renameBackupID(src, time.Now())
+	e.log.Printf("Creating backup %s of %s on cluster %s", backup, src, cluster)
+	err := withHeartbeat(ctx, e, "creating backup "+backup, backupStatus(ac, cluster, backup), func() error {
+		return ac.CreateBackup(ctx, src, cluster, backup, time.Now().Add(renameBackupTTL))
+	})
+	if err != nil {
+		return fmt.Errorf("creating backup: %v", err)
+	}
+	e.log.Printf("Restoring backup %s as %s", backup, dst)
+	err = withHeartbeat(ctx, e, "restoring backup "+backup, nil, func() error {
+		return ac.RestoreTable(ctx, dst, cluster, backup)
+	})
+	if err != nil {
+		return fmt.Errorf("restoring backup %s: %v", backup, err)
+	}
+	if err := ac.DeleteBackup(ctx, cluster, backup); err != nil {
+		e.log.Printf("Could not delete backup %s, it expires in %v: %v", backup, renameBackupTTL, err)
+	}
+	return nil
+}
+
+// copyTable creates dst with the column families of src, split at src's
+// sampled row keys, and copies all cells of src to it with their
+// timestamps. It returns the number of rows copied.
+func (e *env) copyTable(ctx context.Context, ac AdminAPI, c *bigtable.Client, src, dst string) (int, error) {
+	ti, err := ac.TableInfo(ctx, src)
+	if err != nil {
+		return 0, fmt.Errorf("getting table info: %v", err)
+	}
+	conf := bigtable.TableConf{TableID: dst, ColumnFamilies: map[string]bigtable.Family{}}
+	for _, fi := range ti.FamilyInfos {
+		fam := bigtable.Family{GCPolicy: fi.FullGCPolicy, ValueType: fi.ValueType}
+		if fam.GCPolicy == nil {
+			fam.GCPolicy = bigtable.NoGcPolicy()
+		}
+		conf.ColumnFamilies[fi.Name] = fam
+	}
+	srcTbl := c.Open(src)
+	keys, err := srcTbl.SampleRowKeys(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("sampling row keys: %v", err)
+	}
+	for _, k := range keys {
+		if k != "" {
+			conf.SplitKeys = append(conf.SplitKeys, k)
+		}
+	}
+	if err := ac.CreateTableFromConf(ctx, &conf); err != nil {
+		return 0, fmt.Errorf("creating table: %v", err)
+	}
+
+	dstTbl := c.Open(dst)
+	var rowKeys []string
+	var muts []*bigtable.Mutation
+	n := 0
+	flush := func() error {
+		if len(rowKeys) == 0 {
+			return nil
+		}
+		written, err := e.batchWrite(ctx, dstTbl, rowKeys, muts, 0)
+		n += written
+		rowKeys, muts = nil, nil
+		return err
+	}
+	var writeErr error
+	err = srcTbl.ReadRows(ctx, bigtable.InfiniteRange(""), func(r bigtable.Row) bool {
+		mut := bigtable.NewMutation()
+		for fam, items := range r {
+			for _, item := range items {
+				mut.Set(fam, strings.TrimPrefix(item.Column, fam+":"), item.Timestamp, item.Value)
+			}
+		}
+		rowKeys, muts = append(rowKeys, r.Key()), append(muts, mut)
+		if len(rowKeys) == copyBatchSize {
+			writeErr = flush()
+		}
+		return writeErr == nil
+	})
+	if err == nil {
+		err = writeErr
+	}
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return n, fmt.Errorf("copying rows: %v", err)
+	}
+	return n, nil
+}
+
+// firstCluster returns the alphabetically first cluster of the configured
+// instance.
+func (e *env) firstCluster(ctx context.Context) (string, error) {
+	iac, err := e.getInstanceAdminClient()
+	if err != nil {
+		return "", err
+	}
+	clusters, err := iac.Clusters(ctx, e.config.Instance)
+	if err != nil {
+		return "", err
+	}
+	if len(clusters) == 0 {
+		return "", fmt.Errorf("instance %s has no clusters", e.config.Instance)
+	}
+	var names []string
+	for _, c := range clusters {
+		names = append(names, c.Name)
+	}
+	sort.Strings(names)
+	return names[0], nil
+}
+
+func doRenameTable(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt renametable <old-table-id> <new-table-id> [via=backup|copy] [cluster=<cluster-id>] [delete-old=<true|false>] [force]"
+	if len(args) < 2 {
+		return errors.New(usage)
+	}
+	src, dst := args[0], args[1]
+	rest := args[2:]
+	force := false
+	if len(rest) > 0 && rest[len(rest)-1] == "force" {
+		force = true
+		rest = rest[:len(rest)-1]
+	}
+	parsed, err := parseArgs(rest, []string{"via", "cluster", "delete-old"})
+	if err != nil {
+		return errors.New(usage)
+	}
+	deleteOld := false
+	if v := parsed["delete-old"]; v != "" {
+		if deleteOld, err = strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("Bad delete-old %q: must be true or false", v)
+		}
+	}
+
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	switch via := parsed["via"]; via {
+	case "", "backup":
+		cluster := parsed["cluster"]
+		if cluster == "" {
+			if cluster, err = e.firstCluster(ctx); err != nil {
+				return fmt.Errorf("Choosing a cluster for the backup: %v", err)
+			}
+		}
+		if err := renameViaBackup(ctx, e, ac, src, dst, cluster); err != nil {
+			return fmt.Errorf("Renaming table: %v", err)
+		}
+	case "copy":
+		c, err := e.getClient(bigtable.ClientConfig{})
+		if err != nil {
+			return err
+		}
+		n, err := e.copyTable(ctx, ac, c, src, dst)
+		if err != nil {
+			return fmt.Errorf("Renaming table: %v", err)
+		}
+		e.log.Printf("Copied %d rows from %s to %s", n, src, dst)
+	default:
+		return fmt.Errorf("Bad via %q: must be backup or copy", via)
+	}
+	fmt.Fprintf(e.stdout, "Created table %s from %s\n", dst, src)
+
+	if !deleteOld {
+		fmt.Fprintf(e.stdout, "Table %s was kept; delete it with 'cbt deletetable %s' once %s is in use\n", src, src, dst)
+		return nil
+	}
+	printTableDeletionImpact(ctx, e, ac, src)
+	if !force && !e.confirm(fmt.Sprintf("Delete table %q and all of its data?", src)) {
+		return errors.New("Deletion cancelled")
+	}
+	if err := ac.DeleteTable(ctx, src); err != nil {
+		return fmt.Errorf("Deleting table: %v", err)
+	}
+	return nil
+}
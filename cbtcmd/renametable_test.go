@@ -0,0 +1,99 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCopyTable(t *testing.T) {
+	ctx, ac, c := newEmulatorClients(t)
+	if err := ac.CreateTableFromConf(ctx, &bigtable.TableConf{
+		TableID: "old",
+		ColumnFamilies: map[string]bigtable.Family{
+			"f": {GCPolicy: bigtable.MaxVersionsPolicy(2)},
+			"g": {GCPolicy: bigtable.NoGcPolicy()},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	old := c.Open("old")
+	var keys []string
+	var muts []*bigtable.Mutation
+	for i := 0; i < 2500; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", 1000, []byte("v1"))
+		mut.Set("f", "a", 2000, []byte(fmt.Sprint(i)))
+		if i%2 == 0 {
+			mut.Set("g", "b", 3000, []byte("w"))
+		}
+		keys, muts = append(keys, fmt.Sprintf("r%04d", i)), append(muts, mut)
+	}
+	if errs, err := old.ApplyBulk(ctx, keys, muts); err != nil || errs != nil {
+		t.Fatalf("ApplyBulk: %v %v", err, errs)
+	}
+
+	e, _ := newTestEnv(Deps{})
+	n, err := e.copyTable(ctx, ac, c, "old", "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2500 {
+		t.Errorf("copied %d rows, want 2500", n)
+	}
+
+	ti, err := ac.TableInfo(ctx, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policies := map[string]string{}
+	for _, fi := range ti.FamilyInfos {
+		policies[fi.Name] = fi.GCPolicy
+	}
+	if diff := cmp.Diff(map[string]string{"f": "versions() > 2", "g": ""}, policies); diff != "" {
+		t.Errorf("families mismatch (-want +got):\n%s", diff)
+	}
+
+	read := func(table string) map[string]bigtable.Row {
+		rows := map[string]bigtable.Row{}
+		if err := c.Open(table).ReadRows(ctx, bigtable.InfiniteRange(""), func(r bigtable.Row) bool {
+			rows[r.Key()] = r
+			return true
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return rows
+	}
+	if diff := cmp.Diff(read("old"), read("new")); diff != "" {
+		t.Errorf("copied rows mismatch (-old +new):\n%s", diff)
+	}
+}
+
+func TestRenameBackupID(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	if got, want := renameBackupID("my.table", now), "cbt-rename-my.table-1700000000"; got != want {
+		t.Errorf("renameBackupID = %q, want %q", got, want)
+	}
+	if got := renameBackupID("a-very-long-table-name-that-goes-on-and-on", now); len(got) > 50 {
+		t.Errorf("renameBackupID = %q, longer than 50 characters", got)
+	}
+}
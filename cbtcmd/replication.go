@@ -0,0 +1,120 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	btapb "cloud.google.com/go/bigtable/admin/apiv2/adminpb"
+)
+
+// tableReplication is the replication state of a table on each cluster of
+// its instance.
+type tableReplication struct {
+	states map[string]btapb.Table_ClusterState_ReplicationState
+	// consistent, if checked, is whether the writes made before the check
+	// have replicated to every cluster.
+	consistent *bool
+}
+
+// replicationFetcher gets the replication state of a table.
+type replicationFetcher func(ctx context.Context, table string) (*tableReplication, error)
+
+// tableReplicationFetcher returns a replicationFetcher for the tables of
+// instance, the instance's full name, that also checks consistency with a
+// new consistency token if consistency is set.
+func tableReplicationFetcher(rpc btapb.BigtableTableAdminClient, instance string, consistency bool) replicationFetcher {
+	return func(ctx context.Context, table string) (*tableReplication, error) {
+		name := instance + "/tables/" + table
+		t, err := rpc.GetTable(ctx, &btapb.GetTableRequest{Name: name, View: btapb.Table_REPLICATION_VIEW})
+		if err != nil {
+			return nil, fmt.Errorf("getting replication state: %v", err)
+		}
+		r := &tableReplication{states: map[string]btapb.Table_ClusterState_ReplicationState{}}
+		for cluster, cs := range t.ClusterStates {
+			r.states[cluster] = cs.GetReplicationState()
+		}
+		if !consistency {
+			return r, nil
+		}
+		token, err := rpc.GenerateConsistencyToken(ctx, &btapb.GenerateConsistencyTokenRequest{Name: name})
+		if err != nil {
+			return nil, fmt.Errorf("generating consistency token: %v", err)
+		}
+		resp, err := rpc.CheckConsistency(ctx, &btapb.CheckConsistencyRequest{Name: name, ConsistencyToken: token.ConsistencyToken})
+		if err != nil {
+			return nil, fmt.Errorf("checking consistency: %v", err)
+		}
+		consistent := resp.Consistent
+		r.consistent = &consistent
+		return r, nil
+	}
+}
+
+// initializing returns the clusters, in order, that are still copying the
+// table's data.
+func (r *tableReplication) initializing() []string {
+	var clusters []string
+	for cluster, state := range r.states {
+		if state == btapb.Table_ClusterState_INITIALIZING {
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.Strings(clusters)
+	return clusters
+}
+
+// String summarizes the replication state: ready if every cluster serves
+// the table, otherwise the state of each cluster that doesn't, followed by
+// the consistency if it was checked.
+func (r *tableReplication) String() string {
+	var clusters []string
+	for cluster := range r.states {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	var notReady []string
+	for _, cluster := range clusters {
+		switch state := r.states[cluster]; state {
+		case btapb.Table_ClusterState_READY, btapb.Table_ClusterState_READY_OPTIMIZING:
+		default:
+			name := enumName(state.String(), "")
+			if name == "" {
+				name = "unknown"
+			}
+			notReady = append(notReady, cluster+" "+name)
+		}
+	}
+	s := "ready"
+	switch {
+	case len(clusters) == 0:
+		s = "-"
+	case len(notReady) > 0:
+		s = strings.Join(notReady, ", ")
+	}
+	if r.consistent != nil {
+		if *r.consistent {
+			s += ", consistent"
+		} else {
+			s += ", replicating"
+		}
+	}
+	return s
+}
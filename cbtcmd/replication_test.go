@@ -0,0 +1,111 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	btapb "cloud.google.com/go/bigtable/admin/apiv2/adminpb"
+	"google.golang.org/grpc"
+)
+
+// fakeReplicationRPC reports the replication state of every table as
+// states, and consistency as consistent.
+type fakeReplicationRPC struct {
+	btapb.BigtableTableAdminClient
+	states     map[string]btapb.Table_ClusterState_ReplicationState
+	consistent bool
+	names      []string
+}
+
+func (f *fakeReplicationRPC) GetTable(ctx context.Context, req *btapb.GetTableRequest, opts ...grpc.CallOption) (*btapb.Table, error) {
+	f.names = append(f.names, req.Name)
+	t := &btapb.Table{Name: req.Name, ClusterStates: map[string]*btapb.Table_ClusterState{}}
+	for cluster, state := range f.states {
+		t.ClusterStates[cluster] = &btapb.Table_ClusterState{ReplicationState: state}
+	}
+	return t, nil
+}
+
+func (f *fakeReplicationRPC) GenerateConsistencyToken(ctx context.Context, req *btapb.GenerateConsistencyTokenRequest, opts ...grpc.CallOption) (*btapb.GenerateConsistencyTokenResponse, error) {
+	return &btapb.GenerateConsistencyTokenResponse{ConsistencyToken: "token"}, nil
+}
+
+func (f *fakeReplicationRPC) CheckConsistency(ctx context.Context, req *btapb.CheckConsistencyRequest, opts ...grpc.CallOption) (*btapb.CheckConsistencyResponse, error) {
+	return &btapb.CheckConsistencyResponse{Consistent: f.consistent && req.ConsistencyToken == "token"}, nil
+}
+
+func TestTableReplicationString(t *testing.T) {
+	consistent, replicating := true, false
+	for _, test := range []struct {
+		r    tableReplication
+		want string
+	}{
+		{tableReplication{}, "-"},
+		{tableReplication{states: map[string]btapb.Table_ClusterState_ReplicationState{
+			"c1": btapb.Table_ClusterState_READY,
+			"c2": btapb.Table_ClusterState_READY_OPTIMIZING,
+		}, consistent: &consistent}, "ready, consistent"},
+		{tableReplication{states: map[string]btapb.Table_ClusterState_ReplicationState{
+			"c1": btapb.Table_ClusterState_READY,
+			"c3": btapb.Table_ClusterState_INITIALIZING,
+			"c2": btapb.Table_ClusterState_PLANNED_MAINTENANCE,
+		}, consistent: &replicating}, "c2 planned-maintenance, c3 initializing, replicating"},
+	} {
+		if got := test.r.String(); got != test.want {
+			t.Errorf("String() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestTableDetailsReplication(t *testing.T) {
+	ctx, ac, _ := newEmulatorClients(t)
+	if err := ac.CreateTableFromConf(ctx, &bigtable.TableConf{
+		TableID:        "t1",
+		ColumnFamilies: map[string]bigtable.Family{"f": {}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	rpc := &fakeReplicationRPC{states: map[string]btapb.Table_ClusterState_ReplicationState{
+		"c1": btapb.Table_ClusterState_READY,
+		"c2": btapb.Table_ClusterState_INITIALIZING,
+	}}
+	results := fetchTableDetails(ctx, io.Discard, ac, []string{"t1"}, tableReplicationFetcher(rpc, "projects/p/instances/i", true))
+	if len(rpc.names) != 1 || rpc.names[0] != "projects/p/instances/i/tables/t1" {
+		t.Errorf("GetTable called for %q, want projects/p/instances/i/tables/t1", rpc.names)
+	}
+
+	var out bytes.Buffer
+	if failed := printTableDetails(&out, discardLog, 0, results); failed != 0 {
+		t.Fatalf("printTableDetails reported %d failures", failed)
+	}
+	if got := out.String(); !strings.Contains(got, "Replication") || !strings.Contains(got, "c2 initializing, replicating") {
+		t.Errorf("output %q lacks the replication state", got)
+	}
+
+	var logs bytes.Buffer
+	warnInitializing(log.New(&logs, "", 0), results)
+	if got, want := logs.String(), "table t1 is still being replicated to c2"; !strings.Contains(got, want) {
+		t.Errorf("log %q does not contain %q", got, want)
+	}
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bufio"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+	"cloud.google.com/go/bigtable/bttest"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// selftestScripts are the scripts run by cbt selftest and TestSelftest. Each
+// script is a sequence of cbt commands, each followed by the output it is
+// expected to print:
+//
+//	# Comments start with #.
+//	$ cbt createtable t families=f
+//	$ cbt set t r1 f:c=v
+//	$ cbt count t
+//	1
+//
+// A command that fails is followed by "error: " and its message, and one that
+// exits with another status, such as exists, by "exit status" and the status.
+// Arguments
+// are separated by spaces and can't contain them.
+//
+//go:embed selftest/*.txt
+var selftestScripts embed.FS
+
+// selftestStep is a command of a self test script.
+type selftestStep struct {
+	comment string // comment lines before the command, if any
+	args    []string
+	want    string // expected output, without trailing newlines
+	line    int
+}
+
+// parseSelftestScript parses the steps of a self test script.
+func parseSelftestScript(r io.Reader) ([]selftestStep, error) {
+	var (
+		steps   []selftestStep
+		comment strings.Builder
+		out     []string
+	)
+	endStep := func() {
+		if len(steps) > 0 {
+			steps[len(steps)-1].want = strings.TrimRight(strings.Join(out, "\n"), "\n")
+		}
+		out = nil
+	}
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		switch {
+		case strings.HasPrefix(line, "#"):
+			comment.WriteString(line + "\n")
+		case strings.HasPrefix(line, "$ "):
+			endStep()
+			args := strings.Fields(line[2:])
+			if len(args) < 2 || args[0] != "cbt" {
+				return nil, fmt.Errorf("line %d: want a cbt command after $, got %q", n, line)
+			}
+			steps = append(steps, selftestStep{comment: comment.String(), args: args[1:], line: n})
+			comment.Reset()
+		case len(steps) == 0:
+			if strings.TrimSpace(line) != "" {
+				return nil, fmt.Errorf("line %d: output before the first command", n)
+			}
+		default:
+			out = append(out, line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	endStep()
+	return steps, nil
+}
+
+// formatSelftestScript returns the text of a script with the given steps.
+func formatSelftestScript(steps []selftestStep) string {
+	var b strings.Builder
+	for i, step := range steps {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(step.comment)
+		fmt.Fprintf(&b, "$ cbt %s\n", strings.Join(step.args, " "))
+		if step.want != "" {
+			b.WriteString(step.want + "\n")
+		}
+	}
+	return b.String()
+}
+
+// selftestTimestamp matches the cell timestamps printed by read and lookup,
+// which are in the local time zone.
+var selftestTimestamp = regexp.MustCompile(`\d{4}/\d{2}/\d{2}-\d{2}:\d{2}:\d{2}\.\d{6}`)
+
+// runSelftestScript runs the commands of steps against a new in-process
+// emulator and returns their output, with timestamps replaced by <timestamp>.
+func runSelftestScript(ctx context.Context, steps []selftestStep) ([]string, error) {
+	srv, err := bttest.NewServer("localhost:0")
+	if err != nil {
+		return nil, fmt.Errorf("starting the emulator: %v", err)
+	}
+	defer srv.Close()
+	conn, err := grpc.Dial(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the emulator: %v", err)
+	}
+	defer conn.Close()
+	cfg := &Config{Project: "selftest-project", Instance: "selftest-instance"}
+	ac, err := bigtable.NewAdminClient(ctx, cfg.Project, cfg.Instance, option.WithGRPCConn(conn))
+	if err != nil {
+		return nil, err
+	}
+	c, err := bigtable.NewClient(ctx, cfg.Project, cfg.Instance, option.WithGRPCConn(conn))
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []string
+	for _, step := range steps {
+		var out strings.Builder
+		err := runCommand(ctx, cfg, step.args, Deps{Client: c, AdminClient: ac, Stdout: &out, Stderr: io.Discard})
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) {
+			fmt.Fprintf(&out, "%v\n", err)
+		} else if err != nil {
+			fmt.Fprintf(&out, "error: %v\n", err)
+		}
+		got := selftestTimestamp.ReplaceAllString(out.String(), "<timestamp>")
+		outputs = append(outputs, strings.TrimRight(got, "\n"))
+	}
+	return outputs, nil
+}
+
+// selftestScript runs the script name of fsys and writes the differences
+// between the expected and actual output of each command to w, colored if
+// the output of e is. It reports whether there were none.
+func (e *env) selftestScript(ctx context.Context, fsys fs.FS, name string, w io.Writer) (bool, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	steps, err := parseSelftestScript(f)
+	if err != nil {
+		return false, fmt.Errorf("%s: %v", name, err)
+	}
+	outputs, err := runSelftestScript(ctx, steps)
+	if err != nil {
+		return false, err
+	}
+	ok := true
+	for i, step := range steps {
+		if outputs[i] == step.want {
+			continue
+		}
+		ok = false
+		fmt.Fprintf(w, "%s:%d: cbt %s\n", name, step.line, strings.Join(step.args, " "))
+		e.writeUnifiedDiff(w, "want", "got", strings.Split(step.want, "\n"), strings.Split(outputs[i], "\n"), 3)
+	}
+	return ok, nil
+}
+
+func doSelftestReal(ctx context.Context, e *env, args ...string) error {
+	if len(args) > 0 {
+		return errors.New("usage: cbt selftest")
+	}
+	scripts, err := fs.Glob(selftestScripts, "selftest/*.txt")
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, name := range scripts {
+		ok, err := e.selftestScript(ctx, selftestScripts, name, e.stdout)
+		if err != nil {
+			return fmt.Errorf("Running %s: %v", name, err)
+		}
+		result := "ok  "
+		if !ok {
+			result = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(e.stdout, "%s %s\n", result, path.Base(name))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d self tests failed", failed, len(scripts))
+	}
+	fmt.Fprintln(e.stdout, "PASS")
+	return nil
+}
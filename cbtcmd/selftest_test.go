@@ -0,0 +1,110 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var updateSelftest = flag.Bool("update", false, "rewrite the self test scripts with the output of this build")
+
+func TestSelftest(t *testing.T) {
+	scripts, err := fs.Glob(selftestScripts, "selftest/*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scripts) == 0 {
+		t.Fatal("no self test scripts")
+	}
+	for _, name := range scripts {
+		t.Run(name, func(t *testing.T) {
+			if *updateSelftest {
+				// Read the script from disk, not the embedded copy, so that
+				// commands added since the last build are run.
+				data, err := os.ReadFile(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				steps, err := parseSelftestScript(bytes.NewReader(data))
+				if err != nil {
+					t.Fatal(err)
+				}
+				outputs, err := runSelftestScript(context.Background(), steps)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for i := range steps {
+					steps[i].want = outputs[i]
+				}
+				if err := os.WriteFile(name, []byte(formatSelftestScript(steps)), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			e, _ := newTestEnv(Deps{})
+			var diffs bytes.Buffer
+			ok, err := e.selftestScript(context.Background(), selftestScripts, name, &diffs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Errorf("output differs; run go test -run TestSelftest -update to accept it\n%s", diffs.String())
+			}
+		})
+	}
+}
+
+func TestParseSelftestScript(t *testing.T) {
+	script := "# A table.\n" +
+		"$ cbt createtable t families=f\n" +
+		"\n" +
+		"$ cbt ls\n" +
+		"t\n" +
+		"\n" +
+		"# A failure.\n" +
+		"$ cbt count nosuchtable\n" +
+		"error: some error\n"
+	steps, err := parseSelftestScript(strings.NewReader(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []selftestStep{
+		{comment: "# A table.\n", args: []string{"createtable", "t", "families=f"}, line: 2},
+		{args: []string{"ls"}, want: "t", line: 4},
+		{comment: "# A failure.\n", args: []string{"count", "nosuchtable"}, want: "error: some error", line: 8},
+	}
+	if diff := cmp.Diff(want, steps, cmp.AllowUnexported(selftestStep{})); diff != "" {
+		t.Errorf("parseSelftestScript mismatch (-want +got):\n%s", diff)
+	}
+	if got := formatSelftestScript(steps); got != script {
+		t.Errorf("formatSelftestScript = %q, want %q", got, script)
+	}
+
+	for _, bad := range []string{"output first\n$ cbt ls\n", "$ ls\n", "$ cbt\n"} {
+		if _, err := parseSelftestScript(strings.NewReader(bad)); err == nil {
+			t.Errorf("parseSelftestScript(%q) succeeded, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+// Import of the Hadoop SequenceFiles written by HBase's Export tool. Each
+// record has an ImmutableBytesWritable row key and a Result value, which HBase
+// serializes as a length-delimited ClientProtos.Result protocol buffer. Only
+// uncompressed files are supported.
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/bigtable"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	hbaseKeyClass   = "org.apache.hadoop.hbase.io.ImmutableBytesWritable"
+	hbaseValueClass = "org.apache.hadoop.hbase.client.Result"
+
+	// hbaseCellTypePut is the CellType of cells holding values; other types
+	// are delete markers, which are not imported.
+	hbaseCellTypePut = 4
+)
+
+// seqFileReader reads the records of an uncompressed SequenceFile.
+type seqFileReader struct {
+	r          *bufio.Reader
+	sync       [16]byte
+	keyClass   string
+	valueClass string
+}
+
+func newSeqFileReader(r io.Reader) (*seqFileReader, error) {
+	sr := &seqFileReader{r: bufio.NewReader(r)}
+	var magic [4]byte
+	if _, err := io.ReadFull(sr.r, magic[:]); err != nil {
+		return nil, fmt.Errorf("reading SequenceFile header: %v", err)
+	}
+	if string(magic[:3]) != "SEQ" {
+		return nil, errors.New("not a SequenceFile")
+	}
+	if magic[3] < 6 {
+		return nil, fmt.Errorf("unsupported SequenceFile version %d", magic[3])
+	}
+	var err error
+	if sr.keyClass, err = sr.readText(); err != nil {
+		return nil, err
+	}
+	if sr.valueClass, err = sr.readText(); err != nil {
+		return nil, err
+	}
+	var flags [2]byte
+	if _, err := io.ReadFull(sr.r, flags[:]); err != nil {
+		return nil, err
+	}
+	if flags[0] != 0 || flags[1] != 0 {
+		return nil, errors.New("compressed SequenceFiles are not supported; export with compression disabled")
+	}
+	var nMeta int32
+	if err := binary.Read(sr.r, binary.BigEndian, &nMeta); err != nil {
+		return nil, err
+	}
+	for i := 0; i < 2*int(nMeta); i++ {
+		if _, err := sr.readText(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := io.ReadFull(sr.r, sr.sync[:]); err != nil {
+		return nil, err
+	}
+	return sr, nil
+}
+
+// readVInt reads a Hadoop WritableUtils variable-length integer.
+func (sr *seqFileReader) readVInt() (int64, error) {
+	b, err := sr.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	first := int8(b)
+	if first >= -112 {
+		return int64(first), nil
+	}
+	negative := first < -120
+	n := -112 - int(first)
+	if negative {
+		n = -120 - int(first)
+	}
+	var v int64
+	for i := 0; i < n; i++ {
+		b, err := sr.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		v = v<<8 | int64(b)
+	}
+	if negative {
+		v = ^v
+	}
+	return v, nil
+}
+
+// readText reads a Hadoop Text string.
+func (sr *seqFileReader) readText() (string, error) {
+	n, err := sr.readVInt()
+	if err != nil {
+		return "", err
+	}
+	if n < 0 {
+		return "", fmt.Errorf("bad string length %d", n)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(sr.r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// next returns the serialized key and value of the next record, or io.EOF.
+func (sr *seqFileReader) next() (key, value []byte, err error) {
+	var recLen int32
+	for {
+		if err := binary.Read(sr.r, binary.BigEndian, &recLen); err != nil {
+			return nil, nil, err
+		}
+		if recLen != -1 {
+			break
+		}
+		var sync [16]byte
+		if _, err := io.ReadFull(sr.r, sync[:]); err != nil {
+			return nil, nil, io.ErrUnexpectedEOF
+		}
+		if sync != sr.sync {
+			return nil, nil, errors.New("corrupt SequenceFile: bad sync marker")
+		}
+	}
+	var keyLen int32
+	if err := binary.Read(sr.r, binary.BigEndian, &keyLen); err != nil {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	if keyLen < 0 || recLen < keyLen {
+		return nil, nil, fmt.Errorf("corrupt SequenceFile: record length %d, key length %d", recLen, keyLen)
+	}
+	buf := make([]byte, recLen)
+	if _, err := io.ReadFull(sr.r, buf); err != nil {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	return buf[:keyLen], buf[keyLen:], nil
+}
+
+// hbaseCell is a cell of an HBase Result.
+type hbaseCell struct {
+	row, family, qualifier, value []byte
+	timestamp                     int64 // milliseconds
+	cellType                      int
+}
+
+// parseHBaseResult parses a length-delimited ClientProtos.Result.
+func parseHBaseResult(b []byte) ([]hbaseCell, error) {
+	n, l := protowire.ConsumeVarint(b)
+	if l < 0 || uint64(len(b)-l) < n {
+		return nil, errors.New("bad Result length")
+	}
+	b = b[l : l+int(n)]
+	var cells []hbaseCell
+	for len(b) > 0 {
+		num, typ, l := protowire.ConsumeTag(b)
+		if l < 0 {
+			return nil, protowire.ParseError(l)
+		}
+		b = b[l:]
+		if num == 1 && typ == protowire.BytesType {
+			msg, l := protowire.ConsumeBytes(b)
+			if l < 0 {
+				return nil, protowire.ParseError(l)
+			}
+			c, err := parseHBaseCell(msg)
+			if err != nil {
+				return nil, err
+			}
+			cells = append(cells, c)
+			b = b[l:]
+			continue
+		}
+		l = protowire.ConsumeFieldValue(num, typ, b)
+		if l < 0 {
+			return nil, protowire.ParseError(l)
+		}
+		b = b[l:]
+	}
+	return cells, nil
+}
+
+// parseHBaseCell parses a CellProtos.Cell.
+func parseHBaseCell(b []byte) (hbaseCell, error) {
+	c := hbaseCell{cellType: hbaseCellTypePut}
+	for len(b) > 0 {
+		num, typ, l := protowire.ConsumeTag(b)
+		if l < 0 {
+			return c, protowire.ParseError(l)
+		}
+		b = b[l:]
+		switch {
+		case typ == protowire.BytesType && (num == 1 || num == 2 || num == 3 || num == 6):
+			v, l := protowire.ConsumeBytes(b)
+			if l < 0 {
+				return c, protowire.ParseError(l)
+			}
+			switch num {
+			case 1:
+				c.row = v
+			case 2:
+				c.family = v
+			case 3:
+				c.qualifier = v
+			case 6:
+				c.value = v
+			}
+			b = b[l:]
+		case typ == protowire.VarintType && (num == 4 || num == 5):
+			v, l := protowire.ConsumeVarint(b)
+			if l < 0 {
+				return c, protowire.ParseError(l)
+			}
+			if num == 4 {
+				c.timestamp = int64(v)
+			} else {
+				c.cellType = int(v)
+			}
+			b = b[l:]
+		default:
+			l := protowire.ConsumeFieldValue(num, typ, b)
+			if l < 0 {
+				return c, protowire.ParseError(l)
+			}
+			b = b[l:]
+		}
+	}
+	return c, nil
+}
+
+// hbaseRecordMutation converts one record of an HBase export into a row key
+// and mutation. Cell timestamps are kept, converted to microseconds.
+func hbaseRecordMutation(key, value []byte) (string, *bigtable.Mutation, int, error) {
+	// The key is an ImmutableBytesWritable: a 4-byte length and the row key.
+	if len(key) < 4 || int(binary.BigEndian.Uint32(key)) != len(key)-4 {
+		return "", nil, 0, errors.New("bad row key")
+	}
+	row := key[4:]
+	cells, err := parseHBaseResult(value)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	mut := bigtable.NewMutation()
+	n := 0
+	for _, c := range cells {
+		if c.cellType != hbaseCellTypePut {
+			continue
+		}
+		if c.row != nil && !bytes.Equal(c.row, row) {
+			return "", nil, 0, fmt.Errorf("cell row %q does not match record row %q", c.row, row)
+		}
+		mut.Set(string(c.family), string(c.qualifier), bigtable.Timestamp(c.timestamp*1000), c.value)
+		n++
+	}
+	return string(row), mut, n, nil
+}
+
+// importSequenceFile writes the rows of an HBase export SequenceFile to tbl,
+// returning the number of rows written.
+func importSequenceFile(ctx context.Context, e *env, tbl *bigtable.Table, r io.Reader, ia importerArgs) (int, error) {
+	sr, err := newSeqFileReader(r)
+	if err != nil {
+		return 0, err
+	}
+	if sr.keyClass != hbaseKeyClass || sr.valueClass != hbaseValueClass {
+		return 0, fmt.Errorf("not an HBase export: SequenceFile has %s keys and %s values", sr.keyClass, sr.valueClass)
+	}
+
+	p := startImportPipeline(ctx, e, tbl, ia.workers, ia.inFlight)
+	read := 0
+	var b importBatch
+	for err == nil {
+		var key, value []byte
+		key, value, err = sr.next()
+		if err != nil {
+			break
+		}
+		row, mut, cells, rerr := hbaseRecordMutation(key, value)
+		if rerr != nil {
+			err = fmt.Errorf("record %d: %v", read+len(b.keys)+1, rerr)
+			break
+		}
+		if cells == 0 {
+			e.log.Printf("RowKey '%s' has no cells to import, skipping", row)
+			continue
+		}
+		b.keys, b.muts = append(b.keys, row), append(b.muts, mut)
+		if len(b.keys) == ia.sz {
+			read += len(b.keys)
+			err = p.send(b)
+			b = importBatch{}
+		}
+	}
+	if err == io.EOF {
+		err = nil
+		if len(b.keys) > 0 {
+			err = p.send(b)
+		}
+	}
+	total, werr := p.close()
+	if ia.workers > 1 {
+		p.logThroughput()
+	}
+	if err == nil {
+		err = werr
+	}
+	return total, err
+}
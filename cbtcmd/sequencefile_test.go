@@ -0,0 +1,180 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sort"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// writeText writes a Hadoop Text string whose length fits in one vint byte.
+func writeText(buf *bytes.Buffer, s string) {
+	buf.WriteByte(byte(len(s)))
+	buf.WriteString(s)
+}
+
+func hbaseResult(cells ...hbaseCell) []byte {
+	var result []byte
+	for _, c := range cells {
+		var cell []byte
+		cell = protowire.AppendTag(cell, 1, protowire.BytesType)
+		cell = protowire.AppendBytes(cell, c.row)
+		cell = protowire.AppendTag(cell, 2, protowire.BytesType)
+		cell = protowire.AppendBytes(cell, c.family)
+		cell = protowire.AppendTag(cell, 3, protowire.BytesType)
+		cell = protowire.AppendBytes(cell, c.qualifier)
+		cell = protowire.AppendTag(cell, 4, protowire.VarintType)
+		cell = protowire.AppendVarint(cell, uint64(c.timestamp))
+		cell = protowire.AppendTag(cell, 5, protowire.VarintType)
+		cell = protowire.AppendVarint(cell, uint64(c.cellType))
+		cell = protowire.AppendTag(cell, 6, protowire.BytesType)
+		cell = protowire.AppendBytes(cell, c.value)
+		result = protowire.AppendTag(result, 1, protowire.BytesType)
+		result = protowire.AppendBytes(result, cell)
+	}
+	// associated_cell_count, which the importer ignores.
+	result = protowire.AppendTag(result, 2, protowire.VarintType)
+	result = protowire.AppendVarint(result, 0)
+	return protowire.AppendBytes(nil, result)
+}
+
+// hbaseSequenceFile builds an uncompressed HBase export SequenceFile, with a
+// sync marker before every record.
+func hbaseSequenceFile(rows map[string][]hbaseCell, order []string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("SEQ\x06")
+	writeText(&buf, hbaseKeyClass)
+	writeText(&buf, hbaseValueClass)
+	buf.Write([]byte{0, 0})
+	binary.Write(&buf, binary.BigEndian, int32(0))
+	sync := []byte("0123456789abcdef")
+	buf.Write(sync)
+	for _, row := range order {
+		key := binary.BigEndian.AppendUint32(nil, uint32(len(row)))
+		key = append(key, row...)
+		value := hbaseResult(rows[row]...)
+		binary.Write(&buf, binary.BigEndian, int32(-1))
+		buf.Write(sync)
+		binary.Write(&buf, binary.BigEndian, int32(len(key)+len(value)))
+		binary.Write(&buf, binary.BigEndian, int32(len(key)))
+		buf.Write(key)
+		buf.Write(value)
+	}
+	return buf.Bytes()
+}
+
+func TestImportSequenceFile(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"cf1", "cf2"})
+	tbl := c.Open("my-table")
+
+	cell := func(row, fam, qual string, ts int64, typ int, val string) hbaseCell {
+		return hbaseCell{row: []byte(row), family: []byte(fam), qualifier: []byte(qual), timestamp: ts, cellType: typ, value: []byte(val)}
+	}
+	rows := map[string][]hbaseCell{
+		"r1": {
+			cell("r1", "cf1", "a", 2000, hbaseCellTypePut, "new"),
+			cell("r1", "cf1", "a", 1000, hbaseCellTypePut, "old"),
+			cell("r1", "cf2", "b", 1000, hbaseCellTypePut, "b"),
+		},
+		"r2": {cell("r2", "cf1", "a", 3000, hbaseCellTypePut, "x")},
+		"r3": {cell("r3", "cf1", "a", 3000, 8, "")}, // a delete marker
+	}
+	data := hbaseSequenceFile(rows, []string{"r1", "r2", "r3"})
+	ia := importerArgs{sz: 1, workers: 2, format: "hbase-sequencefile"}
+	n, err := importSequenceFile(ctx, e, tbl, bytes.NewReader(data), ia)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("imported %d rows, want 2", n)
+	}
+
+	got := map[string][]string{}
+	err = tbl.ReadRows(ctx, bigtable.InfiniteRange(""), func(r bigtable.Row) bool {
+		for _, items := range r {
+			for _, item := range items {
+				got[r.Key()] = append(got[r.Key()], item.Column+"="+string(item.Value)+"@"+item.Timestamp.Time().UTC().Format("05.000"))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cells := range got {
+		sort.Strings(cells)
+	}
+	want := map[string][]string{
+		"r1": {"cf1:a=new@02.000", "cf1:a=old@01.000", "cf2:b=b@01.000"},
+		"r2": {"cf1:a=x@03.000"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("imported rows mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSequenceFileErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"empty":      "",
+		"not seq":    "PAR1xxxx",
+		"compressed": "SEQ\x06\x01k\x01v\x01\x00",
+	} {
+		if _, err := newSeqFileReader(strings.NewReader(data)); err == nil {
+			t.Errorf("%s: newSeqFileReader did not fail", name)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("SEQ\x06")
+	writeText(&buf, "org.apache.hadoop.io.Text")
+	writeText(&buf, "org.apache.hadoop.io.Text")
+	buf.Write([]byte{0, 0, 0, 0, 0, 0})
+	buf.WriteString("0123456789abcdef")
+	e, _ := newTestEnv(Deps{})
+	if _, err := importSequenceFile(context.Background(), e, nil, &buf, importerArgs{sz: 1, workers: 1}); err == nil {
+		t.Error("importSequenceFile of a non-HBase SequenceFile did not fail")
+	}
+}
+
+func TestReadVInt(t *testing.T) {
+	for _, test := range []struct {
+		in   []byte
+		want int64
+	}{
+		{[]byte{0x05}, 5},
+		{[]byte{0x90}, -112},
+		{[]byte{0x8f, 0xc8}, 200},
+		{[]byte{0x8e, 0x01, 0x00}, 256},
+		{[]byte{0x87, 0xc8}, -201},
+	} {
+		sr := &seqFileReader{r: bufio.NewReader(bytes.NewReader(test.in))}
+		got, err := sr.readVInt()
+		if err != nil || got != test.want {
+			t.Errorf("readVInt(%x) = %d, %v, want %d", test.in, got, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// setManySeparator separates the row sections of cbt setmany.
+const setManySeparator = "--"
+
+// parseSetManyArgs parses the arguments of cbt setmany after the table: row
+// sections separated by setManySeparator, each a row key followed by
+// family:column=val[@ts] cells, and app-profile and authorized-view options
+// anywhere. Cells of a row given in several sections go in one mutation.
+func parseSetManyArgs(args []string, now time.Time) (keys []string, muts []*bigtable.Mutation, opts map[string]string, err error) {
+	opts = map[string]string{}
+	byKey := map[string]*bigtable.Mutation{}
+	var row string
+	cells := 0
+	endSection := func() error {
+		if row == "" {
+			return fmt.Errorf("empty row section")
+		}
+		if cells == 0 {
+			return fmt.Errorf("no cells to set in row %q", row)
+		}
+		row, cells = "", 0
+		return nil
+	}
+	for _, arg := range args {
+		if isOptionArg(arg, []string{"app-profile", "authorized-view"}) {
+			name, val, _ := strings.Cut(arg, "=")
+			opts[name] = val
+			continue
+		}
+		if arg == setManySeparator {
+			if err := endSection(); err != nil {
+				return nil, nil, nil, err
+			}
+			continue
+		}
+		if row == "" {
+			row = arg
+			if _, ok := byKey[row]; !ok {
+				keys = append(keys, row)
+				byKey[row] = bigtable.NewMutation()
+				muts = append(muts, byKey[row])
+			}
+			continue
+		}
+		m := setArg.FindStringSubmatch(arg)
+		if m == nil {
+			return nil, nil, nil, fmt.Errorf("bad set arg %q in row %q", arg, row)
+		}
+		val, ts := splitCellTimestamp(m[3], now)
+		byKey[row].Set(m[1], m[2], ts, []byte(val))
+		cells++
+	}
+	if err := endSection(); err != nil {
+		return nil, nil, nil, err
+	}
+	return keys, muts, opts, nil
+}
+
+func doSetMany(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt setmany <table> [app-profile=<app profile id>] [authorized-view=<authorized-view-id>] " +
+		"<row> family:[column]=val[@ts] ... [-- <row> family:[column]=val[@ts] ...]..."
+	if len(args) < 3 {
+		return errors.New(usage)
+	}
+	keys, muts, opts, err := parseSetManyArgs(args[1:], time.Now())
+	if err != nil {
+		return fmt.Errorf("%v\n%s", err, usage)
+	}
+
+	tbl, err := e.openTableAPI(opts["app-profile"], args[0], opts["authorized-view"])
+	if err != nil {
+		return err
+	}
+	errs, err := tbl.ApplyBulk(ctx, keys, muts)
+	if err != nil {
+		return fmt.Errorf("Applying mutations: %v", err)
+	}
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			e.log.Printf("Setting row %q: %v", keys[i], err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", failed, len(keys))
+	}
+	fmt.Fprintf(e.stdout, "Set %d rows\n", len(keys))
+	return nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+)
+
+func TestParseSetManyArgs(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	keys, muts, opts, err := parseSetManyArgs([]string{
+		"app-profile=batch", "r1", "cf:a=1", "cf:b=2@1000", "--", "r2", "cf:a=3", "--", "r1", "cf:c=4",
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0] != "r1" || keys[1] != "r2" || len(muts) != 2 {
+		t.Errorf("keys = %q, %d mutations; want [r1 r2], 2 mutations", keys, len(muts))
+	}
+	if opts["app-profile"] != "batch" {
+		t.Errorf("opts = %v, want app-profile=batch", opts)
+	}
+
+	for _, args := range [][]string{
+		{"r1", "cf:a=1", "--"},
+		{"r1", "cf:a=1", "--", "--", "r2", "cf:a=2"},
+		{"r1", "--", "r2", "cf:a=2"},
+		{"r1", "not-a-cell"},
+	} {
+		if _, _, _, err := parseSetManyArgs(args, now); err == nil {
+			t.Errorf("parseSetManyArgs(%q) succeeded", args)
+		}
+	}
+}
+
+func TestSetMany(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"my-table"}, []string{"cf"})
+	e, out := newTestEnv(Deps{Client: c})
+
+	if err := doSetMany(ctx, e, "my-table", "r1", "cf:a=1@1000", "cf:b=2@1000", "--", "r2", "cf:a=3@2000"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "Set 2 rows\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	tbl := c.Open("my-table")
+	var got []bigtable.ReadItem
+	if err := tbl.ReadRows(ctx, bigtable.InfiniteRange(""), func(r bigtable.Row) bool {
+		got = append(got, r["cf"]...)
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []bigtable.ReadItem{
+		{Row: "r1", Column: "cf:a", Timestamp: 1000, Value: []byte("1")},
+		{Row: "r1", Column: "cf:b", Timestamp: 1000, Value: []byte("2")},
+		{Row: "r2", Column: "cf:a", Timestamp: 2000, Value: []byte("3")},
+	}
+	if !Equal(got, want) {
+		t.Errorf("cells = %v, want %v", got, want)
+	}
+}
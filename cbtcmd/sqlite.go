@@ -0,0 +1,315 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+// A minimal writer of SQLite database files, so that export can write a
+// table slice that analysts can query offline without cbt depending on a
+// SQLite driver. It writes a single rowid table in one pass: leaf pages are
+// written as they fill, and the interior pages above them when the file is
+// closed. See https://www.sqlite.org/fileformat2.html.
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	sqlitePageSize = 4096
+	// sqliteMaxLocal and sqliteMinLocal bound the part of a record stored in
+	// a table leaf page, the rest going to overflow pages.
+	sqliteMaxLocal = sqlitePageSize - 35
+	sqliteMinLocal = (sqlitePageSize-12)*32/255 - 23
+	// sqliteVersion is the SQLITE_VERSION_NUMBER recorded as having written
+	// the file.
+	sqliteVersion = 3046000
+)
+
+const (
+	sqliteLeafTable     = 0x0d
+	sqliteInteriorTable = 0x05
+)
+
+// sqliteChild is a page of a table b-tree and the largest rowid in it.
+type sqliteChild struct {
+	page     uint32
+	maxRowid int64
+}
+
+// sqliteWriter writes a database with one table to w. Rows must be added
+// with insert, and the file completed with close.
+type sqliteWriter struct {
+	w         io.WriterAt
+	table     string
+	createSQL string
+	pages     uint32 // the number of pages allocated
+	rowid     int64
+	cells     [][]byte // the cells of the leaf page being filled
+	used      int      // the bytes of the leaf page used
+	leaves    []sqliteChild
+}
+
+// newSQLiteWriter starts a database with one table, created by createSQL.
+func newSQLiteWriter(w io.WriterAt, table, createSQL string) *sqliteWriter {
+	// Page 1 holds the schema, and is written last.
+	return &sqliteWriter{w: w, table: table, createSQL: createSQL, pages: 1, used: 8}
+}
+
+// sqliteVarint encodes v as a SQLite variable-length integer.
+func sqliteVarint(v uint64) []byte {
+	if v > 1<<56-1 {
+		buf := make([]byte, 9)
+		buf[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			buf[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return buf
+	}
+	var rev []byte
+	for {
+		rev = append(rev, byte(v&0x7f)|0x80)
+		if v >>= 7; v == 0 {
+			break
+		}
+	}
+	rev[0] &= 0x7f
+	buf := make([]byte, len(rev))
+	for i, b := range rev {
+		buf[len(rev)-1-i] = b
+	}
+	return buf
+}
+
+// sqliteRecord encodes values, which may be nil, int64, string or []byte, in
+// the record format.
+func sqliteRecord(values ...interface{}) ([]byte, error) {
+	var types, body []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case nil:
+			types = append(types, 0)
+		case int64:
+			var n int
+			switch {
+			case v == 0:
+				types = append(types, 8)
+			case v == 1:
+				types = append(types, 9)
+			case v >= math.MinInt8 && v <= math.MaxInt8:
+				types, n = append(types, 1), 1
+			case v >= math.MinInt16 && v <= math.MaxInt16:
+				types, n = append(types, 2), 2
+			case v >= -1<<23 && v < 1<<23:
+				types, n = append(types, 3), 3
+			case v >= math.MinInt32 && v <= math.MaxInt32:
+				types, n = append(types, 4), 4
+			case v >= -1<<47 && v < 1<<47:
+				types, n = append(types, 5), 6
+			default:
+				types, n = append(types, 6), 8
+			}
+			var buf [8]byte
+			binary.BigEndian.PutUint64(buf[:], uint64(v))
+			body = append(body, buf[8-n:]...)
+		case string:
+			types = append(types, sqliteVarint(uint64(13+2*len(v)))...)
+			body = append(body, v...)
+		case []byte:
+			types = append(types, sqliteVarint(uint64(12+2*len(v)))...)
+			body = append(body, v...)
+		default:
+			return nil, fmt.Errorf("can't store a %T in SQLite", v)
+		}
+	}
+	// The header size counts its own varint.
+	size := len(types) + 1
+	if len(sqliteVarint(uint64(size))) > 1 {
+		size = len(types) + len(sqliteVarint(uint64(len(types)+2)))
+	}
+	record := append(sqliteVarint(uint64(size)), types...)
+	return append(record, body...), nil
+}
+
+// allocate returns the number of a new page.
+func (sw *sqliteWriter) allocate() uint32 {
+	sw.pages++
+	return sw.pages
+}
+
+func (sw *sqliteWriter) writePage(page uint32, data []byte) error {
+	_, err := sw.w.WriteAt(data, int64(page-1)*sqlitePageSize)
+	return err
+}
+
+// leafCell returns the cell of a row in a table leaf page, writing the part
+// of its record that doesn't fit in the page to overflow pages.
+func (sw *sqliteWriter) leafCell(rowid int64, record []byte) ([]byte, error) {
+	cell := append(sqliteVarint(uint64(len(record))), sqliteVarint(uint64(rowid))...)
+	local := len(record)
+	if local > sqliteMaxLocal {
+		k := sqliteMinLocal + (len(record)-sqliteMinLocal)%(sqlitePageSize-4)
+		local = sqliteMinLocal
+		if k <= sqliteMaxLocal {
+			local = k
+		}
+	}
+	cell = append(cell, record[:local]...)
+	rest := record[local:]
+	if len(rest) == 0 {
+		return cell, nil
+	}
+	// Each overflow page holds the number of the next, then content.
+	first := sw.pages + 1
+	for len(rest) > 0 {
+		page := sw.allocate()
+		data := make([]byte, sqlitePageSize)
+		n := copy(data[4:], rest)
+		if rest = rest[n:]; len(rest) > 0 {
+			binary.BigEndian.PutUint32(data, page+1)
+		}
+		if err := sw.writePage(page, data); err != nil {
+			return nil, err
+		}
+	}
+	var ptr [4]byte
+	binary.BigEndian.PutUint32(ptr[:], first)
+	return append(cell, ptr[:]...), nil
+}
+
+// btreePage lays out a b-tree page whose header, of headerSize bytes, starts
+// at offset. The caller fills in the type and, for interior pages, the
+// right-most pointer.
+func btreePage(offset, headerSize int, cells [][]byte) []byte {
+	data := make([]byte, sqlitePageSize)
+	ptrs := offset + headerSize
+	end := sqlitePageSize
+	for i, c := range cells {
+		end -= len(c)
+		copy(data[end:], c)
+		binary.BigEndian.PutUint16(data[ptrs+2*i:], uint16(end))
+	}
+	binary.BigEndian.PutUint16(data[offset+3:], uint16(len(cells)))
+	binary.BigEndian.PutUint16(data[offset+5:], uint16(end))
+	return data
+}
+
+// flushLeaf writes the leaf page being filled.
+func (sw *sqliteWriter) flushLeaf() error {
+	page := sw.allocate()
+	data := btreePage(0, 8, sw.cells)
+	data[0] = sqliteLeafTable
+	if err := sw.writePage(page, data); err != nil {
+		return err
+	}
+	sw.leaves = append(sw.leaves, sqliteChild{page, sw.rowid})
+	sw.cells, sw.used = nil, 8
+	return nil
+}
+
+// insert adds a row to the table.
+func (sw *sqliteWriter) insert(values ...interface{}) error {
+	record, err := sqliteRecord(values...)
+	if err != nil {
+		return err
+	}
+	cell, err := sw.leafCell(sw.rowid+1, record)
+	if err != nil {
+		return err
+	}
+	if sw.used+2+len(cell) > sqlitePageSize {
+		if err := sw.flushLeaf(); err != nil {
+			return err
+		}
+	}
+	sw.rowid++
+	sw.cells = append(sw.cells, cell)
+	sw.used += 2 + len(cell)
+	return nil
+}
+
+// buildInterior writes interior pages above children until there is a single
+// root, and returns its page number.
+func (sw *sqliteWriter) buildInterior(children []sqliteChild) (uint32, error) {
+	for len(children) > 1 {
+		var parents []sqliteChild
+		for len(children) > 0 {
+			var cells [][]byte
+			used := 12
+			i := 0
+			// Every child but the last of a page has a cell; the last is
+			// the right-most pointer.
+			for ; i < len(children)-1; i++ {
+				cell := make([]byte, 4, 13)
+				binary.BigEndian.PutUint32(cell, children[i].page)
+				cell = append(cell, sqliteVarint(uint64(children[i].maxRowid))...)
+				if used+2+len(cell) > sqlitePageSize {
+					break
+				}
+				cells = append(cells, cell)
+				used += 2 + len(cell)
+			}
+			right := children[i]
+			page := sw.allocate()
+			data := btreePage(0, 12, cells)
+			data[0] = sqliteInteriorTable
+			binary.BigEndian.PutUint32(data[8:], right.page)
+			if err := sw.writePage(page, data); err != nil {
+				return 0, err
+			}
+			parents = append(parents, sqliteChild{page, right.maxRowid})
+			children = children[i+1:]
+		}
+		children = parents
+	}
+	return children[0].page, nil
+}
+
+// close writes the remaining pages and the schema, completing the file.
+func (sw *sqliteWriter) close() error {
+	if len(sw.cells) > 0 || len(sw.leaves) == 0 {
+		if err := sw.flushLeaf(); err != nil {
+			return err
+		}
+	}
+	root, err := sw.buildInterior(sw.leaves)
+	if err != nil {
+		return err
+	}
+	record, err := sqliteRecord("table", sw.table, sw.table, int64(root), sw.createSQL)
+	if err != nil {
+		return err
+	}
+	cell := append(sqliteVarint(uint64(len(record))), sqliteVarint(1)...)
+	data := btreePage(100, 8, [][]byte{append(cell, record...)})
+	data[100] = sqliteLeafTable
+
+	copy(data, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(data[16:], sqlitePageSize)
+	data[18], data[19] = 1, 1 // legacy journal mode
+	data[21], data[22], data[23] = 64, 32, 32
+	binary.BigEndian.PutUint32(data[24:], 1) // file change counter
+	binary.BigEndian.PutUint32(data[28:], sw.pages)
+	binary.BigEndian.PutUint32(data[40:], 1) // schema cookie
+	binary.BigEndian.PutUint32(data[44:], 4) // schema format
+	binary.BigEndian.PutUint32(data[56:], 1) // UTF-8
+	binary.BigEndian.PutUint32(data[92:], 1) // version-valid-for
+	binary.BigEndian.PutUint32(data[96:], sqliteVersion)
+	return sw.writePage(1, data)
+}
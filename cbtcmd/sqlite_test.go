@@ -0,0 +1,210 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func decodeSQLiteVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 8; i++ {
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return v<<8 | uint64(b[8]), 9
+}
+
+func decodeSQLiteRecord(t *testing.T, payload []byte) []interface{} {
+	size, n := decodeSQLiteVarint(payload)
+	header, body := payload[n:size], payload[size:]
+	var values []interface{}
+	for len(header) > 0 {
+		st, n := decodeSQLiteVarint(header)
+		header = header[n:]
+		switch {
+		case st == 0:
+			values = append(values, nil)
+		case st == 8 || st == 9:
+			values = append(values, int64(st-8))
+		case st >= 1 && st <= 6:
+			width := []int{0, 1, 2, 3, 4, 6, 8}[st]
+			var v int64
+			for i, b := range body[:width] {
+				if i == 0 {
+					v = int64(int8(b))
+				} else {
+					v = v<<8 | int64(b)
+				}
+			}
+			values = append(values, v)
+			body = body[width:]
+		case st >= 12 && st%2 == 0:
+			l := (st - 12) / 2
+			values = append(values, append([]byte{}, body[:l]...))
+			body = body[l:]
+		case st >= 13:
+			l := (st - 13) / 2
+			values = append(values, string(body[:l]))
+			body = body[l:]
+		default:
+			t.Fatalf("unexpected serial type %d", st)
+		}
+	}
+	return values
+}
+
+// readSQLiteTable decodes the rows of the table b-tree rooted at root.
+func readSQLiteTable(t *testing.T, db []byte, root uint32) [][]interface{} {
+	t.Helper()
+	var rows [][]interface{}
+	var walk func(page uint32)
+	walk = func(page uint32) {
+		data := db[(page-1)*sqlitePageSize : page*sqlitePageSize]
+		hdr := 0
+		if page == 1 {
+			hdr = 100
+		}
+		cells := int(binary.BigEndian.Uint16(data[hdr+3:]))
+		switch data[hdr] {
+		case sqliteInteriorTable:
+			for i := 0; i < cells; i++ {
+				off := binary.BigEndian.Uint16(data[hdr+12+2*i:])
+				walk(binary.BigEndian.Uint32(data[off:]))
+			}
+			walk(binary.BigEndian.Uint32(data[hdr+8:]))
+		case sqliteLeafTable:
+			for i := 0; i < cells; i++ {
+				cell := data[binary.BigEndian.Uint16(data[hdr+8+2*i:]):]
+				size, n := decodeSQLiteVarint(cell)
+				_, m := decodeSQLiteVarint(cell[n:])
+				cell = cell[n+m:]
+				local := int(size)
+				if local > sqliteMaxLocal {
+					k := sqliteMinLocal + (local-sqliteMinLocal)%(sqlitePageSize-4)
+					if local = sqliteMinLocal; k <= sqliteMaxLocal {
+						local = k
+					}
+				}
+				payload := append([]byte{}, cell[:local]...)
+				for next := uint32(0); len(payload) < int(size); {
+					if next == 0 {
+						next = binary.BigEndian.Uint32(cell[local:])
+					}
+					over := db[(next-1)*sqlitePageSize : next*sqlitePageSize]
+					rest := int(size) - len(payload)
+					if rest > sqlitePageSize-4 {
+						rest = sqlitePageSize - 4
+					}
+					payload = append(payload, over[4:4+rest]...)
+					next = binary.BigEndian.Uint32(over)
+				}
+				rows = append(rows, decodeSQLiteRecord(t, payload))
+			}
+		default:
+			t.Fatalf("page %d has unexpected type %#x", page, data[hdr])
+		}
+	}
+	walk(root)
+	return rows
+}
+
+// readSQLiteDB checks the header of a database written by sqliteWriter, and
+// returns the SQL creating its table and the table's rows.
+func readSQLiteDB(t *testing.T, path string) (string, [][]interface{}) {
+	t.Helper()
+	db, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(db), "SQLite format 3\x00") || len(db)%sqlitePageSize != 0 {
+		t.Fatalf("%s is not a SQLite database", path)
+	}
+	if pages := binary.BigEndian.Uint32(db[28:]); int(pages) != len(db)/sqlitePageSize {
+		t.Errorf("header has %d pages, file has %d", pages, len(db)/sqlitePageSize)
+	}
+	schema := readSQLiteTable(t, db, 1)
+	if len(schema) != 1 {
+		t.Fatalf("schema = %v, want one table", schema)
+	}
+	return schema[0][4].(string), readSQLiteTable(t, db, uint32(schema[0][3].(int64)))
+}
+
+func TestSQLiteVarint(t *testing.T) {
+	for _, v := range []uint64{0, 1, 127, 128, 16383, 16384, 1<<56 - 1, 1 << 56, 1<<64 - 1} {
+		b := sqliteVarint(v)
+		if got, n := decodeSQLiteVarint(b); got != v || n != len(b) {
+			t.Errorf("sqliteVarint(%d) = %x, decodes to %d (%d bytes)", v, b, got, n)
+		}
+	}
+}
+
+func TestSQLiteWriter(t *testing.T) {
+	var want [][]interface{}
+	ints := []int64{0, 1, -1, 300, -70000, 1 << 30, 1 << 40, -1 << 60}
+	// Enough rows for interior pages, with some values needing overflow pages.
+	for i := 0; i < 5000; i++ {
+		value := []byte(fmt.Sprint(i))
+		if i%500 == 3 {
+			value = []byte(strings.Repeat("v", 3000+7*i))
+		}
+		var last interface{} = fmt.Sprintf("s%d", i)
+		if i%2 == 0 {
+			last = nil
+		}
+		want = append(want, []interface{}{fmt.Sprintf("row%05d", i), ints[i%len(ints)], value, last})
+	}
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const create = "CREATE TABLE t(a TEXT, b INTEGER, c BLOB, d TEXT)"
+	sw := newSQLiteWriter(f, "t", create)
+	for _, row := range want {
+		if err := sw.insert(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sw.close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sql, got := readSQLiteDB(t, path)
+	if sql != create {
+		t.Errorf("table SQL = %q, want %q", sql, create)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("rows mismatch (-want +got):\n%s", diff)
+	}
+	if err := sw.insert(1.5); err == nil {
+		t.Error("insert of a float did not fail")
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rowLimiter limits the rate at which workers write rows, so that bulk
+// mutations such as purge don't take the capacity that serving traffic
+// needs. The workers share it and wait for it before each batch.
+type rowLimiter struct {
+	interval time.Duration // between rows
+
+	mu   sync.Mutex
+	next time.Time // when the next row may be written
+}
+
+// newRowLimiter returns a limiter allowing perSecond rows a second, or nil,
+// which doesn't limit, if perSecond is not positive. Rates above one row a
+// nanosecond are treated as one row a nanosecond.
+func newRowLimiter(perSecond float64) *rowLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	interval := time.Duration(float64(time.Second) / perSecond)
+	if interval < 1 {
+		interval = 1
+	}
+	return &rowLimiter{interval: interval}
+}
+
+// wait blocks until n more rows may be written, or ctx is done.
+func (l *rowLimiter) wait(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	start := l.next
+	l.next = l.next.Add(time.Duration(n) * l.interval)
+	l.mu.Unlock()
+
+	d := time.Until(start)
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// batchSize returns the number of rows to write at a time, at most max, so
+// that each batch is no more than a second of writes at the limit.
+func (l *rowLimiter) batchSize(max int) int {
+	if l == nil {
+		return max
+	}
+	if n := int(time.Second / l.interval); n < max {
+		if n < 1 {
+			return 1
+		}
+		return n
+	}
+	return max
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRowLimiter(t *testing.T) {
+	var unlimited *rowLimiter
+	if l := newRowLimiter(0); l != nil {
+		t.Errorf("newRowLimiter(0) = %v, want nil", l)
+	}
+	if err := unlimited.wait(context.Background(), 1000000); err != nil {
+		t.Fatal(err)
+	}
+	if got := unlimited.batchSize(100); got != 100 {
+		t.Errorf("unlimited batchSize(100) = %d, want 100", got)
+	}
+
+	l := newRowLimiter(1000)
+	start := time.Now()
+	// The first batch is written at once; the others wait 50ms each.
+	for i := 0; i < 5; i++ {
+		if err := l.wait(context.Background(), 50); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := time.Since(start), 200*time.Millisecond; got < want {
+		t.Errorf("5 batches of 50 rows at 1000 rows/s took %v, want at least %v", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l.wait(ctx, 1000)
+	if err := l.wait(ctx, 1); err != context.Canceled {
+		t.Errorf("wait after cancel = %v, want %v", err, context.Canceled)
+	}
+
+	for _, test := range []struct {
+		rate float64
+		want int
+	}{
+		{1000, 100},
+		{50, 50},
+		{0.5, 1},
+		{2e9, 100},
+	} {
+		if got := newRowLimiter(test.rate).batchSize(100); got != test.want {
+			t.Errorf("batchSize(100) at %g rows/s = %d, want %d", test.rate, got, test.want)
+		}
+	}
+}
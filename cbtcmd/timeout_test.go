@@ -14,10 +14,11 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package cbtcmd
 
 import (
 	"context"
+	"io"
 	"testing"
 	"time"
 
@@ -48,12 +49,13 @@ func (ct *ctxTable) ReadRow(
 
 func TestTimeout(t *testing.T) {
 	ctxt := ctxTable{}
-	table = &ctxt
-	defer func() { table = nil }()
+	deps := Deps{Table: &ctxt, Stdout: io.Discard}
 
 	config := Config{Creds: "c", Project: "p", Instance: "i"}
 
-	func() { doMain(&config, []string{"count", "mytable"}) }()
+	if err := RunCommand(&config, []string{"count", "mytable"}, deps); err != nil {
+		t.Fatal(err)
+	}
 
 	_, deadlineSet := ctxt.ctx.Deadline()
 	if deadlineSet {
@@ -63,7 +65,9 @@ func TestTimeout(t *testing.T) {
 	config.Timeout = time.Duration(42e9)
 	now := time.Now()
 
-	func() { doMain(&config, []string{"count", "mytable"}) }()
+	if err := RunCommand(&config, []string{"count", "mytable"}, deps); err != nil {
+		t.Fatal(err)
+	}
 
 	deadline, deadlineSet := ctxt.ctx.Deadline()
 	if !deadlineSet {
@@ -0,0 +1,300 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// releaseURL is the release endpoint that 'cbt update' checks for the latest
+// version of cbt. It is a variable so that tests can point it at a fake server.
+var releaseURL = "https://api.github.com/repos/googleapis/cloud-bigtable-cbt-cli/releases/latest"
+
+// checksumsAsset is the release asset that lists the SHA-256 checksum of each
+// binary, in the format of sha256sum.
+const checksumsAsset = "SHA256SUMS"
+
+// updateTimeout bounds each request that 'cbt update' makes.
+const updateTimeout = 5 * time.Minute
+
+type release struct {
+	TagName string         `json:"tag_name"`
+	HTMLURL string         `json:"html_url"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// asset returns the download URL of the named asset of r.
+func (r *release) asset(name string) (string, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL, true
+		}
+	}
+	return "", false
+}
+
+// binaryAsset returns the name of the release asset holding the cbt binary for
+// goos and goarch.
+func binaryAsset(goos, goarch string) string {
+	name := "cbt_" + goos + "_" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// fetch returns the body of u, failing on any status other than 200 OK.
+func fetch(ctx context.Context, hc *http.Client, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", cliUserAgent)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// latestRelease fetches the latest release from the release endpoint u.
+func latestRelease(ctx context.Context, hc *http.Client, u string) (*release, error) {
+	data, err := fetch(ctx, hc, u)
+	if err != nil {
+		return nil, err
+	}
+	var r release
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parsing the release: %v", err)
+	}
+	if r.TagName == "" {
+		return nil, fmt.Errorf("the release has no version")
+	}
+	return &r, nil
+}
+
+// parseVersion parses a version like "v1.2.3" or "1.2" into its numeric parts.
+// Anything after a "-" or "+" (a pre-release or build suffix) is ignored.
+func parseVersion(v string) ([]int, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	if v == "" {
+		return nil, false
+	}
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// compareVersions returns -1, 0 or 1 as version a is older than, the same as
+// or newer than version b. ok is false if either version can't be parsed.
+func compareVersions(a, b string) (cmp int, ok bool) {
+	pa, ok := parseVersion(a)
+	if !ok {
+		return 0, false
+	}
+	pb, ok := parseVersion(b)
+	if !ok {
+		return 0, false
+	}
+	for len(pa) < len(pb) {
+		pa = append(pa, 0)
+	}
+	for len(pb) < len(pa) {
+		pb = append(pb, 0)
+	}
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1, true
+		case pa[i] > pb[i]:
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+// parseChecksums parses the output of sha256sum into a map from file name to
+// hex-encoded checksum.
+func parseChecksums(data []byte) map[string]string {
+	sums := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// sha256sum marks files read in binary mode with a "*".
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+// downloadBinary downloads the binary asset of r for goos and goarch, and
+// verifies it against the release's checksums.
+func downloadBinary(ctx context.Context, hc *http.Client, r *release, goos, goarch string) ([]byte, error) {
+	name := binaryAsset(goos, goarch)
+	binURL, ok := r.asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no binary for %s/%s", r.TagName, goos, goarch)
+	}
+	sumsURL, ok := r.asset(checksumsAsset)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s, so the download can't be verified", r.TagName, checksumsAsset)
+	}
+	sums, err := fetch(ctx, hc, sumsURL)
+	if err != nil {
+		return nil, err
+	}
+	want, ok := parseChecksums(sums)[name]
+	if !ok {
+		return nil, fmt.Errorf("%s of release %s has no checksum for %s", checksumsAsset, r.TagName, name)
+	}
+	data, err := fetch(ctx, hc, binURL)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if got := fmt.Sprintf("%x", sum); got != want {
+		return nil, fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, want)
+	}
+	return data, nil
+}
+
+// replaceExecutable atomically replaces the file at path with data, keeping the
+// file's permissions. The new binary is written next to the old one so that
+// the final rename doesn't cross file systems.
+func replaceExecutable(path string, data []byte) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".cbt-update-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, fi.Mode().Perm()); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		// Windows doesn't allow replacing a running executable, but does allow
+		// renaming it.
+		old := path + ".old"
+		os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp, path)
+}
+
+func doUpdate(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt update [-check-only]"
+	checkOnly := false
+	for _, arg := range args {
+		switch arg {
+		case "-check-only", "--check-only":
+			checkOnly = true
+		default:
+			return errors.New(usage)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+	hc := http.DefaultClient
+	r, err := latestRelease(ctx, hc, releaseURL)
+	if err != nil {
+		return fmt.Errorf("Checking for the latest release: %v", err)
+	}
+	cmp, ok := compareVersions(version, r.TagName)
+	switch {
+	case !ok:
+		fmt.Fprintf(e.stdout, "The latest cbt version is %s, but the version of this build (%s) is unknown.\n", r.TagName, version)
+		if !checkOnly {
+			return errors.New("Refusing to replace a build of unknown version; install the release manually")
+		}
+		return nil
+	case cmp >= 0:
+		fmt.Fprintf(e.stdout, "cbt %s is up to date.\n", version)
+		return nil
+	}
+	fmt.Fprintf(e.stdout, "A newer version of cbt is available: %s (this is %s).\n", r.TagName, version)
+	if r.HTMLURL != "" {
+		fmt.Fprintf(e.stdout, "Release notes: %s\n", r.HTMLURL)
+	}
+	if checkOnly {
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("Finding the cbt binary: %v", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("Finding the cbt binary: %v", err)
+	}
+	data, err := downloadBinary(ctx, hc, r, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return fmt.Errorf("Downloading cbt %s: %v", r.TagName, err)
+	}
+	if err := replaceExecutable(exe, data); err != nil {
+		return fmt.Errorf("Replacing %s: %v", exe, err)
+	}
+	fmt.Fprintf(e.stdout, "Updated %s to cbt %s.\n", exe, r.TagName)
+	return nil
+}
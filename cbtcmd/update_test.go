@@ -0,0 +1,148 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	for _, test := range []struct {
+		a, b   string
+		want   int
+		wantOK bool
+	}{
+		{"1.2.3", "v1.2.3", 0, true},
+		{"v1.2", "1.2.0", 0, true},
+		{"1.2.3", "1.10.0", -1, true},
+		{"2.0.0", "1.99.99", 1, true},
+		{"1.2.3-rc1", "1.2.3", 0, true},
+		{"<unknown version>", "1.0.0", 0, false},
+		{"1.0.0", "", 0, false},
+	} {
+		got, ok := compareVersions(test.a, test.b)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("compareVersions(%q, %q) = %d, %v; want %d, %v", test.a, test.b, got, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	got := parseChecksums([]byte("ABC123  cbt_linux_amd64\ndef456 *cbt_windows_amd64.exe\n\nmalformed\n"))
+	want := map[string]string{"cbt_linux_amd64": "abc123", "cbt_windows_amd64.exe": "def456"}
+	if len(got) != len(want) {
+		t.Fatalf("parseChecksums = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("parseChecksums[%q] = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+// newReleaseServer serves a release whose linux/amd64 binary is bin and whose
+// checksums list sum for it.
+func newReleaseServer(t *testing.T, bin []byte, sum string) *httptest.Server {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"tag_name": "v1.5.0", "html_url": "%[1]s/v1.5.0", "assets": [
+			{"name": "cbt_linux_amd64", "browser_download_url": "%[1]s/cbt_linux_amd64"},
+			{"name": "SHA256SUMS", "browser_download_url": "%[1]s/SHA256SUMS"}]}`, srv.URL)
+	})
+	mux.HandleFunc("/cbt_linux_amd64", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bin)
+	})
+	mux.HandleFunc("/SHA256SUMS", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  cbt_linux_amd64\n", sum)
+	})
+	return srv
+}
+
+func TestDownloadBinary(t *testing.T) {
+	ctx := context.Background()
+	bin := []byte("new cbt binary")
+	sum := sha256.Sum256(bin)
+
+	srv := newReleaseServer(t, bin, fmt.Sprintf("%x", sum))
+	r, err := latestRelease(ctx, srv.Client(), srv.URL+"/latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.TagName != "v1.5.0" {
+		t.Errorf("TagName = %q, want v1.5.0", r.TagName)
+	}
+	got, err := downloadBinary(ctx, srv.Client(), r, "linux", "amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(bin) {
+		t.Errorf("downloadBinary = %q, want %q", got, bin)
+	}
+	if _, err := downloadBinary(ctx, srv.Client(), r, "darwin", "arm64"); err == nil {
+		t.Error("downloadBinary of a missing platform succeeded")
+	}
+
+	bad := newReleaseServer(t, bin, strings.Repeat("0", 64))
+	r, err = latestRelease(ctx, bad.Client(), bad.URL+"/latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := downloadBinary(ctx, bad.Client(), r, "linux", "amd64"); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("downloadBinary with a bad checksum: got %v, want a checksum mismatch", err)
+	}
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cbt")
+	if err := os.WriteFile(path, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := replaceExecutable(path, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "new" {
+		t.Errorf("contents = %q, want %q", got, "new")
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0755 {
+		t.Errorf("mode = %v, want 0755", fi.Mode().Perm())
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want only the binary", len(entries))
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2022 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"cloud.google.com/go/cbt/cli"
+)
+
+// chdirCLI changes to the cli directory for the rest of the test, since the
+// format files in cli/testdata name their protocol buffer paths relative to it.
+func chdirCLI(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(filepath.Join("..", "cli")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestJSONAndYAML(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	chdirCLI(t)
+	e.formatting = cli.NewValueFormatting()
+	err := e.formatting.Setup(filepath.Join("testdata", "cat.yml"))
+	if err != nil {
+		t.Errorf("Error loading YAML:\n%v", err)
+	}
+
+	row := bigtable.Row{
+		"f1": {
+			bigtable.ReadItem{
+				Row:    "r1",
+				Column: "f1:json",
+				Value:  []byte("{\"name\": \"Brave\", \"age\": 2}"),
+			},
+		},
+	}
+	var out bytes.Buffer
+
+	e.printRow(row, &out)
+	got := out.String()
+	want := ("----------------------------------------\n" +
+		"r1\n" +
+		"  f1:json\n" +
+		"    age:     2.00\n" +
+		"    name:   \"Brave\"")
+
+	timestampsRE := regexp.MustCompile("[ ]+@ [^ \t\n]+")
+	got = string(timestampsRE.ReplaceAll([]byte(got), []byte("")))
+
+	if !strings.Contains(got, want) {
+		t.Errorf("Formatting printed incorrectly: wanted\n%v\n,\ngot\n%v\n", want, got)
+	}
+}
+
+func TestProtobufferAndYAML(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	chdirCLI(t)
+	e.formatting = cli.NewValueFormatting()
+	e.formatting.Setup(filepath.Join("testdata", "cat.yml"))
+
+	row := bigtable.Row{
+		"f1": {
+			bigtable.ReadItem{
+				Row:    "r1",
+				Column: "f1:cat",
+				Value:  []byte("\n\x05Brave\x10\x02"),
+			},
+		},
+	}
+	var out bytes.Buffer
+
+	e.printRow(row, &out)
+	got := out.String()
+	want := ("----------------------------------------\n" +
+		"r1\n" +
+		"  f1:cat\n" +
+		"    name: \"Brave\"\n" +
+		"    age: 2")
+
+	timestampsRE := regexp.MustCompile("[ ]+@ [^ \t\n]+")
+
+	stripTimestamps := func(s string) string {
+		return string(timestampsRE.ReplaceAll([]byte(s), []byte("")))
+	}
+	got = stripTimestamps(got)
+
+	if !strings.Contains(got, want) {
+		t.Errorf("Formatting printed incorrectly: wanted\n%s\n,\ngot\n%s", want, got)
+	}
+}
+
+func TestPrintRow(t *testing.T) {
+	e, _ := newTestEnv(Deps{})
+	row := bigtable.Row{
+		"f1": {
+			bigtable.ReadItem{
+				Row:    "r1",
+				Column: "f1:c1",
+				Value:  []byte("Hello!"),
+			},
+			bigtable.ReadItem{
+				Row:    "r1",
+				Column: "f1:c2",
+				Value:  []byte{1, 2},
+			},
+		},
+		"f2": {
+			bigtable.ReadItem{
+				Row:    "r1",
+				Column: "f2:person",
+				Value: []byte("\n\x03Jim\x10*\x1a\x0fjim@example.com\"" +
+					"\x0c\n\x08555-1212\x10\x01"),
+			},
+		},
+	}
+
+	var out bytes.Buffer
+
+	e.printRow(row, &out)
+	got := out.String()
+	want :=
+		"----------------------------------------\n" +
+			"r1\n" +
+			"  f1:c1\n" +
+			"    \"Hello!\"\n" +
+			"  f1:c2\n" +
+			"    \"\\x01\\x02\"\n" +
+			"  f2:person\n" +
+			"    \"\\n\\x03Jim\\x10*\\x1a\\x0fjim@example.com\\\"\\f\\n\\b555-1212\\x10\\x01\"\n" +
+			""
+
+	timestampsRE := regexp.MustCompile("[ ]+@ [^ \t\n]+")
+
+	stripTimestamps := func(s string) string {
+		return string(timestampsRE.ReplaceAll([]byte(s), []byte("")))
+	}
+	got = stripTimestamps(got)
+
+	if got != want {
+		t.Errorf("Formatting printed incorrectly:\nwanted\n%s,\ngot\n%s",
+			want, got)
+	}
+
+	e.formatting = cli.NewValueFormatting()
+	e.formatting.Settings.ProtocolBufferDefinitions =
+		[]string{filepath.Join("..", "cli", "testdata", "addressbook.proto")}
+	e.formatting.Settings.Columns["c2"] =
+		cli.ValueFormatColumn{Encoding: "Binary", Type: "int16"}
+	e.formatting.Settings.Columns["person"] =
+		cli.ValueFormatColumn{Encoding: "ProtocolBuffer"}
+	e.formatting.Setup("")
+
+	want = ("----------------------------------------\n" +
+		"r1\n" +
+		"  f1:c1\n" +
+		"    \"Hello!\"\n" +
+		"  f1:c2\n" +
+		"    258\n" +
+		"  f2:person\n" +
+		"    name: \"Jim\"\n" +
+		"    id: 42\n" +
+		"    email: \"jim@example.com\"\n" +
+		"    phones: <\n" +
+		"      number: \"555-1212\"\n" +
+		"      type: HOME\n" +
+		"    >\n" +
+		"")
+
+	var out2 bytes.Buffer
+	e.printRow(row, &out2)
+	got = stripTimestamps(out2.String())
+	if got != want {
+		t.Errorf("Formatting printed incorrectly: wanted %s, got %s", want, got)
+	}
+}
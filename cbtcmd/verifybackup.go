@@ -0,0 +1,118 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// tableChecksum returns the checksum of all the rows of tbl, read in up to
+// workers concurrent ranges.
+func tableChecksum(ctx context.Context, logger *log.Logger, tbl TableReader, workers int) (rangeChecksum, error) {
+	bounds, err := checksumBounds(ctx, tbl, "", "", nil, workers)
+	if err != nil {
+		return rangeChecksum{}, err
+	}
+	sums, err := checksumRanges(ctx, logger, tbl, bounds, workers, nil)
+	if err != nil {
+		return rangeChecksum{}, err
+	}
+	return combineChecksums(sums), nil
+}
+
+// verifyRestoredTable compares the row count and checksum of the restored
+// table with those of the table it's verified against, logging the retried
+// reads to logger.
+func verifyRestoredTable(ctx context.Context, logger *log.Logger, restored, against TableReader, workers int) (got, want rangeChecksum, err error) {
+	if got, err = tableChecksum(ctx, logger, restored, workers); err != nil {
+		return got, want, fmt.Errorf("reading the restored table: %v", err)
+	}
+	if want, err = tableChecksum(ctx, logger, against, workers); err != nil {
+		return got, want, fmt.Errorf("reading the table to verify against: %v", err)
+	}
+	return got, want, nil
+}
+
+func doVerifyBackup(ctx context.Context, e *env, args ...string) error {
+	usage := "usage: cbt verifybackup <cluster> <backup> [against=<table-id>] [workers=<1>]"
+	if len(args) < 2 {
+		return errors.New(usage)
+	}
+	cluster, backup := args[0], args[1]
+	parsed, err := parseArgs(args[2:], []string{"against", "workers"})
+	if err != nil {
+		return err
+	}
+	workers := 1
+	if v := parsed["workers"]; v != "" {
+		if workers, err = strconv.Atoi(v); err != nil || workers <= 0 {
+			return errors.New("workers must be > 0")
+		}
+	}
+	ac, err := e.getAdminClient()
+	if err != nil {
+		return err
+	}
+	c, err := e.getClient(bigtable.ClientConfig{})
+	if err != nil {
+		return err
+	}
+	against := parsed["against"]
+	if against == "" {
+		bi, err := ac.BackupInfo(ctx, cluster, backup)
+		if err != nil {
+			return fmt.Errorf("Getting backup info: %v", err)
+		}
+		against = bi.SourceTable
+		fmt.Fprintf(e.stdout, "Verifying against the source table %s; rows written since the backup was taken will show up as a mismatch.\n", against)
+	}
+
+	tmp := fmt.Sprintf("cbt-verify-%s-%d", backup, time.Now().Unix())
+	if len(tmp) > 50 {
+		tmp = fmt.Sprintf("cbt-verify-%d", time.Now().UnixNano())
+	}
+	fmt.Fprintf(e.stdout, "Restoring backup %s to the temporary table %s...\n", backup, tmp)
+	err = withHeartbeat(ctx, e, "restoring backup "+backup, nil, func() error {
+		return ac.RestoreTable(ctx, tmp, cluster, backup)
+	})
+	if err != nil {
+		return fmt.Errorf("Restoring the backup: %v", err)
+	}
+	got, want, verr := verifyRestoredTable(ctx, e.log, c.Open(tmp), c.Open(against), workers)
+	// Always drop the temporary table, even when reading it failed.
+	if err := ac.DeleteTable(ctx, tmp); err != nil {
+		e.log.Printf("Deleting the temporary table %s: %v", tmp, err)
+	}
+	if verr != nil {
+		return verr
+	}
+
+	fmt.Fprintf(e.stdout, "Backup %s: %d rows, digest %016x\n", backup, got.Rows, got.Digest)
+	fmt.Fprintf(e.stdout, "Table %s: %d rows, digest %016x\n", against, want.Rows, want.Digest)
+	if got.Rows != want.Rows || got.Digest != want.Digest {
+		return fmt.Errorf("Backup %s does not match table %s", backup, against)
+	}
+	fmt.Fprintln(e.stdout, "OK: the backup restores to the same data.")
+	return nil
+}
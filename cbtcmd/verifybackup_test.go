@@ -0,0 +1,59 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+)
+
+func TestVerifyRestoredTable(t *testing.T) {
+	ctx, c := setupEmulator(t, []string{"restored", "source"}, []string{"f"})
+	restored, source := c.Open("restored"), c.Open("source")
+	for i := 0; i < 20; i++ {
+		mut := bigtable.NewMutation()
+		mut.Set("f", "a", 1000, []byte(fmt.Sprint(i)))
+		for _, tbl := range []*bigtable.Table{restored, source} {
+			if err := tbl.Apply(ctx, fmt.Sprintf("r%02d", i), mut); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	got, want, err := verifyRestoredTable(ctx, discardLog, restored, source, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want || got.Rows != 20 {
+		t.Errorf("verifyRestoredTable = %+v, %+v; want equal checksums of 20 rows", got, want)
+	}
+
+	// A row written to the source after the backup makes them differ.
+	mut := bigtable.NewMutation()
+	mut.Set("f", "a", 2000, []byte("new"))
+	if err := source.Apply(ctx, "r99", mut); err != nil {
+		t.Fatal(err)
+	}
+	got, want, err = verifyRestoredTable(ctx, discardLog, restored, source, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Rows != 20 || want.Rows != 21 || got.Digest == want.Digest {
+		t.Errorf("verifyRestoredTable after a write = %+v, %+v; want different checksums", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// workloadTagHeader is the request metadata that carries -workload-tag.
+const workloadTagHeader = "x-cbt-workload-tag"
+
+// validWorkloadTag matches the workload tags that can go in a user agent and
+// request metadata unchanged.
+var validWorkloadTag = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// workloadUserAgent returns the user agent ua tagged with a workload.
+func workloadUserAgent(ua, tag string) (string, error) {
+	if !validWorkloadTag.MatchString(tag) {
+		return "", fmt.Errorf("bad -workload-tag %q: use up to 64 letters, digits, '.', '_' or '-'", tag)
+	}
+	return ua + " workload/" + tag, nil
+}
+
+// workloadTagOpts adds options that send the -workload-tag, if there is one,
+// as metadata with every request.
+func workloadTagOpts(cfg *Config, opts []option.ClientOption) []option.ClientOption {
+	tag := cfg.WorkloadTag
+	if tag == "" {
+		return opts
+	}
+	unary := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		return invoker(metadata.AppendToOutgoingContext(ctx, workloadTagHeader, tag), method, req, reply, cc, callOpts...)
+	}
+	stream := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(metadata.AppendToOutgoingContext(ctx, workloadTagHeader, tag), desc, cc, method, callOpts...)
+	}
+	return append(opts,
+		option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(unary)),
+		option.WithGRPCDialOption(grpc.WithChainStreamInterceptor(stream)))
+}
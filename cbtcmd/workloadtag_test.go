@@ -0,0 +1,72 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cbtcmd
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"cloud.google.com/go/bigtable/bttest"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestWorkloadUserAgent(t *testing.T) {
+	got, err := workloadUserAgent("cbt-cli-go/1.0", "nightly-etl")
+	if err != nil || got != "cbt-cli-go/1.0 workload/nightly-etl" {
+		t.Errorf("workloadUserAgent = %q, %v", got, err)
+	}
+	for _, tag := range []string{"two words", "a/b", ""} {
+		if _, err := workloadUserAgent("cbt", tag); err == nil {
+			t.Errorf("workloadUserAgent(%q) succeeded, want an error", tag)
+		}
+	}
+}
+
+func TestWorkloadTagOpts(t *testing.T) {
+	// Record the metadata the emulator receives.
+	var got []string
+	srv, err := bttest.NewServer("localhost:0", grpc.ChainUnaryInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			got = append(got, md.Get(workloadTagHeader)...)
+			return handler(ctx, req)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	opts := workloadTagOpts(&Config{WorkloadTag: "nightly-etl"}, []option.ClientOption{
+		option.WithEndpoint(srv.Addr),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	})
+	ctx := context.Background()
+	ac, err := bigtable.NewAdminClient(ctx, "proj", "instance", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.CreateTable(ctx, "my-table"); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "nightly-etl" {
+		t.Errorf("the emulator received %s metadata %q, want [nightly-etl]", workloadTagHeader, got)
+	}
+}
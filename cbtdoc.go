@@ -14,7 +14,7 @@
 
 // DO NOT EDIT. THIS IS AUTOMATICALLY GENERATED.
 // Run "go generate" to regenerate.
-//go:generate go run cbt.go gcpolicy.go cbtconfig.go valueformatting.go -o cbtdoc.go doc
+//go:generate go run . -o cbtdoc.go doc
 
 /*
 The `cbt` CLI is a command-line interface that lets you interact with Cloud Bigtable.
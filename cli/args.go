@@ -0,0 +1,42 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ParseArgs takes a slice of arguments of the form key=value and returns a map from
+// key to value. It returns an error if an argument is malformed or a key is not in
+// the valid slice.
+func ParseArgs(args []string, valid []string) (map[string]string, error) {
+	parsed := make(map[string]string)
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("bad arg %q", arg)
+		}
+		key, val := arg[:i], arg[i+1:]
+		if !slices.Contains(valid, key) {
+			return nil, fmt.Errorf("unknown arg key %q", key)
+		}
+		parsed[key] = val
+	}
+	return parsed, nil
+}
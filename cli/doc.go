@@ -0,0 +1,24 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cli holds the parts of the cbt command-line tool that are useful to
+// programs embedding it: the parsers for key=value arguments, column lists and
+// GC policies, and the value formatting that read and lookup apply to cells
+// with a format-file.
+//
+// The API follows the cbt command-line syntax, which is stable across
+// releases.
+package cli
@@ -0,0 +1,70 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// ParseColumnsFilter returns the filter for a comma-separated list of columns,
+// as given to the columns= argument of read and lookup. Each column is
+// family:qualifier, qualifier (any family), or family: (all columns of the
+// family); the filters for several columns are interleaved.
+func ParseColumnsFilter(columns string) (bigtable.Filter, error) {
+	splitColumns := strings.FieldsFunc(columns, func(c rune) bool { return c == ',' })
+	if len(splitColumns) == 1 {
+		filter, err := ParseColumnFilter(splitColumns[0])
+		if err != nil {
+			return nil, err
+		}
+		return filter, nil
+	}
+
+	var columnFilters []bigtable.Filter
+	for _, column := range splitColumns {
+		filter, err := ParseColumnFilter(column)
+		if err != nil {
+			return nil, err
+		}
+		columnFilters = append(columnFilters, filter)
+	}
+	return bigtable.InterleaveFilters(columnFilters...), nil
+}
+
+// ParseColumnFilter returns the filter for a single column in the format
+// accepted by ParseColumnsFilter.
+func ParseColumnFilter(column string) (bigtable.Filter, error) {
+	splitColumn := strings.Split(column, ":")
+	if len(splitColumn) == 1 {
+		return bigtable.ColumnFilter(splitColumn[0]), nil
+	} else if len(splitColumn) == 2 {
+		if strings.HasSuffix(column, ":") {
+			return bigtable.FamilyFilter(splitColumn[0]), nil
+		} else if strings.HasPrefix(column, ":") {
+			return bigtable.ColumnFilter(splitColumn[1]), nil
+		} else {
+			familyFilter := bigtable.FamilyFilter(splitColumn[0])
+			qualifierFilter := bigtable.ColumnFilter(splitColumn[1])
+			return bigtable.ChainFilters(familyFilter, qualifierFilter), nil
+		}
+	} else {
+		return nil, fmt.Errorf("bad format for column %q", column)
+	}
+}
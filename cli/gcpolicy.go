@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package cli
 
 import (
 	"bytes"
@@ -23,18 +23,21 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"cloud.google.com/go/bigtable"
 )
 
-// Parse a GC policy. Valid policies include
-//     never
-//     maxage = 5d
-//     maxversions = 3
-//     maxage = 5d || maxversions = 3
-//     maxage=30d || (maxage=3d && maxversions=100)
-func parseGCPolicy(s string) (bigtable.GCPolicy, error) {
+// ParseGCPolicy parses a GC policy, as given to cbt setgcpolicy and
+// createfamily. Valid policies include
+//
+//	never
+//	maxage = 5d
+//	maxversions = 3
+//	maxage = 5d || maxversions = 3
+//	maxage=30d || (maxage=3d && maxversions=100)
+func ParseGCPolicy(s string) (bigtable.GCPolicy, error) {
 	if strings.TrimSpace(s) == "never" {
 		return bigtable.NoGcPolicy(), nil
 	}
@@ -105,7 +108,7 @@ func parsePolicyTerm(r io.RuneScanner) (bigtable.GCPolicy, error) {
 			return nil, errors.New("expected a token after '='")
 		}
 		if tok == "maxage" {
-			dur, err := parseDuration(tok2)
+			dur, err := ParseDuration(tok2)
 			if err != nil {
 				return nil, err
 			}
@@ -213,3 +216,46 @@ func ungetToken(tok string) {
 	}
 	ungotToken = tok
 }
+
+// ParseDuration parses a duration string.
+// It is similar to Go's time.ParseDuration, except with a different set of supported units,
+// and only simple formats supported: a number followed by one of ms, s, m, h
+// or d, e.g. 30d.
+func ParseDuration(s string) (time.Duration, error) {
+	// [0-9]+[a-z]+
+
+	// Split [0-9]+ from [a-z]+.
+	i := 0
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			break
+		}
+	}
+	ds, u := s[:i], s[i:]
+	if ds == "" || u == "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	// Parse them.
+	d, err := strconv.ParseUint(ds, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %v", s, err)
+	}
+	unit, ok := unitMap[u]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q in duration %q", u, s)
+	}
+	if d > uint64((1<<63-1)/unit) {
+		// overflow
+		return 0, fmt.Errorf("invalid duration %q overflows", s)
+	}
+	return time.Duration(d) * unit, nil
+}
+
+var unitMap = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+}
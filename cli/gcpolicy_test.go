@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package cli
 
 import (
 	"strings"
@@ -90,7 +90,7 @@ func TestParseGCPolicy(t *testing.T) {
 				bigtable.MaxAgePolicy(3*time.Hour)),
 		},
 	} {
-		got, err := parseGCPolicy(test.in)
+		got, err := ParseGCPolicy(test.in)
 		if err != nil {
 			t.Errorf("%s: %v", test.in, err)
 			continue
@@ -118,7 +118,7 @@ func TestParseGCPolicyErrors(t *testing.T) {
 		"maxage=30d || ((maxage=3d && maxversions=100)",
 		"maxversions = 3 and",
 	} {
-		_, err := parseGCPolicy(in)
+		_, err := ParseGCPolicy(in)
 		if err == nil {
 			t.Errorf("%s: got nil, want error", in)
 		}
@@ -194,3 +194,42 @@ func tokenizeGCPolicy(s string) ([]string, error) {
 	}
 	return tokens, nil
 }
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in string
+		// out or fail are mutually exclusive
+		out  time.Duration
+		fail bool
+	}{
+		{in: "10ms", out: 10 * time.Millisecond},
+		{in: "3s", out: 3 * time.Second},
+		{in: "60m", out: 60 * time.Minute},
+		{in: "12h", out: 12 * time.Hour},
+		{in: "7d", out: 168 * time.Hour},
+
+		{in: "", fail: true},
+		{in: "0", fail: true},
+		{in: "7ns", fail: true},
+		{in: "14mo", fail: true},
+		{in: "3.5h", fail: true},
+		{in: "106752d", fail: true}, // overflow
+	}
+	for _, tc := range tests {
+		got, err := ParseDuration(tc.in)
+		if !tc.fail && err != nil {
+			t.Errorf("ParseDuration(%q) unexpectedly failed: %v", tc.in, err)
+			continue
+		}
+		if tc.fail && err == nil {
+			t.Errorf("ParseDuration(%q) did not fail", tc.in)
+			continue
+		}
+		if tc.fail {
+			continue
+		}
+		if got != tc.out {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tc.in, got, tc.out)
+		}
+	}
+}
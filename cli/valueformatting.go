@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package cli
 
 import (
 	"bytes"
@@ -25,57 +25,81 @@ import (
 	"sort"
 	"strings"
 
+	"cloud.google.com/go/bigtable"
 	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/desc/protoparse"
 	"github.com/jhump/protoreflect/dynamic"
 	"gopkg.in/yaml.v2"
 )
 
-type valueFormatColumn struct {
+// ValueFormatColumn is the encoding and type of a column in a format file.
+type ValueFormatColumn struct {
 	Encoding string
 	Type     string
 }
 
-type valueFormatFamily struct {
+// ValueFormatFamily is the default encoding and type of a family in a format
+// file, and the settings of its columns.
+type ValueFormatFamily struct {
 	DefaultEncoding string `yaml:"default_encoding"`
 	DefaultType     string `yaml:"default_type"`
-	Columns         map[string]valueFormatColumn
+	Columns         map[string]ValueFormatColumn
 }
 
-func newValueFormatFamily() valueFormatFamily { // for tests :)
-	family := valueFormatFamily{}
-	family.Columns = make(map[string]valueFormatColumn)
+func newValueFormatFamily() ValueFormatFamily { // for tests :)
+	family := ValueFormatFamily{}
+	family.Columns = make(map[string]ValueFormatColumn)
 	return family
 }
 
-type valueFormatSettings struct {
+// ValueFormatSettings is the content of a format file.
+type ValueFormatSettings struct {
 	ProtocolBufferDefinitions []string `yaml:"protocol_buffer_definitions"`
 	ProtocolBufferPaths       []string `yaml:"protocol_buffer_paths"`
 	DefaultEncoding           string   `yaml:"default_encoding"`
 	DefaultType               string   `yaml:"default_type"`
-	Columns                   map[string]valueFormatColumn
-	Families                  map[string]valueFormatFamily
+	Columns                   map[string]ValueFormatColumn
+	Families                  map[string]ValueFormatFamily
+	Display                   []string
+	Hide                      []string
 }
 
 type valueFormatter func([]byte) (string, error)
 
-type valueFormatting struct {
-	settings       valueFormatSettings
+// AggregateDecoder decodes the cells of an aggregate family, such as an
+// intsum family.
+type AggregateDecoder interface {
+	// Decode returns the current value of an aggregate cell.
+	Decode(v []byte) (string, error)
+	// String describes the aggregate, e.g. "sum of int64".
+	String() string
+}
+
+// ValueFormatting formats cell values as the settings of a format file
+// direct: as integers, floats, JSON or protocol buffer messages, in hex or as
+// quoted strings. Use NewValueFormatting to make one, then Setup.
+type ValueFormatting struct {
+	Settings       ValueFormatSettings
 	pbMessageTypes map[string]*desc.MessageDescriptor
 	formatters     map[[2]string]valueFormatter
+	// Aggregates are the aggregate families whose cells are decoded, if
+	// decode-aggregates is set.
+	Aggregates map[string]AggregateDecoder
+	// cellOrder is how the cells of a row are ordered: "column" (the
+	// default), "timestamp-desc" or "timestamp-asc".
+	cellOrder string
 }
 
-func newValueFormatting() valueFormatting {
-	formatting := valueFormatting{}
-	formatting.settings.Columns = make(map[string]valueFormatColumn)
-	formatting.settings.Families = make(map[string]valueFormatFamily)
+// NewValueFormatting returns a ValueFormatting with empty settings.
+func NewValueFormatting() ValueFormatting {
+	formatting := ValueFormatting{}
+	formatting.Settings.Columns = make(map[string]ValueFormatColumn)
+	formatting.Settings.Families = make(map[string]ValueFormatFamily)
 	formatting.pbMessageTypes = make(map[string]*desc.MessageDescriptor)
 	formatting.formatters = make(map[[2]string]valueFormatter)
 	return formatting
 }
 
-var globalValueFormatting = newValueFormatting()
-
 func binaryFormatterHelper(
 	in []byte,
 	byteOrder binary.ByteOrder,
@@ -145,7 +169,7 @@ var binaryValueFormatters = map[string]binaryValueFormatter{
 	},
 }
 
-func (f *valueFormatting) binaryFormatter(
+func (f *ValueFormatting) binaryFormatter(
 	encoding validEncodings, ctype string,
 ) valueFormatter {
 	var byteOrder binary.ByteOrder
@@ -164,7 +188,7 @@ func (f *valueFormatting) binaryFormatter(
 }
 
 // jsonFormatter returns a valueFormatter function that pretty-prints JSON values.
-func (f *valueFormatting) jsonFormatter() (valueFormatter, error) {
+func (f *ValueFormatting) jsonFormatter() (valueFormatter, error) {
 	return func(in []byte) (string, error) {
 
 		var outJSON interface{}
@@ -219,7 +243,7 @@ func (f *valueFormatting) jsonFormatter() (valueFormatter, error) {
 	}, nil
 }
 
-func (f *valueFormatting) pbFormatter(ctype string) (valueFormatter, error) {
+func (f *ValueFormatting) pbFormatter(ctype string) (valueFormatter, error) {
 	md := f.pbMessageTypes[strings.ToLower(ctype)]
 
 	if md == nil {
@@ -271,7 +295,7 @@ var validValueFormattingEncodings = map[string]validEncodings{
 	"":                none,
 }
 
-func (f *valueFormatting) validateEncoding(encoding string) (validEncodings, error) {
+func (f *ValueFormatting) validateEncoding(encoding string) (validEncodings, error) {
 	validEncoding, got := validValueFormattingEncodings[strings.ToLower(encoding)]
 	if !got {
 		return 0, fmt.Errorf("invalid encoding: %s", encoding)
@@ -279,7 +303,7 @@ func (f *valueFormatting) validateEncoding(encoding string) (validEncodings, err
 	return validEncoding, nil
 }
 
-func (f *valueFormatting) validateType(
+func (f *ValueFormatting) validateType(
 	cname string, validEncoding validEncodings, encoding, ctype string,
 ) (string, error) {
 	var got bool
@@ -309,7 +333,7 @@ func (f *valueFormatting) validateType(
 	return ctype, nil
 }
 
-func (f *valueFormatting) validateFormat(
+func (f *ValueFormatting) validateFormat(
 	cname, encoding, ctype string,
 ) (validEncodings, string, error) {
 	validEncoding, err := f.validateEncoding(encoding)
@@ -320,19 +344,19 @@ func (f *valueFormatting) validateFormat(
 	return validEncoding, ctype, err
 }
 
-func (f *valueFormatting) override(old, new string) string {
+func (f *ValueFormatting) override(old, new string) string {
 	if new != "" {
 		return new
 	}
 	return old
 }
 
-func (f *valueFormatting) validateColumns() error {
-	defaultEncoding := f.settings.DefaultEncoding
-	defaultType := f.settings.DefaultType
+func (f *ValueFormatting) validateColumns() error {
+	defaultEncoding := f.Settings.DefaultEncoding
+	defaultType := f.Settings.DefaultType
 
 	var errs []string
-	for cname, col := range f.settings.Columns {
+	for cname, col := range f.Settings.Columns {
 		_, _, err := f.validateFormat(
 			cname,
 			f.override(defaultEncoding, col.Encoding),
@@ -341,7 +365,7 @@ func (f *valueFormatting) validateColumns() error {
 			errs = append(errs, fmt.Sprintf("%s: %s", cname, err))
 		}
 	}
-	for fname, fam := range f.settings.Families {
+	for fname, fam := range f.Settings.Families {
 		familyEncoding :=
 			f.override(defaultEncoding, fam.DefaultEncoding)
 		familyType := f.override(defaultType, fam.DefaultType)
@@ -364,21 +388,133 @@ func (f *valueFormatting) validateColumns() error {
 	return nil
 }
 
-func (f *valueFormatting) parse(path string) error {
+// validateColumnSelection checks that every entry in the display and hide
+// lists names a family, either as "family:qualifier" or as "family:" to match
+// every column in the family.
+func (f *ValueFormatting) validateColumnSelection() error {
+	var errs []string
+	for _, list := range [][]string{f.Settings.Display, f.Settings.Hide} {
+		for _, name := range list {
+			if i := strings.Index(name, ":"); i <= 0 {
+				errs = append(errs, fmt.Sprintf("%q: want family:qualifier or family:", name))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("bad display or hide columns:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// SetColumnSelection overrides the display and hide lists from the format
+// file with comma-separated lists given on the command line. Empty lists
+// leave the format file settings in place.
+func (f *ValueFormatting) SetColumnSelection(display, hide string) error {
+	split := func(s string) []string {
+		return strings.FieldsFunc(s, func(c rune) bool { return c == ',' })
+	}
+	if display != "" {
+		f.Settings.Display = split(display)
+	}
+	if hide != "" {
+		f.Settings.Hide = split(hide)
+	}
+	return f.validateColumnSelection()
+}
+
+// SetCellOrder sets the sort-cells order of printed cells.
+func (f *ValueFormatting) SetCellOrder(order string) error {
+	switch order {
+	case "", "column", "timestamp-desc", "timestamp-asc":
+		f.cellOrder = order
+		return nil
+	}
+	return fmt.Errorf("bad sort-cells %q: must be column, timestamp-desc or timestamp-asc", order)
+}
+
+// columnMatches reports whether a "family:qualifier" column name is matched
+// by a display or hide list entry.
+func columnMatches(entry, column string) bool {
+	if strings.HasSuffix(entry, ":") {
+		return strings.HasPrefix(column, entry)
+	}
+	return entry == column
+}
+
+// DisplayItems returns the cells of a row in the order they should be
+// printed. Without a display list, cells are ordered by family and then
+// column. With one, only the listed columns are kept, in list order. Columns
+// matching the hide list are always dropped. A timestamp cell order then
+// sorts the cells by timestamp across columns, keeping that order for ties.
+func (f *ValueFormatting) DisplayItems(r bigtable.Row) []bigtable.ReadItem {
+	items := f.selectItems(r)
+	switch f.cellOrder {
+	case "timestamp-desc":
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Timestamp > items[j].Timestamp })
+	case "timestamp-asc":
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Timestamp < items[j].Timestamp })
+	}
+	return items
+}
+
+func (f *ValueFormatting) selectItems(r bigtable.Row) []bigtable.ReadItem {
+	var fams []string
+	for fam := range r {
+		fams = append(fams, fam)
+	}
+	sort.Strings(fams)
+	var all []bigtable.ReadItem
+	for _, fam := range fams {
+		ris := r[fam]
+		sort.Stable(byColumn(ris))
+		all = append(all, ris...)
+	}
+
+	hidden := func(column string) bool {
+		for _, entry := range f.Settings.Hide {
+			if columnMatches(entry, column) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var items []bigtable.ReadItem
+	if len(f.Settings.Display) == 0 {
+		for _, ri := range all {
+			if !hidden(ri.Column) {
+				items = append(items, ri)
+			}
+		}
+		return items
+	}
+	seen := make(map[int]bool)
+	for _, entry := range f.Settings.Display {
+		for i, ri := range all {
+			if !seen[i] && columnMatches(entry, ri.Column) && !hidden(ri.Column) {
+				seen[i] = true
+				items = append(items, ri)
+			}
+		}
+	}
+	return items
+}
+
+func (f *ValueFormatting) parse(path string) error {
 	data, err := ioutil.ReadFile(path)
 	if err == nil {
-		err = yaml.UnmarshalStrict([]byte(data), &f.settings)
+		err = yaml.UnmarshalStrict([]byte(data), &f.Settings)
 	}
 	return err
 }
 
-func (f *valueFormatting) setupPBMessages() error {
-	if len(f.settings.ProtocolBufferDefinitions) > 0 {
+func (f *ValueFormatting) setupPBMessages() error {
+	if len(f.Settings.ProtocolBufferDefinitions) > 0 {
 		parser := protoparse.Parser{
-			ImportPaths: f.settings.ProtocolBufferPaths,
+			ImportPaths: f.Settings.ProtocolBufferPaths,
 		}
 		fds, err := parser.ParseFiles(
-			f.settings.ProtocolBufferDefinitions...)
+			f.Settings.ProtocolBufferDefinitions...)
 		if err != nil {
 			return err
 		}
@@ -397,7 +533,9 @@ func (f *valueFormatting) setupPBMessages() error {
 	return nil
 }
 
-func (f *valueFormatting) setup(formatFilePath string) error {
+// Setup reads the format file at formatFilePath, if set, and loads the
+// protocol buffer definitions it names.
+func (f *ValueFormatting) Setup(formatFilePath string) error {
 	var err error = nil
 
 	if formatFilePath != "" {
@@ -419,16 +557,16 @@ func (f *valueFormatting) setup(formatFilePath string) error {
 	if err != nil {
 		return err
 	}
-	return nil
+	return f.validateColumnSelection()
 }
 
-func (f *valueFormatting) colEncodingType(
+func (f *ValueFormatting) colEncodingType(
 	family, column string,
 ) (string, string) {
-	defaultEncoding := f.settings.DefaultEncoding
-	defaultType := f.settings.DefaultType
+	defaultEncoding := f.Settings.DefaultEncoding
+	defaultType := f.Settings.DefaultType
 
-	fam, got := f.settings.Families[family]
+	fam, got := f.Settings.Families[family]
 	if got {
 		familyEncoding :=
 			f.override(defaultEncoding, fam.DefaultEncoding)
@@ -440,7 +578,7 @@ func (f *valueFormatting) colEncodingType(
 		}
 		return familyEncoding, familyType
 	}
-	col, got := f.settings.Columns[column]
+	col, got := f.Settings.Columns[column]
 	if got {
 		return f.override(defaultEncoding, col.Encoding),
 			f.override(defaultType, col.Type)
@@ -448,21 +586,41 @@ func (f *valueFormatting) colEncodingType(
 	return defaultEncoding, defaultType
 }
 
-func (f *valueFormatting) badFormatter(err error) valueFormatter {
+func (f *ValueFormatting) badFormatter(err error) valueFormatter {
 	return func(in []byte) (string, error) {
 		return "", err
 	}
 }
 
-func (f *valueFormatting) hexFormatter(in []byte) (string, error) {
+func (f *ValueFormatting) hexFormatter(in []byte) (string, error) {
 	return fmt.Sprintf("% x", in), nil
 }
 
-func (f *valueFormatting) defaultFormatter(in []byte) (string, error) {
+func (f *ValueFormatting) defaultFormatter(in []byte) (string, error) {
 	return fmt.Sprintf("%q", in), nil
 }
 
-func (f *valueFormatting) format(
+// FormatValue formats a single cell value with no indentation or trailing
+// newline. Columns with no configured encoding are returned as raw text
+// rather than quoted, which is what scripts consuming the value expect.
+func (f *ValueFormatting) FormatValue(family, column string, value []byte) (string, error) {
+	if agg, ok := f.Aggregates[family]; ok {
+		return agg.Decode(value)
+	}
+	famcolumn := strings.SplitN(column, ":", 2)
+	if len(famcolumn) == 2 {
+		encoding, _ := f.colEncodingType(family, famcolumn[1])
+		if validEncoding, err := f.validateEncoding(encoding); err == nil && validEncoding == none {
+			return string(value), nil
+		}
+	}
+	formatted, err := f.Format("", family, column, value)
+	return strings.TrimSuffix(formatted, "\n"), err
+}
+
+// Format formats a cell value of column, a "family:qualifier" name, for
+// printing: each line starts with prefix, and the result ends with a newline.
+func (f *ValueFormatting) Format(
 	prefix, family, column string, value []byte,
 ) (string, error) {
 	famcolumn := strings.SplitN(column, ":", 2)
@@ -474,6 +632,13 @@ func (f *valueFormatting) format(
 	if fam != family {
 		return "", fmt.Errorf("family, %s, and column family, %s, don't match", family, fam)
 	}
+	if agg, ok := f.Aggregates[family]; ok {
+		decoded, err := agg.Decode(value)
+		if err != nil {
+			decoded = fmt.Sprintf("%q", value)
+		}
+		return prefix + decoded + "  (aggregate " + agg.String() + ")\n", nil
+	}
 	key := [2]string{family, column}
 	formatter, got := f.formatters[key]
 	if !got {
@@ -515,3 +680,9 @@ func (f *valueFormatting) format(
 	}
 	return formatted, err
 }
+
+type byColumn []bigtable.ReadItem
+
+func (b byColumn) Len() int           { return len(b) }
+func (b byColumn) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byColumn) Less(i, j int) bool { return b[i].Column < b[j].Column }
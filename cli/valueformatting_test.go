@@ -14,15 +14,13 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package cli
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
 	"testing"
@@ -35,11 +33,11 @@ import (
 )
 
 func TestParseValueFormatSettings(t *testing.T) {
-	want := valueFormatSettings{
+	want := ValueFormatSettings{
 		DefaultEncoding:           "HEX",
 		ProtocolBufferDefinitions: []string{"MyProto.proto", "MyOtherProto.proto"},
 		ProtocolBufferPaths:       []string{"mycode/stuff", "/home/user/dev/othercode/"},
-		Columns: map[string]valueFormatColumn{
+		Columns: map[string]ValueFormatColumn{
 			"col3": {
 				Encoding: "P",
 				Type:     "person",
@@ -49,11 +47,11 @@ func TestParseValueFormatSettings(t *testing.T) {
 				Type:     "hobby",
 			},
 		},
-		Families: map[string]valueFormatFamily{
+		Families: map[string]ValueFormatFamily{
 			"family1": {
 				DefaultEncoding: "BigEndian",
 				DefaultType:     "INT64",
-				Columns: map[string]valueFormatColumn{
+				Columns: map[string]ValueFormatColumn{
 					"address": {
 						Encoding: "PROTO",
 						Type:     "tutorial.Person",
@@ -62,7 +60,7 @@ func TestParseValueFormatSettings(t *testing.T) {
 			},
 
 			"family2": {
-				Columns: map[string]valueFormatColumn{
+				Columns: map[string]ValueFormatColumn{
 					"col1": {
 						Encoding: "B",
 						Type:     "INT32",
@@ -78,7 +76,7 @@ func TestParseValueFormatSettings(t *testing.T) {
 				},
 			},
 			"family3": {
-				Columns: map[string]valueFormatColumn{
+				Columns: map[string]ValueFormatColumn{
 					"proto_col": {
 						Encoding: "PROTO",
 						Type:     "MyProtoMessageType",
@@ -88,32 +86,32 @@ func TestParseValueFormatSettings(t *testing.T) {
 		},
 	}
 
-	formatting := newValueFormatting()
+	formatting := NewValueFormatting()
 
 	err := formatting.parse(filepath.Join("testdata", t.Name()+".yml"))
 	if err != nil {
 		t.Errorf("Parse error: %s", err)
 	}
-	if !cmp.Equal(formatting.settings, want) {
+	if !cmp.Equal(formatting.Settings, want) {
 		t.Error("Formatting error: formatting settings don't match return value")
 	}
 }
 
 func TestSetupPBMessages(t *testing.T) {
 
-	formatting := newValueFormatting()
+	formatting := NewValueFormatting()
 
-	formatting.settings.ProtocolBufferPaths = append(
-		formatting.settings.ProtocolBufferPaths,
+	formatting.Settings.ProtocolBufferPaths = append(
+		formatting.Settings.ProtocolBufferPaths,
 		"testdata")
-	formatting.settings.ProtocolBufferPaths = append(
-		formatting.settings.ProtocolBufferPaths,
+	formatting.Settings.ProtocolBufferPaths = append(
+		formatting.Settings.ProtocolBufferPaths,
 		filepath.Join("testdata", "protoincludes"))
-	formatting.settings.ProtocolBufferDefinitions = append(
-		formatting.settings.ProtocolBufferDefinitions,
+	formatting.Settings.ProtocolBufferDefinitions = append(
+		formatting.Settings.ProtocolBufferDefinitions,
 		"addressbook.proto")
-	formatting.settings.ProtocolBufferDefinitions = append(
-		formatting.settings.ProtocolBufferDefinitions,
+	formatting.Settings.ProtocolBufferDefinitions = append(
+		formatting.Settings.ProtocolBufferDefinitions,
 		"club.proto")
 	err := formatting.setupPBMessages()
 	if err != nil {
@@ -240,7 +238,7 @@ func TestBinaryValueFormaterFLOAT64(t *testing.T) {
 }
 
 func TestValueFormattingBinaryFormatter(t *testing.T) {
-	formatting := newValueFormatting()
+	formatting := NewValueFormatting()
 	var formatter = formatting.binaryFormatter(bigEndian, "int32")
 	s, err := formatter(TestBinaryFormaterTestData)
 	want := "[66051 67438087 -1 -100]"
@@ -268,7 +266,7 @@ func TestValueFormattingBinaryFormatter(t *testing.T) {
 }
 
 func TestValueFormattingJSONFormatter(t *testing.T) {
-	vf := newValueFormatting()
+	vf := NewValueFormatting()
 	f, err := vf.jsonFormatter()
 
 	if err != nil {
@@ -298,9 +296,9 @@ name:   "Brave"`
 }
 
 func TestValueFormattingPBFormatter(t *testing.T) {
-	formatting := newValueFormatting()
-	formatting.settings.ProtocolBufferDefinitions = append(
-		formatting.settings.ProtocolBufferDefinitions,
+	formatting := NewValueFormatting()
+	formatting.Settings.ProtocolBufferDefinitions = append(
+		formatting.Settings.ProtocolBufferDefinitions,
 		filepath.Join("testdata", "addressbook.proto"))
 	err := formatting.setupPBMessages()
 	if err != nil {
@@ -342,25 +340,25 @@ phones: <
 }
 
 func TestValueFormattingValidateColumns(t *testing.T) {
-	formatting := newValueFormatting()
+	formatting := NewValueFormatting()
 
 	// Typeless encoding:
-	formatting.settings.Columns["c1"] = valueFormatColumn{Encoding: "HEX"}
+	formatting.Settings.Columns["c1"] = ValueFormatColumn{Encoding: "HEX"}
 	err := formatting.validateColumns()
 	if err != nil {
 		t.Errorf("Error validating columns: %v", err)
 	}
 
 	// Inherit encoding:
-	formatting.settings.Columns["c1"] = valueFormatColumn{}
-	formatting.settings.DefaultEncoding = "H"
+	formatting.Settings.Columns["c1"] = ValueFormatColumn{}
+	formatting.Settings.DefaultEncoding = "H"
 	err = formatting.validateColumns()
 	if err != nil {
 		t.Errorf("Error validating columns: %v", err)
 	}
 
 	// Inherited encoding wants a type:
-	formatting.settings.DefaultEncoding = "B"
+	formatting.Settings.DefaultEncoding = "B"
 	err = formatting.validateColumns()
 	got := fmt.Sprint(err)
 	want := "bad encoding and types:\nc1: no type specified for encoding: B"
@@ -371,7 +369,7 @@ func TestValueFormattingValidateColumns(t *testing.T) {
 	}
 
 	// provide a type:
-	formatting.settings.Columns["c1"] = valueFormatColumn{Type: "INT"}
+	formatting.Settings.Columns["c1"] = ValueFormatColumn{Type: "INT"}
 	err = formatting.validateColumns()
 	got = fmt.Sprint(err)
 	want = "bad encoding and types:\nc1: invalid type: INT for encoding: B"
@@ -382,7 +380,7 @@ func TestValueFormattingValidateColumns(t *testing.T) {
 	}
 
 	// Fix the type:
-	formatting.settings.Columns["c1"] = valueFormatColumn{Type: "INT64"}
+	formatting.Settings.Columns["c1"] = ValueFormatColumn{Type: "INT64"}
 	err = formatting.validateColumns()
 	if err != nil {
 		t.Errorf("Error validating columns: %v", err)
@@ -390,8 +388,8 @@ func TestValueFormattingValidateColumns(t *testing.T) {
 
 	// Now, do a bunch of this again in a family
 	family := newValueFormatFamily()
-	formatting.settings.Families["f"] = family
-	formatting.settings.Families["f"].Columns["c2"] = valueFormatColumn{}
+	formatting.Settings.Families["f"] = family
+	formatting.Settings.Families["f"].Columns["c2"] = ValueFormatColumn{}
 	err = formatting.validateColumns()
 	got = fmt.Sprint(err)
 	want = "bad encoding and types:\nf:c2: no type specified for encoding: B"
@@ -400,8 +398,8 @@ func TestValueFormattingValidateColumns(t *testing.T) {
 		t.Errorf("Responded incorrectly to bad input:\nwanted\n%s,\ngot\n%s",
 			want, got)
 	}
-	formatting.settings.Families["f"].Columns["c2"] =
-		valueFormatColumn{Type: "int64"}
+	formatting.Settings.Families["f"].Columns["c2"] =
+		ValueFormatColumn{Type: "int64"}
 	err = formatting.validateColumns()
 	if err != nil {
 		t.Errorf("Error validating columns: %v", err)
@@ -409,7 +407,7 @@ func TestValueFormattingValidateColumns(t *testing.T) {
 
 	// Change the family encoding.  The type won't work anymore.
 	family.DefaultEncoding = "p"
-	formatting.settings.Families["f"] = family
+	formatting.Settings.Families["f"] = family
 	err = formatting.validateColumns()
 	got = fmt.Sprint(err)
 	want = "bad encoding and types:\nf:c2: invalid type: int64 for encoding: p"
@@ -420,7 +418,7 @@ func TestValueFormattingValidateColumns(t *testing.T) {
 	}
 
 	// clear the type_ to make sure we get that message:
-	formatting.settings.Families["f"].Columns["c2"] = valueFormatColumn{}
+	formatting.Settings.Families["f"].Columns["c2"] = ValueFormatColumn{}
 	err = formatting.validateColumns()
 	// we're bad here because no type was specified, so we fall
 	// back to the column name, which doesn't have a
@@ -434,7 +432,7 @@ func TestValueFormattingValidateColumns(t *testing.T) {
 	}
 
 	// Look! Multiple errors!
-	formatting.settings.Columns["c1"] = valueFormatColumn{}
+	formatting.Settings.Columns["c1"] = ValueFormatColumn{}
 	err = formatting.validateColumns()
 	got = fmt.Sprint(err)
 	want = "bad encoding and types:\n" +
@@ -447,8 +445,8 @@ func TestValueFormattingValidateColumns(t *testing.T) {
 
 	// Fix the protocol-buffer problem:
 	formatting.pbMessageTypes["address"] = &desc.MessageDescriptor{}
-	formatting.settings.Families["f"].Columns["c2"] =
-		valueFormatColumn{Type: "address"}
+	formatting.Settings.Families["f"].Columns["c2"] =
+		ValueFormatColumn{Type: "address"}
 	err = formatting.validateColumns()
 	got = fmt.Sprint(err)
 	want = "bad encoding and types:\n" +
@@ -460,8 +458,8 @@ func TestValueFormattingValidateColumns(t *testing.T) {
 }
 
 func TestValueFormattingSetup(t *testing.T) {
-	formatting := newValueFormatting()
-	err := formatting.setup(filepath.Join("testdata", t.Name()+".yml"))
+	formatting := NewValueFormatting()
+	err := formatting.Setup(filepath.Join("testdata", t.Name()+".yml"))
 	got := fmt.Sprint(err)
 	want := "bad encoding and types:\ncol1: no type specified for encoding: B"
 
@@ -472,27 +470,27 @@ func TestValueFormattingSetup(t *testing.T) {
 }
 
 func TestValueFormattingFormat(t *testing.T) {
-	formatting := newValueFormatting()
-	formatting.settings.ProtocolBufferDefinitions =
-		append(formatting.settings.ProtocolBufferDefinitions,
+	formatting := NewValueFormatting()
+	formatting.Settings.ProtocolBufferDefinitions =
+		append(formatting.Settings.ProtocolBufferDefinitions,
 			filepath.Join("testdata", "addressbook.proto"))
 	family := newValueFormatFamily()
 	family.DefaultEncoding = "Binary"
-	formatting.settings.Families["binaries"] = family
-	formatting.settings.Families["binaries"].Columns["cb"] =
-		valueFormatColumn{Type: "int16"}
-
-	formatting.settings.Columns["hexy"] =
-		valueFormatColumn{Encoding: "hex"}
-	formatting.settings.Columns["address"] =
-		valueFormatColumn{Encoding: "p", Type: "tutorial.Person"}
-	formatting.settings.Columns["person"] = valueFormatColumn{Encoding: "p"}
-	err := formatting.setup("")
+	formatting.Settings.Families["binaries"] = family
+	formatting.Settings.Families["binaries"].Columns["cb"] =
+		ValueFormatColumn{Type: "int16"}
+
+	formatting.Settings.Columns["hexy"] =
+		ValueFormatColumn{Encoding: "hex"}
+	formatting.Settings.Columns["address"] =
+		ValueFormatColumn{Encoding: "p", Type: "tutorial.Person"}
+	formatting.Settings.Columns["person"] = ValueFormatColumn{Encoding: "p"}
+	err := formatting.Setup("")
 	if err != nil {
 		t.Errorf("Error setting up formattting: %v", err)
 	}
 
-	got, err := formatting.format("", "f1", "f1:c1", []byte("Hello world!"))
+	got, err := formatting.Format("", "f1", "f1:c1", []byte("Hello world!"))
 	want := "\"Hello world!\"\n"
 
 	if err != nil {
@@ -503,7 +501,7 @@ func TestValueFormattingFormat(t *testing.T) {
 		t.Errorf("Values formatted incorrectly: wanted %s, got %s", want, got)
 	}
 
-	got, err = formatting.format("  ", "f1", "f1:hexy", []byte("Hello world!"))
+	got, err = formatting.Format("  ", "f1", "f1:hexy", []byte("Hello world!"))
 	want = "  48 65 6c 6c 6f 20 77 6f 72 6c 64 21\n"
 	if err != nil {
 		t.Errorf("Error when formatting: %v", err)
@@ -513,7 +511,7 @@ func TestValueFormattingFormat(t *testing.T) {
 		t.Errorf("Values formatted incorrectly: wanted %s, got %s", want, got)
 	}
 
-	got, err = formatting.format(
+	got, err = formatting.Format(
 		"    ", "binaries", "binaries:cb", []byte("Hello world!"))
 	want = "    [18533 27756 28448 30575 29292 25633]\n"
 
@@ -539,7 +537,7 @@ func TestValueFormattingFormat(t *testing.T) {
 	}
 
 	for _, col := range []string{"address", "person"} {
-		got, err = formatting.format("      ", "f1", "f1:"+col, in)
+		got, err = formatting.Format("      ", "f1", "f1:"+col, in)
 		if err != nil {
 			t.Errorf("Error formatting data: %v", err)
 		}
@@ -550,168 +548,103 @@ func TestValueFormattingFormat(t *testing.T) {
 	}
 }
 
-func TestJSONAndYAML(t *testing.T) {
-	globalValueFormatting = newValueFormatting()
-	err := globalValueFormatting.setup(filepath.Join("testdata", "cat.yml"))
-	if err != nil {
-		t.Errorf("Error loading YAML:\n%v", err)
-	}
-
-	row := bigtable.Row{
-		"f1": {
-			bigtable.ReadItem{
-				Row:    "r1",
-				Column: "f1:json",
-				Value:  []byte("{\"name\": \"Brave\", \"age\": 2}"),
-			},
-		},
-	}
-	var out bytes.Buffer
-
-	printRow(row, &out)
-	got := out.String()
-	want := ("----------------------------------------\n" +
-		"r1\n" +
-		"  f1:json\n" +
-		"    age:     2.00\n" +
-		"    name:   \"Brave\"")
-
-	timestampsRE := regexp.MustCompile("[ ]+@ [^ \t\n]+")
-	got = string(timestampsRE.ReplaceAll([]byte(got), []byte("")))
+func TestFormatBadColumnNames(t *testing.T) {
+	formatting := NewValueFormatting()
+	_, err := formatting.Format("", "fam", "nofamilynamecolumn", []byte("value not used"))
 
-	if !strings.Contains(got, want) {
-		t.Errorf("Formatting printed incorrectly: wanted\n%v\n,\ngot\n%v\n", want, got)
+	if err == nil {
+		t.Errorf("Formatter didn't throw error on bad column name")
 	}
 }
 
-func TestProtobufferAndYAML(t *testing.T) {
-
-	globalValueFormatting = newValueFormatting()
-	globalValueFormatting.setup(filepath.Join("testdata", "cat.yml"))
-
+func TestDisplayItems(t *testing.T) {
 	row := bigtable.Row{
 		"f1": {
-			bigtable.ReadItem{
-				Row:    "r1",
-				Column: "f1:cat",
-				Value:  []byte("\n\x05Brave\x10\x02"),
-			},
+			bigtable.ReadItem{Row: "r1", Column: "f1:b"},
+			bigtable.ReadItem{Row: "r1", Column: "f1:a"},
+		},
+		"f2": {
+			bigtable.ReadItem{Row: "r1", Column: "f2:meta"},
+			bigtable.ReadItem{Row: "r1", Column: "f2:c"},
 		},
 	}
-	var out bytes.Buffer
-
-	printRow(row, &out)
-	got := out.String()
-	want := ("----------------------------------------\n" +
-		"r1\n" +
-		"  f1:cat\n" +
-		"    name: \"Brave\"\n" +
-		"    age: 2")
-
-	timestampsRE := regexp.MustCompile("[ ]+@ [^ \t\n]+")
-
-	stripTimestamps := func(s string) string {
-		return string(timestampsRE.ReplaceAll([]byte(s), []byte("")))
-	}
-	got = stripTimestamps(got)
 
-	if !strings.Contains(got, want) {
-		t.Errorf("Formatting printed incorrectly: wanted\n%s\n,\ngot\n%s", want, got)
+	tests := []struct {
+		display, hide string
+		want          []string
+		fail          bool
+	}{
+		{want: []string{"f1:a", "f1:b", "f2:c", "f2:meta"}},
+		{hide: "f2:meta", want: []string{"f1:a", "f1:b", "f2:c"}},
+		{hide: "f1:", want: []string{"f2:c", "f2:meta"}},
+		{display: "f2:c,f1:b", want: []string{"f2:c", "f1:b"}},
+		{display: "f2:,f1:a", hide: "f2:meta", want: []string{"f2:c", "f1:a"}},
+		{display: "f1:a,f1:", want: []string{"f1:a", "f1:b"}},
+		{display: "nofamily", fail: true},
+		{hide: ":c", fail: true},
+	}
+	for _, tc := range tests {
+		f := NewValueFormatting()
+		err := f.SetColumnSelection(tc.display, tc.hide)
+		if tc.fail {
+			if err == nil {
+				t.Errorf("setColumnSelection(%q, %q) did not fail", tc.display, tc.hide)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("setColumnSelection(%q, %q) unexpectedly failed: %v", tc.display, tc.hide, err)
+			continue
+		}
+		var got []string
+		for _, ri := range f.DisplayItems(row) {
+			got = append(got, ri.Column)
+		}
+		if !cmp.Equal(got, tc.want) {
+			t.Errorf("displayItems(display=%q, hide=%q) = %v, want %v", tc.display, tc.hide, got, tc.want)
+		}
 	}
 }
 
-func TestPrintRow(t *testing.T) {
+func TestDisplayItemsCellOrder(t *testing.T) {
 	row := bigtable.Row{
 		"f1": {
-			bigtable.ReadItem{
-				Row:    "r1",
-				Column: "f1:c1",
-				Value:  []byte("Hello!"),
-			},
-			bigtable.ReadItem{
-				Row:    "r1",
-				Column: "f1:c2",
-				Value:  []byte{1, 2},
-			},
+			bigtable.ReadItem{Row: "r1", Column: "f1:a", Timestamp: 3000},
+			bigtable.ReadItem{Row: "r1", Column: "f1:a", Timestamp: 1000},
+			bigtable.ReadItem{Row: "r1", Column: "f1:b", Timestamp: 2000},
 		},
 		"f2": {
-			bigtable.ReadItem{
-				Row:    "r1",
-				Column: "f2:person",
-				Value: []byte("\n\x03Jim\x10*\x1a\x0fjim@example.com\"" +
-					"\x0c\n\x08555-1212\x10\x01"),
-			},
+			bigtable.ReadItem{Row: "r1", Column: "f2:c", Timestamp: 2000},
 		},
 	}
-
-	var out bytes.Buffer
-
-	printRow(row, &out)
-	got := out.String()
-	want :=
-		"----------------------------------------\n" +
-			"r1\n" +
-			"  f1:c1\n" +
-			"    \"Hello!\"\n" +
-			"  f1:c2\n" +
-			"    \"\\x01\\x02\"\n" +
-			"  f2:person\n" +
-			"    \"\\n\\x03Jim\\x10*\\x1a\\x0fjim@example.com\\\"\\f\\n\\b555-1212\\x10\\x01\"\n" +
-			""
-
-	timestampsRE := regexp.MustCompile("[ ]+@ [^ \t\n]+")
-
-	stripTimestamps := func(s string) string {
-		return string(timestampsRE.ReplaceAll([]byte(s), []byte("")))
-	}
-	got = stripTimestamps(got)
-
-	if got != want {
-		t.Errorf("Formatting printed incorrectly:\nwanted\n%s,\ngot\n%s",
-			want, got)
-	}
-
-	oldValueFormatting := globalValueFormatting
-	defer func() { globalValueFormatting = oldValueFormatting }()
-
-	globalValueFormatting = newValueFormatting()
-	globalValueFormatting.settings.ProtocolBufferDefinitions =
-		[]string{filepath.Join("testdata", "addressbook.proto")}
-	globalValueFormatting.settings.Columns["c2"] =
-		valueFormatColumn{Encoding: "Binary", Type: "int16"}
-	globalValueFormatting.settings.Columns["person"] =
-		valueFormatColumn{Encoding: "ProtocolBuffer"}
-	globalValueFormatting.setup("")
-
-	want = ("----------------------------------------\n" +
-		"r1\n" +
-		"  f1:c1\n" +
-		"    \"Hello!\"\n" +
-		"  f1:c2\n" +
-		"    258\n" +
-		"  f2:person\n" +
-		"    name: \"Jim\"\n" +
-		"    id: 42\n" +
-		"    email: \"jim@example.com\"\n" +
-		"    phones: <\n" +
-		"      number: \"555-1212\"\n" +
-		"      type: HOME\n" +
-		"    >\n" +
-		"")
-
-	var out2 bytes.Buffer
-	printRow(row, &out2)
-	got = stripTimestamps(out2.String())
-	if got != want {
-		t.Errorf("Formatting printed incorrectly: wanted %s, got %s", want, got)
+	cell := func(ri bigtable.ReadItem) string { return fmt.Sprintf("%s@%d", ri.Column, ri.Timestamp) }
+
+	for _, tc := range []struct {
+		order string
+		want  []string
+	}{
+		{"", []string{"f1:a@3000", "f1:a@1000", "f1:b@2000", "f2:c@2000"}},
+		{"column", []string{"f1:a@3000", "f1:a@1000", "f1:b@2000", "f2:c@2000"}},
+		// Ties keep the column order.
+		{"timestamp-desc", []string{"f1:a@3000", "f1:b@2000", "f2:c@2000", "f1:a@1000"}},
+		{"timestamp-asc", []string{"f1:a@1000", "f1:b@2000", "f2:c@2000", "f1:a@3000"}},
+	} {
+		f := NewValueFormatting()
+		if err := f.SetCellOrder(tc.order); err != nil {
+			t.Errorf("setCellOrder(%q) unexpectedly failed: %v", tc.order, err)
+			continue
+		}
+		var got []string
+		for _, ri := range f.DisplayItems(row) {
+			got = append(got, cell(ri))
+		}
+		if !cmp.Equal(got, tc.want) {
+			t.Errorf("displayItems(sort-cells=%q) = %v, want %v", tc.order, got, tc.want)
+		}
 	}
-}
-
-func TestFormatBadColumnNames(t *testing.T) {
-	globalValueFormatting = newValueFormatting()
-	_, err := globalValueFormatting.format("", "fam", "nofamilynamecolumn", []byte("value not used"))
 
-	if err == nil {
-		t.Errorf("Formatter didn't throw error on bad column name")
+	f := NewValueFormatting()
+	if err := f.SetCellOrder("newest"); err == nil {
+		t.Error(`setCellOrder("newest") did not fail`)
 	}
 }
@@ -14,13 +14,13 @@ require (
 	gopkg.in/yaml.v2 v2.4.0 // Third-party dependency; proceed with caution
 )
 
-// IMPORTANT: If this ever changes, ensure that THIRD_PARTY_NOTICES.txt is
-// updated accordingly.
+// IMPORTANT: If this ever changes, regenerate THIRD_PARTY_NOTICES.txt with
+// "go run ./internal/third_party_notices".
 require (
 	cloud.google.com/go v0.117.0 // indirect
 	cloud.google.com/go/compute/metadata v0.6.0 // indirect
 	cloud.google.com/go/iam v1.2.2 // indirect
-	cloud.google.com/go/longrunning v0.6.2 // indirect
+	cloud.google.com/go/longrunning v0.6.2
 	github.com/census-instrumentation/opencensus-proto v0.4.1 // indirect
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/cncf/xds/go v0.0.0-20240905190251-b4127c9b8d78 // indirect
@@ -61,5 +61,5 @@ require (
 	golang.org/x/sync v0.10.0 // indirect
 	golang.org/x/time v0.8.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20241118233622-e639e219e697 // indirect
-	google.golang.org/genproto/googleapis/rpc v0.0.0-20241209162323-e6fa225c2576 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20241209162323-e6fa225c2576
 )
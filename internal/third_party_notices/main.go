@@ -0,0 +1,252 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command third_party_notices generates THIRD_PARTY_NOTICES.txt from the
+// modules of the packages built into cbt, including the license text of every
+// module but cbt's own. Modules only used by tests or tools, which aren't in
+// the binary, need no notice.
+//
+// Usage, from the root of the repository:
+//
+//	go run ./internal/third_party_notices -o cbtcmd/THIRD_PARTY_NOTICES.txt
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// licenseFiles and noticeFiles are the lower-case names of the files holding
+// a module's license and its NOTICE, in order of preference.
+var (
+	licenseFiles = []string{"license", "license.txt", "license.md", "licence", "copying", "license-2.0.txt"}
+	noticeFiles  = []string{"notice", "notice.txt", "notice.md"}
+)
+
+// module is a module as reported by 'go list -json' and 'go mod download
+// -json'.
+type module struct {
+	Path    string
+	Version string
+	Main    bool
+	Dir     string
+	Replace *module
+}
+
+// pkg is a package as reported by 'go list -json'. Module is nil for the
+// standard library.
+type pkg struct {
+	ImportPath string
+	Module     *module
+}
+
+// notice is the notice of one third-party module.
+type notice struct {
+	Path, License, Text, Notice string
+}
+
+// goJSON runs a go command in dir and decodes the stream of JSON values it
+// prints into values of type T.
+func goJSON[T any](dir string, args ...string) ([]T, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go %s: %v", strings.Join(args, " "), err)
+	}
+	var vs []T
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var v T
+		if err := dec.Decode(&v); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("go %s: %v", strings.Join(args, " "), err)
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+// thirdPartyModules returns the modules other than the main one of the
+// packages built into the command in dir, sorted by path, downloading any
+// that are not in the module cache.
+func thirdPartyModules(dir string) ([]module, error) {
+	pkgs, err := goJSON[pkg](dir, "list", "-mod=readonly", "-deps", "-json=ImportPath,Module", ".")
+	if err != nil {
+		return nil, err
+	}
+	return packageModules(pkgs, func(m module) (string, error) {
+		// Download outside the module, so that its go.sum is left alone.
+		downloaded, err := goJSON[module](os.TempDir(), "mod", "download", "-json", m.Path+"@"+m.Version)
+		if err != nil {
+			return "", err
+		}
+		return downloaded[0].Dir, nil
+	})
+}
+
+// packageModules returns the modules of pkgs other than the main module and
+// the standard library, once each and sorted by path, using download to find
+// the directory of any that has none.
+func packageModules(pkgs []pkg, download func(module) (string, error)) ([]module, error) {
+	seen := map[string]bool{}
+	var mods []module
+	for _, p := range pkgs {
+		if p.Module == nil || p.Module.Main {
+			continue
+		}
+		m := *p.Module
+		if m.Replace != nil {
+			m = *m.Replace
+		}
+		if seen[m.Path] {
+			continue
+		}
+		seen[m.Path] = true
+		if m.Dir == "" {
+			dir, err := download(m)
+			if err != nil {
+				return nil, err
+			}
+			m.Dir = dir
+		}
+		mods = append(mods, m)
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Path < mods[j].Path })
+	return mods, nil
+}
+
+// readFirst returns the contents of the file in dir whose name, ignoring
+// case, comes first in names.
+func readFirst(dir string, names []string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	byName := map[string]string{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			byName[strings.ToLower(e.Name())] = e.Name()
+		}
+	}
+	for _, name := range names {
+		if file, ok := byName[name]; ok {
+			data, err := os.ReadFile(filepath.Join(dir, file))
+			return string(data), err
+		}
+	}
+	return "", os.ErrNotExist
+}
+
+// moduleNotice reads the license and NOTICE of a module.
+func moduleNotice(m module) (notice, error) {
+	text, err := readFirst(m.Dir, licenseFiles)
+	if err != nil {
+		return notice{}, fmt.Errorf("%s: no license file in %s", m.Path, m.Dir)
+	}
+	n := notice{Path: m.Path, License: licenseName(text), Text: text}
+	if text, err := readFirst(m.Dir, noticeFiles); err == nil {
+		n.Notice = text
+	}
+	return n, nil
+}
+
+// licenseName identifies a license from its text.
+func licenseName(text string) string {
+	has := func(s string) bool { return strings.Contains(text, s) }
+	switch {
+	case has("Apache License") && has("Version 2.0"):
+		return "Apache 2.0"
+	case has("Mozilla Public License") && has("2.0"):
+		return "MPL 2.0"
+	case has("Permission is hereby granted, free of charge"):
+		return "MIT"
+	case has("Permission to use, copy, modify, and/or distribute"):
+		return "ISC"
+	case has("Redistribution and use") && has("Neither the name"):
+		return "BSD 3-Clause"
+	case has("Redistribution and use"):
+		return "BSD 2-Clause"
+	}
+	return "Unknown"
+}
+
+const (
+	separator       = "--------------------------------------------------------------------------------"
+	noticeSeparator = "----------------------------------------"
+)
+
+// writeNotices writes notices in the format of THIRD_PARTY_NOTICES.txt.
+func writeNotices(w io.Writer, notices []notice) error {
+	var b strings.Builder
+	b.WriteString("NOTICES AND INFORMATION\n\n")
+	for _, n := range notices {
+		fmt.Fprintf(&b, "%s\n%s\n%s\n%s\n\n", separator, n.Path, n.License, separator)
+		b.WriteString(strings.TrimRight(n.Text, "\n") + "\n\n")
+		if n.Notice != "" {
+			fmt.Fprintf(&b, "%s\nNOTICES\n%s\n\n", noticeSeparator, noticeSeparator)
+			b.WriteString(strings.TrimRight(n.Notice, "\n") + "\n\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func main() {
+	out := flag.String("o", "cbtcmd/THIRD_PARTY_NOTICES.txt", "file to write, or - for stdout")
+	dir := flag.String("C", ".", "directory of the module to generate notices for")
+	flag.Parse()
+
+	mods, err := thirdPartyModules(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var notices []notice
+	for _, m := range mods {
+		n, err := moduleNotice(m)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if n.License == "Unknown" {
+			log.Printf("Warning: could not identify the license of %s", m.Path)
+		}
+		notices = append(notices, n)
+	}
+
+	w := os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeNotices(w, notices); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLicenseName(t *testing.T) {
+	for text, want := range map[string]string{
+		"Apache License\nVersion 2.0, January 2004":                                       "Apache 2.0",
+		"Permission is hereby granted, free of charge, to any person":                     "MIT",
+		"Redistribution and use in source and binary forms... Neither the name of Google": "BSD 3-Clause",
+		"Redistribution and use in source and binary forms":                               "BSD 2-Clause",
+		"Permission to use, copy, modify, and/or distribute this software":                "ISC",
+		"All rights reserved.": "Unknown",
+	} {
+		if got := licenseName(text); got != want {
+			t.Errorf("licenseName(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestModuleNotice(t *testing.T) {
+	dir := t.TempDir()
+	for name, text := range map[string]string{
+		"License":   "Permission is hereby granted, free of charge\n",
+		"NOTICE.md": "Copyright 2020 Someone\n",
+		"go.mod":    "module example.com/m\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n, err := moduleNotice(module{Path: "example.com/m", Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.License != "MIT" || !strings.HasPrefix(n.Text, "Permission") || n.Notice != "Copyright 2020 Someone\n" {
+		t.Errorf("moduleNotice = %+v", n)
+	}
+	if _, err := moduleNotice(module{Path: "example.com/empty", Dir: t.TempDir()}); err == nil {
+		t.Error("moduleNotice of a module without a license did not fail")
+	}
+
+	var b strings.Builder
+	if err := writeNotices(&b, []notice{n}); err != nil {
+		t.Fatal(err)
+	}
+	want := "NOTICES AND INFORMATION\n\n" +
+		separator + "\nexample.com/m\nMIT\n" + separator + "\n\n" +
+		"Permission is hereby granted, free of charge\n\n" +
+		noticeSeparator + "\nNOTICES\n" + noticeSeparator + "\n\n" +
+		"Copyright 2020 Someone\n\n"
+	if b.String() != want {
+		t.Errorf("writeNotices wrote\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestPackageModules(t *testing.T) {
+	cbt := &module{Path: "cloud.google.com/go/cbt", Main: true}
+	bigtable := &module{Path: "cloud.google.com/go/bigtable", Version: "v1.34.0", Dir: "/mod/bigtable"}
+	oauth2 := &module{Path: "golang.org/x/oauth2", Version: "v0.25.0"}
+	pkgs := []pkg{
+		{ImportPath: "fmt"},
+		{ImportPath: "cloud.google.com/go/cbt", Module: cbt},
+		{ImportPath: "cloud.google.com/go/cbt/cli", Module: cbt},
+		{ImportPath: "golang.org/x/oauth2", Module: oauth2},
+		{ImportPath: "cloud.google.com/go/bigtable", Module: bigtable},
+		{ImportPath: "cloud.google.com/go/bigtable/bttest", Module: bigtable},
+	}
+	var downloaded []string
+	mods, err := packageModules(pkgs, func(m module) (string, error) {
+		downloaded = append(downloaded, m.Path)
+		return "/mod/oauth2", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []module{
+		{Path: "cloud.google.com/go/bigtable", Version: "v1.34.0", Dir: "/mod/bigtable"},
+		{Path: "golang.org/x/oauth2", Version: "v0.25.0", Dir: "/mod/oauth2"},
+	}
+	if !reflect.DeepEqual(mods, want) {
+		t.Errorf("packageModules = %+v, want %+v", mods, want)
+	}
+	if !reflect.DeepEqual(downloaded, []string{"golang.org/x/oauth2"}) {
+		t.Errorf("downloaded %q, want only golang.org/x/oauth2", downloaded)
+	}
+}